@@ -0,0 +1,69 @@
+package moneroger
+
+import (
+	"context"
+	"time"
+
+	moneroconst "github.com/opd-ai/moneroger/const"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// autosaveInterval returns the configured wallet save period, 0 when
+// saving is disabled.
+func autosaveInterval(config util.Config) time.Duration {
+	switch {
+	case config.AutosaveInterval < 0:
+		return 0
+	case config.AutosaveInterval == 0:
+		return moneroconst.DefaultAutosaveInterval
+	}
+	return config.AutosaveInterval
+}
+
+// watchAutosave saves the open wallets at the configured interval, so a
+// crash of wallet-rpc loses at most one interval of scanning.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchAutosave(ctx context.Context) {
+	interval := autosaveInterval(m.currentConfig())
+	if interval == 0 {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(util.Jitter(interval, 0.1)):
+		}
+		m.saveWallets(ctx)
+	}
+}
+
+// saveWallets saves the open wallet of every running wallet service.
+// Failures are logged, as the next save or the wallet's own save on exit
+// may still succeed.
+func (m *Moneroger) saveWallets(ctx context.Context) {
+	m.eachWallet(func(name string, w *monerowalletrpc.WalletRPC) {
+		if w.State() != util.StateRunning {
+			return
+		}
+		if err := w.Store(ctx); err != nil {
+			m.logger().Warn("failed to save wallet", "wallet", name, "error", err)
+			return
+		}
+		m.logger().Debug("wallet saved", "wallet", name)
+	})
+}
+
+// saveBeforeShutdown saves the open wallets once more before the services
+// stop, bounded by the shutdown timeout, unless autosave is disabled.
+func (m *Moneroger) saveBeforeShutdown(ctx context.Context, config util.Config) {
+	if autosaveInterval(config) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.EffectiveShutdownTimeout())
+	defer cancel()
+	m.saveWallets(ctx)
+}
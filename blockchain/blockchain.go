@@ -0,0 +1,285 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// importProgram and exportProgram are the names of the Monero tools
+	importProgram = "monero-blockchain-import"
+	exportProgram = "monero-blockchain-export"
+)
+
+// Operation names for blockchain tool errors
+const (
+	opImport = errors.Op("Blockchain.Import")
+	opExport = errors.Op("Blockchain.Export")
+)
+
+// progressLine matches the progress the tools print, such as
+// "block 1200 / 3000" from the importer and "block 1200/3000" from the
+// exporter.
+var progressLine = regexp.MustCompile(`block (\d+) ?/ ?(\d+)`)
+
+// Progress is how far an import or export has come.
+//
+// Fields:
+//   - Height: Blocks processed so far
+//   - Total: Blocks to process, 0 when not yet known
+type Progress struct {
+	Height uint64
+	Total  uint64
+}
+
+// Percent returns the share of blocks processed, 0 while Total is
+// unknown.
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return 100 * float64(p.Height) / float64(p.Total)
+}
+
+// DefaultFile returns the raw block file the tools use when none is
+// given, export/blockchain.raw under the data directory.
+//
+// Parameters:
+//   - dataDir: Data directory of the daemon
+//
+// Returns:
+//   - string: Path of the default block file
+func DefaultFile(dataDir string) string {
+	return filepath.Join(dataDir, "export", "blockchain.raw")
+}
+
+// ImportOptions configures Import.
+//
+// Fields:
+//   - InputFile: Raw block file to import, DefaultFile when empty
+//   - BatchSize: Blocks committed per database transaction, 0 for the
+//     tool's default
+//   - Unverified: Skip verifying the blocks, which imports much faster
+//     but must only be used with files from a trusted source
+//   - Progress: Called with each progress update, may be nil
+type ImportOptions struct {
+	InputFile  string
+	BatchSize  int
+	Unverified bool
+	Progress   func(Progress)
+}
+
+// ExportOptions configures Export.
+//
+// Fields:
+//   - OutputFile: Raw block file to write, DefaultFile when empty; its
+//     directory is created if needed
+//   - BlockStop: Last block exported, 0 for the whole chain
+//   - Progress: Called with each progress update, may be nil
+type ExportOptions struct {
+	OutputFile string
+	BlockStop  uint64
+	Progress   func(Progress)
+}
+
+// Import loads blocks from a raw block file, as written by Export, into
+// the blockchain database of config.NetworkDataDir(). monerod must not run
+// on the data directory meanwhile; a lock left by a crashed daemon is
+// removed.
+//
+// Parameters:
+//   - ctx: Context bounding the import; cancelling it interrupts the tool,
+//     which commits the blocks imported so far before exiting
+//   - config: Data directory, network, driver and logger, as passed to
+//     monerod.NewMoneroDaemon
+//   - opts: Input file and import settings
+//
+// Returns:
+//   - Progress: The last progress reported by the tool
+//   - error: KindConfig without a data directory or input file,
+//     KindSystem if the database is in use, KindProcess if the tool is
+//     missing or fails, KindTimeout if ctx ended first
+//
+// Related:
+//   - Export for writing the block file
+//   - ImportPath for locating the tool
+func Import(ctx context.Context, config util.Config, opts ImportOptions) (Progress, error) {
+	dataDir := config.NetworkDataDir()
+	if dataDir == "" {
+		return Progress{}, errors.E(opImport, errors.ComponentBlockchain, errors.KindConfig,
+			fmt.Errorf("data directory cannot be empty"))
+	}
+	input := opts.InputFile
+	if input == "" {
+		input = DefaultFile(dataDir)
+	}
+	if !util.FileExists(input) {
+		return Progress{}, errors.E(opImport, errors.ComponentBlockchain, errors.KindConfig,
+			fmt.Errorf("block file %s does not exist", input))
+	}
+	if _, docker := config.Driver().(*util.DockerDriver); !docker {
+		_, err := util.RemoveStaleLMDBLock(dataDir)
+		if stderrors.Is(err, util.ErrDatabaseInUse) {
+			return Progress{}, errors.E(opImport, errors.ComponentBlockchain, errors.KindSystem,
+				fmt.Errorf("monerod is using %s, stop it before importing: %w", dataDir, err))
+		}
+		if err != nil {
+			return Progress{}, errors.E(opImport, errors.ComponentBlockchain, errors.KindSystem, err)
+		}
+	}
+
+	args := []string{"--data-dir", dataDir, "--input-file", input}
+	if opts.BatchSize > 0 {
+		args = append(args, "--batch-size", strconv.Itoa(opts.BatchSize))
+	}
+	if opts.Unverified {
+		args = append(args, "--dangerous-unverified-import", "1")
+	}
+	t := tool{op: opImport, program: importProgram, find: ImportPath}
+	return t.run(ctx, config, args, []string{dataDir, input}, opts.Progress)
+}
+
+// Export writes the blockchain database of config.NetworkDataDir() to a
+// raw block file, for backups or to bootstrap another node with Import.
+//
+// Parameters:
+//   - ctx: Context bounding the export; cancelling it interrupts the tool
+//   - config: Data directory, network, driver and logger, as passed to
+//     monerod.NewMoneroDaemon
+//   - opts: Output file and last block
+//
+// Returns:
+//   - Progress: The last progress reported by the tool
+//   - error: KindConfig without a data directory or blockchain,
+//     KindSystem if the output directory cannot be created, KindProcess
+//     if the tool is missing or fails, KindTimeout if ctx ended first
+//
+// Related:
+//   - Import for loading the block file
+//   - ExportPath for locating the tool
+func Export(ctx context.Context, config util.Config, opts ExportOptions) (Progress, error) {
+	dataDir := config.NetworkDataDir()
+	if dataDir == "" {
+		return Progress{}, errors.E(opExport, errors.ComponentBlockchain, errors.KindConfig,
+			fmt.Errorf("data directory cannot be empty"))
+	}
+	database := filepath.Join(filepath.Dir(util.LMDBLockFile(dataDir)), "data.mdb")
+	if _, err := os.Stat(database); stderrors.Is(err, fs.ErrNotExist) {
+		return Progress{}, errors.E(opExport, errors.ComponentBlockchain, errors.KindConfig,
+			fmt.Errorf("no blockchain in %s", dataDir))
+	}
+	output := opts.OutputFile
+	if output == "" {
+		output = DefaultFile(dataDir)
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return Progress{}, errors.E(opExport, errors.ComponentBlockchain, errors.KindSystem, err)
+	}
+
+	args := []string{"--data-dir", dataDir, "--output-file", output}
+	if opts.BlockStop > 0 {
+		args = append(args, "--block-stop", strconv.FormatUint(opts.BlockStop, 10))
+	}
+	t := tool{op: opExport, program: exportProgram, find: ExportPath}
+	return t.run(ctx, config, args, []string{dataDir, filepath.Dir(output)}, opts.Progress)
+}
+
+// tool is one of the Monero blockchain programs.
+//
+// Fields:
+//   - op: Operation reported in errors
+//   - program: Program name, such as "monero-blockchain-import"
+//   - find: Locates the program on the host
+type tool struct {
+	op      errors.Op
+	program string
+	find    func() (string, error)
+}
+
+// run executes the tool through the configured driver until it exits,
+// reporting its progress and logging its other output at debug level.
+func (t tool) run(ctx context.Context, config util.Config, args, mounts []string, progress func(Progress)) (Progress, error) {
+	logger := config.Log().With("component", errors.ComponentBlockchain, "program", t.program)
+	driver := config.Driver()
+	path, err := driver.Executable(t.find)
+	if err != nil {
+		return Progress{}, errors.E(t.op, errors.ComponentBlockchain, errors.KindProcess, err)
+	}
+	if flag := config.NetType().Flag(); flag != "" {
+		args = append(args, flag)
+	}
+	cmd, err := driver.Command(ctx, util.ProcessSpec{
+		Path:    path,
+		Program: t.program,
+		Name:    t.program,
+		Args:    args,
+		Mounts:  mounts,
+	})
+	if err != nil {
+		return Progress{}, errors.E(t.op, errors.ComponentBlockchain, errors.KindSystem, err)
+	}
+	util.PrepareCommand(cmd)
+	// Interrupt rather than kill, so the tool closes the database cleanly
+	kill := cmd.Cancel
+	cmd.Cancel = func() error {
+		if err := util.InterruptProcess(cmd.Process); err != nil && kill != nil {
+			return kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = config.EffectiveShutdownTimeout()
+
+	var last Progress
+	lines := util.NewLineWriter(func(line string) {
+		if m := progressLine.FindStringSubmatch(line); m != nil {
+			last.Height, _ = strconv.ParseUint(m[1], 10, 64)
+			last.Total, _ = strconv.ParseUint(m[2], 10, 64)
+			if progress != nil {
+				progress(last)
+			}
+		} else if line != "" {
+			logger.Debug(line)
+		}
+	})
+	tail := util.NewRingBuffer(config.OutputBufferSize())
+	out := io.MultiWriter(tail, carriageReturns{lines})
+	cmd.Stdout, cmd.Stderr = out, out
+
+	logger.Info("running", "args", args)
+	err = cmd.Run()
+	lines.Flush()
+	if ctx.Err() != nil {
+		return last, errors.E(t.op, errors.ComponentBlockchain, errors.KindTimeout,
+			fmt.Errorf("%s interrupted at block %d: %w", t.program, last.Height, ctx.Err()))
+	}
+	if err != nil {
+		return last, errors.E(t.op, errors.ComponentBlockchain, errors.KindProcess,
+			fmt.Errorf("%s: %w\nOutput: %s", t.program, err, tail.String()))
+	}
+	logger.Info("finished", "height", last.Height)
+	return last, nil
+}
+
+// carriageReturns ends lines at carriage returns as well as newlines, as
+// the tools redraw their progress in place with "\r".
+type carriageReturns struct {
+	w io.Writer
+}
+
+func (c carriageReturns) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\r"), []byte("\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,103 @@
+package blockchain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// fakeTool installs a shell script named program on PATH that records its
+// arguments to args.txt in dir and then runs body
+func fakeTool(t *testing.T, program, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args.txt") + "\n" + body
+	if err := os.WriteFile(filepath.Join(dir, program), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+// TestImport verifies the importer's arguments and progress reporting,
+// and the checks made before it runs
+func TestImport(t *testing.T) {
+	dir := fakeTool(t, importProgram,
+		"printf 'block 100 / 300\\rblock 300 / 300\\r'\necho done\n")
+	config := util.Config{DataDir: t.TempDir(), ShutdownTimeout: 5 * time.Second}
+
+	_, err := Import(context.Background(), config, ImportOptions{})
+	if errors.GetKind(err) != errors.KindConfig {
+		t.Fatalf("Import() without block file error = %v, want KindConfig", err)
+	}
+
+	input := filepath.Join(t.TempDir(), "blockchain.raw")
+	if err := os.WriteFile(input, []byte("blocks"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var updates []Progress
+	progress, err := Import(context.Background(), config, ImportOptions{
+		InputFile:  input,
+		BatchSize:  500,
+		Unverified: true,
+		Progress:   func(p Progress) { updates = append(updates, p) },
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if progress != (Progress{Height: 300, Total: 300}) || len(updates) != 2 {
+		t.Errorf("Import() = %+v with updates %+v, want 2 updates ending at 300/300", progress, updates)
+	}
+	if updates[0].Percent() < 33 || updates[0].Percent() > 34 {
+		t.Errorf("Percent() = %v, want 33.3", updates[0].Percent())
+	}
+	args, err := os.ReadFile(filepath.Join(dir, "args.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"--input-file " + input, "--batch-size 500", "--dangerous-unverified-import 1"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("arguments %q missing %q", args, want)
+		}
+	}
+}
+
+// TestExport verifies the exporter's arguments and that a failing tool
+// is reported with its output
+func TestExport(t *testing.T) {
+	fakeTool(t, exportProgram, "echo 'block 50/200'\necho 'database corrupt' >&2\nexit 1\n")
+	config := util.Config{DataDir: t.TempDir(), ShutdownTimeout: 5 * time.Second}
+
+	_, err := Export(context.Background(), config, ExportOptions{})
+	if errors.GetKind(err) != errors.KindConfig {
+		t.Fatalf("Export() without blockchain error = %v, want KindConfig", err)
+	}
+
+	lmdb := filepath.Join(config.DataDir, "lmdb")
+	if err := os.MkdirAll(lmdb, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(lmdb, "data.mdb"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	progress, err := Export(context.Background(), config, ExportOptions{BlockStop: 200})
+	if errors.GetKind(err) != errors.KindProcess || !strings.Contains(err.Error(), "database corrupt") {
+		t.Errorf("Export() error = %v, want KindProcess with output", err)
+	}
+	if progress.Height != 50 {
+		t.Errorf("Export() progress = %+v, want height 50", progress)
+	}
+	if _, err := os.Stat(filepath.Dir(DefaultFile(config.DataDir))); err != nil {
+		t.Error("Export() did not create the output directory")
+	}
+}
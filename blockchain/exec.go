@@ -0,0 +1,47 @@
+// Package blockchain drives monero-blockchain-import and
+// monero-blockchain-export, which load a local daemon's blockchain from a
+// raw block file and write it to one. They bootstrap a new node from a
+// file instead of the network and back up an existing one.
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/opd-ai/moneroger/util"
+)
+
+// ImportPath searches for the monero-blockchain-import executable in the
+// same locations as monerod.MoneroDPath.
+//
+// Returns:
+//   - string: The full path to the executable if found
+//   - error: An error if the executable cannot be found
+//
+// Related:
+//   - util.Path() for search path generation
+//   - util.FindExecutable() for extension-aware lookup
+func ImportPath() (string, error) {
+	path, err := util.FindExecutable(util.Path(), importProgram)
+	if err != nil {
+		return "", fmt.Errorf("Monero blockchain import tool(%s) not found", importProgram)
+	}
+	return path, nil
+}
+
+// ExportPath searches for the monero-blockchain-export executable in the
+// same locations as monerod.MoneroDPath.
+//
+// Returns:
+//   - string: The full path to the executable if found
+//   - error: An error if the executable cannot be found
+//
+// Related:
+//   - util.Path() for search path generation
+//   - util.FindExecutable() for extension-aware lookup
+func ExportPath() (string, error) {
+	path, err := util.FindExecutable(util.Path(), exportProgram)
+	if err != nil {
+		return "", fmt.Errorf("Monero blockchain export tool(%s) not found", exportProgram)
+	}
+	return path, nil
+}
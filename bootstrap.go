@@ -0,0 +1,57 @@
+package moneroger
+
+import (
+	"context"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// bootstrapCheckInterval is how often a bootstrapping daemon is polled
+const bootstrapCheckInterval = 30 * time.Second
+
+// watchBootstrap reports when a daemon started with a bootstrap node has
+// synchronized its own chain and stopped relaying wallet queries. It
+// emits EventLocalChainTakeover once and exits; it does nothing when no
+// bootstrap node is configured or a remote node replaces the daemon.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchBootstrap(ctx context.Context) {
+	config := m.currentConfig()
+	if !config.Bootstrap.Enabled() || len(config.RemoteNodeList()) > 0 {
+		return
+	}
+	ticker := time.NewTicker(bootstrapCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if m.localChainReady(ctx) {
+			m.logger().Info("local chain synchronized, bootstrap daemon no longer used",
+				"bootstrap", config.Bootstrap.Address)
+			m.emit(EventLocalChainTakeover, errors.ComponentMonerod, nil)
+			return
+		}
+	}
+}
+
+// localChainReady reports whether the managed daemon serves queries from
+// its own synchronized chain.
+func (m *Moneroger) localChainReady(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, bootstrapCheckInterval)
+	defer cancel()
+	info, err := m.monerod.Client().GetInfo(ctx)
+	if err != nil {
+		m.logger().Debug("bootstrap sync check failed", "error", err)
+		return false
+	}
+	if !info.LocalChainReady() {
+		m.logger().Debug("daemon still syncing", "height", info.Height, "target", info.TargetHeight,
+			"bootstrap", info.BootstrapDaemonAddress)
+	}
+	return info.LocalChainReady()
+}
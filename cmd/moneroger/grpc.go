@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/grpcapi"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// listenGRPC opens the gRPC API listener before the services start, so
+// a taken address fails startup before any process is spawned. It
+// returns nil when the API is disabled or the services will be detached.
+func listenGRPC(config util.Config) (net.Listener, error) {
+	if !config.GRPC.Enabled() || config.Detach {
+		return nil, nil
+	}
+	return net.Listen("tcp", config.GRPC.Address)
+}
+
+// serveGRPC serves the gRPC API on ln until the returned function is
+// called, which stops it, waiting up to timeout for calls in progress.
+func serveGRPC(logger *slog.Logger, ln net.Listener, manager *moneroger.Moneroger, config util.GRPCConfig, timeout time.Duration) (func(), error) {
+	if ln == nil {
+		return func() {}, nil
+	}
+	server, err := grpcapi.NewServer(manager, config)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	logger.Info("serving the gRPC API", "address", ln.Addr().String(), "tls", config.TLS.Enabled())
+	go func() {
+		if err := server.Serve(ln); err != nil {
+			logger.Error("gRPC API stopped", "error", err)
+		}
+	}()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			server.Stop()
+		}
+	}, nil
+}
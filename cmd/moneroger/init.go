@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opd-ai/moneroger/util"
+)
+
+// runInit implements "moneroger init": it writes a commented configuration
+// file built by util.RecommendConfig, to be edited and passed back with
+// -config.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	var (
+		dataDir  = fs.String("datadir", os.Getenv(util.EnvPrefix+"_DATA_DIR"), "Directory for blockchain data and wallet files")
+		output   = fs.String("config", "moneroger.yaml", "Configuration file to write (.yaml, .toml or .json)")
+		testnet  = fs.Bool("testnet", false, "Use testnet instead of mainnet; shorthand for -network testnet")
+		network  = fs.String("network", "mainnet", "Monero network: mainnet, testnet or stagenet")
+		force    = fs.Bool("force", false, "Overwrite an existing configuration file")
+		fullGB   = fs.Int("full-node-gb", 0, "Free gigabytes needed to recommend a full node (default 250)")
+		prunedGB = fs.Int("pruned-node-gb", 0, "Free gigabytes needed to recommend a pruned node; with less a remote node is used (default 100)")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s init [flags]\n\nWrite a commented configuration file with recommended settings.\n\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *dataDir == "" {
+		return fmt.Errorf("--datadir is required")
+	}
+	absDataDir, err := filepath.Abs(*dataDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve data directory path: %w", err)
+	}
+	if !*force && util.FileExists(*output) {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", *output)
+	}
+
+	selected, err := util.ParseNetwork(*network)
+	if err != nil {
+		return err
+	}
+	if *testnet {
+		selected = util.NetworkTestnet
+	}
+
+	thresholds := util.RecommendThresholds{FullGB: *fullGB, PrunedGB: *prunedGB}
+	if err := thresholds.Validate(); err != nil {
+		return err
+	}
+
+	config := util.RecommendConfigWithThresholds(absDataDir, thresholds)
+	// Moves default ports, and drops the mainnet-only public nodes
+	config.SetNetwork(selected)
+	if err := util.SaveConfig(*output, config); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	fmt.Printf("wrote %s\n", *output)
+	return nil
+}
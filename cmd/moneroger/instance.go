@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// instanceTimeout bounds the status queries of "moneroger status" and
+// the shutdown of "moneroger stop" and "moneroger restart"
+const instanceTimeout = 3 * time.Minute
+
+// runStatus implements "moneroger status": it prints the state of the
+// running instance, leaving it running. With -json the status is a
+// single JSON line. With -probe live or ready nothing is printed and
+// the result is the probe's, for orchestrators' exec probes.
+func runStatus(config util.Config, jsonOutput bool, probe string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), instanceTimeout)
+	defer cancel()
+	status, err := moneroger.InspectInstance(ctx, config)
+	if err != nil {
+		return err
+	}
+	switch probe {
+	case "":
+	case "live":
+		return status.Live()
+	case "ready":
+		return status.Ready()
+	default:
+		return fmt.Errorf("unknown probe %q, want live or ready", probe)
+	}
+	if jsonOutput {
+		return json.NewEncoder(os.Stdout).Encode(status)
+	}
+	return printStatus(os.Stdout, status)
+}
+
+// printStatus writes status as a table with one row per service.
+func printStatus(w io.Writer, status *moneroger.Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tSTATE\tPID\tPORT\tUPTIME\tCPU\tMEM\tVERSION\tDETAILS")
+	d := status.Daemon
+	details := fmt.Sprintf("height %d/%d, %d peers", d.Height, d.TargetHeight, d.Peers)
+	if d.Synchronized {
+		details += ", synchronized"
+	}
+	if d.RestrictedPort != 0 {
+		details += fmt.Sprintf(", restricted RPC on %d", d.RestrictedPort)
+	}
+	if d.Error != "" {
+		details = d.Error
+	}
+	cpu, mem := usageColumns(d.Usage)
+	fmt.Fprintf(tw, "monerod\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", d.State, pidColumn(d.PID), d.Port,
+		d.Uptime.Round(time.Second), cpu, mem, d.Version, details)
+	for _, wallet := range status.Wallets {
+		details := "no wallet open"
+		if wallet.WalletOpen {
+			details = fmt.Sprintf("%s at height %d", wallet.Wallet, wallet.Height)
+			if !wallet.Refreshed {
+				details += ", refreshing"
+			}
+		}
+		if wallet.Error != "" {
+			details = wallet.Error
+		}
+		cpu, mem := usageColumns(wallet.Usage)
+		fmt.Fprintf(tw, "wallet %s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", wallet.Name, wallet.State, pidColumn(wallet.PID),
+			wallet.Port, wallet.Uptime.Round(time.Second), cpu, mem, wallet.Version, details)
+	}
+	return tw.Flush()
+}
+
+// pidColumn renders a process ID, "-" for services without one.
+func pidColumn(pid int) string {
+	if pid == 0 {
+		return "-"
+	}
+	return fmt.Sprint(pid)
+}
+
+// usageColumns renders the CPU use and resident memory of a process,
+// "-" for services without one.
+func usageColumns(usage *util.ProcessUsage) (cpu, mem string) {
+	if usage == nil {
+		return "-", "-"
+	}
+	return fmt.Sprintf("%.1f%%", usage.CPUPercent), fmt.Sprintf("%dMiB", usage.RSS>>20)
+}
+
+// runStop implements "moneroger stop" and the first half of "moneroger
+// restart": it gracefully stops the running instance, whether a
+// foreground manager or detached services.
+//
+// Parameters:
+//   - mustRun: Whether having no running instance is an error
+func runStop(logger *slog.Logger, config util.Config, mustRun bool) error {
+	instance, err := moneroger.FindInstance(config.DataDir)
+	if err != nil {
+		return err
+	}
+	if instance == nil {
+		if mustRun {
+			return fmt.Errorf("no running instance in %s", config.DataDir)
+		}
+		return nil
+	}
+	if instance.Detached() {
+		logger.Info("stopping detached Monero services")
+	} else {
+		logger.Info("stopping Monero services", "manager", instance.PID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), instanceTimeout)
+	defer cancel()
+	if err := moneroger.StopInstance(ctx, config); err != nil {
+		return err
+	}
+	logger.Info("Monero services stopped")
+	return nil
+}
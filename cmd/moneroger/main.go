@@ -4,115 +4,519 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/monerod"
 	"github.com/opd-ai/moneroger/util"
 )
 
-// verifyExecutables checks if required Monero executables are available
-func verifyExecutables() error {
-	executables := []string{"monerod", "monero-wallet-rpc"}
-	for _, exe := range executables {
-		_, err := exec.LookPath(exe)
-		if err != nil {
-			return fmt.Errorf("%s not found in PATH: %w", exe, err)
+// verifyExecutables checks if required Monero executables are available,
+// searching the same directories as the services do, or that the
+// container CLI is when the services run in containers
+func verifyExecutables(config util.Config) error {
+	if config.Docker.Enabled() {
+		_, err := config.Driver().Executable(nil)
+		return err
+	}
+	for _, find := range []func() (string, error){monerod.MoneroDPath, monerowalletrpc.MoneroWalletRPCPath} {
+		if _, err := find(); err != nil {
+			return fmt.Errorf("%w; install Monero or run \"%s fetch\"", err, filepath.Base(os.Args[0]))
 		}
 	}
 	return nil
 }
 
+// argList is a flag that collects every occurrence, in order
+type argList []string
+
+func (a *argList) String() string {
+	return strings.Join(*a, " ")
+}
+
+func (a *argList) Set(value string) error {
+	*a = append(*a, value)
+	return nil
+}
+
+// parseGigabytes parses a comma separated list of gigabyte thresholds,
+// empty for none.
+func parseGigabytes(s string) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gigabytes %q", field)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// commandUsage lists the subcommands for -help
+const commandUsage = `Usage: %s [command] [flags]
+
+Commands:
+  start     Start the services and manage them until interrupted (default)
+  stop      Gracefully stop the running instance
+  status    Print the state of the running instance, or probe it with -probe
+  restart   Stop the running instance and start again
+  init      Write a commented configuration file
+  systemd   Install a systemd unit
+  fetch     Download and install verified Monero executables
+
+start, stop, status and restart take the flags below; pass the same
+-datadir or -config to find the running instance.
+
+`
+
 func main() {
+	command := "start"
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command = os.Args[1]
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+	switch command {
+	case "init":
+		if err := runInit(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "systemd":
+		if err := runSystemd(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "fetch":
+		if err := runFetch(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	case "start", "stop", "status", "restart":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, see %s -help\n", command, filepath.Base(os.Args[0]))
+		os.Exit(2)
+	}
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), commandUsage, filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+
 	// Command line flags for configuration
 	var (
-		dataDir    = flag.String("datadir", "", "Directory for blockchain data and wallet files")
-		walletDir  = flag.String("wallet", "", "Path to wallet file (directory)")
-		moneroPort = flag.Int("daemon-port", 18081, "Port for Monero daemon RPC")
-		walletPort = flag.Int("wallet-port", 18083, "Port for wallet RPC")
-		testnet    = flag.Bool("testnet", false, "Use testnet instead of mainnet")
+		configFile = flag.String("config", "", "Configuration file written by \"init\" (.yaml, .toml or .json); flags given explicitly override it")
+		dataDir    = flag.String("datadir", os.Getenv(util.EnvPrefix+"_DATA_DIR"), "Directory for blockchain data and wallet files; testnet and stagenet use its testnet and stagenet subdirectories")
+		walletDir  = flag.String("wallet", "", "Directory holding the wallet files (default the data directory, or its testnet or stagenet subdirectory)")
+		walletName = flag.String("wallet-name", "", "Wallet in the wallet directory to open at startup with the managed wallet password")
+		moneroPort = flag.Int("daemon-port", 0, "Port for Monero daemon RPC (default 18081, 28081 on testnet, 38081 on stagenet)")
+		walletPort = flag.Int("wallet-port", 0, "Port for wallet RPC (default 18083, 28083 on testnet, 38083 on stagenet)")
+		restricted = flag.Int("restricted-port", 0, "Also serve restricted daemon RPC without a login on this port, for a public node (e.g. 18089)")
+		publicNode = flag.Bool("public-node", false, "Share the node: advertise restricted RPC (default port 18089) to peers, with peer and rate limits for serving the public")
+		autoPort   = flag.Bool("auto-port", false, "Use a free port when the daemon or wallet port is taken by another program")
+		portRange  = flag.String("auto-port-range", "", "Ports -auto-port chooses from, such as 20000-20999 (default any free port)")
+		keyring    = flag.Bool("keyring", false, "Keep generated RPC passwords and the wallet password in the OS keyring")
+		detach     = flag.Bool("detach", false, "Start the services detached, logging to files in the data directory, and exit; see the status and stop commands")
+		daemonBind = flag.String("daemon-bind", "", "IPv4 address for the daemon RPC to listen on (default loopback)")
+		walletBind = flag.String("wallet-bind", "", "IPv4 address for the wallet RPC to listen on (default loopback)")
+		testnet    = flag.Bool("testnet", false, "Use testnet instead of mainnet; shorthand for -network testnet")
+		network    = flag.String("network", "", "Monero network: mainnet, testnet or stagenet")
 		debug      = flag.Bool("debug", false, "Enable debug logging")
+		jsonOutput = flag.Bool("json", false, "Write logs, errors and status as JSON lines, for scripts and orchestration tools")
+		statusAddr = flag.String("status-addr", "", "Serve /healthz, /readyz and /status over HTTP on this host:port (e.g. 127.0.0.1:18090); other than loopback, set a token with MONEROGER_STATUS_TOKEN or StatusTLS in the configuration file")
+		dockerImg  = flag.String("docker-image", "", "Run monerod and monero-wallet-rpc in containers of this image, which must provide both on its PATH")
+		grpcAddr   = flag.String("grpc-addr", "", "Serve the gRPC management API on this host:port; set the token with MONEROGER_GRPC_TOKEN or the configuration file")
+		probe      = flag.String("probe", "", "With status, print nothing and fail unless the instance is \"live\" (processes running) or \"ready\" (synced, wallet open and refreshed)")
+		torProxy   = flag.String("tor-proxy", "", "Route all node traffic through this Tor SOCKS proxy (e.g. 127.0.0.1:9050)")
+		bootstrap  = flag.String("bootstrap-daemon", "", "Node (host:port or \"auto\") answering wallet queries while the local daemon syncs")
+		i2pProxy   = flag.String("i2p-proxy", "", "Broadcast transactions through this I2P SOCKS proxy (e.g. 127.0.0.1:4447)")
+		startWait  = flag.Duration("startup-timeout", 0, "How long each service may take to become ready (default 30s); raise it when creating a new blockchain database")
+		diskWarn   = flag.String("disk-warn-gb", "", "Warn when free space under the data directory drops below these gigabytes, such as 50,20")
+		diskLimit  = flag.Int("disk-fallback-gb", 0, "Stop monerod and switch the wallets to a remote node when free space drops below this many gigabytes")
+		autosave   = flag.Duration("autosave-interval", 0, "How often open wallets are saved to their files (default 5m); negative disables")
+		payments   = flag.Duration("payment-interval", 0, "How often open wallets are checked for incoming payments (default 30s); negative disables")
+		logFile    = flag.String("daemon-log-file", "", "File monerod logs to (default logs/bitmonero.log in the network's data directory)")
+		logLevel   = flag.Int("daemon-log-level", 0, "monerod log verbosity, 0 to 4")
+		logSize    = flag.Int("daemon-max-log-size", 0, "Bytes at which monerod rotates its log file (default monerod's, about 100 MB)")
+		outPeers   = flag.Int("out-peers", 0, "Outgoing peer connections monerod makes (default monerod's, 12)")
+		inPeers    = flag.Int("in-peers", 0, "Incoming peer connections monerod accepts (default unlimited)")
+		limitRate  = flag.Int("limit-rate", 0, "monerod upload and download limit in kB/s")
+		limitUp    = flag.Int("limit-rate-up", 0, "monerod upload limit in kB/s, overriding -limit-rate")
+		limitDown  = flag.Int("limit-rate-down", 0, "monerod download limit in kB/s, overriding -limit-rate")
+		prune      = flag.Bool("prune", false, "Keep a pruned blockchain, about a third of the full size")
+		banList    = flag.String("ban-list", "", "File of IP addresses and subnets, one per line, that monerod refuses peers from")
+		hwDevice   = flag.String("hw-device", "", "Keep wallet keys on a hardware wallet: Ledger or Trezor")
+		hwPath     = flag.String("hw-device-deriv-path", "", "Derivation path on the hardware wallet, such as m/44'/128'/1'")
 	)
+	var daemonArgs, walletArgs argList
+	flag.Var(&daemonArgs, "daemon-arg", "Argument appended to the monerod command line, such as --enable-dns-blocklist; repeat for several")
+	flag.Var(&walletArgs, "wallet-arg", "Argument appended to the monero-wallet-rpc command line; repeat for several")
 	flag.Parse()
 
-	// Enable debug logging if requested
+	// Enable debug logging if requested, with the origin of errors
+	level := slog.LevelInfo
 	if *debug {
-		log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Llongfile)
+		level = slog.LevelDebug
+		errors.SetTrace(true)
 	}
-
-	// Verify Monero executables are available
-	if err := verifyExecutables(); err != nil {
-		log.Fatalf("Prerequisite check failed: %v", err)
+	handlerOptions := &slog.HandlerOptions{Level: level, AddSource: *debug}
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, handlerOptions)
+	if *jsonOutput {
+		handler = slog.NewJSONHandler(os.Stderr, handlerOptions)
 	}
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	util.SetLogger(logger)
 
-	// Validate command line arguments
-	if *dataDir == "" {
-		log.Fatal("--datadir is required")
-	}
-	if *walletDir == "" {
-		*walletDir = *dataDir
-	}
+	// Flags override a configuration file only when given explicitly
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	set := func(name string) bool { return *configFile == "" || explicit[name] }
 
-	// Convert paths to absolute
-	absDataDir, err := filepath.Abs(*dataDir)
-	if err != nil {
-		log.Fatalf("Failed to resolve data directory path: %v", err)
+	// buildConfig assembles the configuration from the file, explicit
+	// flags and the environment; SIGHUP calls it again to reload
+	buildConfig := func() (util.Config, error) {
+		var config util.Config
+		if *configFile != "" {
+			loaded, err := util.LoadConfig(*configFile)
+			if err != nil {
+				return config, fmt.Errorf("failed to load configuration: %w", err)
+			}
+			config = *loaded
+		} else if *dataDir == "" {
+			return config, fmt.Errorf("--datadir or --config is required")
+		}
+		if set("datadir") {
+			absDataDir, err := filepath.Abs(*dataDir)
+			if err != nil {
+				return config, fmt.Errorf("failed to resolve data directory path: %w", err)
+			}
+			if *configFile == "" {
+				config = util.RecommendConfig(absDataDir)
+			}
+			config.DataDir = absDataDir
+		}
+		if set("wallet") {
+			// Replaces a deprecated WalletFile from the configuration file;
+			// left empty, the wallets follow the network's data directory
+			config.WalletDir, config.WalletFile = "", ""
+			if *walletDir != "" {
+				absWalletDir, err := filepath.Abs(*walletDir)
+				if err != nil {
+					return config, fmt.Errorf("failed to resolve wallet directory path: %w", err)
+				}
+				config.WalletDir = absWalletDir
+			}
+		}
+		if set("wallet-name") {
+			config.WalletName = *walletName
+		}
+		if set("network") || set("testnet") {
+			selected := util.NetworkMainnet
+			if *testnet {
+				selected = util.NetworkTestnet
+			}
+			if *network != "" {
+				parsed, err := util.ParseNetwork(*network)
+				if err != nil {
+					return config, err
+				}
+				selected = parsed
+			}
+			// Moves default ports, and drops the mainnet-only public nodes
+			config.SetNetwork(selected)
+		}
+		if set("daemon-port") && *moneroPort != 0 {
+			config.MoneroPort = *moneroPort
+		}
+		if set("wallet-port") && *walletPort != 0 {
+			config.WalletPort = *walletPort
+		}
+		if set("restricted-port") {
+			config.RestrictedPort = *restricted
+		}
+		if set("public-node") {
+			config.PublicNode = *publicNode
+		}
+		if set("auto-port") {
+			config.AutoPort = *autoPort
+		}
+		if set("auto-port-range") {
+			parsed, err := util.ParsePortRange(*portRange)
+			if err != nil {
+				return config, err
+			}
+			config.AutoPortRange = parsed
+		}
+		if set("keyring") {
+			config.Keyring = *keyring
+		}
+		if set("detach") {
+			config.Detach = *detach
+		}
+		if set("status-addr") {
+			config.StatusAddress = *statusAddr
+		}
+		if set("daemon-arg") {
+			config.MonerodExtraArgs = daemonArgs
+		}
+		if set("wallet-arg") {
+			config.WalletRPCExtraArgs = walletArgs
+		}
+		if set("startup-timeout") {
+			config.StartupTimeout = *startWait
+		}
+		if set("autosave-interval") {
+			config.AutosaveInterval = *autosave
+		}
+		if set("payment-interval") {
+			config.PaymentInterval = *payments
+		}
+		if set("ban-list") {
+			config.BanList = *banList
+		}
+		if set("hw-device") {
+			config.HWDevice.Name = *hwDevice
+		}
+		if set("hw-device-deriv-path") {
+			config.HWDevice.DerivationPath = *hwPath
+		}
+		if set("docker-image") {
+			config.Docker.Image = *dockerImg
+		}
+		if set("grpc-addr") {
+			config.GRPC.Address = *grpcAddr
+		}
+		if set("daemon-bind") {
+			config.MoneroBindIP = *daemonBind
+		}
+		if set("wallet-bind") {
+			config.WalletBindIP = *walletBind
+		}
+		if set("tor-proxy") {
+			config.Tor.Proxy = *torProxy
+		}
+		if set("i2p-proxy") {
+			config.I2P.Proxy = *i2pProxy
+		}
+		if set("bootstrap-daemon") {
+			config.Bootstrap.Address = *bootstrap
+		}
+		if set("disk-warn-gb") {
+			thresholds, err := parseGigabytes(*diskWarn)
+			if err != nil {
+				return config, err
+			}
+			config.DiskWatch.WarnGB = thresholds
+		}
+		if set("disk-fallback-gb") {
+			config.DiskWatch.FallbackGB = *diskLimit
+		}
+		if set("daemon-log-file") {
+			config.DaemonLogFile = ""
+			if *logFile != "" {
+				absLogFile, err := filepath.Abs(*logFile)
+				if err != nil {
+					return config, fmt.Errorf("failed to resolve daemon log file path: %w", err)
+				}
+				config.DaemonLogFile = absLogFile
+			}
+		}
+		if set("daemon-log-level") {
+			config.DaemonLogLevel = *logLevel
+		}
+		if set("daemon-max-log-size") {
+			config.DaemonMaxLogFileSize = *logSize
+		}
+		if set("out-peers") {
+			config.OutPeers = *outPeers
+		}
+		if set("in-peers") {
+			config.InPeers = *inPeers
+		}
+		if set("limit-rate") {
+			config.LimitRate = *limitRate
+		}
+		if set("limit-rate-up") {
+			config.LimitRateUp = *limitUp
+		}
+		if set("limit-rate-down") {
+			config.LimitRateDown = *limitDown
+		}
+		if set("prune") {
+			config.Prune = *prune
+		}
+		if set("debug") {
+			config.LogProcessOutput = *debug
+		}
+		config.Logger = logger
+		// MONEROGER_* environment variables override flags, for containers
+		if err := config.ApplyEnv(); err != nil {
+			return config, fmt.Errorf("invalid environment configuration: %w", err)
+		}
+		return config, nil
 	}
-	absWalletFile, err := filepath.Abs(*walletDir)
+
+	// Create configuration
+	config, err := buildConfig()
 	if err != nil {
-		log.Fatalf("Failed to resolve wallet file path: %v", err)
+		fatal(logger, "invalid configuration", err)
 	}
 
-	// Ensure data directory exists
-	if err := os.MkdirAll(absDataDir, 0o755); err != nil {
-		log.Fatalf("Failed to create data directory: %v", err)
+	// Verify Monero executables are available
+	if err := verifyExecutables(config); err != nil {
+		fatal(logger, "prerequisite check failed", err)
 	}
 
-	// Create configuration
-	config := util.RecommendConfig(absDataDir)
-	config.WalletFile = absWalletFile
-	config.MoneroPort = *moneroPort
-	config.WalletPort = *walletPort
-	config.TestNet = *testnet
+	// Ensure the network's data directory exists
+	if err := os.MkdirAll(config.NetworkDataDir(), 0o755); err != nil {
+		fatal(logger, "failed to create data directory", err)
+	}
 
-	if *debug {
-		log.Printf("Using configuration: %+v", config)
+	switch command {
+	case "status":
+		if err := runStatus(config, *jsonOutput, *probe); err != nil {
+			fatal(logger, "status failed", err)
+		}
+		return
+	case "stop":
+		if err := runStop(logger, config, true); err != nil {
+			fatal(logger, "stop failed", err)
+		}
+		return
+	case "restart":
+		if err := runStop(logger, config, false); err != nil {
+			fatal(logger, "restart failed", err)
+		}
+	default:
+		instance, err := moneroger.FindInstance(config.DataDir)
+		if err != nil {
+			fatal(logger, "failed to look for a running instance", err)
+		}
+		if instance != nil && !instance.Detached() {
+			fatal(logger, fmt.Sprintf("%s is already managed by process %d; use stop or restart", config.DataDir, instance.PID), nil)
+		}
 	}
+
+	logger.Debug("using configuration", "config", fmt.Sprintf("%+v", config))
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Initialize Moneroger with increased timeout for debugging
-	log.Printf("Initializing Monero services (testnet: %v)...", *testnet)
+	logger.Info("initializing Monero services", "network", config.NetType())
 
-	manager, err := moneroger.NewMoneroger(config)
+	grpcListener, err := listenGRPC(config)
 	if err != nil {
-		log.Fatalf("Failed to initialize Moneroger: %v", err)
+		fatal(logger, "failed to listen for the gRPC API", err)
+	}
+	// An interrupt during startup stops the services started so far
+	startCtx, stopStartup := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	manager, err := moneroger.NewMonerogerContext(startCtx, config)
+	stopStartup()
+	if err != nil {
+		fatal(logger, "failed to initialize Moneroger", err)
+	}
+	logger.Info("Monero services initialized",
+		"monerod", manager.MoneroDaemonPID(), "daemon_port", manager.DaemonRPCPort(),
+		"monero-wallet-rpc", manager.RPCWalletPID(), "wallet_port", manager.WalletRPCPort())
+	if config.Detach {
+		if err := manager.Detach(); err != nil {
+			fatal(logger, "failed to detach from Monero services", err)
+		}
+		logger.Info("Monero services left running; use the status and stop commands with the same settings to manage them")
+		return
 	}
-	log.Printf("Monero services initialized: monerod: %s, monero-wallet-rpc %s", manager.MoneroDaemonPID(), manager.RPCWalletPID())
 	defer manager.Shutdown(ctx)
+	stopGRPC, err := serveGRPC(logger, grpcListener, manager, config.GRPC, 5*time.Second)
+	if err != nil {
+		manager.Shutdown(ctx)
+		fatal(logger, "failed to start the gRPC API", err)
+	}
+	if err := manager.Register(); err != nil {
+		logger.Warn("status, stop and restart commands will not find this instance", "error", err)
+	}
+	// Both services have passed their health checks by now
+	notify(logger, util.NotifyReady)
+	go runWatchdog(ctx, logger, manager)
+	if len(config.RemoteNodeList()) == 0 {
+		go showSyncProgress(ctx, logger, manager, !*jsonOutput && isTerminal(os.Stderr))
+	}
 
-	// Handle graceful shutdown
+	// Handle graceful shutdown, and configuration reloads on SIGHUP
 	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Wait for shutdown signal
 	sig := <-signalChan
-	log.Printf("Received signal %v, initiating shutdown...", sig)
+	for sig == syscall.SIGHUP {
+		reload(logger, manager, buildConfig)
+		sig = <-signalChan
+	}
+	logger.Info("received signal, initiating shutdown", "signal", sig)
+	notify(logger, util.NotifyStopping)
 
-	// Create shutdown context with timeout
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Create shutdown context with timeout: three service shutdown
+	// timeouts, 30 seconds by default
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(),
+		3*config.EffectiveShutdownTimeout())
 	defer shutdownCancel()
 
-	// Shutdown services
+	// Stop accepting API calls, then shutdown services
+	stopGRPC()
 	if err := manager.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Error during shutdown: %v", err)
+		logger.Error("error during shutdown", "error", err)
 		os.Exit(1)
 	}
 
-	log.Println("Shutdown complete")
+	logger.Info("shutdown complete")
+}
+
+// reload rebuilds the configuration and applies it to the running services
+func reload(logger *slog.Logger, manager *moneroger.Moneroger, buildConfig func() (util.Config, error)) {
+	logger.Info("received SIGHUP, reloading configuration")
+	notify(logger, util.NotifyReloading)
+	// The services keep running whether or not the reload succeeds
+	defer notify(logger, util.NotifyReady)
+	config, err := buildConfig()
+	if err != nil {
+		logger.Error("configuration reload failed", "error", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	if err := manager.Reload(ctx, config); err != nil {
+		logger.Error("configuration reload failed", "error", err)
+		return
+	}
+	logger.Info("configuration reloaded")
+}
+
+// fatal logs msg at error level and exits with status 1. With -debug the
+// stacks recorded by the error are logged too.
+func fatal(logger *slog.Logger, msg string, err error) {
+	if err != nil {
+		if trace := fmt.Sprintf("%+v", err); trace != err.Error() {
+			logger.Error(msg, "error", err, "trace", trace)
+		} else {
+			logger.Error(msg, "error", err)
+		}
+	} else {
+		logger.Error(msg)
+	}
+	os.Exit(1)
 }
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/monerod"
+)
+
+// progressBarWidth is the number of cells in the sync progress bar
+const progressBarWidth = 30
+
+// syncLogInterval spaces out progress log lines when stderr is not a terminal
+const syncLogInterval = time.Minute
+
+// showSyncProgress reports the daemon's blockchain sync until it
+// completes: as a progress bar redrawn in place when tty is set,
+// otherwise as periodic log lines.
+func showSyncProgress(ctx context.Context, logger *slog.Logger, manager *moneroger.Moneroger, tty bool) {
+	var lastLog time.Time
+	drawn := false
+	for s := range manager.WatchSync(ctx) {
+		switch {
+		case s.Err != nil:
+			logger.Debug("sync progress unavailable", "error", s.Err)
+		case s.Synced:
+			if drawn {
+				fmt.Fprintln(os.Stderr, "\r"+progressBar(s))
+			}
+			logger.Info("blockchain synchronized", "height", s.Height)
+		case tty:
+			fmt.Fprint(os.Stderr, "\r"+progressBar(s))
+			drawn = true
+		case time.Since(lastLog) >= syncLogInterval:
+			logger.Info("syncing blockchain", "height", s.Height, "target", s.TargetHeight,
+				"percent", fmt.Sprintf("%.1f", s.Percent))
+			lastLog = time.Now()
+		}
+	}
+}
+
+// progressBar renders s as "syncing [#####-----]  50.0% 1500/3000".
+func progressBar(s monerod.SyncStatus) string {
+	filled := int(s.Percent / 100 * progressBarWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	target := "?"
+	if s.TargetHeight > 0 {
+		target = fmt.Sprint(s.TargetHeight)
+	}
+	return fmt.Sprintf("syncing [%s] %5.1f%% %d/%s ", bar, s.Percent, s.Height, target)
+}
+
+// isTerminal reports whether w is an interactive terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// notify tells systemd about a state change when running under it with
+// Type=notify, and does nothing otherwise
+func notify(logger *slog.Logger, state string) {
+	if _, err := util.Notify(state); err != nil {
+		logger.Warn("systemd notification failed", "state", state, "error", err)
+	}
+}
+
+// runWatchdog pings the systemd watchdog while the default wallet is
+// healthy or being restarted, so systemd restarts a manager that hangs
+// or has lost its services. It returns at once when WatchdogSec is not
+// set.
+func runWatchdog(ctx context.Context, logger *slog.Logger, manager *moneroger.Moneroger) {
+	interval := util.WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	wallet, err := manager.Wallet(moneroger.DefaultWalletName)
+	if err != nil {
+		logger.Warn("systemd watchdog disabled", "error", err)
+		return
+	}
+	logger.Debug("pinging systemd watchdog", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		err := wallet.CheckHealth(checkCtx)
+		cancel()
+		if err == nil || wallet.State() == util.StateStarting {
+			notify(logger, util.NotifyWatchdog)
+		} else {
+			logger.Warn("withholding systemd watchdog ping", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSystemd implements "moneroger systemd install": it writes a
+// Type=notify unit running this executable with the given flags.
+func runSystemd(args []string) error {
+	if len(args) == 0 || args[0] != "install" {
+		return fmt.Errorf("usage: %s systemd install [flags] -- [moneroger flags]", filepath.Base(os.Args[0]))
+	}
+	fs := flag.NewFlagSet("systemd install", flag.ExitOnError)
+	var (
+		unitDir  = fs.String("unit-dir", "/etc/systemd/system", "Directory to write the unit file to")
+		name     = fs.String("name", "moneroger", "Unit name, without .service")
+		user     = fs.String("user", "", "User to run the services as (default root)")
+		watchdog = fs.Duration("watchdog", time.Minute, "WatchdogSec of the unit, 0 to disable the watchdog")
+		printOut = fs.Bool("print", false, "Print the unit file instead of writing it")
+		force    = fs.Bool("force", false, "Overwrite an existing unit file")
+	)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s systemd install [flags] -- [moneroger flags]\n\nWrite a systemd unit starting moneroger with the given flags, e.g. -- -config /etc/moneroger.yaml\n\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args[1:])
+
+	for _, arg := range fs.Args() {
+		if strings.TrimLeft(arg, "-") == "detach" {
+			return fmt.Errorf("-detach cannot be used under systemd, which manages the process itself")
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the moneroger executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	unit := systemdUnit(exe, fs.Args(), *user, *watchdog)
+	if *printOut {
+		fmt.Print(unit)
+		return nil
+	}
+
+	path := filepath.Join(*unitDir, *name+".service")
+	if !*force && util.FileExists(path) {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+	}
+	if err := os.WriteFile(path, []byte(unit), 0o644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+	fmt.Printf("Wrote %s. Start it with:\n  systemctl daemon-reload\n  systemctl enable --now %s\n", path, *name)
+	return nil
+}
+
+// systemdUnit renders the unit file. Startup waits for READY=1, sent
+// once both services pass their health checks; SIGHUP reloads the
+// configuration.
+func systemdUnit(exe string, args []string, user string, watchdog time.Duration) string {
+	command := []string{systemdQuote(exe)}
+	for _, arg := range args {
+		command = append(command, systemdQuote(arg))
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=Monero daemon and wallet RPC managed by moneroger\n")
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=notify\n")
+	b.WriteString("NotifyAccess=main\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(command, " "))
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	b.WriteString("KillMode=mixed\n")
+	b.WriteString("TimeoutStartSec=10min\n")
+	b.WriteString("TimeoutStopSec=2min\n")
+	if watchdog > 0 {
+		fmt.Fprintf(&b, "WatchdogSec=%d\n", max(1, int(watchdog.Seconds())))
+	}
+	b.WriteString("Restart=on-failure\n")
+	if user != "" {
+		fmt.Fprintf(&b, "User=%s\n", user)
+	}
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+	return b.String()
+}
+
+// systemdQuote escapes an ExecStart argument: specifiers and variable
+// references are doubled, and arguments with spaces or quotes are
+// double-quoted.
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
@@ -16,11 +16,41 @@ const (
 	// This port is used by applications to communicate with the wallet
 	DefaultWalletRPCPort = 18083
 
+	// DefaultZMQPubPort is the port for monerod's ZMQ publisher (18086)
+	// It avoids the wallet port and the Tor and I2P inbound ports 18084-18085
+	DefaultZMQPubPort = 18086
+
+	// DefaultRestrictedPort is the conventional port for monerod's
+	// restricted RPC server on public nodes (18089)
+	DefaultRestrictedPort = 18089
+
 	// DefaultStartupTimeout defines how long to wait for daemons to start (30 seconds)
-	// If a daemon doesn't respond within this time, startup is considered failed
+	// If a daemon doesn't respond within this time, startup is considered failed;
+	// util.Config.StartupTimeout overrides it
 	DefaultStartupTimeout = 30 * time.Second
 
 	// DefaultShutdownTimeout defines how long to wait for graceful shutdown (10 seconds)
-	// After this timeout, the process will be forcefully terminated
+	// After this timeout, the process will be forcefully terminated;
+	// util.Config.ShutdownTimeout overrides it
 	DefaultShutdownTimeout = 10 * time.Second
+
+	// DefaultOutputBufferSize is how much child process output is retained (64 KiB)
+	// Only the most recent output is kept, for inclusion in error reports
+	DefaultOutputBufferSize = 64 * 1024
+
+	// DefaultHealthCacheTTL defines how long a health check result is reused (2 seconds)
+	// Concurrent callers within this window share a single RPC round trip
+	DefaultHealthCacheTTL = 2 * time.Second
+
+	// DefaultHealthInterval defines how often the manager checks running services (30 seconds)
+	// Each check is an RPC call, so hung services are noticed, not only exited ones
+	DefaultHealthInterval = 30 * time.Second
+
+	// DefaultAutosaveInterval defines how often the manager saves open wallets (5 minutes)
+	// Saving writes the whole wallet cache, so it is kept infrequent
+	DefaultAutosaveInterval = 5 * time.Minute
+
+	// DefaultPaymentInterval defines how often the manager looks for incoming payments (30 seconds)
+	// Only recent transfers are queried, so polling is cheap
+	DefaultPaymentInterval = 30 * time.Second
 )
@@ -0,0 +1,123 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// OpDetach is the operation name for errors from Detach
+	OpDetach errors.Op = "Detach"
+
+	// OpAttach is the operation name for errors from Attach
+	OpAttach errors.Op = "Attach"
+)
+
+// Detach lets the manager exit while monerod and the default wallet keep
+// running. Supervision and other background tasks stop, the ports are
+// recorded in the data directory, and the event stream is closed; the
+// services are left alone. A later Attach with the same configuration
+// manages them again.
+//
+// Returns:
+//   - error: KindConfig unless the services were started with
+//     Config.Detach, KindSystem if the state cannot be recorded
+//
+// Wallets added with AddWallet keep running as well but are not
+// reattached; adding them again with the same settings adopts them.
+// The manager must not be used after Detach.
+//
+// Related:
+//   - Attach
+//   - util.Config.Detach
+func (m *Moneroger) Detach() error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	config := m.currentConfig()
+	if !config.Detach || config.DataDir == "" {
+		return errors.E(OpDetach, errors.KindConfig,
+			fmt.Errorf("services were not started detached with a data directory"))
+	}
+
+	// Nothing may restart or stop the services from here on
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.daemonSup.stop()
+	m.walletSup.stop()
+	m.walletsMu.Lock()
+	m.walletsClosed = true
+	wallets := m.wallets
+	m.wallets = nil
+	m.walletsMu.Unlock()
+	for _, w := range wallets {
+		if w != nil {
+			w.sup.stop()
+		}
+	}
+
+	if err := m.writeInstance(0); err != nil {
+		return errors.E(OpDetach, errors.KindSystem, err)
+	}
+	if err := util.RemovePIDFile(filepath.Join(config.NetworkDataDir(), managerPIDFile)); err != nil {
+		m.logger().Warn("could not remove manager PID file", "error", err)
+	}
+	m.logger().Info("detached from running services", "daemon_port", m.DaemonRPCPort(), "wallet_port", m.WalletRPCPort())
+	m.emit(EventDetached, "", nil)
+	m.events.close()
+	return nil
+}
+
+// Attach manages services left running by Detach again, for example to
+// report their status or stop them from a later invocation. Nothing is
+// spawned: the daemon and wallet recorded in the data directory must
+// still be running.
+//
+// Parameters:
+//   - config: The configuration the services were started with; the
+//     recorded ports replace the configured ones and Detach is set
+//
+// Returns:
+//   - *Moneroger: Manager supervising the attached services
+//   - error: KindProcess if nothing was detached or a service is no
+//     longer running, or configuration validation errors
+//
+// Related:
+//   - Moneroger.Detach
+//   - monerod.AttachMoneroDaemon
+//   - monerowalletrpc.AttachWalletRPC
+func Attach(config util.Config) (*Moneroger, error) {
+	if config.DataDir == "" {
+		return nil, errors.E(OpAttach, errors.KindConfig, fmt.Errorf("data directory cannot be empty"))
+	}
+	instance, err := FindInstance(config.NetworkDataDir())
+	if err != nil {
+		return nil, err
+	}
+	if instance == nil || !instance.Detached() {
+		return nil, errors.E(OpAttach, errors.KindProcess,
+			fmt.Errorf("no detached services recorded in %s", config.NetworkDataDir()))
+	}
+
+	config = instance.apply(config)
+	config.Detach = true
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	daemon, err := monerod.AttachMoneroDaemon(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := monerowalletrpc.AttachWalletRPC(ctx, config, daemon)
+	if err != nil {
+		return nil, err
+	}
+	return newManager(config, daemon, wallet), nil
+}
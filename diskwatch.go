@@ -0,0 +1,114 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// OpDiskFallback is the operation name for errors from replacing monerod
+// with a remote node when the disk runs low
+const OpDiskFallback errors.Op = "DiskFallback"
+
+// diskState remembers which thresholds have been acted on, so each is
+// reported once until free space rises above it again.
+//
+// Fields:
+//   - warned: Warning thresholds in gigabytes free space is below
+//   - fellBack: Whether the fallback was attempted below its threshold
+type diskState struct {
+	warned   map[int]bool
+	fellBack bool
+}
+
+// lowThresholds returns the thresholds free space has newly dropped
+// below, and re-arms those it has risen above.
+func (s *diskState) lowThresholds(free uint64, thresholds []int) []int {
+	if s.warned == nil {
+		s.warned = make(map[int]bool)
+	}
+	var crossed []int
+	for _, gb := range thresholds {
+		below := free < uint64(gb)*util.Gigabyte
+		if below && !s.warned[gb] {
+			crossed = append(crossed, gb)
+		}
+		s.warned[gb] = below
+	}
+	return crossed
+}
+
+// fallbackDue reports whether free space has newly dropped below the
+// fallback threshold, and re-arms the fallback once it rises above it.
+func (s *diskState) fallbackDue(free uint64, gb int) bool {
+	below := gb > 0 && free < uint64(gb)*util.Gigabyte
+	due := below && !s.fellBack
+	s.fellBack = below
+	return due
+}
+
+// watchDisk checks free space under the data directory at the configured
+// interval, warning as it crosses each threshold and replacing the local
+// daemon with a remote node before the blockchain fills the disk. It only
+// runs when Config.DiskWatch is enabled.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchDisk(ctx context.Context) {
+	if config := m.currentConfig(); !config.DiskWatch.Enabled() || config.DataDir == "" {
+		return
+	}
+	var state diskState
+	for {
+		interval := m.currentConfig().DiskWatch.EffectiveInterval()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(util.Jitter(interval, 0.1)):
+		}
+		m.checkDisk(ctx, &state)
+	}
+}
+
+// checkDisk measures free space once and acts on the thresholds crossed.
+func (m *Moneroger) checkDisk(ctx context.Context, state *diskState) {
+	config := m.currentConfig()
+	free := util.AvailableSpace(config.DataDir)
+	for _, gb := range state.lowThresholds(free, config.DiskWatch.WarnGB) {
+		err := fmt.Errorf("%.1f GB free under %s, below %d GB", float64(free)/float64(util.Gigabyte), config.DataDir, gb)
+		m.logger().Warn("disk space low", "path", config.DataDir, "free", free, "threshold_gb", gb)
+		m.emit(EventDiskSpaceLow, "", err)
+	}
+	// With a remote node there is no local daemon left to stop
+	if !state.fallbackDue(free, config.DiskWatch.FallbackGB) || len(config.RemoteNodeList()) > 0 {
+		return
+	}
+	err := m.diskFallback(ctx, config)
+	if err != nil {
+		m.logger().Error("disk space fallback failed", "error", err)
+	}
+	m.emit(EventDiskFallback, errors.ComponentMonerod, err)
+}
+
+// diskFallback stops monerod and switches every wallet to a remote node,
+// by reloading the configuration with the fallback nodes as remote nodes.
+// The blockchain stays on disk; a later Reload with the original
+// configuration starts monerod on it again.
+//
+// Returns:
+//   - error: KindConfig without a remote node to switch to, otherwise
+//     any Reload error
+func (m *Moneroger) diskFallback(ctx context.Context, config util.Config) error {
+	nodes := config.DiskFallbackNodes(ctx)
+	if len(nodes) == 0 {
+		return errors.E(OpDiskFallback, errors.ComponentMonerod, errors.KindConfig,
+			fmt.Errorf("no remote node to fall back to, set DiskWatch.FallbackNodes"))
+	}
+	m.logger().Warn("disk nearly full, stopping monerod and switching wallets to a remote node",
+		"path", config.DataDir, "node", nodes[0])
+	config.RemoteNode, config.RemoteNodes = nodes[0], nodes[1:]
+	return m.reload(ctx, config)
+}
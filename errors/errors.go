@@ -13,6 +13,21 @@ const (
 
 	// ComponentUtil identifies the utility functions component
 	ComponentUtil = "util"
+
+	// ComponentRPC identifies the JSON-RPC client component
+	ComponentRPC = "rpc"
+
+	// ComponentFetch identifies the release download component
+	ComponentFetch = "fetch"
+
+	// ComponentGRPC identifies the gRPC management API component
+	ComponentGRPC = "grpc"
+
+	// ComponentBlockchain identifies the blockchain import and export tools
+	ComponentBlockchain = "blockchain"
+
+	// ComponentPayments identifies the payment request tracker
+	ComponentPayments = "payments"
 )
 
 // Common operations represent standard actions performed across components.
@@ -49,11 +64,14 @@ const (
 // Returns:
 //   - error: A new Error instance with fields set based on the provided arguments
 //
+// With SetTrace enabled, the error also records the stack of its caller,
+// printed by the %+v verb.
+//
 // Example:
 //
 //	E(OpStart, ComponentMonerod, KindNetwork, err)
 func E(args ...interface{}) error {
-	e := &Error{}
+	e := &Error{stack: callers()}
 	for _, arg := range args {
 		switch a := arg.(type) {
 		case Op:
@@ -1,7 +1,10 @@
 package errors
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"strings"
 	"testing"
 )
 
@@ -160,6 +163,12 @@ func TestKindString(t *testing.T) {
 		{KindConfig, "configuration error"},
 		{KindTimeout, "timeout error"},
 		{KindSystem, "system error"},
+		{KindRPC, "rpc error"},
+		{KindAuth, "authentication error"},
+		{KindWallet, "wallet error"},
+		{KindSync, "sync error"},
+		{KindQuota, "quota exceeded"},
+		{KindDevice, "device error"},
 		{KindUnknown, "unknown error"},
 		{Kind(99), "unknown error"}, // Invalid kind
 	}
@@ -172,3 +181,65 @@ func TestKindString(t *testing.T) {
 		})
 	}
 }
+
+// TestTrace verifies stacks are recorded only with SetTrace, and printed
+// for every layer by %+v
+func TestTrace(t *testing.T) {
+	inner := E(OpStart, ComponentMonerod, KindTimeout, fmt.Errorf("not ready"))
+	if got := fmt.Sprintf("%+v", inner); got != inner.Error() {
+		t.Errorf("%%+v without tracing = %q, want %q", got, inner.Error())
+	}
+
+	SetTrace(true)
+	defer SetTrace(false)
+	inner = E(OpStart, ComponentMonerod, KindTimeout, fmt.Errorf("not ready"))
+	outer := E(Op("Moneroger.Start"), KindProcess, fmt.Errorf("starting: %w", inner))
+
+	stack := outer.(*Error).Stack()
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestTrace") {
+		t.Fatalf("Stack() = %v, want TestTrace first", stack)
+	}
+	if got := fmt.Sprintf("%v", outer); got != outer.Error() {
+		t.Errorf("%%v = %q, want %q", got, outer.Error())
+	}
+	got := fmt.Sprintf("%+v", outer)
+	for _, want := range []string{outer.Error(), ": Moneroger.Start:", "monerod: Start:", "errors_test.go:"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%%+v = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+// TestIsRetryable verifies retry classification by kind and by mark
+func TestIsRetryable(t *testing.T) {
+	network := E(OpStart, ComponentMonerod, KindNetwork, fmt.Errorf("refused"))
+	config := E(OpStart, ComponentMonerod, KindConfig, fmt.Errorf("bad port"))
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"network", network, true},
+		{"sync", E(KindSync), true},
+		{"config", config, false},
+		{"auth", E(KindAuth), false},
+		{"wrapped network", fmt.Errorf("probe: %w", network), true},
+		{"canceled", E(KindNetwork, context.Canceled), false},
+		{"deadline", E(KindNetwork, context.DeadlineExceeded), true},
+		{"net.Error", &net.OpError{Op: "dial", Err: fmt.Errorf("refused")}, true},
+		{"plain", fmt.Errorf("plain"), false},
+		{"permanent network", Permanent(network), false},
+		{"transient config", Transient(config), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+	if GetKind(Transient(config)) != KindConfig {
+		t.Error("GetKind() should see through Transient")
+	}
+}
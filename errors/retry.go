@@ -0,0 +1,94 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// Retryable reports whether errors of this kind are usually transient,
+// so the failed operation may succeed when attempted again: network
+// failures, timeouts and a daemon busy syncing. Configuration,
+// authentication, wallet, process and system errors need a change
+// before a new attempt can succeed.
+func (k Kind) Retryable() bool {
+	switch k {
+	case KindNetwork, KindTimeout, KindSync:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryMark overrides the retry classification of the error it wraps.
+type retryMark struct {
+	err   error
+	retry bool
+}
+
+func (m *retryMark) Error() string { return m.err.Error() }
+
+func (m *retryMark) Unwrap() error { return m.err }
+
+// Transient marks err as retryable regardless of its kind, such as an
+// RPC error known to clear up on its own.
+//
+// Parameters:
+//   - err: The error to mark, nil returns nil
+//
+// Returns:
+//   - error: err, reported as retryable by IsRetryable; errors.Is,
+//     errors.As and GetKind see through the mark
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryMark{err: err, retry: true}
+}
+
+// Permanent marks err as not retryable regardless of its kind, such as a
+// network error caused by an invalid address.
+//
+// Parameters:
+//   - err: The error to mark, nil returns nil
+//
+// Returns:
+//   - error: err, reported as not retryable by IsRetryable
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryMark{err: err, retry: false}
+}
+
+// IsRetryable reports whether the operation that returned err may
+// succeed when attempted again.
+//
+// Parameters:
+//   - err: The error to classify
+//
+// Returns:
+//   - bool: The outermost Transient or Permanent mark when there is one;
+//     otherwise false for nil and context.Canceled, Kind.Retryable for
+//     an Error, and true for net.Error values such as dial failures
+//
+// Related:
+//   - util.Retry, which retries until an error is not retryable
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var mark *retryMark
+	if errors.As(err, &mark) {
+		return mark.retry
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+)
+
+// maxStackDepth is the number of frames E records when tracing is enabled
+const maxStackDepth = 8
+
+// tracing reports whether E records where errors are created
+var tracing atomic.Bool
+
+// SetTrace enables or disables recording of the call stack in E. Tracing
+// is off by default, since capturing stacks costs time on every error;
+// enable it when debugging failures that cross several layers, such as
+// process startup, and print errors with %+v.
+//
+// Parameters:
+//   - enabled: Whether errors created from now on record their stack
+//
+// Related:
+//   - Error.Stack
+//   - Error.Format
+func SetTrace(enabled bool) {
+	tracing.Store(enabled)
+}
+
+// callers returns the stack of the caller of E, or nil when tracing is
+// disabled.
+func callers() []uintptr {
+	if !tracing.Load() {
+		return nil
+	}
+	pcs := make([]uintptr, maxStackDepth)
+	// Skip runtime.Callers, callers and E
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// Stack returns the frames recorded when the error was created, starting
+// with the function that called E.
+//
+// Returns:
+//   - []runtime.Frame: Up to 8 frames, nil unless tracing was enabled
+//     with SetTrace
+func (e *Error) Stack() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			return stack
+		}
+	}
+}
+
+// Format implements fmt.Formatter. The %s and %v verbs print the message
+// returned by Error; %+v adds, for every Error in the chain that recorded
+// a stack, its component, operation and frames.
+//
+// Example output of %+v:
+//
+//	monerod: Start: timeout error: ...
+//	monerod: Start:
+//	    github.com/opd-ai/moneroger/monerod.(*MoneroDaemon).start
+//	        /src/moneroger/monerod/monerod.go:312
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.Error())
+		if !s.Flag('+') {
+			return
+		}
+		for err := error(e); err != nil; err = errors.Unwrap(err) {
+			layer, ok := err.(*Error)
+			if !ok || len(layer.stack) == 0 {
+				continue
+			}
+			fmt.Fprintf(s, "\n%s: %s:", layer.Component, layer.Op)
+			for _, frame := range layer.Stack() {
+				fmt.Fprintf(s, "\n    %s\n        %s:%d", frame.Function, frame.File, frame.Line)
+			}
+		}
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(*errors.Error=%s)", verb, e.Error())
+	}
+}
@@ -47,6 +47,40 @@ const (
 	// - Resource exhaustion
 	// - System call failures
 	KindSystem
+
+	// KindRPC represents errors reported by a service over RPC such as:
+	// - JSON-RPC error responses
+	// - Non-OK status fields
+	// - Responses that cannot be decoded
+	KindRPC
+
+	// KindAuth represents authentication failures such as:
+	// - Wrong RPC username or password
+	// - Access denied by the server
+	KindAuth
+
+	// KindWallet represents wallet-level errors such as:
+	// - Wrong wallet password
+	// - Wallet file not found or already existing
+	// - No wallet open
+	KindWallet
+
+	// KindSync represents conditions caused by blockchain synchronization
+	// such as:
+	// - Daemon busy syncing
+	// - Wallet waiting for the daemon to catch up
+	KindSync
+
+	// KindQuota represents limits set by the manager being reached such
+	// as:
+	// - A tenant exceeding its RPC call rate
+	// - A tenant holding its maximum number of wallet files
+	KindQuota
+
+	// KindDevice represents hardware wallet failures such as:
+	// - Ledger or Trezor disconnected or not found
+	// - Device locked or its Monero app not open
+	KindDevice
 )
 
 // Error is the fundamental error type for the moneroger library.
@@ -58,6 +92,7 @@ const (
 //   - Component: The system component (e.g., "monerod", "wallet-rpc")
 //   - Kind: The category of error
 //   - Err: The underlying error (if any)
+//   - stack: Where E created the error, recorded only with SetTrace
 //
 // Usage:
 //
@@ -79,6 +114,10 @@ type Error struct {
 
 	// Err is the underlying error that triggered this error, if any
 	Err error
+
+	// stack holds the program counters recorded by E while tracing is
+	// enabled with SetTrace
+	stack []uintptr
 }
 
 // Error implements the error interface, providing a formatted error message.
@@ -126,6 +165,18 @@ func (k Kind) String() string {
 		return "timeout error"
 	case KindSystem:
 		return "system error"
+	case KindRPC:
+		return "rpc error"
+	case KindAuth:
+		return "authentication error"
+	case KindWallet:
+		return "wallet error"
+	case KindSync:
+		return "sync error"
+	case KindQuota:
+		return "quota exceeded"
+	case KindDevice:
+		return "device error"
 	default:
 		return "unknown error"
 	}
@@ -0,0 +1,228 @@
+package moneroger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// eventBufferSize is the per-subscriber channel capacity. Events are dropped
+// for subscribers that fall this far behind rather than blocking the manager.
+const eventBufferSize = 32
+
+// EventType identifies a lifecycle transition of the managed services.
+type EventType uint8
+
+// Lifecycle event types emitted by the manager.
+const (
+	EventUnknown            EventType = iota // Unrecognized event
+	EventDaemonStarted                       // monerod is running (spawned or adopted)
+	EventDaemonCrashed                       // monerod exited unexpectedly
+	EventDaemonRestarted                     // monerod was restarted after a crash
+	EventWalletReady                         // wallet-rpc is running and passed its health check
+	EventWalletCrashed                       // wallet-rpc exited unexpectedly
+	EventWalletRestarted                     // wallet-rpc was restarted after a crash
+	EventShutdownBegan                       // Shutdown was called
+	EventShutdownComplete                    // All services have stopped
+	EventRemoteNodeSwitched                  // A wallet failed over to another remote node
+	EventLocalChainTakeover                  // The local chain replaced the bootstrap daemon
+	EventConfigReloaded                      // Reload applied a new configuration
+	EventDetached                            // Detach left the services running
+	EventServiceDegraded                     // A running service failed its health check
+	EventServiceRecovered                    // A degraded service passed its health check again
+	EventDiskSpaceLow                        // Free space under DataDir dropped below a warning threshold
+	EventDiskFallback                        // Low disk space replaced monerod with a remote node
+	EventPaymentReceived                     // A wallet received a payment, see Event.Payment
+)
+
+// String returns a human-readable name for the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventDaemonStarted:
+		return "daemon-started"
+	case EventDaemonCrashed:
+		return "daemon-crashed"
+	case EventDaemonRestarted:
+		return "daemon-restarted"
+	case EventWalletReady:
+		return "wallet-ready"
+	case EventWalletCrashed:
+		return "wallet-crashed"
+	case EventWalletRestarted:
+		return "wallet-restarted"
+	case EventShutdownBegan:
+		return "shutdown-began"
+	case EventShutdownComplete:
+		return "shutdown-complete"
+	case EventRemoteNodeSwitched:
+		return "remote-node-switched"
+	case EventLocalChainTakeover:
+		return "local-chain-takeover"
+	case EventConfigReloaded:
+		return "config-reloaded"
+	case EventDetached:
+		return "detached"
+	case EventServiceDegraded:
+		return "service-degraded"
+	case EventServiceRecovered:
+		return "service-recovered"
+	case EventDiskSpaceLow:
+		return "disk-space-low"
+	case EventDiskFallback:
+		return "disk-fallback"
+	case EventPaymentReceived:
+		return "payment-received"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a lifecycle transition.
+//
+// Fields:
+//   - Type: What happened
+//   - Component: Affected component (errors.ComponentMonerod, errors.ComponentWalletRPC),
+//     empty for manager-wide events
+//   - Wallet: Wallet name for wallet events, DefaultWalletName for the
+//     default wallet
+//   - Tenant: ID of the tenant owning the wallet, empty for wallets
+//     added without AddTenant
+//   - Time: When the event was emitted
+//   - Err: Associated error, e.g. the exit error of a crashed process
+//   - Payment: The payment of an EventPaymentReceived, nil otherwise
+type Event struct {
+	Type      EventType
+	Component string
+	Wallet    string
+	Tenant    string
+	Time      time.Time
+	Err       error
+	Payment   *PaymentReceived
+}
+
+// eventBus fans events out to subscribers without ever blocking the publisher.
+// The zero value is ready to use.
+//
+// Fields:
+//   - subs: Subscriber channels, keyed by their receive-only view
+//   - filters: Events accepted by subscribers that only want some
+//   - closed: Whether close was called
+type eventBus struct {
+	mu      sync.Mutex
+	subs    map[<-chan Event]chan Event
+	filters map[<-chan Event]func(Event) bool
+	closed  bool
+}
+
+// subscribe registers a new subscriber channel receiving every event.
+func (b *eventBus) subscribe() <-chan Event {
+	return b.subscribeFiltered(nil)
+}
+
+// subscribeFiltered registers a new subscriber channel receiving the
+// events accepted by filter, or every event when filter is nil.
+func (b *eventBus) subscribeFiltered(filter func(Event) bool) <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, eventBufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	if b.subs == nil {
+		b.subs = make(map[<-chan Event]chan Event)
+	}
+	b.subs[ch] = ch
+	if filter != nil {
+		if b.filters == nil {
+			b.filters = make(map[<-chan Event]func(Event) bool)
+		}
+		b.filters[ch] = filter
+	}
+	return ch
+}
+
+// unsubscribe removes and closes a subscriber channel.
+func (b *eventBus) unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		delete(b.filters, ch)
+		close(c)
+	}
+}
+
+// publish delivers ev to every subscriber with room in its buffer.
+func (b *eventBus) publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key, c := range b.subs {
+		if filter := b.filters[key]; filter != nil && !filter(ev) {
+			continue
+		}
+		select {
+		case c <- ev:
+		default:
+		}
+	}
+}
+
+// close closes all subscriber channels; later subscribers get a closed channel.
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for key, c := range b.subs {
+		delete(b.subs, key)
+		delete(b.filters, key)
+		close(c)
+	}
+	b.closed = true
+}
+
+// Subscribe returns a channel receiving lifecycle events such as
+// EventDaemonStarted, EventDaemonCrashed, EventWalletReady,
+// EventShutdownBegan and EventShutdownComplete.
+//
+// Returns:
+//   - <-chan Event: Buffered event stream, closed after EventShutdownComplete
+//     or when passed to Unsubscribe
+//
+// Events are delivered without blocking the manager; a subscriber that
+// falls more than 32 events behind misses events until it catches up.
+//
+// Example:
+//
+//	events := manager.Subscribe()
+//	for ev := range events {
+//	    log.Printf("%s %s %v", ev.Type, ev.Component, ev.Err)
+//	}
+func (m *Moneroger) Subscribe() <-chan Event {
+	return m.events.subscribe()
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and closes it.
+func (m *Moneroger) Unsubscribe(ch <-chan Event) {
+	m.events.unsubscribe(ch)
+}
+
+// emit publishes an event for component.
+func (m *Moneroger) emit(t EventType, component string, err error) {
+	m.events.publish(Event{Type: t, Component: component, Err: err})
+}
+
+// emitWallet publishes a wallet event for the named wallet, tagged with
+// the tenant owning it.
+func (m *Moneroger) emitWallet(t EventType, name string, err error) {
+	m.events.publish(Event{
+		Type:      t,
+		Component: errors.ComponentWalletRPC,
+		Wallet:    name,
+		Tenant:    m.walletTenant(name),
+		Err:       err,
+	})
+}
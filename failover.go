@@ -0,0 +1,107 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+)
+
+const (
+	// remoteNodeCheckInterval is how often the current remote node is probed
+	remoteNodeCheckInterval = 30 * time.Second
+
+	// remoteNodeFailureThreshold is how many consecutive failed probes
+	// trigger a failover, so one dropped request does not cause a switch
+	remoteNodeFailureThreshold = 2
+)
+
+// watchRemoteNodes probes the remote node of every wallet and fails over
+// to the next configured node once it stops answering. It only runs when
+// fallback remote nodes are configured, and not over Tor, since the
+// probes connect directly and would reveal the host's address.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchRemoteNodes(ctx context.Context) {
+	if config := m.currentConfig(); len(config.RemoteNodeList()) < 2 || config.Tor.Enabled() {
+		return
+	}
+	failures := make(map[string]int)
+	ticker := time.NewTicker(remoteNodeCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.eachWallet(func(name string, w *monerowalletrpc.WalletRPC) {
+			m.checkRemoteNode(ctx, name, w, failures)
+		})
+	}
+}
+
+// checkRemoteNode probes one wallet's remote node and switches it after
+// remoteNodeFailureThreshold consecutive failures.
+//
+// Parameters:
+//   - ctx: Context for the probe and switch
+//   - name: Wallet name, used for events and failure counting
+//   - w: The wallet whose node is probed
+//   - failures: Consecutive failure counts by wallet name
+func (m *Moneroger) checkRemoteNode(ctx context.Context, name string, w *monerowalletrpc.WalletRPC, failures map[string]int) {
+	if w.Stopping() {
+		return
+	}
+	err := w.CheckRemoteNode(ctx)
+	if err == nil {
+		failures[name] = 0
+		return
+	}
+	failures[name]++
+	m.logger().Warn("remote node probe failed", "wallet", name, "node", w.RemoteNode(), "failures", failures[name], "error", err)
+	if failures[name] < remoteNodeFailureThreshold {
+		return
+	}
+	failures[name] = 0
+	node, restart, err := w.SwitchRemoteNode(ctx)
+	if err == nil && restart {
+		err = m.restartWallet(ctx, name, w)
+	}
+	if err != nil {
+		m.logger().Error("remote node failover failed", "wallet", name, "node", node, "error", err)
+	}
+	m.emitWallet(EventRemoteNodeSwitched, name, err)
+}
+
+// restartWallet restarts one wallet with its supervision paused, so the
+// deliberate exit is not taken for a crash.
+//
+// Parameters:
+//   - ctx: Context for the restart
+//   - name: Wallet name, DefaultWalletName for the default wallet
+//   - w: The wallet to restart
+//
+// Returns:
+//   - error: Any error stopping or starting the wallet, or KindProcess
+//     when the manager was shut down
+func (m *Moneroger) restartWallet(ctx context.Context, name string, w *monerowalletrpc.WalletRPC) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if m.closed() {
+		return errors.E(OpRestart, errors.ComponentWalletRPC, errors.KindProcess, fmt.Errorf("manager was shut down"))
+	}
+	m.logger().Info("restarting monero-wallet-rpc against new remote node", "wallet", name, "node", w.RemoteNode())
+	defer m.pauseWalletSupervision(name)()
+	if w.Alive() {
+		if err := w.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+	// The process must outlive the watcher's context, so that shutdown
+	// stops it gracefully
+	return w.Start(context.WithoutCancel(ctx))
+}
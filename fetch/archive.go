@@ -0,0 +1,110 @@
+package fetch
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// stagingSuffix marks extracted executables not yet renamed into place
+const stagingSuffix = ".download"
+
+// maxExecutableSize bounds each extracted executable
+const maxExecutableSize = 1 << 30
+
+// extract copies the executables listed in Executables out of a release
+// archive into dir, each under its final name plus stagingSuffix.
+//
+// Returns:
+//   - map[string]string: Staged file by executable name, including
+//     partial files on error, for the caller to rename or remove
+//   - error: Unreadable archives or write failures
+func extract(archivePath, archive, dir string) (map[string]string, error) {
+	staged := make(map[string]string)
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return staged, err
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(archive, ".zip") {
+		info, err := f.Stat()
+		if err != nil {
+			return staged, err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return staged, err
+		}
+		for _, file := range zr.File {
+			name, ok := executableName(file.Name)
+			if !ok || file.FileInfo().IsDir() {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return staged, err
+			}
+			err = stage(staged, dir, name, path.Base(file.Name), rc)
+			rc.Close()
+			if err != nil {
+				return staged, err
+			}
+		}
+		return staged, nil
+	}
+
+	tr := tar.NewReader(bzip2.NewReader(f))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return staged, nil
+		}
+		if err != nil {
+			return staged, fmt.Errorf("%s: %w", archive, err)
+		}
+		name, ok := executableName(header.Name)
+		if !ok || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := stage(staged, dir, name, path.Base(header.Name), tr); err != nil {
+			return staged, err
+		}
+	}
+}
+
+// executableName returns which of Executables an archive entry is, such
+// as "monerod" for "monero-x86_64-linux-gnu-v0.18.3.4/monerod".
+func executableName(entry string) (string, bool) {
+	base := strings.TrimSuffix(path.Base(entry), ".exe")
+	for _, name := range Executables {
+		if base == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// stage writes one executable to dir as file plus stagingSuffix.
+func stage(staged map[string]string, dir, name, file string, r io.Reader) error {
+	dest := filepath.Join(dir, file+stagingSuffix)
+	staged[name] = dest
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(out, io.LimitReader(r, maxExecutableSize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n > maxExecutableSize {
+		err = fmt.Errorf("%s is larger than %d bytes", file, maxExecutableSize)
+	}
+	return err
+}
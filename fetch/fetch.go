@@ -0,0 +1,319 @@
+// Package fetch provides functionality for downloading official Monero
+// release binaries. It verifies the release against the signed hash list
+// published by the Monero project and installs monerod and
+// monero-wallet-rpc into util.BinDir, where the rest of moneroger finds
+// them.
+package fetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// Operation names for fetch errors
+const (
+	opInstall  = errors.Op("Fetch.Install")
+	opDownload = errors.Op("Fetch.Download")
+	opVerify   = errors.Op("Fetch.Verify")
+	opExtract  = errors.Op("Fetch.Extract")
+)
+
+// Official release locations
+const (
+	// DefaultBaseURL is where release archives are downloaded from
+	DefaultBaseURL = "https://downloads.getmonero.org/cli/"
+
+	// DefaultHashesURL is the signed list of release archive hashes
+	DefaultHashesURL = "https://www.getmonero.org/downloads/hashes.txt"
+
+	// DefaultKeyURL is the public key signing the hash list
+	DefaultKeyURL = "https://raw.githubusercontent.com/monero-project/monero/master/utils/gpg_keys/binaryfate.asc"
+
+	// SigningKeyFingerprint identifies binaryFate's key, which signs the
+	// hash list. A downloaded key is only trusted if it matches.
+	SigningKeyFingerprint = "81AC591FE9C4B65C5806AFC3F0AF4D462A0BDF92"
+)
+
+// maxHashesSize bounds the download of the hash list and signing key
+const maxHashesSize = 1 << 20
+
+// Executables are the programs installed from a release archive.
+var Executables = []string{"monerod", "monero-wallet-rpc"}
+
+// Options configures Install. The zero value installs the latest release
+// for the running platform into util.BinDir.
+//
+// Fields:
+//   - BinDir: Installation directory, util.BinDir when empty
+//   - Version: Release such as "v0.18.3.4", the latest listed when empty
+//   - GOOS, GOARCH: Target platform, the running one when empty
+//   - BaseURL, HashesURL, KeyURL: Download locations, the official ones
+//     when empty
+//   - Fingerprint: Required signing key, SigningKeyFingerprint when empty
+//   - SkipSignature: Trust the hash list without checking its signature,
+//     for systems without gpg. Archive hashes are still checked, but
+//     only against an unauthenticated list.
+//   - Client: HTTP client, http.DefaultClient when nil
+//   - Logger: Progress messages, slog.Default() when nil
+type Options struct {
+	BinDir        string
+	Version       string
+	GOOS          string
+	GOARCH        string
+	BaseURL       string
+	HashesURL     string
+	KeyURL        string
+	Fingerprint   string
+	SkipSignature bool
+	Client        *http.Client
+	Logger        *slog.Logger
+}
+
+// withDefaults fills in the empty fields of o.
+func (o Options) withDefaults() Options {
+	if o.BinDir == "" {
+		o.BinDir = util.BinDir()
+	}
+	if o.GOOS == "" {
+		o.GOOS = runtime.GOOS
+	}
+	if o.GOARCH == "" {
+		o.GOARCH = runtime.GOARCH
+	}
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultBaseURL
+	}
+	if o.HashesURL == "" {
+		o.HashesURL = DefaultHashesURL
+	}
+	if o.KeyURL == "" {
+		o.KeyURL = DefaultKeyURL
+	}
+	if o.Fingerprint == "" {
+		o.Fingerprint = SigningKeyFingerprint
+	}
+	if o.Client == nil {
+		o.Client = http.DefaultClient
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	o.Logger = o.Logger.With("component", errors.ComponentFetch)
+	return o
+}
+
+// Result describes an installed release.
+//
+// Fields:
+//   - Version: Installed release, such as "v0.18.3.4"
+//   - Archive: Name of the verified release archive
+//   - SHA256: Hash of the archive, as listed in the signed hash list
+//   - Installed: Paths of the installed executables
+type Result struct {
+	Version   string
+	Archive   string
+	SHA256    string
+	Installed []string
+}
+
+// Install downloads a Monero release, verifies it and installs monerod
+// and monero-wallet-rpc.
+//
+// Parameters:
+//   - ctx: Context bounding the downloads
+//   - opts: Release, platform and locations; see Options
+//
+// Returns:
+//   - *Result: The installed release
+//   - error: KindConfig for unsupported platforms or unknown versions,
+//     KindNetwork for download failures, KindSystem for signature or
+//     hash mismatches and installation failures
+//
+// The steps are:
+// 1. Download the hash list and verify its signature with gpg against
+// the pinned signing key
+// 2. Pick the archive for the platform and version from the signed list
+// 3. Download the archive and compare its SHA-256 hash
+// 4. Extract the executables into the bin directory, replacing earlier
+// versions only once everything succeeded
+//
+// Related:
+//   - util.BinDir for the default installation directory
+//   - util.Path, which searches it
+func Install(ctx context.Context, opts Options) (*Result, error) {
+	opts = opts.withDefaults()
+	if opts.BinDir == "" {
+		return nil, errors.E(opInstall, errors.ComponentFetch, errors.KindConfig,
+			fmt.Errorf("no bin directory: set %s_BIN_DIR", util.EnvPrefix))
+	}
+
+	hashes, err := opts.hashList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version := opts.Version
+	if version == "" {
+		version, err = LatestVersion(hashes, opts.GOOS, opts.GOARCH)
+		if err != nil {
+			return nil, errors.E(opInstall, errors.ComponentFetch, errors.KindConfig, err)
+		}
+	}
+	archive, err := ArchiveName(opts.GOOS, opts.GOARCH, version)
+	if err != nil {
+		return nil, errors.E(opInstall, errors.ComponentFetch, errors.KindConfig, err)
+	}
+	want, ok := hashes[archive]
+	if !ok {
+		return nil, errors.E(opInstall, errors.ComponentFetch, errors.KindConfig,
+			fmt.Errorf("%s is not in the signed hash list", archive))
+	}
+
+	opts.Logger.Info("downloading Monero release", "archive", archive)
+	path, err := opts.downloadArchive(ctx, archive, want)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	installed, err := install(path, archive, opts.BinDir)
+	if err != nil {
+		return nil, err
+	}
+	opts.Logger.Info("installed Monero release", "version", version, "dir", opts.BinDir)
+	return &Result{Version: version, Archive: archive, SHA256: want, Installed: installed}, nil
+}
+
+// hashList downloads the hash list and returns the archive hashes it
+// lists, taken only from the signed part unless SkipSignature is set.
+func (o Options) hashList(ctx context.Context) (map[string]string, error) {
+	list, err := o.get(ctx, o.HashesURL, maxHashesSize)
+	if err != nil {
+		return nil, err
+	}
+	if o.SkipSignature {
+		o.Logger.Warn("not verifying the signature of the hash list")
+		return ParseHashes(list), nil
+	}
+	key, err := o.get(ctx, o.KeyURL, maxHashesSize)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := VerifySignature(ctx, list, key, o.Fingerprint)
+	if err != nil {
+		return nil, errors.E(opVerify, errors.ComponentFetch, errors.KindSystem, err)
+	}
+	return ParseHashes(signed), nil
+}
+
+// get downloads a small file into memory.
+func (o Options) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	body, err := o.open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, limit))
+	if err != nil {
+		return nil, errors.E(opDownload, errors.ComponentFetch, errors.KindNetwork, err)
+	}
+	return data, nil
+}
+
+// open starts a download, failing on HTTP errors.
+func (o Options) open(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.E(opDownload, errors.ComponentFetch, errors.KindConfig, err)
+	}
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, errors.E(opDownload, errors.ComponentFetch, errors.KindNetwork, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, errors.E(opDownload, errors.ComponentFetch, errors.KindNetwork,
+			fmt.Errorf("GET %s: %s", url, resp.Status))
+	}
+	return resp.Body, nil
+}
+
+// downloadArchive saves the archive to a temporary file and checks its
+// SHA-256 hash.
+//
+// Returns:
+//   - string: Path of the verified archive, to be removed by the caller
+//   - error: KindNetwork for download failures, KindSystem for a hash
+//     mismatch or temporary file errors
+func (o Options) downloadArchive(ctx context.Context, archive, want string) (string, error) {
+	body, err := o.open(ctx, strings.TrimSuffix(o.BaseURL, "/")+"/"+archive)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	f, err := os.CreateTemp("", "moneroger-*-"+archive)
+	if err != nil {
+		return "", errors.E(opDownload, errors.ComponentFetch, errors.KindSystem, err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", errors.E(opDownload, errors.ComponentFetch, errors.KindNetwork, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, want) {
+		os.Remove(f.Name())
+		return "", errors.E(opVerify, errors.ComponentFetch, errors.KindSystem,
+			fmt.Errorf("%s: SHA-256 is %s, the signed hash list says %s", archive, got, want))
+	}
+	return f.Name(), nil
+}
+
+// install extracts the executables from a verified archive into binDir.
+// They are extracted next to their destination first and renamed into
+// place once all succeeded, so a failure leaves earlier versions intact.
+func install(archivePath, archive, binDir string) ([]string, error) {
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return nil, errors.E(opExtract, errors.ComponentFetch, errors.KindSystem, err)
+	}
+	staged, err := extract(archivePath, archive, binDir)
+	defer func() {
+		for _, tmp := range staged {
+			os.Remove(tmp)
+		}
+	}()
+	if err != nil {
+		return nil, errors.E(opExtract, errors.ComponentFetch, errors.KindSystem, err)
+	}
+	for _, name := range Executables {
+		if _, ok := staged[name]; !ok {
+			return nil, errors.E(opExtract, errors.ComponentFetch, errors.KindSystem,
+				fmt.Errorf("%s does not contain %s", archive, name))
+		}
+	}
+
+	var installed []string
+	for _, name := range Executables {
+		dest := strings.TrimSuffix(staged[name], stagingSuffix)
+		if err := os.Rename(staged[name], dest); err != nil {
+			return nil, errors.E(opExtract, errors.ComponentFetch, errors.KindSystem, err)
+		}
+		delete(staged, name)
+		installed = append(installed, dest)
+	}
+	return installed, nil
+}
@@ -0,0 +1,198 @@
+package fetch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// TestArchiveName verifies release archive naming per platform
+func TestArchiveName(t *testing.T) {
+	tests := []struct {
+		goos, goarch, version string
+		want                  string
+		wantErr               bool
+	}{
+		{"linux", "amd64", "v0.18.3.4", "monero-linux-x64-v0.18.3.4.tar.bz2", false},
+		{"darwin", "arm64", "0.18.3.4", "monero-mac-armv8-v0.18.3.4.tar.bz2", false},
+		{"windows", "amd64", "v0.18.3.4", "monero-win-x64-v0.18.3.4.zip", false},
+		{"plan9", "amd64", "v0.18.3.4", "", true},
+		{"linux", "amd64", "latest", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ArchiveName(tt.goos, tt.goarch, tt.version)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ArchiveName(%s, %s, %s) = %q, %v; want %q, error %v",
+				tt.goos, tt.goarch, tt.version, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestParseHashes verifies only the signed part of a clearsigned list is
+// read, and the newest version is picked per platform
+func TestParseHashes(t *testing.T) {
+	a, b, c := strings.Repeat("a", 64), strings.Repeat("B", 64), strings.Repeat("c", 64)
+	list := fmt.Sprintf(`-----BEGIN PGP SIGNED MESSAGE-----
+Hash: SHA256
+
+# comment
+%s  monero-linux-x64-v0.18.3.4.tar.bz2
+%s  monero-linux-x64-v0.18.10.0.tar.bz2
+-----BEGIN PGP SIGNATURE-----
+
+xyz
+-----END PGP SIGNATURE-----
+%s  monero-linux-x64-v9.9.9.9.tar.bz2
+`, a, b, c)
+	hashes := ParseHashes([]byte(list))
+	if len(hashes) != 2 {
+		t.Fatalf("ParseHashes() = %v, want the 2 signed entries", hashes)
+	}
+	if hashes["monero-linux-x64-v0.18.10.0.tar.bz2"] != strings.ToLower(b) {
+		t.Errorf("hash not lowercased: %v", hashes)
+	}
+	version, err := LatestVersion(hashes, "linux", "amd64")
+	if err != nil || version != "v0.18.10.0" {
+		t.Errorf("LatestVersion() = %q, %v; want v0.18.10.0", version, err)
+	}
+	if _, err := LatestVersion(hashes, "darwin", "arm64"); err == nil {
+		t.Error("LatestVersion() for a platform without releases should fail")
+	}
+}
+
+// releaseZip builds a Windows release archive holding the executables.
+func releaseZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"monerod.exe", "monero-wallet-rpc.exe", "monero-wallet-cli.exe"} {
+		w, err := zw.Create("monero-x86_64-w64-mingw32-v0.18.3.4/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(w, "binary %s", name)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestInstall verifies a release is downloaded, checked against the hash
+// list and installed, and that a hash mismatch installs nothing
+func TestInstall(t *testing.T) {
+	archive := releaseZip(t)
+	sum := sha256.Sum256(archive)
+	hashes := hex.EncodeToString(sum[:]) + "  monero-win-x64-v0.18.3.4.zip\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hashes.txt":
+			fmt.Fprint(w, hashes)
+		case "/cli/monero-win-x64-v0.18.3.4.zip":
+			w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	binDir := t.TempDir()
+	opts := Options{
+		BinDir:        binDir,
+		GOOS:          "windows",
+		GOARCH:        "amd64",
+		BaseURL:       server.URL + "/cli/",
+		HashesURL:     server.URL + "/hashes.txt",
+		SkipSignature: true,
+	}
+	result, err := Install(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if result.Version != "v0.18.3.4" || len(result.Installed) != 2 {
+		t.Errorf("Install() = %+v, want v0.18.3.4 with 2 executables", result)
+	}
+	data, err := os.ReadFile(filepath.Join(binDir, "monerod.exe"))
+	if err != nil || string(data) != "binary monerod.exe" {
+		t.Errorf("monerod.exe = %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(binDir); len(entries) != 2 {
+		t.Errorf("bin directory holds %d files, want only the 2 executables", len(entries))
+	}
+
+	hashes = strings.Repeat("0", 64) + "  monero-win-x64-v0.18.3.4.zip\n"
+	opts.BinDir = t.TempDir()
+	if _, err := Install(context.Background(), opts); errors.GetKind(err) != errors.KindSystem {
+		t.Errorf("Install() with a hash mismatch error = %v, want KindSystem", err)
+	}
+	if entries, _ := os.ReadDir(opts.BinDir); len(entries) != 0 {
+		t.Errorf("Install() with a hash mismatch left %d files", len(entries))
+	}
+
+	opts.Version = "v0.17.0.0"
+	if _, err := Install(context.Background(), opts); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("Install() of an unlisted version error = %v, want KindConfig", err)
+	}
+}
+
+// TestVerifySignature verifies hash lists must be signed by the pinned key
+func TestVerifySignature(t *testing.T) {
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		t.Skip("gpg not installed")
+	}
+	home := t.TempDir()
+	run := func(args ...string) []byte {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, gpg, append([]string{"--batch", "--homedir", home}, args...)...).Output()
+		if err != nil {
+			t.Skipf("gpg %s: %v", args[0], err)
+		}
+		return out
+	}
+	run("--passphrase", "", "--quick-gen-key", "release@example.com", "ed25519", "sign", "never")
+	colons := run("--with-colons", "--fingerprint", "release@example.com")
+	fingerprint := regexp.MustCompile(`(?m)^fpr:+([0-9A-F]{40}):`).FindSubmatch(colons)
+	if fingerprint == nil {
+		t.Fatalf("no fingerprint in %s", colons)
+	}
+	key := run("--armor", "--export", "release@example.com")
+	listFile := filepath.Join(home, "hashes.txt")
+	hashes := strings.Repeat("a", 64) + "  monero-linux-x64-v0.18.3.4.tar.bz2\n"
+	if err := os.WriteFile(listFile, []byte(hashes), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	signed := run("--pinentry-mode", "loopback", "--passphrase", "", "--clearsign", "--output", "-", listFile)
+
+	ctx := context.Background()
+	text, err := VerifySignature(ctx, signed, key, string(fingerprint[1]))
+	if err != nil {
+		t.Fatalf("VerifySignature() error = %v", err)
+	}
+	if string(text) != hashes {
+		t.Errorf("VerifySignature() = %q, want the signed text %q", text, hashes)
+	}
+	if _, err := VerifySignature(ctx, signed, key, SigningKeyFingerprint); err == nil {
+		t.Error("VerifySignature() accepted a signature by another key")
+	}
+	tampered := bytes.Replace(signed, []byte("aaaa"), []byte("bbbb"), 1)
+	if _, err := VerifySignature(ctx, tampered, key, string(fingerprint[1])); err == nil {
+		t.Error("VerifySignature() accepted a tampered list")
+	}
+}
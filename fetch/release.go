@@ -0,0 +1,242 @@
+package fetch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hashLine matches "<sha256>  <file>" lines of the hash list
+var hashLine = regexp.MustCompile(`^([0-9a-fA-F]{64})\s+\*?(\S+)$`)
+
+// platforms maps GOOS/GOARCH to the platform part of release archive
+// names, such as "linux-x64" in monero-linux-x64-v0.18.3.4.tar.bz2
+var platforms = map[string]string{
+	"linux/amd64":   "linux-x64",
+	"linux/386":     "linux-x86",
+	"linux/arm64":   "linux-armv8",
+	"linux/arm":     "linux-armv7",
+	"linux/riscv64": "linux-riscv64",
+	"darwin/amd64":  "mac-x64",
+	"darwin/arm64":  "mac-armv8",
+	"windows/amd64": "win-x64",
+	"windows/386":   "win-x86",
+	"freebsd/amd64": "freebsd-x64",
+}
+
+// archivePrefix returns the archive name up to the version, such as
+// "monero-linux-x64-".
+func archivePrefix(goos, goarch string) (string, error) {
+	platform, ok := platforms[goos+"/"+goarch]
+	if !ok {
+		return "", fmt.Errorf("no Monero release for %s/%s", goos, goarch)
+	}
+	return "monero-" + platform + "-", nil
+}
+
+// archiveExt returns the extension of release archives for goos.
+func archiveExt(goos string) string {
+	if goos == "windows" {
+		return ".zip"
+	}
+	return ".tar.bz2"
+}
+
+// ArchiveName returns the file name of a release archive.
+//
+// Parameters:
+//   - goos, goarch: Target platform, as in runtime.GOOS and runtime.GOARCH
+//   - version: Release such as "v0.18.3.4"; the leading "v" is optional
+//
+// Returns:
+//   - string: Archive name, such as "monero-linux-x64-v0.18.3.4.tar.bz2"
+//   - error: If the platform has no release or the version is malformed
+func ArchiveName(goos, goarch, version string) (string, error) {
+	prefix, err := archivePrefix(goos, goarch)
+	if err != nil {
+		return "", err
+	}
+	version = "v" + strings.TrimPrefix(version, "v")
+	if _, ok := parseVersion(version); !ok {
+		return "", fmt.Errorf("malformed version %q", version)
+	}
+	return prefix + version + archiveExt(goos), nil
+}
+
+// ParseHashes reads a hash list as published with Monero releases. For a
+// clearsigned list only the signed part is read, so lines added outside
+// it are ignored.
+//
+// Parameters:
+//   - data: Hash list, lines of "<sha256>  <file name>"
+//
+// Returns:
+//   - map[string]string: Lowercase SHA-256 hash by file name
+func ParseHashes(data []byte) map[string]string {
+	hashes := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	signed := false
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "-----BEGIN PGP SIGNED MESSAGE-----":
+			signed = true
+			continue
+		case "-----BEGIN PGP SIGNATURE-----":
+			if signed {
+				return hashes
+			}
+		}
+		if m := hashLine.FindStringSubmatch(line); m != nil {
+			hashes[m[2]] = strings.ToLower(m[1])
+		}
+	}
+	return hashes
+}
+
+// LatestVersion returns the newest release for a platform in a hash list.
+//
+// Parameters:
+//   - hashes: Hash list as returned by ParseHashes
+//   - goos, goarch: Target platform
+//
+// Returns:
+//   - string: Version such as "v0.18.3.4"
+//   - error: If the list has no archive for the platform
+func LatestVersion(hashes map[string]string, goos, goarch string) (string, error) {
+	prefix, err := archivePrefix(goos, goarch)
+	if err != nil {
+		return "", err
+	}
+	ext := archiveExt(goos)
+	var latest string
+	var latestParts []int
+	for name := range hashes {
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		version := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		parts, ok := parseVersion(version)
+		if ok && (latest == "" || compareVersions(parts, latestParts) > 0) {
+			latest, latestParts = version, parts
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("the hash list has no release for %s/%s", goos, goarch)
+	}
+	return latest, nil
+}
+
+// parseVersion splits "v0.18.3.4" into its numbers.
+func parseVersion(version string) ([]int, bool) {
+	if !strings.HasPrefix(version, "v") {
+		return nil, false
+	}
+	var parts []int
+	for _, field := range strings.Split(version[1:], ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// compareVersions orders versions split by parseVersion.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// VerifySignature checks a clearsigned hash list with gpg, using a
+// temporary keyring holding only the given key, and requires the
+// signature to be made by the key with the given fingerprint.
+//
+// Parameters:
+//   - ctx: Context bounding the gpg runs
+//   - signed: Clearsigned hash list
+//   - key: Armored public key
+//   - fingerprint: Fingerprint the signing key or its primary key must have
+//
+// Returns:
+//   - []byte: The signed text, without the signature
+//   - error: If gpg is missing or the signature does not verify
+func VerifySignature(ctx context.Context, signed, key []byte, fingerprint string) ([]byte, error) {
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		return nil, fmt.Errorf("gpg is required to verify the release signature: %w", err)
+	}
+	home, err := os.MkdirTemp("", "moneroger-gpg-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(home)
+
+	keyFile := filepath.Join(home, "signing-key.asc")
+	listFile := filepath.Join(home, "hashes.txt")
+	if err := os.WriteFile(keyFile, key, 0o600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(listFile, signed, 0o600); err != nil {
+		return nil, err
+	}
+	if out, err := exec.CommandContext(ctx, gpg, "--batch", "--homedir", home, "--import", keyFile).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to import the signing key: %w: %s", err, bytes.TrimSpace(out))
+	}
+
+	// --decrypt writes exactly the signed text, which is all that may be trusted
+	statusFile := filepath.Join(home, "status")
+	var text, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, gpg, "--batch", "--homedir", home, "--status-file", statusFile, "--decrypt", listFile)
+	cmd.Stdout, cmd.Stderr = &text, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("bad signature on the hash list: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	status, err := os.ReadFile(statusFile)
+	if err != nil {
+		return nil, err
+	}
+	if !signedBy(string(status), fingerprint) {
+		return nil, fmt.Errorf("the hash list is not signed by key %s", fingerprint)
+	}
+	return text.Bytes(), nil
+}
+
+// signedBy reports whether gpg status output contains a valid signature
+// by the key with the given fingerprint, or by a subkey of it.
+func signedBy(status, fingerprint string) bool {
+	want := strings.ToUpper(strings.ReplaceAll(fingerprint, " ", ""))
+	for _, line := range strings.Split(status, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "[GNUPG:]" || fields[1] != "VALIDSIG" {
+			continue
+		}
+		// The signing key comes first, its primary key last
+		if fields[2] == want || fields[len(fields)-1] == want {
+			return true
+		}
+	}
+	return false
+}
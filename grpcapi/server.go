@@ -0,0 +1,267 @@
+// Package grpcapi provides the gRPC management API of moneroger. It
+// lets remote control planes query status, restart services, open
+// wallets and send transfers without shell access to the host. Every
+// call is authenticated with a bearer token, and the API is served over
+// TLS unless it listens on a loopback address.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// opNewServer is the operation name for errors building the server
+var opNewServer = errors.Op("GRPC.NewServer")
+
+// authorizationKey is the metadata key carrying the bearer token
+const authorizationKey = "authorization"
+
+// bearerPrefix precedes the token in the authorization metadata
+const bearerPrefix = "Bearer "
+
+// Manager is the part of *moneroger.Moneroger the API controls.
+type Manager interface {
+	Status(ctx context.Context) *moneroger.Status
+	Restart(ctx context.Context, component string) error
+	Wallet(name string) (*monerowalletrpc.WalletRPC, error)
+}
+
+// NewServer creates a gRPC server offering the Manager service.
+//
+// Parameters:
+//   - manager: Manager whose services the API controls
+//   - config: Token and TLS settings; config.Validate must pass
+//
+// Returns:
+//   - *grpc.Server: Server ready to Serve a listener on config.Address
+//   - error: KindConfig if the configuration is invalid or the TLS
+//     certificate cannot be loaded
+//
+// Example:
+//
+//	server, err := grpcapi.NewServer(manager, config.GRPC)
+//	if err != nil {
+//	    return err
+//	}
+//	ln, err := net.Listen("tcp", config.GRPC.Address)
+//	if err != nil {
+//	    return err
+//	}
+//	go server.Serve(ln)
+//	defer server.GracefulStop()
+func NewServer(manager Manager, config util.GRPCConfig) (*grpc.Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.E(opNewServer, errors.ComponentGRPC, errors.KindConfig, err)
+	}
+	options := []grpc.ServerOption{grpc.UnaryInterceptor(authenticate(config.Token))}
+	tlsConfig, err := config.TLS.ServerConfig()
+	if err != nil {
+		return nil, errors.E(opNewServer, errors.ComponentGRPC, errors.KindConfig, err)
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(options...)
+	RegisterManagerServer(server, &service{manager: manager})
+	return server, nil
+}
+
+// TokenCredentials returns call credentials sending token the way the
+// server expects it, for clients dialing the API.
+//
+// Parameters:
+//   - token: The configured util.GRPCConfig.Token
+//   - secure: Whether the connection uses TLS; gRPC refuses to send
+//     credentials that require it over a plain connection
+//
+// Returns:
+//   - credentials.PerRPCCredentials: Credentials for grpc.WithPerRPCCredentials
+func TokenCredentials(token string, secure bool) credentials.PerRPCCredentials {
+	return tokenCredentials{token: token, secure: secure}
+}
+
+// tokenCredentials implements credentials.PerRPCCredentials
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{authorizationKey: bearerPrefix + t.token}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return t.secure
+}
+
+// authenticate rejects calls that do not carry token, comparing in
+// constant time so the token cannot be guessed from response times.
+func authenticate(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get(authorizationKey) {
+			given, ok := strings.CutPrefix(value, bearerPrefix)
+			if ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+}
+
+// service implements ManagerServer on top of a Manager
+type service struct {
+	UnimplementedManagerServer
+	manager Manager
+}
+
+func (s *service) Status(ctx context.Context, _ *StatusRequest) (*StatusResponse, error) {
+	st := s.manager.Status(ctx)
+	d := st.Daemon
+	resp := &StatusResponse{
+		Daemon: &DaemonStatus{
+			State:        d.State,
+			Pid:          int64(d.PID),
+			Port:         int32(d.Port),
+			UptimeNs:     int64(d.Uptime),
+			Adopted:      d.Adopted,
+			Version:      d.Version,
+			Height:       d.Height,
+			TargetHeight: d.TargetHeight,
+			Synchronized: d.Synchronized,
+			Peers:        d.Peers,
+			Error:        d.Error,
+		},
+		Live:  true,
+		Ready: true,
+	}
+	for _, w := range st.Wallets {
+		resp.Wallets = append(resp.Wallets, &WalletStatus{
+			Name:       w.Name,
+			State:      w.State,
+			Pid:        int64(w.PID),
+			Port:       int32(w.Port),
+			UptimeNs:   int64(w.Uptime),
+			Version:    w.Version,
+			WalletOpen: w.WalletOpen,
+			Wallet:     w.Wallet,
+			Height:     w.Height,
+			Refreshed:  w.Refreshed,
+			Error:      w.Error,
+		})
+	}
+	if err := st.Live(); err != nil {
+		resp.Live, resp.LiveError = false, err.Error()
+	}
+	if err := st.Ready(); err != nil {
+		resp.Ready, resp.ReadyError = false, err.Error()
+	}
+	return resp, nil
+}
+
+func (s *service) Restart(ctx context.Context, req *RestartRequest) (*RestartResponse, error) {
+	if err := s.manager.Restart(ctx, req.GetComponent()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &RestartResponse{}, nil
+}
+
+func (s *service) OpenWallet(ctx context.Context, req *OpenWalletRequest) (*OpenWalletResponse, error) {
+	if req.GetFilename() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename is required")
+	}
+	w, err := s.wallet(req.GetWallet())
+	if err != nil {
+		return nil, err
+	}
+	if err := w.OpenWallet(ctx, req.GetFilename(), req.GetPassword()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &OpenWalletResponse{}, nil
+}
+
+func (s *service) Transfer(ctx context.Context, req *TransferRequest) (*TransferResponse, error) {
+	w, err := s.wallet(req.GetWallet())
+	if err != nil {
+		return nil, err
+	}
+	transfer := monerowalletrpc.TransferRequest{
+		Priority:        monerowalletrpc.Priority(req.GetPriority()),
+		AccountIndex:    req.GetAccountIndex(),
+		SubaddrIndices:  req.GetSubaddrIndices(),
+		SubtractFeeFrom: req.GetSubtractFeeFrom(),
+		DoNotRelay:      req.GetDoNotRelay(),
+	}
+	for _, d := range req.GetDestinations() {
+		transfer.Destinations = append(transfer.Destinations, monerowalletrpc.Destination{
+			Address: d.GetAddress(),
+			Amount:  monerowalletrpc.Piconero(d.GetAmount()),
+		})
+	}
+	result, err := w.Transfer(ctx, transfer)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &TransferResponse{
+		Amount:     uint64(result.Amount),
+		Fee:        uint64(result.Fee),
+		TxHash:     result.TxHash,
+		TxKey:      result.TxKey,
+		TxBlob:     result.TxBlob,
+		TxMetadata: result.TxMetadata,
+		Weight:     result.Weight,
+	}, nil
+}
+
+// wallet finds a managed wallet, the default wallet for an empty name.
+func (s *service) wallet(name string) (*monerowalletrpc.WalletRPC, error) {
+	if name == "" {
+		name = moneroger.DefaultWalletName
+	}
+	w, err := s.manager.Wallet(name)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return w, nil
+}
+
+// toStatus converts a moneroger error to a gRPC status, choosing the
+// code from its kind.
+func toStatus(err error) error {
+	code := codes.Unknown
+	switch errors.GetKind(err) {
+	case errors.KindConfig:
+		code = codes.InvalidArgument
+	case errors.KindNetwork:
+		code = codes.Unavailable
+	case errors.KindProcess:
+		code = codes.FailedPrecondition
+	case errors.KindTimeout:
+		code = codes.DeadlineExceeded
+	case errors.KindSystem, errors.KindRPC, errors.KindAuth:
+		// KindAuth means the manager's own credentials were rejected by a
+		// service, not that the caller is unauthenticated
+		code = codes.Internal
+	case errors.KindWallet:
+		code = codes.FailedPrecondition
+	case errors.KindSync:
+		code = codes.Unavailable
+	case errors.KindQuota:
+		code = codes.ResourceExhausted
+	case errors.KindDevice:
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,104 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/opd-ai/moneroger"
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// fakeManager serves a fixed status and records restarts
+type fakeManager struct {
+	status    moneroger.Status
+	restarted []string
+}
+
+func (f *fakeManager) Status(ctx context.Context) *moneroger.Status {
+	return &f.status
+}
+
+func (f *fakeManager) Restart(ctx context.Context, component string) error {
+	if component != errors.ComponentWalletRPC {
+		return errors.E(moneroger.OpRestart, errors.KindConfig, fmt.Errorf("unknown component %q", component))
+	}
+	f.restarted = append(f.restarted, component)
+	return nil
+}
+
+func (f *fakeManager) Wallet(name string) (*monerowalletrpc.WalletRPC, error) {
+	return nil, errors.E(moneroger.OpWallet, errors.KindConfig, fmt.Errorf("no wallet named %q", name))
+}
+
+// dial serves manager on an in-memory listener and connects to it
+func dial(t *testing.T, manager Manager, token string) ManagerClient {
+	t.Helper()
+	config := util.GRPCConfig{Address: "127.0.0.1:18095", Token: strings.Repeat("t", 16)}
+	server, err := NewServer(manager, config)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	ln := bufconn.Listen(1 << 16)
+	go server.Serve(ln)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(TokenCredentials(token, false)))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewManagerClient(conn)
+}
+
+// TestServer verifies authentication and the mapping of manager calls
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	running := util.StateRunning.String()
+	manager := &fakeManager{status: moneroger.Status{
+		Daemon:  moneroger.DaemonStatus{State: running, Port: 18081, Height: 100, TargetHeight: 100, Synchronized: true},
+		Wallets: []moneroger.WalletStatus{{Name: moneroger.DefaultWalletName, State: running, WalletOpen: true, Height: 90}},
+	}}
+
+	if _, err := dial(t, manager, "wrong").Status(ctx, &StatusRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Status() with a wrong token error = %v, want Unauthenticated", err)
+	}
+
+	client := dial(t, manager, strings.Repeat("t", 16))
+	resp, err := client.Status(ctx, &StatusRequest{})
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if resp.GetDaemon().GetPort() != 18081 || !resp.GetDaemon().GetSynchronized() || len(resp.GetWallets()) != 1 {
+		t.Errorf("Status() = %v, want the fake status", resp)
+	}
+	if !resp.GetLive() || resp.GetReady() || !strings.Contains(resp.GetReadyError(), "refreshing") {
+		t.Errorf("Status() live = %v, ready = %v (%s); want live and refreshing", resp.GetLive(), resp.GetReady(), resp.GetReadyError())
+	}
+
+	if _, err := client.Restart(ctx, &RestartRequest{Component: errors.ComponentWalletRPC}); err != nil || len(manager.restarted) != 1 {
+		t.Errorf("Restart() error = %v, restarted %v", err, manager.restarted)
+	}
+	if _, err := client.Restart(ctx, &RestartRequest{Component: "bogus"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Restart(bogus) error = %v, want InvalidArgument", err)
+	}
+	if _, err := client.OpenWallet(ctx, &OpenWalletRequest{Wallet: "missing", Filename: "w"}); status.Code(err) != codes.NotFound {
+		t.Errorf("OpenWallet() of an unknown wallet error = %v, want NotFound", err)
+	}
+	if _, err := client.Transfer(ctx, &TransferRequest{Wallet: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Transfer() from an unknown wallet error = %v, want NotFound", err)
+	}
+}
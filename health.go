@@ -0,0 +1,161 @@
+package moneroger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	moneroconst "github.com/opd-ai/moneroger/const"
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// HealthFunc receives EventServiceDegraded and EventServiceRecovered
+// events from the health monitor. It is called from the monitor's
+// goroutine, so it should return quickly.
+type HealthFunc func(ev Event)
+
+// healthMonitor tracks the outcome of the periodic health checks.
+// The zero value is ready to use.
+//
+// Fields:
+//   - failing: Last check failure by service, absent while healthy
+//   - callbacks: Functions registered with OnHealthChange, by ID
+//   - nextID: ID of the next registered callback
+type healthMonitor struct {
+	mu        sync.Mutex
+	failing   map[healthKey]error
+	callbacks map[int]HealthFunc
+	nextID    int
+}
+
+// healthKey identifies a checked service: monerod, or a wallet by name.
+type healthKey struct {
+	component string
+	wallet    string
+}
+
+// OnHealthChange registers fn to be called when a service fails a
+// health check after passing it, and when it passes again.
+//
+// Parameters:
+//   - fn: Callback receiving the events, also delivered to Subscribe
+//
+// Returns:
+//   - func(): Removes the callback
+//
+// Example:
+//
+//	remove := manager.OnHealthChange(func(ev moneroger.Event) {
+//	    if ev.Type == moneroger.EventServiceDegraded {
+//	        alert(ev.Component, ev.Wallet, ev.Err)
+//	    }
+//	})
+//	defer remove()
+//
+// Related:
+//   - util.Config.HealthInterval for the check period
+func (m *Moneroger) OnHealthChange(fn HealthFunc) func() {
+	h := &m.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.callbacks == nil {
+		h.callbacks = make(map[int]HealthFunc)
+	}
+	id := h.nextID
+	h.nextID++
+	h.callbacks[id] = fn
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.callbacks, id)
+	}
+}
+
+// healthInterval returns the configured health check period, 0 when
+// the checks are disabled.
+func healthInterval(config util.Config) time.Duration {
+	switch {
+	case config.HealthInterval < 0:
+		return 0
+	case config.HealthInterval == 0:
+		return moneroconst.DefaultHealthInterval
+	}
+	return config.HealthInterval
+}
+
+// watchHealth checks monerod and every wallet over RPC at the configured
+// interval, so services that hang while their process keeps running are
+// reported, not only those that exit.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchHealth(ctx context.Context) {
+	interval := healthInterval(m.currentConfig())
+	if interval == 0 {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(util.Jitter(interval, 0.1)):
+		}
+		m.checkServices(ctx)
+	}
+}
+
+// checkServices runs one round of health checks. Services being started
+// or stopped are skipped, as supervision reports their exits.
+func (m *Moneroger) checkServices(ctx context.Context) {
+	if m.monerod.State() == util.StateRunning {
+		m.recordHealth(healthKey{component: errors.ComponentMonerod}, m.monerod.CheckHealth(ctx))
+	}
+	m.eachWallet(func(name string, w *monerowalletrpc.WalletRPC) {
+		if w.State() != util.StateRunning {
+			return
+		}
+		w.InvalidateHealth()
+		m.recordHealth(healthKey{component: errors.ComponentWalletRPC, wallet: name}, w.CheckHealth(ctx))
+	})
+}
+
+// recordHealth compares a check result with the previous one and
+// reports a change to subscribers and callbacks.
+func (m *Moneroger) recordHealth(key healthKey, err error) {
+	h := &m.health
+	h.mu.Lock()
+	_, wasFailing := h.failing[key]
+	if err != nil {
+		if h.failing == nil {
+			h.failing = make(map[healthKey]error)
+		}
+		h.failing[key] = err
+	} else {
+		delete(h.failing, key)
+	}
+	callbacks := make([]HealthFunc, 0, len(h.callbacks))
+	for _, fn := range h.callbacks {
+		callbacks = append(callbacks, fn)
+	}
+	h.mu.Unlock()
+
+	ev := Event{Type: EventServiceRecovered, Component: key.component, Wallet: key.wallet, Time: time.Now(), Err: err}
+	if key.wallet != "" {
+		ev.Tenant = m.walletTenant(key.wallet)
+	}
+	switch {
+	case err != nil && !wasFailing:
+		ev.Type = EventServiceDegraded
+		m.logger().Warn("health check failed", "component", key.component, "wallet", key.wallet, "error", err)
+	case err == nil && wasFailing:
+		m.logger().Info("health check passed again", "component", key.component, "wallet", key.wallet)
+	default:
+		return
+	}
+	m.events.publish(ev)
+	for _, fn := range callbacks {
+		fn(ev)
+	}
+}
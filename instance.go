@@ -0,0 +1,248 @@
+package moneroger
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// OpInstance is the operation name for errors from Register,
+	// FindInstance, InspectInstance and StopInstance
+	OpInstance errors.Op = "Instance"
+
+	// instanceFile is the file under the data directory describing the
+	// services of a registered or detached manager
+	instanceFile = "moneroger-instance.json"
+
+	// managerPIDFile is the file under the data directory holding the
+	// process ID of a registered manager
+	managerPIDFile = "moneroger.pid"
+
+	// instanceStopTimeout is how long a registered manager may take to
+	// shut down after StopInstance interrupts it before it is killed
+	instanceStopTimeout = 2 * time.Minute
+)
+
+// Instance describes services managed from a data directory, either by
+// a running manager that called Register or left running by Detach.
+//
+// Fields:
+//   - PID: Process ID of the managing process, 0 once detached
+//   - DaemonPort, WalletPort: Ports the services listen on, which differ
+//     from the configured ones after Config.AutoPort moved them
+//   - Since: When the manager registered or detached
+type Instance struct {
+	PID        int       `json:"pid,omitempty"`
+	DaemonPort int       `json:"daemon_port"`
+	WalletPort int       `json:"wallet_port"`
+	Since      time.Time `json:"since"`
+}
+
+// Detached reports whether the services run without a manager.
+func (i *Instance) Detached() bool {
+	return i.PID == 0
+}
+
+// apply points config at the instance's services.
+func (i *Instance) apply(config util.Config) util.Config {
+	if i.DaemonPort > 0 {
+		config.MoneroPort = i.DaemonPort
+	}
+	if i.WalletPort > 0 {
+		config.WalletPort = i.WalletPort
+	}
+	return config
+}
+
+// Register records this process as the manager of its data directory,
+// so other processes can find it with FindInstance, report on it with
+// InspectInstance and stop it with StopInstance. Shutdown removes the
+// record.
+//
+// Returns:
+//   - error: KindProcess if another manager is registered, KindSystem
+//     if the record cannot be written
+func (m *Moneroger) Register() error {
+	dataDir := m.currentConfig().NetworkDataDir()
+	other, err := FindInstance(dataDir)
+	if err != nil {
+		return err
+	}
+	if other != nil && !other.Detached() && other.PID != os.Getpid() {
+		return errors.E(OpInstance, errors.KindProcess,
+			fmt.Errorf("%s is already managed by process %d", dataDir, other.PID))
+	}
+	if err := util.WritePIDFile(filepath.Join(dataDir, managerPIDFile), os.Getpid()); err != nil {
+		return errors.E(OpInstance, errors.KindSystem, err)
+	}
+	if err := m.writeInstance(os.Getpid()); err != nil {
+		return errors.E(OpInstance, errors.KindSystem, err)
+	}
+	return nil
+}
+
+// writeInstance records the services' ports along with the managing
+// process, 0 when detaching.
+func (m *Moneroger) writeInstance(pid int) error {
+	instance := Instance{
+		PID:        pid,
+		DaemonPort: m.DaemonRPCPort(),
+		WalletPort: m.WalletRPCPort(),
+		Since:      time.Now(),
+	}
+	data, err := json.MarshalIndent(instance, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.currentConfig().NetworkDataDir(), instanceFile), data, 0o600)
+}
+
+// removeInstance deletes the records written by Register and Detach
+// once the services they describe have stopped.
+func (m *Moneroger) removeInstance() {
+	dataDir := m.currentConfig().NetworkDataDir()
+	if dataDir == "" {
+		return
+	}
+	for _, name := range []string{instanceFile, managerPIDFile} {
+		path := filepath.Join(dataDir, name)
+		if err := os.Remove(path); err != nil && !stderrors.Is(err, fs.ErrNotExist) {
+			m.logger().Warn("could not remove instance record", "path", path, "error", err)
+		}
+	}
+}
+
+// FindInstance looks for services managed from dataDir. A record left by
+// a manager that is no longer running is removed; its services, if any
+// are left, are adopted by the next manager started.
+//
+// Parameters:
+//   - dataDir: Data directory of the services
+//
+// Returns:
+//   - *Instance: The instance, nil if there is none
+//   - error: KindSystem for unreadable records
+func FindInstance(dataDir string) (*Instance, error) {
+	if dataDir == "" {
+		return nil, nil
+	}
+	path := filepath.Join(dataDir, instanceFile)
+	data, err := os.ReadFile(path)
+	if stderrors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.E(OpInstance, errors.KindSystem, err)
+	}
+	var instance Instance
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return nil, errors.E(OpInstance, errors.KindSystem, fmt.Errorf("%s: %w", path, err))
+	}
+	if instance.Detached() || managerAlive(instance.PID) {
+		return &instance, nil
+	}
+	for _, name := range []string{instanceFile, managerPIDFile} {
+		if err := util.RemovePIDFile(filepath.Join(dataDir, name)); err != nil {
+			return nil, errors.E(OpInstance, errors.KindSystem, err)
+		}
+	}
+	return nil, nil
+}
+
+// managerAlive reports whether the process with the given ID is running.
+// Managers may be embedded in any executable, so unlike service PID
+// files the executable is not compared.
+func managerAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	return err == nil && util.ProcessAlive(p)
+}
+
+// InspectInstance reports the status of the services managed from
+// config.NetworkDataDir() without taking them over: a registered manager
+// keeps managing them, and detached services stay detached.
+//
+// Parameters:
+//   - ctx: Context bounding the RPC queries
+//   - config: The configuration the services were started with
+//
+// Returns:
+//   - *Status: Status of the daemon and the default wallet
+//   - error: KindProcess if no instance is running or a service does not
+//     answer RPC
+//
+// Each call watches the service processes until they exit, so it is
+// meant for tools that inspect an instance once rather than polling.
+func InspectInstance(ctx context.Context, config util.Config) (*Status, error) {
+	instance, err := FindInstance(config.NetworkDataDir())
+	if err != nil {
+		return nil, err
+	}
+	if instance == nil {
+		return nil, errors.E(OpInstance, errors.KindProcess,
+			fmt.Errorf("no running instance in %s", config.NetworkDataDir()))
+	}
+	config = instance.apply(config)
+	daemon, err := monerod.AttachMoneroDaemon(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := monerowalletrpc.AttachWalletRPC(ctx, config, daemon)
+	if err != nil {
+		return nil, err
+	}
+	m := &Moneroger{
+		monerod:         daemon,
+		monerowalletrpc: wallet,
+		config:          config,
+		log:             config.Log(),
+	}
+	return m.Status(ctx), nil
+}
+
+// StopInstance gracefully stops the services managed from
+// config.NetworkDataDir(): a registered manager is interrupted and shuts
+// them down itself, detached services are attached and shut down.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdown
+//   - config: The configuration the services were started with
+//
+// Returns:
+//   - error: KindProcess if no instance is running or the manager could
+//     not be stopped, or shutdown errors of detached services
+func StopInstance(ctx context.Context, config util.Config) error {
+	instance, err := FindInstance(config.NetworkDataDir())
+	if err != nil {
+		return err
+	}
+	if instance == nil {
+		return errors.E(OpInstance, errors.KindProcess,
+			fmt.Errorf("no running instance in %s", config.NetworkDataDir()))
+	}
+	if instance.Detached() {
+		m, err := Attach(config)
+		if err != nil {
+			return err
+		}
+		return m.Shutdown(ctx)
+	}
+	p, err := os.FindProcess(instance.PID)
+	if err == nil {
+		err = util.StopProcess(ctx, p, instanceStopTimeout)
+	}
+	if err != nil {
+		return errors.E(OpInstance, errors.KindProcess, err)
+	}
+	return nil
+}
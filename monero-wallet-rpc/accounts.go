@@ -0,0 +1,114 @@
+package monerowalletrpc
+
+import "context"
+
+// NewAccount is the response of the create_account RPC method.
+type NewAccount struct {
+	AccountIndex uint32 `json:"account_index"`
+	Address      string `json:"address"`
+}
+
+// NewAddress is the response of the create_address RPC method.
+type NewAddress struct {
+	Address      string `json:"address"`
+	AddressIndex uint32 `json:"address_index"`
+}
+
+// Account is the summary of one account returned by get_accounts.
+type Account struct {
+	AccountIndex    uint32   `json:"account_index"`
+	BaseAddress     string   `json:"base_address"`
+	Balance         Piconero `json:"balance"`
+	UnlockedBalance Piconero `json:"unlocked_balance"`
+	Label           string   `json:"label"`
+	Tag             string   `json:"tag"`
+}
+
+// Accounts is the response of the get_accounts RPC method.
+type Accounts struct {
+	SubaddressAccounts   []Account `json:"subaddress_accounts"`
+	TotalBalance         Piconero  `json:"total_balance"`
+	TotalUnlockedBalance Piconero  `json:"total_unlocked_balance"`
+}
+
+// CreateAccount adds an account to the open wallet.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - label: Account label, may be empty
+//
+// Returns:
+//   - *NewAccount: Index and primary address of the new account
+//   - error: RPC failures
+func (c *Client) CreateAccount(ctx context.Context, label string) (*NewAccount, error) {
+	var params interface{}
+	if label != "" {
+		params = map[string]string{"label": label}
+	}
+	var a NewAccount
+	if err := c.rpc.Call(ctx, "create_account", params, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CreateAddress adds a subaddress to an account, for example one per
+// customer so incoming payments can be told apart.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - accountIndex: Account receiving the subaddress
+//   - label: Subaddress label, may be empty
+//
+// Returns:
+//   - *NewAddress: The subaddress and its index within the account
+//   - error: RPC failures
+func (c *Client) CreateAddress(ctx context.Context, accountIndex uint32, label string) (*NewAddress, error) {
+	params := struct {
+		AccountIndex uint32 `json:"account_index"`
+		Label        string `json:"label,omitempty"`
+	}{accountIndex, label}
+	var a NewAddress
+	if err := c.rpc.Call(ctx, "create_address", params, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// LabelAddress sets the label of a subaddress.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - index: Account and subaddress index
+//   - label: New label, empty to clear it
+//
+// Returns:
+//   - error: RPC failures, e.g. for subaddresses that do not exist
+func (c *Client) LabelAddress(ctx context.Context, index SubaddrIndex, label string) error {
+	params := struct {
+		Index SubaddrIndex `json:"index"`
+		Label string       `json:"label"`
+	}{index, label}
+	return c.rpc.Call(ctx, "label_address", params, nil)
+}
+
+// GetAccounts lists the accounts of the open wallet with their balances.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - tag: Only list accounts with this tag, empty for all
+//
+// Returns:
+//   - *Accounts: Per-account and total balances
+//   - error: RPC failures
+func (c *Client) GetAccounts(ctx context.Context, tag string) (*Accounts, error) {
+	var params interface{}
+	if tag != "" {
+		params = map[string]string{"tag": tag}
+	}
+	var a Accounts
+	if err := c.rpc.Call(ctx, "get_accounts", params, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
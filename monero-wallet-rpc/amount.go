@@ -0,0 +1,11 @@
+package monerowalletrpc
+
+import "github.com/opd-ai/moneroger/xmr"
+
+// PiconeroPerXMR is the number of piconero in one XMR.
+const PiconeroPerXMR = xmr.PiconeroPerXMR
+
+// Piconero is an amount of Monero in its smallest unit, 1e-12 XMR, as
+// used by every wallet-rpc method. It is an xmr.Amount, with its exact
+// parsing, formatting and overflow-checked arithmetic.
+type Piconero = xmr.Amount
@@ -0,0 +1,434 @@
+package monerowalletrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/rpc"
+)
+
+// opAddress is the operation name for errors from WalletRPC.Address
+const opAddress = errors.Op("WalletRPC.Address")
+
+// Client is a typed JSON-RPC client for monero-wallet-rpc.
+// It is safe for concurrent use, although wallet-rpc itself serves
+// requests one at a time.
+//
+// Fields:
+//   - rpc: Underlying JSON-RPC client handling transport and authentication
+type Client struct {
+	rpc *rpc.Client
+}
+
+// NewClient creates a wallet client for an arbitrary wallet-rpc endpoint.
+//
+// Parameters:
+//   - endpoint: Base URL, e.g. "http://127.0.0.1:18083"
+//   - user: RPC username, empty if the wallet does not require login
+//   - pass: RPC password
+//   - opts: HTTP tuning options
+//
+// Returns:
+//   - *Client: Ready-to-use client
+//
+// Related:
+//   - WalletRPC.Client for a client bound to a managed wallet service
+func NewClient(endpoint, user, pass string, opts rpc.Options) *Client {
+	if opts.Component == "" {
+		opts.Component = errors.ComponentWalletRPC
+	}
+	if opts.ErrorKind == nil {
+		opts.ErrorKind = errorKind
+	}
+	return &Client{rpc: rpc.NewClient(endpoint, user, pass, opts)}
+}
+
+// Client returns a typed RPC client for this wallet service,
+// authenticated with the wallet's RPC credentials.
+//
+// Returns:
+//   - *Client: Client sharing connections with the manager's own probes
+func (w *WalletRPC) Client() *Client {
+	return &Client{rpc: w.rpcClient()}
+}
+
+// RPC returns the underlying JSON-RPC client, for methods without a typed
+// wrapper.
+func (c *Client) RPC() *rpc.Client {
+	return c.rpc
+}
+
+// SubaddressBalance is the balance of a single subaddress.
+type SubaddressBalance struct {
+	AccountIndex      uint32   `json:"account_index"`
+	AddressIndex      uint32   `json:"address_index"`
+	Address           string   `json:"address"`
+	Balance           Piconero `json:"balance"`
+	UnlockedBalance   Piconero `json:"unlocked_balance"`
+	Label             string   `json:"label"`
+	NumUnspentOutputs uint64   `json:"num_unspent_outputs"`
+	BlocksToUnlock    uint64   `json:"blocks_to_unlock"`
+}
+
+// Balance is the response of the get_balance RPC method. Funds received
+// recently are included in Balance but only spendable once they appear
+// in UnlockedBalance, after BlocksToUnlock more blocks.
+type Balance struct {
+	Balance              Piconero            `json:"balance"`
+	UnlockedBalance      Piconero            `json:"unlocked_balance"`
+	MultisigImportNeeded bool                `json:"multisig_import_needed"`
+	BlocksToUnlock       uint64              `json:"blocks_to_unlock"`
+	TimeToUnlock         uint64              `json:"time_to_unlock"`
+	PerSubaddress        []SubaddressBalance `json:"per_subaddress"`
+}
+
+// Subaddress is an address entry returned by get_address.
+type Subaddress struct {
+	Address      string `json:"address"`
+	Label        string `json:"label"`
+	AddressIndex uint32 `json:"address_index"`
+	Used         bool   `json:"used"`
+}
+
+// AddressResult is the response of the get_address RPC method.
+type AddressResult struct {
+	Address   string       `json:"address"`
+	Addresses []Subaddress `json:"addresses"`
+}
+
+// Destination is a single recipient of a transfer.
+type Destination struct {
+	Amount  Piconero `json:"amount"`
+	Address string   `json:"address"`
+}
+
+// Priority selects the fee level of a transaction; higher priorities pay
+// larger fees for faster confirmation.
+type Priority uint32
+
+// Transaction priorities, as understood by wallet-rpc
+const (
+	PriorityDefault     Priority = iota // Wallet default, normally Normal
+	PriorityUnimportant                 // Lowest fee
+	PriorityNormal                      // Standard fee
+	PriorityElevated                    // Higher fee
+	PriorityHighest                     // Highest fee
+)
+
+// TransferParams are the parameters of the transfer RPC method.
+type TransferParams struct {
+	Destinations           []Destination `json:"destinations"`
+	AccountIndex           uint32        `json:"account_index,omitempty"`
+	SubaddrIndices         []uint32      `json:"subaddr_indices,omitempty"`
+	SubtractFeeFromOutputs []uint32      `json:"subtract_fee_from_outputs,omitempty"`
+	Priority               Priority      `json:"priority,omitempty"`
+	UnlockTime             uint64        `json:"unlock_time,omitempty"`
+	GetTxKey               bool          `json:"get_tx_key,omitempty"`
+	DoNotRelay             bool          `json:"do_not_relay,omitempty"`
+	GetTxHex               bool          `json:"get_tx_hex,omitempty"`
+	GetTxMetadata          bool          `json:"get_tx_metadata,omitempty"`
+}
+
+// TransferResult is the response of the transfer RPC method.
+type TransferResult struct {
+	Amount        Piconero `json:"amount"`
+	Fee           Piconero `json:"fee"`
+	TxHash        string   `json:"tx_hash"`
+	TxKey         string   `json:"tx_key"`
+	TxBlob        string   `json:"tx_blob"`
+	TxMetadata    string   `json:"tx_metadata"`
+	Weight        uint64   `json:"weight"`
+	MultisigTxset string   `json:"multisig_txset"`
+	UnsignedTxset string   `json:"unsigned_txset"`
+}
+
+// GetTransfersParams selects which transfers get_transfers returns.
+type GetTransfersParams struct {
+	In             bool     `json:"in"`
+	Out            bool     `json:"out"`
+	Pending        bool     `json:"pending"`
+	Failed         bool     `json:"failed"`
+	Pool           bool     `json:"pool"`
+	FilterByHeight bool     `json:"filter_by_height,omitempty"`
+	MinHeight      uint64   `json:"min_height,omitempty"`
+	MaxHeight      uint64   `json:"max_height,omitempty"`
+	AccountIndex   uint32   `json:"account_index,omitempty"`
+	SubaddrIndices []uint32 `json:"subaddr_indices,omitempty"`
+	AllAccounts    bool     `json:"all_accounts,omitempty"`
+}
+
+// SubaddrIndex identifies a subaddress by account and index.
+type SubaddrIndex struct {
+	Major uint32 `json:"major"`
+	Minor uint32 `json:"minor"`
+}
+
+// TransferType is the category of a transfer in get_transfers results.
+type TransferType string
+
+// Transfer categories
+const (
+	TransferIn      TransferType = "in"      // Incoming, confirmed
+	TransferOut     TransferType = "out"     // Outgoing, confirmed
+	TransferPending TransferType = "pending" // Outgoing, not yet mined
+	TransferFailed  TransferType = "failed"  // Outgoing, rejected
+	TransferPool    TransferType = "pool"    // Incoming, in the mempool
+	TransferBlock   TransferType = "block"   // Mining reward
+)
+
+// Transfer is a single transfer entry returned by get_transfers.
+// Timestamp is the block time, or when a pending transfer was created.
+type Transfer struct {
+	Address         string        `json:"address"`
+	Amount          Piconero      `json:"amount"`
+	Confirmations   uint64        `json:"confirmations"`
+	Destinations    []Destination `json:"destinations"`
+	DoubleSpendSeen bool          `json:"double_spend_seen"`
+	Fee             Piconero      `json:"fee"`
+	Height          uint64        `json:"height"`
+	Note            string        `json:"note"`
+	PaymentID       string        `json:"payment_id"`
+	SubaddrIndex    SubaddrIndex  `json:"subaddr_index"`
+	Timestamp       time.Time     `json:"-"`
+	TxID            string        `json:"txid"`
+	Type            TransferType  `json:"type"`
+	UnlockTime      uint64        `json:"unlock_time"`
+	Locked          bool          `json:"locked"`
+}
+
+// UnmarshalJSON decodes a transfer, converting the Unix timestamp
+// wallet-rpc reports into a time.Time.
+func (t *Transfer) UnmarshalJSON(data []byte) error {
+	type plain Transfer
+	raw := struct {
+		*plain
+		Timestamp int64 `json:"timestamp"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t.Timestamp = time.Time{}
+	if raw.Timestamp > 0 {
+		t.Timestamp = time.Unix(raw.Timestamp, 0)
+	}
+	return nil
+}
+
+// Transfers groups get_transfers results by category.
+type Transfers struct {
+	In      []Transfer `json:"in"`
+	Out     []Transfer `json:"out"`
+	Pending []Transfer `json:"pending"`
+	Failed  []Transfer `json:"failed"`
+	Pool    []Transfer `json:"pool"`
+}
+
+// RefreshResult is the response of the refresh RPC method.
+type RefreshResult struct {
+	BlocksFetched uint64 `json:"blocks_fetched"`
+	ReceivedMoney bool   `json:"received_money"`
+}
+
+// GetBalance returns the balance of an account.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - accountIndex: Account to query, 0 for the primary account
+//
+// Returns:
+//   - *Balance: Total and unlocked balance with a per-subaddress breakdown
+//   - error: RPC failures, e.g. when no wallet is open
+func (c *Client) GetBalance(ctx context.Context, accountIndex uint32) (*Balance, error) {
+	var b Balance
+	params := map[string]uint32{"account_index": accountIndex}
+	if err := c.rpc.Call(ctx, "get_balance", params, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetAddress returns the primary address of an account and the requested
+// subaddresses.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - accountIndex: Account to query
+//   - addressIndices: Subaddress indices to include, nil for all
+//
+// Returns:
+//   - *AddressResult: Account address and subaddress list
+//   - error: RPC failures
+func (c *Client) GetAddress(ctx context.Context, accountIndex uint32, addressIndices []uint32) (*AddressResult, error) {
+	params := struct {
+		AccountIndex uint32   `json:"account_index"`
+		AddressIndex []uint32 `json:"address_index,omitempty"`
+	}{accountIndex, addressIndices}
+	var a AddressResult
+	if err := c.rpc.Call(ctx, "get_address", params, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Transfer sends funds to one or more destinations.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - params: Destinations and transaction options
+//
+// Returns:
+//   - *TransferResult: Transaction hash, key and fee
+//   - error: RPC failures, e.g. insufficient funds
+func (c *Client) Transfer(ctx context.Context, params TransferParams) (*TransferResult, error) {
+	var r TransferResult
+	if err := c.rpc.Call(ctx, "transfer", params, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// GetTransfers returns wallet transfers matching params.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - params: Categories and ranges to include
+//
+// Returns:
+//   - *Transfers: Matching transfers grouped by category
+//   - error: RPC failures
+func (c *Client) GetTransfers(ctx context.Context, params GetTransfersParams) (*Transfers, error) {
+	var t Transfers
+	if err := c.rpc.Call(ctx, "get_transfers", params, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Refresh scans the blockchain for new transactions belonging to the
+// open wallet.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - startHeight: Height to start scanning from, 0 to continue where it left off
+//
+// Returns:
+//   - *RefreshResult: Number of blocks fetched and whether funds arrived
+//   - error: RPC failures
+func (c *Client) Refresh(ctx context.Context, startHeight uint64) (*RefreshResult, error) {
+	var params interface{}
+	if startHeight > 0 {
+		params = map[string]uint64{"start_height": startHeight}
+	}
+	var r RefreshResult
+	if err := c.rpc.Call(ctx, "refresh", params, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// SetDaemon points the running wallet-rpc at a different daemon without
+// restarting it.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - address: Daemon URL, such as "http://node.example:18081"
+//   - trusted: Whether the daemon is trusted with wallet privacy
+//
+// Returns:
+//   - error: RPC failures, including servers too old to support set_daemon
+func (c *Client) SetDaemon(ctx context.Context, address string, trusted bool) error {
+	params := map[string]interface{}{
+		"address": address,
+		"trusted": trusted,
+	}
+	return c.rpc.Call(ctx, "set_daemon", params, nil)
+}
+
+// Balance returns the balance of the open wallet summed over all of its
+// accounts, with the per-subaddress breakdown.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *Balance: Total and unlocked balance in piconero, with unlock info
+//   - error: RPC failures, e.g. when no wallet is open
+//
+// Related:
+//   - Client.GetBalance for a single account
+func (w *WalletRPC) Balance(ctx context.Context) (*Balance, error) {
+	var b Balance
+	params := map[string]bool{"all_accounts": true}
+	if err := w.rpcClient().Call(ctx, "get_balance", params, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Address returns one address of the open wallet: the primary address
+// for account 0, index 0, otherwise a subaddress.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - account: Account index
+//   - index: Address index within the account
+//
+// Returns:
+//   - *Subaddress: The address with its label and whether it received funds
+//   - error: RPC failures, e.g. for indices that do not exist
+//
+// Related:
+//   - Client.GetAddress for several addresses at once
+func (w *WalletRPC) Address(ctx context.Context, account, index uint32) (*Subaddress, error) {
+	a, err := w.Client().GetAddress(ctx, account, []uint32{index})
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range a.Addresses {
+		if sub.AddressIndex == index {
+			return &sub, nil
+		}
+	}
+	return nil, errors.E(opAddress, errors.ComponentWalletRPC, errors.KindUnknown,
+		fmt.Errorf("get_address returned no address %d/%d", account, index))
+}
+
+// Version is the response of the get_version RPC method.
+//
+// Fields:
+//   - Version: Packed RPC version, major in the upper 16 bits
+//   - Release: Whether wallet-rpc is a tagged release build
+type Version struct {
+	Version uint32 `json:"version"`
+	Release bool   `json:"release"`
+}
+
+// Major returns the major RPC version.
+func (v Version) Major() uint32 {
+	return v.Version >> 16
+}
+
+// Minor returns the minor RPC version.
+func (v Version) Minor() uint32 {
+	return v.Version & 0xffff
+}
+
+// GetVersion returns the RPC interface version of wallet-rpc. It works
+// without an open wallet.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *Version: RPC version information
+//   - error: RPC failures
+func (c *Client) GetVersion(ctx context.Context) (*Version, error) {
+	var v Version
+	if err := c.rpc.Call(ctx, "get_version", nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,130 @@
+package monerowalletrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// opCreateFromDevice is the operation name for errors from
+// CreateWalletFromDevice
+const opCreateFromDevice = errors.Op("WalletRPC.CreateWalletFromDevice")
+
+// deviceErrors are fragments of the messages wallet-rpc and wallet-cli
+// report when a hardware wallet is missing, disconnected, locked or not
+// running its Monero app
+var deviceErrors = []string{
+	"device not connected",
+	"device is not connected",
+	"device disconnected",
+	"no device found",
+	"unable to open device",
+	"could not connect to the device",
+	"device connect failed",
+	"failed to connect to device",
+	"wrong device status",
+	"hardware device",
+}
+
+// isDeviceError reports whether msg describes a hardware wallet failure.
+func isDeviceError(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, fragment := range deviceErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWalletFromDevice creates a wallet file whose keys stay on the
+// configured hardware wallet, then opens it. wallet-rpc has no method for
+// this, so monero-wallet-cli --generate-from-device creates the file;
+// the device asks for confirmation while it does.
+//
+// Parameters:
+//   - ctx: Context bounding the creation, which waits for confirmation
+//     on the device
+//   - name: Wallet file name, without directory
+//   - password: Password protecting the wallet file
+//   - restoreHeight: Block height the wallet scans from, 0 for the
+//     genesis block; use the height the device first received funds at
+//
+// Returns:
+//   - error: KindConfig without Config.HWDevice or for invalid names,
+//     KindDevice when the device is disconnected, locked or refuses,
+//     KindProcess if monero-wallet-cli is missing or fails, otherwise
+//     any OpenWallet error
+//
+// Related:
+//   - util.HWDeviceConfig
+func (w *WalletRPC) CreateWalletFromDevice(ctx context.Context, name, password string, restoreHeight uint64) error {
+	if !w.hwDevice.Enabled() {
+		return errors.E(opCreateFromDevice, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("no hardware wallet configured"))
+	}
+	if err := util.ValidateWalletName(name); err != nil {
+		return errors.E(opCreateFromDevice, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	driver := w.processDriver()
+	path, err := driver.Executable(MoneroWalletCLIPath)
+	if err != nil {
+		return errors.E(opCreateFromDevice, errors.ComponentWalletRPC, errors.KindProcess, err)
+	}
+
+	// The password goes into an options file, out of sight of ps
+	optionsPath := filepath.Join(w.walletDir, fmt.Sprintf(".%s.device-options", name))
+	if err := util.WriteOptionsFile(optionsPath, map[string]string{"password": password}); err != nil {
+		return errors.E(opCreateFromDevice, errors.ComponentWalletRPC, errors.KindSystem, err)
+	}
+	defer os.Remove(optionsPath)
+
+	args := []string{
+		"--generate-from-device", filepath.Join(w.walletDir, name),
+		"--config-file", optionsPath,
+		"--restore-height", strconv.FormatUint(restoreHeight, 10),
+		"--offline",
+		"--log-file", os.DevNull,
+	}
+	args = append(args, w.hwDevice.WalletArgs()...)
+	if flag := w.network.Flag(); flag != "" {
+		args = append(args, flag)
+	}
+	// Run one harmless command instead of the interactive shell
+	args = append(args, "address")
+	cmd, err := driver.Command(ctx, util.ProcessSpec{
+		Path:    path,
+		Program: "monero-wallet-cli",
+		Name:    "monero-wallet-cli",
+		Args:    args,
+		Mounts:  []string{w.walletDir},
+	})
+	if err != nil {
+		return errors.E(opCreateFromDevice, errors.ComponentWalletRPC, errors.KindSystem, err)
+	}
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+
+	w.log().Info("creating wallet from device, confirm on the device", "wallet", name, "device", w.hwDevice.Name)
+	err = cmd.Run()
+	if err == nil && !util.FileExists(filepath.Join(w.walletDir, name+".keys")) {
+		err = fmt.Errorf("no wallet file created")
+	}
+	if err != nil {
+		kind := errors.KindProcess
+		if isDeviceError(output.String()) {
+			kind = errors.KindDevice
+		}
+		return errors.E(opCreateFromDevice, errors.ComponentWalletRPC, kind,
+			fmt.Errorf("monero-wallet-cli: %w\nOutput: %s", err, strings.TrimSpace(output.String())))
+	}
+	w.log().Info("wallet created from device", "wallet", name)
+	return w.OpenWallet(ctx, name, password)
+}
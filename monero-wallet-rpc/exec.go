@@ -4,7 +4,6 @@ package monerowalletrpc
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/opd-ai/moneroger/util"
 )
@@ -39,15 +38,30 @@ import (
 //
 // Related:
 //   - util.Path() for search path generation
-//   - util.FileExists() for file checking
+//   - util.FindExecutable() for extension-aware lookup
 //   - github.com/opd-ai/moneroger/monerod.MoneroDPath() for daemon executable
 func MoneroWalletRPCPath() (string, error) {
-	paths := util.Path()
-	for _, path := range paths {
-		moneroWalletRPCPath := filepath.Join(path, "monero-wallet-rpc")
-		if util.FileExists(moneroWalletRPCPath) {
-			return moneroWalletRPCPath, nil
-		}
+	moneroWalletRPCPath, err := util.FindExecutable(util.Path(), "monero-wallet-rpc")
+	if err != nil {
+		return "", fmt.Errorf("Monero wallet RPC(monero-wallet-rpc) not found")
 	}
-	return "", fmt.Errorf("Monero wallet RPC(monero-wallet-rpc) not found")
+	return moneroWalletRPCPath, nil
+}
+
+// MoneroWalletCLIPath searches for the monero-wallet-cli executable in the
+// same locations as MoneroWalletRPCPath. It is only needed to create
+// wallets from a hardware wallet, which wallet-rpc cannot do.
+//
+// Returns:
+//   - string: The full path to the monero-wallet-cli executable if found
+//   - error: An error if the executable cannot be found
+//
+// Related:
+//   - WalletRPC.CreateWalletFromDevice
+func MoneroWalletCLIPath() (string, error) {
+	path, err := util.FindExecutable(util.Path(), "monero-wallet-cli")
+	if err != nil {
+		return "", fmt.Errorf("Monero wallet CLI(monero-wallet-cli) not found")
+	}
+	return path, nil
 }
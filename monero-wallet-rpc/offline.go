@@ -0,0 +1,409 @@
+package monerowalletrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// Operation names for errors from the offline signing methods
+const (
+	opPrepareOffline  = errors.Op("WalletRPC.PrepareOfflineTransfer")
+	opSignOffline     = errors.Op("WalletRPC.SignOfflineTransfer")
+	opSubmitSigned    = errors.Op("WalletRPC.SubmitSignedTransfer")
+	opImportOutputs   = errors.Op("WalletRPC.ImportOutputs")
+	opImportKeyImages = errors.Op("WalletRPC.ImportKeyImages")
+)
+
+// SignedKeyImage is a key image with the signature proving the wallet
+// that exported it owns the output.
+type SignedKeyImage struct {
+	KeyImage  string `json:"key_image"`
+	Signature string `json:"signature"`
+}
+
+// KeyImages is the response of the export_key_images RPC method and the
+// parameters of import_key_images.
+//
+// Fields:
+//   - Offset: Index of the first output the key images belong to, 0
+//     when all of them were exported
+//   - SignedKeyImages: Key images of the wallet's outputs from Offset on
+type KeyImages struct {
+	Offset          uint32           `json:"offset,omitempty"`
+	SignedKeyImages []SignedKeyImage `json:"signed_key_images"`
+}
+
+// KeyImageImport is the response of the import_key_images RPC method.
+//
+// Fields:
+//   - Height: Height of the last block scanned
+//   - Spent: Amount spent by the imported key images
+//   - Unspent: Amount still unspent
+type KeyImageImport struct {
+	Height  uint64   `json:"height"`
+	Spent   Piconero `json:"spent"`
+	Unspent Piconero `json:"unspent"`
+}
+
+// TransferRecipient is a destination in a TransferDescription.
+type TransferRecipient struct {
+	Address string   `json:"address"`
+	Amount  Piconero `json:"amount"`
+}
+
+// TransferDescription describes one transaction of an unsigned
+// transaction set, as returned by the describe_transfer RPC method.
+type TransferDescription struct {
+	AmountIn      Piconero            `json:"amount_in"`
+	AmountOut     Piconero            `json:"amount_out"`
+	Recipients    []TransferRecipient `json:"recipients"`
+	ChangeAmount  Piconero            `json:"change_amount"`
+	ChangeAddress string              `json:"change_address"`
+	Fee           Piconero            `json:"fee"`
+	RingSize      uint32              `json:"ring_size"`
+	UnlockTime    uint64              `json:"unlock_time"`
+	PaymentID     string              `json:"payment_id"`
+}
+
+// SignedTransfer is the response of the sign_transfer RPC method.
+//
+// Fields:
+//   - SignedTxset: Signed transaction set, passed to
+//     SubmitSignedTransfer on the online wallet
+//   - TxHashList: Hashes of the signed transactions
+//   - TxKeyList: Transaction keys, for proving payments
+type SignedTransfer struct {
+	SignedTxset string   `json:"signed_txset"`
+	TxHashList  []string `json:"tx_hash_list"`
+	TxKeyList   []string `json:"tx_key_list"`
+}
+
+// ExportOutputs returns the open wallet's outputs with the
+// export_outputs RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - all: Export every output rather than those added since the last
+//     export
+//
+// Returns:
+//   - string: Hex encoded outputs for ImportOutputs
+//   - error: RPC failures
+func (c *Client) ExportOutputs(ctx context.Context, all bool) (string, error) {
+	var resp struct {
+		OutputsDataHex string `json:"outputs_data_hex"`
+	}
+	if err := c.rpc.Call(ctx, "export_outputs", map[string]bool{"all": all}, &resp); err != nil {
+		return "", err
+	}
+	return resp.OutputsDataHex, nil
+}
+
+// ImportOutputs adds outputs exported by another wallet of the same
+// account with the import_outputs RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - outputs: Hex encoded outputs from ExportOutputs
+//
+// Returns:
+//   - uint64: Number of outputs imported
+//   - error: RPC failures
+func (c *Client) ImportOutputs(ctx context.Context, outputs string) (uint64, error) {
+	var resp struct {
+		NumImported uint64 `json:"num_imported"`
+	}
+	params := map[string]string{"outputs_data_hex": outputs}
+	if err := c.rpc.Call(ctx, "import_outputs", params, &resp); err != nil {
+		return 0, err
+	}
+	return resp.NumImported, nil
+}
+
+// ExportKeyImages returns the signed key images of the open wallet's
+// outputs with the export_key_images RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - all: Export every key image rather than those added since the
+//     last export
+//
+// Returns:
+//   - *KeyImages: Key images for ImportKeyImages
+//   - error: RPC failures
+func (c *Client) ExportKeyImages(ctx context.Context, all bool) (*KeyImages, error) {
+	var k KeyImages
+	if err := c.rpc.Call(ctx, "export_key_images", map[string]bool{"all": all}, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// ImportKeyImages adds key images exported by another wallet of the same
+// account with the import_key_images RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - images: Key images from ExportKeyImages
+//
+// Returns:
+//   - *KeyImageImport: Spent and unspent amounts after the import
+//   - error: RPC failures
+func (c *Client) ImportKeyImages(ctx context.Context, images KeyImages) (*KeyImageImport, error) {
+	var r KeyImageImport
+	if err := c.rpc.Call(ctx, "import_key_images", images, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// DescribeTransfer decodes an unsigned transaction set with the
+// describe_transfer RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - unsignedTxset: Unsigned transaction set from a view-only wallet
+//
+// Returns:
+//   - []TransferDescription: One description per transaction
+//   - error: RPC failures
+func (c *Client) DescribeTransfer(ctx context.Context, unsignedTxset string) ([]TransferDescription, error) {
+	var resp struct {
+		Desc []TransferDescription `json:"desc"`
+	}
+	params := map[string]string{"unsigned_txset": unsignedTxset}
+	if err := c.rpc.Call(ctx, "describe_transfer", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Desc, nil
+}
+
+// SignTransfer signs an unsigned transaction set with the sign_transfer
+// RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - unsignedTxset: Unsigned transaction set from a view-only wallet
+//
+// Returns:
+//   - *SignedTransfer: Signed transaction set, hashes and keys
+//   - error: RPC failures
+func (c *Client) SignTransfer(ctx context.Context, unsignedTxset string) (*SignedTransfer, error) {
+	params := struct {
+		UnsignedTxset string `json:"unsigned_txset"`
+		GetTxKeys     bool   `json:"get_tx_keys"`
+	}{unsignedTxset, true}
+	var s SignedTransfer
+	if err := c.rpc.Call(ctx, "sign_transfer", params, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SubmitTransfer broadcasts a signed transaction set with the
+// submit_transfer RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - signedTxset: Signed transaction set from SignTransfer
+//
+// Returns:
+//   - []string: Hashes of the broadcast transactions
+//   - error: RPC failures
+func (c *Client) SubmitTransfer(ctx context.Context, signedTxset string) ([]string, error) {
+	var resp struct {
+		TxHashList []string `json:"tx_hash_list"`
+	}
+	params := map[string]string{"tx_data_hex": signedTxset}
+	if err := c.rpc.Call(ctx, "submit_transfer", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.TxHashList, nil
+}
+
+// PrepareOfflineTransfer creates an unsigned transaction set in the open
+// view-only wallet, to be signed by the offline wallet holding the spend
+// key. The transaction is never relayed: a wallet able to sign itself is
+// refused rather than spending directly.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - req: Destinations and transaction options; DoNotRelay is implied
+//
+// Returns:
+//   - string: Unsigned transaction set for SignOfflineTransfer
+//   - error: KindConfig for invalid requests, KindWallet when the open
+//     wallet is not view-only, otherwise RPC failures
+//
+// The offline signing loop:
+//  1. Online: ExportOutputs; offline: ImportOutputs
+//  2. Offline: ExportKeyImages; online: ImportKeyImages, so the
+//     view-only wallet knows which outputs are spent
+//  3. Online: PrepareOfflineTransfer; offline: SignOfflineTransfer
+//  4. Online: SubmitSignedTransfer
+func (w *WalletRPC) PrepareOfflineTransfer(ctx context.Context, req TransferRequest) (string, error) {
+	if err := req.validate(); err != nil {
+		return "", errors.E(opPrepareOffline, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	r, err := w.Client().Transfer(ctx, TransferParams{
+		Destinations:           req.Destinations,
+		AccountIndex:           req.AccountIndex,
+		SubaddrIndices:         req.SubaddrIndices,
+		SubtractFeeFromOutputs: req.SubtractFeeFrom,
+		Priority:               req.Priority,
+		DoNotRelay:             true,
+	})
+	if err != nil {
+		return "", err
+	}
+	if r.UnsignedTxset == "" {
+		return "", errors.E(opPrepareOffline, errors.ComponentWalletRPC, errors.KindWallet,
+			fmt.Errorf("open wallet is not view-only; the transaction was signed and not relayed"))
+	}
+	w.log().Info("unsigned transfer prepared", "amount", r.Amount, "fee", r.Fee)
+	return r.UnsignedTxset, nil
+}
+
+// SignOfflineTransfer signs an unsigned transaction set in the open
+// offline wallet. It first decodes the set, so the caller can check the
+// recipients and amounts it is signing.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - unsignedTxset: Unsigned transaction set from PrepareOfflineTransfer
+//   - approve: Called with the decoded transactions before signing;
+//     returning an error aborts. Nil signs without review
+//
+// Returns:
+//   - *SignedTransfer: Signed transaction set for SubmitSignedTransfer
+//   - error: KindConfig for an empty set, the error of approve,
+//     KindWallet when the set cannot be decoded or signed, otherwise RPC
+//     failures
+func (w *WalletRPC) SignOfflineTransfer(ctx context.Context, unsignedTxset string, approve func([]TransferDescription) error) (*SignedTransfer, error) {
+	if unsignedTxset == "" {
+		return nil, errors.E(opSignOffline, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("unsigned transaction set cannot be empty"))
+	}
+	if approve != nil {
+		desc, err := w.Client().DescribeTransfer(ctx, unsignedTxset)
+		if err != nil {
+			return nil, walletError(opSignOffline, err)
+		}
+		if err := approve(desc); err != nil {
+			return nil, err
+		}
+	}
+	s, err := w.Client().SignTransfer(ctx, unsignedTxset)
+	if err != nil {
+		return nil, walletError(opSignOffline, err)
+	}
+	w.log().Info("transfer signed", "tx_hashes", s.TxHashList)
+	return s, nil
+}
+
+// SubmitSignedTransfer broadcasts a transaction set signed offline
+// through the open view-only wallet's daemon.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - signedTxset: Signed transaction set from SignOfflineTransfer
+//
+// Returns:
+//   - []string: Hashes of the broadcast transactions
+//   - error: KindConfig for an empty set, KindWallet when the daemon
+//     rejects it, otherwise RPC failures
+func (w *WalletRPC) SubmitSignedTransfer(ctx context.Context, signedTxset string) ([]string, error) {
+	if signedTxset == "" {
+		return nil, errors.E(opSubmitSigned, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("signed transaction set cannot be empty"))
+	}
+	hashes, err := w.Client().SubmitTransfer(ctx, signedTxset)
+	if err != nil {
+		return nil, walletError(opSubmitSigned, err)
+	}
+	w.log().Info("signed transfer submitted", "tx_hashes", hashes)
+	return hashes, nil
+}
+
+// ExportOutputs returns the outputs the open view-only wallet has found,
+// for ImportOutputs on the offline wallet.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - all: Export every output rather than those added since the last
+//     export
+//
+// Returns:
+//   - string: Hex encoded outputs
+//   - error: RPC failures
+func (w *WalletRPC) ExportOutputs(ctx context.Context, all bool) (string, error) {
+	return w.Client().ExportOutputs(ctx, all)
+}
+
+// ImportOutputs adds outputs exported by the view-only wallet to the
+// open offline wallet, so it can compute their key images.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - outputs: Hex encoded outputs from ExportOutputs
+//
+// Returns:
+//   - uint64: Number of outputs imported
+//   - error: KindConfig for empty input, KindWallet when the outputs
+//     belong to another account or cannot be decoded, otherwise RPC
+//     failures
+func (w *WalletRPC) ImportOutputs(ctx context.Context, outputs string) (uint64, error) {
+	if outputs == "" {
+		return 0, errors.E(opImportOutputs, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("outputs cannot be empty"))
+	}
+	n, err := w.Client().ImportOutputs(ctx, outputs)
+	if err != nil {
+		return 0, walletError(opImportOutputs, err)
+	}
+	w.log().Info("outputs imported", "count", n)
+	return n, nil
+}
+
+// ExportKeyImages returns the signed key images of the open offline
+// wallet's outputs, for ImportKeyImages on the view-only wallet.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - all: Export every key image rather than those added since the
+//     last export
+//
+// Returns:
+//   - *KeyImages: Signed key images
+//   - error: RPC failures
+func (w *WalletRPC) ExportKeyImages(ctx context.Context, all bool) (*KeyImages, error) {
+	return w.Client().ExportKeyImages(ctx, all)
+}
+
+// ImportKeyImages adds key images exported by the offline wallet to the
+// open view-only wallet, which can then tell spent outputs from unspent
+// ones and build transactions from the unspent.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - images: Key images from ExportKeyImages
+//
+// Returns:
+//   - *KeyImageImport: Spent and unspent amounts after the import
+//   - error: KindConfig without key images, KindWallet when their
+//     signatures do not match the wallet, otherwise RPC failures
+func (w *WalletRPC) ImportKeyImages(ctx context.Context, images KeyImages) (*KeyImageImport, error) {
+	if len(images.SignedKeyImages) == 0 {
+		return nil, errors.E(opImportKeyImages, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("no key images to import"))
+	}
+	r, err := w.Client().ImportKeyImages(ctx, images)
+	if err != nil {
+		return nil, walletError(opImportKeyImages, err)
+	}
+	w.log().Info("key images imported", "count", len(images.SignedKeyImages),
+		"spent", r.Spent, "unspent", r.Unspent)
+	return r, nil
+}
@@ -0,0 +1,215 @@
+package monerowalletrpc
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// pidFileFormat names the PID file in the data directory of the
+// wallet-rpc listening on a port
+const pidFileFormat = "monero-wallet-rpc-%d.pid"
+
+// logFileFormat names the file in the data directory receiving the
+// output of the detached wallet-rpc listening on a port
+const logFileFormat = "monero-wallet-rpc-%d.log"
+
+// pidFile returns the path of the wallet's PID file, empty without a
+// data directory.
+func (w *WalletRPC) pidFile() string {
+	if w.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(w.dataDir, fmt.Sprintf(pidFileFormat, w.WalletRPCPort()))
+}
+
+// track makes p the wallet's process and records its exit when it
+// terminates.
+func (w *WalletRPC) track(p *os.Process, exit *util.ProcessExit) {
+	w.mu.Lock()
+	w.process = p
+	w.exit = exit
+	w.mu.Unlock()
+	go func() {
+		<-exit.Done()
+		w.processExited(exit)
+	}()
+}
+
+// restoreOrphanLogin reuses the RPC login an earlier manager passed to
+// the wallet-rpc on this port, unless a password is configured.
+func (w *WalletRPC) restoreOrphanLogin() {
+	if w.rpcPass != "" {
+		return
+	}
+	path := filepath.Join(w.walletDir, fmt.Sprintf(optionsFileFormat, w.WalletRPCPort()))
+	options, err := util.ReadOptionsFile(path)
+	if err != nil {
+		return
+	}
+	user, pass, ok := strings.Cut(options["rpc-login"], ":")
+	if !ok || (w.rpcUser != "" && w.rpcUser != user) {
+		return
+	}
+	w.rpcUser, w.rpcPass = user, pass
+	w.resetClient()
+}
+
+// adoptOrphan looks for a wallet-rpc left running by an earlier manager,
+// found through the PID file. A process that runs the wallet-rpc
+// executable and answers RPC with this wallet's credentials is adopted
+// and managed as if spawned; with cleanup one that does not answer is
+// stopped so a new wallet-rpc can take its port.
+//
+// Parameters:
+//   - ctx: Context bounding the probe and the cleanup
+//   - cleanup: Whether an unresponsive orphan is stopped
+//
+// Returns:
+//   - bool: Whether an orphan was adopted
+//   - error: KindProcess if an unresponsive orphan could not be stopped
+func (w *WalletRPC) adoptOrphan(ctx context.Context, cleanup bool) (bool, error) {
+	pidFile := w.pidFile()
+	if pidFile == "" {
+		return false, nil
+	}
+	exe, err := w.processDriver().Executable(MoneroWalletRPCPath)
+	if err != nil {
+		// Reported when spawning
+		return false, nil
+	}
+	p, err := util.FindOrphan(pidFile, exe)
+	if err != nil {
+		w.log().Warn("ignoring unreadable PID file", "path", pidFile, "error", err)
+		return false, nil
+	}
+	if p == nil {
+		return false, nil
+	}
+	w.restoreOrphanLogin()
+	if err := w.checkHealth(ctx); err == nil {
+		w.log().Info("adopting monero-wallet-rpc left running by a previous manager", "pid", p.Pid, "port", w.WalletRPCPort())
+		w.track(p, util.WatchOrphan(p))
+		return true, nil
+	}
+	if !cleanup {
+		return false, nil
+	}
+	w.log().Warn("stopping unresponsive monero-wallet-rpc left running by a previous manager", "pid", p.Pid)
+	if err := util.StopProcess(ctx, p, w.shutdownTimeout()); err != nil {
+		return false, errors.E(opStart, errors.ComponentWalletRPC, errors.KindProcess, err)
+	}
+	if err := util.RemovePIDFile(pidFile); err != nil {
+		w.log().Warn("could not remove PID file", "path", pidFile, "error", err)
+	}
+	return false, nil
+}
+
+// AttachWalletRPC reconnects to a wallet-rpc left running detached by an
+// earlier manager, see util.Config.Detach. Unlike NewWalletRPC it never
+// spawns the process: the wallet-rpc recorded in the PID file for
+// config.WalletPort must still be running and answer RPC. An
+// unresponsive wallet-rpc is left alone.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - config: The configuration the wallet was started with, with the
+//     port it listens on
+//   - daemon: Daemon the wallet is connected to
+//
+// Returns:
+//   - *WalletRPC: The attached wallet, managed as if spawned
+//   - error: KindProcess if no running wallet-rpc was found
+//
+// Related:
+//   - NewWalletRPC for starting a wallet
+func AttachWalletRPC(ctx context.Context, config util.Config, daemon *monerod.MoneroDaemon) (*WalletRPC, error) {
+	wallet := &WalletRPC{
+		daemon: daemon,
+		logs:   util.NewRingBuffer(config.OutputBufferSize()),
+	}
+	if err := wallet.configure(config); err != nil {
+		return nil, err
+	}
+	orphan, err := wallet.adoptOrphan(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if !orphan {
+		return nil, errors.E(opAttach, errors.ComponentWalletRPC, errors.KindProcess,
+			fmt.Errorf("no wallet-rpc answering RPC recorded in %s", wallet.pidFile()))
+	}
+	if err := wallet.Start(ctx); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// StopStale stops wallet-rpc processes an earlier manager left running
+// from config.NetworkDataDir(), for example when it crashed, on ports
+// other than those in keep. A leftover wallet-rpc keeps its wallet file
+// locked, so a new one could not open it. PID files of processes no
+// longer running are removed. Call it only when no other manager uses
+// the data directory: wallets it detached are stopped as well.
+//
+// Parameters:
+//   - ctx: Context bounding the cleanup
+//   - config: Configuration with the data directory and driver
+//   - keep: Ports whose wallet-rpc is left alone, such as one
+//     NewWalletRPC may adopt
+//
+// Returns:
+//   - error: KindProcess for processes that could not be stopped,
+//     KindSystem if the data directory cannot be read
+//
+// Related:
+//   - NewWalletRPC, which adopts or stops the wallet-rpc on its own port
+func StopStale(ctx context.Context, config util.Config, keep ...int) error {
+	if config.DataDir == "" {
+		return nil
+	}
+	exe, err := config.Driver().Executable(MoneroWalletRPCPath)
+	if err != nil {
+		// Reported when spawning
+		return nil
+	}
+	pidFiles, err := filepath.Glob(filepath.Join(config.NetworkDataDir(), strings.Replace(pidFileFormat, "%d", "*", 1)))
+	if err != nil {
+		return errors.E(opStopStale, errors.ComponentWalletRPC, errors.KindSystem, err)
+	}
+	var errs []error
+	for _, pidFile := range pidFiles {
+		var port int
+		if _, err := fmt.Sscanf(filepath.Base(pidFile), pidFileFormat, &port); err != nil || slices.Contains(keep, port) {
+			continue
+		}
+		p, err := util.FindOrphan(pidFile, exe)
+		if err != nil {
+			config.Log().Warn("ignoring unreadable PID file", "path", pidFile, "error", err)
+			continue
+		}
+		if p == nil {
+			continue
+		}
+		config.Log().Warn("stopping monero-wallet-rpc left running by a previous manager", "pid", p.Pid, "port", port)
+		if err := util.StopProcess(ctx, p, config.EffectiveShutdownTimeout()); err != nil {
+			errs = append(errs, fmt.Errorf("port %d: %w", port, err))
+			continue
+		}
+		if err := util.RemovePIDFile(pidFile); err != nil {
+			config.Log().Warn("could not remove PID file", "path", pidFile, "error", err)
+		}
+	}
+	if err := stderrors.Join(errs...); err != nil {
+		return errors.E(opStopStale, errors.ComponentWalletRPC, errors.KindProcess, err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package monerowalletrpc
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// opNewPool is the operation name for pool creation errors
+	opNewPool = errors.Op("WalletRPC.NewPool")
+
+	// opPoolPick is the operation name for member selection errors
+	opPoolPick = errors.Op("WalletRPC.Pool.Pick")
+)
+
+// Pool runs several monero-wallet-rpc processes for the same wallet
+// directory and spreads RPC calls across them. monero-wallet-rpc handles
+// one request at a time, so a pool raises throughput for read-heavy
+// payment backends.
+//
+// Fields:
+//   - members: The wallet-rpc processes, listening on consecutive ports
+//   - next: Round-robin cursor used to break ties between idle members
+//
+// Each member opens its wallets independently. Monero locks an open
+// wallet file, so members should serve distinct wallets from the shared
+// directory or open view-only copies; a pool is not a way to send
+// concurrent transfers from one wallet.
+type Pool struct {
+	members []*WalletRPC
+	next    atomic.Uint64
+}
+
+// NewPool starts size wallet-rpc processes against daemon.
+//
+// Parameters:
+//   - ctx: Context for process startup
+//   - config: Shared configuration; member i listens on config.WalletPort+i
+//   - daemon: The daemon every member connects to
+//   - size: Number of processes, at least 1
+//
+// Returns:
+//   - *Pool: Pool with every member started
+//   - error: KindConfig for an invalid size, otherwise the first startup
+//     error, after stopping members that had already started
+//
+// All members share one set of RPC credentials, generated when config
+// leaves them empty.
+func NewPool(ctx context.Context, config util.Config, daemon *monerod.MoneroDaemon, size int) (*Pool, error) {
+	if size < 1 {
+		return nil, errors.E(opNewPool, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("invalid pool size: %d", size))
+	}
+	if config.WalletRPCPass == "" {
+		config.WalletRPCPass = util.SecurePassword()
+	}
+
+	p := &Pool{}
+	for i := 0; i < size; i++ {
+		memberConfig := config
+		memberConfig.WalletPort = config.EffectiveWalletPort() + i
+		memberConfig.Logger = config.Log().With("member", i)
+		w, err := NewWalletRPC(ctx, memberConfig, daemon)
+		if err != nil {
+			_ = p.Shutdown(ctx)
+			return nil, err
+		}
+		p.members = append(p.members, w)
+	}
+	return p, nil
+}
+
+// newPool builds a pool from existing wallets, for tests.
+func newPool(members ...*WalletRPC) *Pool {
+	return &Pool{members: members}
+}
+
+// Size returns the number of pool members.
+func (p *Pool) Size() int {
+	return len(p.members)
+}
+
+// Members returns the wallet-rpc processes in the pool, for health checks
+// and diagnostics.
+func (p *Pool) Members() []*WalletRPC {
+	return append([]*WalletRPC(nil), p.members...)
+}
+
+// Pick selects the member best able to take the next request: the one
+// with the fewest in-flight and queued requests, rotating among equally
+// loaded members. Members whose process has exited are skipped.
+//
+// Returns:
+//   - *WalletRPC: Selected member
+//   - error: KindProcess if every member has exited
+func (p *Pool) Pick() (*WalletRPC, error) {
+	n := len(p.members)
+	start := int(p.next.Add(1) % uint64(n))
+
+	var best *WalletRPC
+	bestLoad := 0
+	for i := 0; i < n; i++ {
+		w := p.members[(start+i)%n]
+		if w.processExit().Exited() {
+			continue
+		}
+		stats := w.RPCStats()
+		load := stats.InFlight + stats.Queued
+		if best == nil || load < bestLoad {
+			best, bestLoad = w, load
+		}
+	}
+	if best == nil {
+		return nil, errors.E(opPoolPick, errors.ComponentWalletRPC, errors.KindProcess,
+			fmt.Errorf("all %d pool members have exited", n))
+	}
+	return best, nil
+}
+
+// Client returns a typed client for the least loaded member. Use a fresh
+// Client per request so calls keep being balanced.
+//
+// Returns:
+//   - *Client: Client bound to the selected member
+//   - error: Any error from Pick
+func (p *Pool) Client() (*Client, error) {
+	w, err := p.Pick()
+	if err != nil {
+		return nil, err
+	}
+	return w.Client(), nil
+}
+
+// Call performs a raw JSON-RPC call on the least loaded member.
+//
+// Parameters:
+//   - ctx: Context for the request
+//   - method: JSON-RPC method name
+//   - params: Request parameters, or nil
+//   - result: Destination for the decoded result, or nil
+//
+// Returns:
+//   - error: Any error from Pick or the call
+func (p *Pool) Call(ctx context.Context, method string, params, result interface{}) error {
+	w, err := p.Pick()
+	if err != nil {
+		return err
+	}
+	return w.rpcClient().Call(ctx, method, params, result)
+}
+
+// Shutdown stops every member.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdown
+//
+// Returns:
+//   - error: The first shutdown error, after attempting all members
+func (p *Pool) Shutdown(ctx context.Context) error {
+	var firstErr error
+	for _, w := range p.members {
+		if err := w.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
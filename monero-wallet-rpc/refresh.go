@@ -0,0 +1,70 @@
+package monerowalletrpc
+
+import (
+	"context"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/rpc"
+)
+
+// opRefreshProgress is the operation name for errors from RefreshProgress
+const opRefreshProgress = errors.Op("WalletRPC.RefreshProgress")
+
+// GetHeight returns how many blocks the open wallet has scanned.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - uint64: Wallet height, equal to the daemon's height once caught up
+//   - error: RPC failures, e.g. when no wallet is open
+func (c *Client) GetHeight(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Height uint64 `json:"height"`
+	}
+	if err := c.rpc.Call(ctx, "get_height", nil, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Height, nil
+}
+
+// daemonHeight returns the height of the daemon the wallet syncs from:
+// the remote node in use, or the local daemon.
+func (w *WalletRPC) daemonHeight(ctx context.Context) (uint64, error) {
+	node := w.RemoteNode()
+	if node == "" {
+		return w.daemon.Client().GetHeight(ctx)
+	}
+	addr, err := remoteDaemonAddress(node)
+	if err != nil {
+		return 0, errors.E(opRefreshProgress, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	client := monerod.NewClient(addr, "", "", rpc.Options{Component: errors.ComponentWalletRPC})
+	defer client.RPC().Close()
+	return client.GetHeight(ctx)
+}
+
+// RefreshProgress compares the open wallet's height with its daemon's,
+// showing how far the wallet's scan of the chain is behind.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - walletHeight: Blocks scanned by the open wallet
+//   - daemonHeight: Blocks in the daemon's chain
+//   - error: RPC failures of either service, e.g. when no wallet is open
+//
+// The wallet has caught up once walletHeight reaches daemonHeight.
+func (w *WalletRPC) RefreshProgress(ctx context.Context) (walletHeight, daemonHeight uint64, err error) {
+	walletHeight, err = w.Client().GetHeight(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	daemonHeight, err = w.daemonHeight(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	return walletHeight, daemonHeight, nil
+}
@@ -0,0 +1,126 @@
+package monerowalletrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// opCheckRemoteNode is the operation name for remote node probe errors
+	opCheckRemoteNode = errors.Op("WalletRPC.CheckRemoteNode")
+
+	// opSwitchRemoteNode is the operation name for remote node failover errors
+	opSwitchRemoteNode = errors.Op("WalletRPC.SwitchRemoteNode")
+
+	// remoteProbeTimeout bounds a single reachability probe of a remote node
+	remoteProbeTimeout = 10 * time.Second
+)
+
+// RemoteNode returns the remote daemon the wallet currently uses, or ""
+// when it is connected to the local daemon.
+func (w *WalletRPC) RemoteNode() string {
+	if len(w.remoteNodes) == 0 {
+		return ""
+	}
+	return w.remoteNodes[int(w.nodeIndex.Load())%len(w.remoteNodes)]
+}
+
+// RemoteNodes returns the configured remote daemons in failover order.
+func (w *WalletRPC) RemoteNodes() []string {
+	return append([]string(nil), w.remoteNodes...)
+}
+
+// CheckRemoteNode probes the current remote node directly with get_info,
+// independent of the wallet-rpc process.
+//
+// Parameters:
+//   - ctx: Context for the probe, bounded by remoteProbeTimeout
+//
+// Returns:
+//   - error: KindNetwork if the node is unreachable or not answering,
+//     nil when no remote node is configured
+//
+// Transient failures are retried with util.DefaultRetryPolicy, so one
+// dropped request does not trigger a failover.
+func (w *WalletRPC) CheckRemoteNode(ctx context.Context) error {
+	node := w.RemoteNode()
+	if node == "" {
+		return nil
+	}
+	addr, err := remoteDaemonAddress(node)
+	if err != nil {
+		return errors.E(opCheckRemoteNode, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, remoteProbeTimeout)
+	defer cancel()
+	client := monerod.NewClient(addr, "", "", rpc.Options{Component: errors.ComponentWalletRPC})
+	defer client.RPC().Close()
+	err = util.Retry(ctx, util.DefaultRetryPolicy(), func(ctx context.Context) error {
+		_, err := client.GetInfo(ctx)
+		return err
+	})
+	if err != nil {
+		return errors.E(opCheckRemoteNode, errors.ComponentWalletRPC, errors.KindNetwork,
+			fmt.Errorf("remote node %s unreachable: %w", node, err))
+	}
+	return nil
+}
+
+// SwitchRemoteNode moves the wallet to the next configured remote node.
+// The running wallet-rpc is repointed with set_daemon; if that fails, or
+// the process is not running, the caller must restart it against the new
+// node, pausing any supervision of the wallet around the restart.
+//
+// Parameters:
+//   - ctx: Context for the set_daemon call
+//
+// Returns:
+//   - string: The remote node now in use
+//   - bool: Whether the wallet must be restarted to use the node
+//   - error: KindConfig if fewer than two remote nodes are configured or
+//     the node is malformed
+func (w *WalletRPC) SwitchRemoteNode(ctx context.Context) (string, bool, error) {
+	if len(w.remoteNodes) < 2 {
+		return w.RemoteNode(), false, errors.E(opSwitchRemoteNode, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("no fallback remote node configured"))
+	}
+	previous := w.RemoteNode()
+	w.nodeIndex.Store((w.nodeIndex.Load() + 1) % int32(len(w.remoteNodes)))
+	node := w.RemoteNode()
+	w.log().Warn("switching remote node", "from", previous, "to", node)
+	w.InvalidateHealth()
+
+	addr, err := remoteDaemonAddress(node)
+	if err != nil {
+		return node, false, errors.E(opSwitchRemoteNode, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	if !w.Alive() {
+		return node, true, nil
+	}
+	if err := w.Client().SetDaemon(ctx, addr, false); err != nil {
+		w.log().Info("set_daemon failed, wallet-rpc needs a restart against the new node", "node", node, "error", err)
+		return node, true, nil
+	}
+	return node, false, nil
+}
+
+// proxyArgs returns the --proxy flag for the daemon connection: Tor when
+// configured, otherwise the I2P proxy if the remote node is an I2P address.
+func (w *WalletRPC) proxyArgs(remoteNode string) []string {
+	if w.tor.Enabled() {
+		return w.tor.WalletArgs()
+	}
+	if w.i2p.Enabled() && remoteNode != "" {
+		if _, host, _, err := validateRemoteDaemon(remoteNode); err == nil && strings.HasSuffix(host, ".i2p") {
+			return []string{"--proxy", w.i2p.Proxy}
+		}
+	}
+	return nil
+}
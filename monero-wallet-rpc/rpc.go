@@ -0,0 +1,79 @@
+package monerowalletrpc
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// resetClient drops the cached RPC client, so the next call connects
+// with the current endpoint and credentials.
+func (w *WalletRPC) resetClient() {
+	if w.client != nil {
+		w.client.Close()
+	}
+	w.client = nil
+	w.clientOnce = sync.Once{}
+}
+
+// movePort switches the RPC port to a free one after the configured port
+// turned out to be taken.
+//
+// Returns:
+//   - error: KindNetwork if no free port could be allocated
+func (w *WalletRPC) movePort() error {
+	ports, err := util.GetFreePorts(w.rpcHost, 1, w.portRange)
+	if err != nil {
+		return errors.E(opStart, errors.ComponentWalletRPC, errors.KindNetwork,
+			fmt.Errorf("port %d is in use and no free port was found: %w", w.rpcPort, err))
+	}
+	w.log().Warn("RPC port taken by another program, using a free port", "port", w.rpcPort, "chosen", ports[0])
+	w.rpcPort = ports[0]
+	w.resetClient()
+	return nil
+}
+
+// rpcClient returns the JSON-RPC client for this wallet service, creating
+// it on first use. The client is shared so that connections are reused.
+// It retries transient failures of safe calls and stops calling a
+// wallet-rpc that keeps failing until it recovers.
+func (w *WalletRPC) rpcClient() *rpc.Client {
+	w.clientOnce.Do(func() {
+		w.client = rpc.NewClient(
+			fmt.Sprintf("%s://%s", w.tls.Scheme(), net.JoinHostPort(w.RPCHost(), strconv.Itoa(w.WalletRPCPort()))),
+			w.WalletRPCUser(),
+			w.WalletRPCPass(),
+			rpc.Options{
+				Component:        errors.ComponentWalletRPC,
+				TLS:              w.clientTLS,
+				ErrorKind:        errorKind,
+				Retry:            util.RetryRPC,
+				BreakerThreshold: rpc.DefaultBreakerThreshold,
+			},
+		)
+	})
+	return w.client
+}
+
+// RPCHost returns the host the wallet RPC server is reached on:
+// localhost unless a specific bind address is configured.
+func (w *WalletRPC) RPCHost() string {
+	return util.DialHost(w.rpcHost)
+}
+
+// RPCStats reports in-flight, queued and completed wallet RPC requests
+// made through this instance, broken down by method.
+//
+// Returns:
+//   - rpc.Stats: Snapshot of request activity
+//
+// monero-wallet-rpc serves requests one at a time; sustained in-flight
+// counts above one or rising latencies indicate the wallet is the bottleneck.
+func (w *WalletRPC) RPCStats() rpc.Stats {
+	return w.rpcClient().Stats()
+}
@@ -3,13 +3,15 @@
 package monerowalletrpc
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"os"
+	"io"
+	"log/slog"
 	"os/exec"
+	"path/filepath"
 	"time"
 
+	moneroconst "github.com/opd-ai/moneroger/const"
 	"github.com/opd-ai/moneroger/errors"
 	"github.com/opd-ai/moneroger/monerod"
 	"github.com/opd-ai/moneroger/util"
@@ -18,11 +20,23 @@ import (
 // Common operation constants for error wrapping
 const (
 	opStart          = errors.Op("WalletRPC.Start")
+	opAttach         = errors.Op("WalletRPC.Attach")
 	opShutdown       = errors.Op("WalletRPC.Shutdown")
 	opValidateConfig = errors.Op("WalletRPC.ValidateConfig")
 	opCheckHealth    = errors.Op("WalletRPC.CheckHealth")
+	opReconfigure    = errors.Op("WalletRPC.Reconfigure")
+	opSetWalletPass  = errors.Op("WalletRPC.SetWalletPass")
+	opState          = errors.Op("WalletRPC.State")
+	opStopStale      = errors.Op("WalletRPC.StopStale")
 )
 
+// optionsFileFormat names the --config-file in the wallet directory
+// passing secrets to the wallet-rpc listening on a port
+const optionsFileFormat = ".moneroger-wallet-rpc-%d.conf"
+
+// healthCheckTimeout bounds a single health check RPC call
+const healthCheckTimeout = 5 * time.Second
+
 // NewWalletRPC creates and starts a new Monero wallet RPC service instance.
 //
 // Parameters:
@@ -50,14 +64,12 @@ const (
 //   - validateConfig for configuration validation
 //   - WalletRPC.start for process management
 func NewWalletRPC(ctx context.Context, config util.Config, daemon *monerod.MoneroDaemon) (*WalletRPC, error) {
-	if err := validateConfig(config); err != nil {
-		return nil, err
-	}
-
 	wallet := &WalletRPC{
-		walletDir: config.WalletFile,
-		rpcPort:   config.WalletPort,
-		daemon:    daemon,
+		daemon: daemon,
+		logs:   util.NewRingBuffer(config.OutputBufferSize()),
+	}
+	if err := wallet.configure(config); err != nil {
+		return nil, err
 	}
 
 	if err := wallet.Start(ctx); err != nil {
@@ -67,6 +79,94 @@ func NewWalletRPC(ctx context.Context, config util.Config, daemon *monerod.Moner
 	return wallet, nil
 }
 
+// configure validates config and applies it to the wallet's settings.
+// Process state and captured output are left alone.
+func (w *WalletRPC) configure(config util.Config) error {
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+	clientTLS, err := config.WalletTLS.ClientConfig()
+	if err != nil {
+		return errors.E(opValidateConfig, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+
+	w.walletDir = config.EffectiveWalletDir()
+	w.walletName = config.WalletName
+	w.dataDir = config.NetworkDataDir()
+	w.detach = config.Detach && config.DataDir != ""
+	w.driver = config.Driver()
+	w.extraArgs = config.WalletRPCExtraArgs
+	w.hwDevice = config.HWDevice
+	w.startWait = config.EffectiveStartupTimeout()
+	w.stopWait = config.EffectiveShutdownTimeout()
+	w.rpcPort = config.EffectiveWalletPort()
+	w.rpcUser = config.WalletRPCUser
+	w.rpcPass = config.WalletRPCPass
+	w.rpcHost = config.WalletBindIP
+	w.autoPort = config.AutoPort
+	w.portRange = config.AutoPortRange
+	w.network = config.NetType()
+	w.remoteNodes = config.RemoteNodeList()
+	w.nodeIndex.Store(0)
+	w.tor = config.Tor
+	w.i2p = config.I2P
+	w.tls = config.WalletTLS
+	w.clientTLS = clientTLS
+	w.output = config.WalletOutput
+	w.logOutput = config.LogProcessOutput
+	w.logger = config.Log().With("component", errors.ComponentWalletRPC)
+	w.credentials = config.CredentialStore()
+	if w.credentials != nil {
+		if err := w.resolveCredentials(); err != nil {
+			return err
+		}
+	}
+
+	// Endpoint and credentials may have changed
+	w.resetClient()
+	return nil
+}
+
+// resolveCredentials fetches the RPC password, unless configured, and
+// the wallet password from the credential store, generating and storing
+// those it does not hold yet.
+func (w *WalletRPC) resolveCredentials() error {
+	if w.rpcPass == "" {
+		pass, err := util.ResolveCredential(w.credentials, util.WalletRPCAccount(w.walletDir))
+		if err != nil {
+			return errors.E(opValidateConfig, errors.ComponentWalletRPC, errors.KindSystem, err)
+		}
+		w.rpcPass = pass
+	}
+	pass, err := util.ResolveCredential(w.credentials, util.WalletAccount(w.walletDir))
+	if err != nil {
+		return errors.E(opValidateConfig, errors.ComponentWalletRPC, errors.KindSystem, err)
+	}
+	w.walletPass = pass
+	return nil
+}
+
+// Reconfigure applies a new configuration to a stopped wallet; the next
+// Start uses it, connecting to the same daemon instance. Moneroger.Reload
+// uses this to restart the wallet with changed settings.
+//
+// Parameters:
+//   - config: New configuration
+//
+// Returns:
+//   - error: KindProcess if the wallet is still running, KindConfig for
+//     invalid settings
+func (w *WalletRPC) Reconfigure(config util.Config) error {
+	w.startMu.Lock()
+	defer w.startMu.Unlock()
+	if w.Alive() {
+		return errors.E(opReconfigure, errors.ComponentWalletRPC, errors.KindProcess,
+			fmt.Errorf("wallet-rpc must be stopped before it is reconfigured"))
+	}
+	w.InvalidateHealth()
+	return w.configure(config)
+}
+
 // validateConfig checks the validity of wallet RPC configuration parameters.
 //
 // Parameters:
@@ -76,20 +176,20 @@ func NewWalletRPC(ctx context.Context, config util.Config, daemon *monerod.Moner
 //   - error: Validation error if any parameter is invalid
 //
 // Validates:
-// 1. Wallet file path existence
+// 1. Wallet directory and wallet name
 // 2. RPC port number validity
 // 3. File system permissions
 func validateConfig(config util.Config) error {
-	if config.WalletFile == "" {
+	if config.EffectiveWalletDir() == "" {
 		return errors.E(
 			opValidateConfig,
 			errors.ComponentWalletRPC,
 			errors.KindConfig,
-			fmt.Errorf("wallet file path cannot be empty"),
+			fmt.Errorf("wallet directory cannot be empty"),
 		)
 	}
 
-	if config.WalletPort <= 0 {
+	if config.WalletPort < 0 {
 		return errors.E(
 			opValidateConfig,
 			errors.ComponentWalletRPC,
@@ -98,14 +198,39 @@ func validateConfig(config util.Config) error {
 		)
 	}
 
-	/*if _, err := os.Stat(config.WalletFile); os.IsNotExist(err) {
-		return errors.E(
-			opValidateConfig,
-			errors.ComponentWalletRPC,
-			errors.KindSystem,
-			fmt.Errorf("wallet file does not exist: %s", config.WalletFile),
-		)
-	}*/
+	for _, validate := range []func() error{
+		config.Tor.Validate,
+		config.I2P.Validate,
+		config.WalletTLS.Validate,
+		func() error { return util.ValidateBindIP(config.WalletBindIP) },
+		func() error {
+			if config.WalletName == "" {
+				return nil
+			}
+			return util.ValidateWalletName(config.WalletName)
+		},
+		config.ValidateWalletRPCExtraArgs,
+	} {
+		if err := validate(); err != nil {
+			return errors.E(
+				opValidateConfig,
+				errors.ComponentWalletRPC,
+				errors.KindConfig,
+				err,
+			)
+		}
+	}
+
+	for _, node := range config.RemoteNodeList() {
+		if _, err := remoteDaemonAddress(node); err != nil {
+			return errors.E(
+				opValidateConfig,
+				errors.ComponentWalletRPC,
+				errors.KindConfig,
+				fmt.Errorf("invalid remote daemon URL: %s : %w", node, err),
+			)
+		}
+	}
 
 	return nil
 }
@@ -120,44 +245,89 @@ func validateConfig(config util.Config) error {
 //
 // The method:
 // 1. Checks port availability
-// 2. Configures process arguments
+// 2. Configures process arguments (local daemon or current remote node)
 // 3. Launches wallet RPC process
 // 4. Verifies service availability
 // 5. Performs health check
+// 6. Opens Config.WalletName, if set, with the managed wallet password;
+// a wallet that cannot be opened stops the process and fails with KindWallet
+//
+// Start is safe for concurrent use: calls are serialized, and a call
+// finding the wallet running returns nil. Starting a wallet that is
+// still stopping fails with KindProcess.
+//
+// Related:
+//   - State for the lifecycle state
 func (w *WalletRPC) Start(ctx context.Context) error {
-	if util.IsPortInUse(w.WalletRPCPort()) {
-		return errors.E(
-			opStart,
-			errors.ComponentWalletRPC,
-			errors.KindNetwork,
-			fmt.Errorf("port %d is already in use", w.WalletRPCPort()),
-		)
+	w.startMu.Lock()
+	defer w.startMu.Unlock()
+
+	w.mu.Lock()
+	if w.state == WalletStateRunning {
+		w.mu.Unlock()
+		return nil
+	}
+	err := w.setState(WalletStateStarting)
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	err = w.start(ctx)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		if w.state == WalletStateStarting {
+			_ = w.setState(WalletStateStopped)
+		}
+		return err
+	}
+	if w.state != WalletStateStarting {
+		return errors.E(opStart, errors.ComponentWalletRPC, errors.KindProcess,
+			fmt.Errorf("wallet-rpc %s during startup", w.state))
+	}
+	return w.setState(WalletStateRunning)
+}
+
+// start adopts or spawns the process for Start and waits until it is
+// healthy.
+func (w *WalletRPC) start(ctx context.Context) error {
+	if exit := w.processExit(); exit != nil && !exit.Exited() {
+		return nil
 	}
+	if orphan, err := w.adoptOrphan(ctx, true); err != nil || orphan {
+		return err
+	}
+	if !util.IsPortAvailable(w.rpcHost, w.WalletRPCPort()) {
+		if !w.autoPort {
+			return errors.E(
+				opStart,
+				errors.ComponentWalletRPC,
+				errors.KindNetwork,
+				fmt.Errorf("port %d is already in use", w.WalletRPCPort()),
+			)
+		}
+		if err := w.movePort(); err != nil {
+			return err
+		}
+	}
+	remoteNode := w.RemoteNode()
 	var daemonAddr string
-	if w.remoteNode == "" {
-		daemonAddr = fmt.Sprintf("http://localhost:%d", w.daemon.RPCPort())
+	if remoteNode == "" {
+		daemonAddr = w.processDriver().DaemonAddress(w.daemon.RPCAddress())
 	} else {
-		scheme, host, port, err := validateRemoteDaemon(w.remoteNode)
+		addr, err := remoteDaemonAddress(remoteNode)
 		if err != nil {
-			errors.E(
+			return errors.E(
 				opStart,
 				errors.ComponentWalletRPC,
 				errors.KindConfig,
-				fmt.Errorf("invalid remote daemon URL: %s : %s", w.remoteNode, err),
+				fmt.Errorf("invalid remote daemon URL: %s : %w", remoteNode, err),
 			)
 		}
-		daemonAddr = fmt.Sprintf("%s://%s:%s", scheme, host, port)
-	}
-	args := []string{
-		"--wallet-dir", w.walletDir,
-		"--rpc-bind-port", fmt.Sprintf("%d", w.WalletRPCPort()),
-		"--daemon-address", daemonAddr,
-		"--prompt-for-password",
-		"--daemon-login", fmt.Sprintf("%s:%s", w.daemon.RPCUser(), w.daemon.RPCPass()),
-		"--rpc-login", fmt.Sprintf("%s:%s", w.WalletRPCUser(), w.WalletRPCPass()),
-		"--password", w.WalletPass(),
+		daemonAddr = addr
 	}
-	moneroWalletRPC, err := MoneroWalletRPCPath()
+	moneroWalletRPC, err := w.processDriver().Executable(MoneroWalletRPCPath)
 	if err != nil {
 		return errors.E(
 			opStart,
@@ -167,12 +337,49 @@ func (w *WalletRPC) Start(ctx context.Context) error {
 		)
 	}
 
-	cmd := exec.CommandContext(ctx, moneroWalletRPC, args...)
+	w.log().Info("starting monero-wallet-rpc", "path", moneroWalletRPC, "port", w.WalletRPCPort(), "daemon", daemonAddr)
+	cmdCtx := ctx
+	if w.detach {
+		// A detached wallet-rpc outlives the context that started it
+		cmdCtx = context.WithoutCancel(ctx)
+	}
+	cmd, err := w.command(cmdCtx, moneroWalletRPC, daemonAddr, remoteNode)
+	if err != nil {
+		return errors.E(
+			opStart,
+			errors.ComponentWalletRPC,
+			errors.KindSystem,
+			err,
+		)
+	}
 
-	// Add stdout/stderr capture
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// Capture the tail of stdout/stderr for error reports, optionally
+	// streaming everything to the configured sink as well
+	if w.logs == nil {
+		w.logs = util.NewRingBuffer(moneroconst.DefaultOutputBufferSize)
+	}
+	stdout := util.NewRingBuffer(moneroconst.DefaultOutputBufferSize)
+	stderr := util.NewRingBuffer(moneroconst.DefaultOutputBufferSize)
+	w.stdout, w.stderr = stdout, stderr
+	if w.detach {
+		// The output must not depend on pipes read by this process
+		logFile, err := util.OpenProcessLog(filepath.Join(w.dataDir, fmt.Sprintf(logFileFormat, w.WalletRPCPort())))
+		if err != nil {
+			return errors.E(
+				opStart,
+				errors.ComponentWalletRPC,
+				errors.KindSystem,
+				err,
+			)
+		}
+		defer logFile.Close()
+		cmd.Stdout, cmd.Stderr = logFile, logFile
+		util.PrepareDetachedCommand(cmd)
+	} else {
+		cmd.Stdout = w.outputWriter(stdout, "stdout")
+		cmd.Stderr = w.outputWriter(stderr, "stderr")
+		util.PrepareCommand(cmd)
+	}
 
 	if err := cmd.Start(); err != nil {
 		return errors.E(
@@ -184,9 +391,23 @@ func (w *WalletRPC) Start(ctx context.Context) error {
 		)
 	}
 
+	w.mu.Lock()
 	w.cmd = cmd
+	w.mu.Unlock()
+	w.track(cmd.Process, util.WatchProcess(cmd))
+	if pidFile := w.pidFile(); pidFile != "" {
+		if err := util.WritePIDFile(pidFile, cmd.Process.Pid); err != nil {
+			w.log().Warn("could not write PID file, a restarted manager cannot adopt the wallet", "path", pidFile, "error", err)
+		}
+	}
+	// A new process starts without a wallet open
+	w.setOpenWallet("")
 
-	if err := util.WaitForPort(ctx, w.WalletRPCPort()); err != nil {
+	// An open port is not enough: wallet-rpc must answer RPC
+	waitCtx, cancel := context.WithTimeout(ctx, w.startupTimeout())
+	err = util.WaitReady(waitCtx, w.probeRPC)
+	cancel()
+	if err != nil {
 		// Capture output before cleanup
 		output := fmt.Sprintf("Output: %s\nError: %s", stdout.String(), stderr.String())
 		_ = w.Shutdown(ctx)
@@ -194,7 +415,7 @@ func (w *WalletRPC) Start(ctx context.Context) error {
 			opStart,
 			errors.ComponentWalletRPC,
 			errors.KindTimeout,
-			fmt.Errorf("wallet-rpc failed to bind to port %d: %w\n%s",
+			fmt.Errorf("wallet-rpc did not become ready on port %d: %w\n%s",
 				w.WalletRPCPort(), err, output),
 		)
 	}
@@ -204,9 +425,84 @@ func (w *WalletRPC) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Open the configured wallet like --wallet-file would, with the
+	// password sent over RPC instead of the command line
+	if w.walletName != "" {
+		if err := w.OpenWallet(ctx, w.walletName, w.WalletPass()); err != nil {
+			_ = w.Shutdown(ctx)
+			return err
+		}
+	}
+
 	return nil
 }
 
+// command builds the monero-wallet-rpc command line. The RPC and daemon
+// logins are written to a --config-file readable only by the owner
+// instead of appearing in the arguments, where any local user could read
+// them with ps. Wallet passwords are never passed: in --wallet-dir mode
+// they are supplied per wallet over RPC.
+//
+// Parameters:
+//   - ctx: Context killing the process when done
+//   - path: Executable returned by the driver
+//   - daemonAddr: Daemon the wallet connects to
+//   - remoteNode: The remote node in use, empty for the local daemon
+//
+// Returns:
+//   - *exec.Cmd: The unstarted command
+//   - error: If the options file cannot be written
+func (w *WalletRPC) command(ctx context.Context, path, daemonAddr, remoteNode string) (*exec.Cmd, error) {
+	options := map[string]string{
+		"rpc-login": fmt.Sprintf("%s:%s", w.WalletRPCUser(), w.WalletRPCPass()),
+	}
+	// The local daemon's credentials mean nothing to a remote node
+	if remoteNode == "" {
+		options["daemon-login"] = fmt.Sprintf("%s:%s", w.daemon.RPCUser(), w.daemon.RPCPass())
+	}
+	// Several wallets may share a directory, so the file is per port
+	optionsPath := filepath.Join(w.walletDir, fmt.Sprintf(optionsFileFormat, w.WalletRPCPort()))
+	if err := util.WriteOptionsFile(optionsPath, options); err != nil {
+		return nil, fmt.Errorf("failed to write wallet-rpc options: %w", err)
+	}
+
+	args := []string{
+		"--wallet-dir", w.walletDir,
+		"--config-file", optionsPath,
+		"--rpc-bind-port", fmt.Sprintf("%d", w.WalletRPCPort()),
+		"--daemon-address", daemonAddr,
+		"--prompt-for-password",
+	}
+	if flag := w.network.Flag(); flag != "" {
+		args = append(args, flag)
+	}
+	driver := w.processDriver()
+	args = append(args, util.BindArgs(driver.BindIP(w.rpcHost))...)
+	args = append(args, w.tls.ServerArgs()...)
+	args = append(args, w.proxyArgs(remoteNode)...)
+	args = append(args, w.hwDevice.WalletArgs()...)
+	mounts := []string{w.walletDir, w.tls.CertFile, w.tls.KeyFile, w.tls.CAFile}
+	if remoteNode == "" {
+		if daemonTLS := w.daemon.TLS(); daemonTLS.Enabled() {
+			args = append(args,
+				"--daemon-ssl", "enabled",
+				"--daemon-ssl-ca-certificates", daemonTLS.VerifyFile(),
+			)
+			mounts = append(mounts, daemonTLS.VerifyFile())
+		}
+	}
+	args = append(args, w.extraArgs...)
+	return driver.Command(ctx, util.ProcessSpec{
+		Path:      path,
+		Program:   "monero-wallet-rpc",
+		Name:      fmt.Sprintf("monero-wallet-rpc-%d", w.WalletRPCPort()),
+		Args:      args,
+		Mounts:    mounts,
+		Ports:     []int{w.WalletRPCPort()},
+		PublishIP: w.rpcHost,
+	})
+}
+
 // Shutdown gracefully stops the wallet RPC service.
 //
 // Parameters:
@@ -221,37 +517,52 @@ func (w *WalletRPC) Start(ctx context.Context) error {
 // 3. Cleans up resources
 //
 // Timeout:
-//   - Default 10 second shutdown timeout
+//   - Config.ShutdownTimeout, 10 seconds by default
 //   - Returns error if shutdown exceeds timeout
 //
 // Related:
 //   - checkHealth for service verification
 func (w *WalletRPC) Shutdown(ctx context.Context) error {
-	if w.cmd.Process == nil {
+	w.mu.Lock()
+	process, exit := w.process, w.exit
+	if process == nil {
+		w.mu.Unlock()
+		return nil
+	}
+	// Later callers only wait for the exit the first one requested
+	first := w.state == WalletStateStarting || w.state == WalletStateRunning
+	switch {
+	case first:
+		if err := w.setState(WalletStateStopping); err != nil {
+			w.mu.Unlock()
+			return err
+		}
+	case w.state != WalletStateStopping:
+		// Already exited
+		w.process = nil
+		w.mu.Unlock()
 		return nil
 	}
+	w.mu.Unlock()
 
 	// Create a timeout context for shutdown
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, w.shutdownTimeout())
 	defer cancel()
 
-	// Send interrupt signal
-	if err := w.cmd.Process.Signal(os.Interrupt); err != nil {
-		return errors.E(
-			opShutdown,
-			errors.ComponentWalletRPC,
-			errors.KindProcess,
-			fmt.Errorf("failed to send interrupt signal: %w", err),
-		)
+	if first {
+		w.log().Info("stopping monero-wallet-rpc", "pid", process.Pid)
+		// Send interrupt signal
+		if err := util.InterruptProcess(process); err != nil {
+			return errors.E(
+				opShutdown,
+				errors.ComponentWalletRPC,
+				errors.KindProcess,
+				fmt.Errorf("failed to send interrupt signal: %w", err),
+			)
+		}
 	}
 
 	// Wait for process to exit
-	done := make(chan error, 1)
-	go func() {
-		_, err := w.cmd.Process.Wait()
-		done <- err
-	}()
-
 	select {
 	case <-ctx.Done():
 		return errors.E(
@@ -260,8 +571,9 @@ func (w *WalletRPC) Shutdown(ctx context.Context) error {
 			errors.KindTimeout,
 			fmt.Errorf("shutdown timed out"),
 		)
-	case err := <-done:
-		if err != nil {
+	case <-exit.Done():
+		w.processExited(exit)
+		if err := exit.Err(); err != nil && !util.IsInterruptExit(err) {
 			return errors.E(
 				opShutdown,
 				errors.ComponentWalletRPC,
@@ -271,8 +583,11 @@ func (w *WalletRPC) Shutdown(ctx context.Context) error {
 		}
 	}
 
-	w.cmd.Process = nil
-	w.cmd = nil
+	w.mu.Lock()
+	if w.process == process {
+		w.process = nil
+	}
+	w.mu.Unlock()
 	return nil
 }
 
@@ -284,28 +599,209 @@ func (w *WalletRPC) Shutdown(ctx context.Context) error {
 // Returns:
 //   - error: Any error encountered during health check
 //
-// Currently:
-// - Verifies port is still in use
-// TODO: Implement full RPC health check
+// The check issues an authenticated get_version JSON-RPC call, so a
+// process that has bound its port but is not actually serving requests,
+// or rejects our credentials, is reported as unhealthy. Having no wallet
+// open is a healthy state; see checkOpenWallet.
 func (w *WalletRPC) checkHealth(ctx context.Context) error {
-	// TODO: Implement actual health check using RPC call
-	// For now, just check if the port is still open
-	if !util.IsPortInUse(w.WalletRPCPort()) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if err := w.probeRPC(ctx); err != nil {
 		return errors.E(
 			opCheckHealth,
 			errors.ComponentWalletRPC,
 			errors.KindNetwork,
-			fmt.Errorf("wallet-rpc is not responding on port %d", w.WalletRPCPort()),
+			fmt.Errorf("wallet-rpc is not responding on port %d: %w", w.WalletRPCPort(), err),
 		)
 	}
-	return nil
+	return w.checkOpenWallet(ctx)
+}
+
+// probeRPC issues an authenticated get_version call, bounded by
+// healthCheckTimeout.
+func (w *WalletRPC) probeRPC(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	var version struct {
+		Version uint32 `json:"version"`
+	}
+	return w.rpcClient().Call(ctx, "get_version", nil, &version)
+}
+
+// CheckHealth reports whether the wallet RPC service is responding.
+//
+// Parameters:
+//   - ctx: Context bounding how long the caller waits
+//
+// Returns:
+//   - error: nil if healthy, otherwise the health check failure
+//
+// Concurrent callers share a single underlying check and results are
+// reused for moneroconst.DefaultHealthCacheTTL, so frequent polling does
+// not add load to the serially processing wallet-rpc.
+//
+// Related:
+//   - util.CachedCheck for coalescing behaviour
+func (w *WalletRPC) CheckHealth(ctx context.Context) error {
+	return w.cachedHealth().Check(ctx)
+}
+
+// cachedHealth returns the health check cache, creating it on first use.
+func (w *WalletRPC) cachedHealth() *util.CachedCheck {
+	w.healthOnce.Do(func() {
+		w.health = util.NewCachedCheck(moneroconst.DefaultHealthCacheTTL, w.checkHealth)
+	})
+	return w.health
+}
+
+// InvalidateHealth discards any cached health check result, forcing the
+// next CheckHealth call to query the service.
+func (w *WalletRPC) InvalidateHealth() {
+	w.cachedHealth().Invalidate()
+}
+
+// Alive reports whether the wallet-rpc process is still running.
+func (w *WalletRPC) Alive() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.process != nil && !w.exit.Exited()
+}
+
+// processExit returns the exit tracker of the current process.
+func (w *WalletRPC) processExit() *util.ProcessExit {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.exit
+}
+
+// Exited returns a channel closed when the wallet-rpc process exits,
+// or a nil channel if no process has been started.
+func (w *WalletRPC) Exited() <-chan struct{} {
+	return w.processExit().Done()
+}
+
+// ExitErr returns the exit error of the last process, or nil if it is
+// still running or exited cleanly.
+func (w *WalletRPC) ExitErr() error {
+	return w.processExit().Err()
+}
+
+// Uptime returns how long the current wallet-rpc process has been running.
+func (w *WalletRPC) Uptime() time.Duration {
+	return w.processExit().Uptime()
+}
+
+// Stopping reports whether the process exit was requested via Shutdown,
+// as opposed to an unexpected crash.
+func (w *WalletRPC) Stopping() bool {
+	state := w.State()
+	return state == WalletStateStopping || state == WalletStateStopped
 }
 
 func (m *WalletRPC) PID() string {
-	if m.cmd != nil {
-		if m.cmd.Process != nil {
-			return fmt.Sprintf("%d", m.cmd.Process.Pid)
-		}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.process != nil {
+		return fmt.Sprintf("%d", m.process.Pid)
 	}
 	return "-1"
 }
+
+// State returns the lifecycle state of the wallet service.
+//
+// Returns:
+//   - WalletState: Current state, WalletStateCrashed after an exit that
+//     Shutdown did not request
+func (w *WalletRPC) State() WalletState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// setState validates and applies a state change. w.mu must be held.
+func (w *WalletRPC) setState(to WalletState) error {
+	if err := util.CheckTransition(w.state, to); err != nil {
+		return errors.E(opState, errors.ComponentWalletRPC, errors.KindProcess, err)
+	}
+	w.log().Debug("state changed", "from", w.state, "to", to)
+	w.state = to
+	return nil
+}
+
+// processExited records the exit of the process: stopped if Shutdown
+// requested it, crashed otherwise. Exits of processes that have since
+// been replaced are ignored.
+func (w *WalletRPC) processExited(exit *util.ProcessExit) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.exit != exit {
+		return
+	}
+	if pidFile := w.pidFile(); pidFile != "" {
+		if err := util.RemovePIDFile(pidFile); err != nil {
+			w.log().Warn("could not remove PID file", "path", pidFile, "error", err)
+		}
+	}
+	switch w.state {
+	case WalletStateStopping:
+		_ = w.setState(WalletStateStopped)
+	case WalletStateStarting, WalletStateRunning:
+		_ = w.setState(WalletStateCrashed)
+	}
+}
+
+// outputWriter combines a capture buffer with the optional output sink
+// and debug logging for one output stream.
+func (w *WalletRPC) outputWriter(buf *util.RingBuffer, stream string) io.Writer {
+	writers := []io.Writer{buf, util.NewPrefixWriter(w.logs, "")}
+	if w.output != nil {
+		writers = append(writers, util.NewPrefixWriter(w.output, "["+string(errors.ComponentWalletRPC)+"] "))
+	}
+	if w.logOutput {
+		writers = append(writers, util.NewLogWriter(w.log(), slog.LevelDebug, stream))
+	}
+	return io.MultiWriter(writers...)
+}
+
+// SetOutput configures a writer that receives the stdout and stderr
+// stream of the wallet-rpc process while it runs, each line prefixed
+// with "[monero-wallet-rpc] ". It must be called before Start.
+//
+// Parameters:
+//   - out: Destination for process output, or nil to disable streaming
+//
+// Only the most recent output is retained in memory regardless of this
+// setting, so long-running processes do not grow without bound.
+func (w *WalletRPC) SetOutput(out io.Writer) {
+	w.output = out
+}
+
+// RecentLogs returns the most recent combined stdout and stderr output
+// of the process, including output from before the last restart, up to
+// the configured LogBufferSize.
+//
+// Returns:
+//   - string: Retained output lines, oldest first, empty if never started
+func (w *WalletRPC) RecentLogs() string {
+	if w.logs == nil {
+		return ""
+	}
+	return w.logs.String()
+}
+
+// RecentOutput returns the most recent stdout and stderr output of the
+// wallet-rpc process, bounded by moneroconst.DefaultOutputBufferSize each.
+//
+// Returns:
+//   - stdout: Retained standard output, empty if never started
+//   - stderr: Retained standard error, empty if never started
+func (w *WalletRPC) RecentOutput() (stdout, stderr string) {
+	if w.stdout != nil {
+		stdout = w.stdout.String()
+	}
+	if w.stderr != nil {
+		stderr = w.stderr.String()
+	}
+	return
+}
@@ -2,12 +2,25 @@ package monerowalletrpc
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/opd-ai/moneroger/errors"
 	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/rpc"
 	"github.com/opd-ai/moneroger/util"
 )
 
@@ -41,6 +54,42 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "wallet directory and name",
+			config: util.Config{
+				WalletDir:  walletFile,
+				WalletName: "shop",
+				WalletPort: 18082,
+			},
+			wantErr: false,
+		},
+		{
+			name: "wallet name with a path",
+			config: util.Config{
+				WalletDir:  walletFile,
+				WalletName: "../shop",
+				WalletPort: 18082,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid remote node",
+			config: util.Config{
+				WalletFile: walletFile,
+				WalletPort: 18082,
+				RemoteNode: "node.example.com:18089",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid remote node",
+			config: util.Config{
+				WalletFile: walletFile,
+				WalletPort: 18082,
+				RemoteNode: "ftp://node.example.com",
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty wallet file",
 			config: util.Config{
@@ -189,3 +238,867 @@ func MockDaemon(t *testing.T) *monerod.MoneroDaemon {
 	t.Helper()
 	return &monerod.MoneroDaemon{}
 }
+
+// TestCheckHealth verifies the RPC-based health check
+func TestCheckHealth(t *testing.T) {
+	t.Run("serving", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"id":1,"jsonrpc":"2.0","result":{"version":65562}}`))
+		}))
+		defer srv.Close()
+
+		w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+		if err := w.checkHealth(context.Background()); err != nil {
+			t.Errorf("checkHealth() error = %v", err)
+		}
+	})
+
+	t.Run("bound but not serving", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+		err := w.checkHealth(context.Background())
+		if errors.GetKind(err) != errors.KindNetwork {
+			t.Errorf("checkHealth() error = %v, want network error", err)
+		}
+	})
+}
+
+// mockWallet serves canned JSON-RPC results keyed by method name and
+// records the params of each call
+func mockWallet(t *testing.T, results map[string]string, params map[string]json.RawMessage) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if params != nil {
+			mu.Lock()
+			params[req.Method] = req.Params
+			mu.Unlock()
+		}
+		result, ok := results[req.Method]
+		if !ok {
+			fmt.Fprintf(w, `{"id":%d,"error":{"code":-32601,"message":"Method not found"}}`, req.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%d,"result":%s}`, req.ID, result)
+	}))
+}
+
+// TestClient verifies the typed wallet RPC wrappers
+func TestClient(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	srv := mockWallet(t, map[string]string{
+		"get_balance":   `{"balance":1500000000000,"unlocked_balance":1000000000000,"per_subaddress":[{"address_index":1,"balance":500000000000}]}`,
+		"get_address":   `{"address":"4primary","addresses":[{"address":"8sub","address_index":1,"used":true}]}`,
+		"transfer":      `{"amount":100,"fee":7,"tx_hash":"deadbeef","tx_key":"key"}`,
+		"get_transfers": `{"in":[{"txid":"t1","amount":5,"type":"in","subaddr_index":{"major":0,"minor":2}}]}`,
+		"refresh":       `{"blocks_fetched":24,"received_money":true}`,
+	}, params)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", rpc.Options{})
+	ctx := context.Background()
+
+	b, err := c.GetBalance(ctx, 0)
+	if err != nil || b.UnlockedBalance != 1000000000000 || len(b.PerSubaddress) != 1 {
+		t.Errorf("GetBalance() = %+v, %v", b, err)
+	}
+	a, err := c.GetAddress(ctx, 0, []uint32{1})
+	if err != nil || a.Address != "4primary" || !a.Addresses[0].Used {
+		t.Errorf("GetAddress() = %+v, %v", a, err)
+	}
+	tr, err := c.Transfer(ctx, TransferParams{Destinations: []Destination{{Amount: 100, Address: "4dest"}}, GetTxKey: true})
+	if err != nil || tr.TxHash != "deadbeef" || tr.Fee != 7 {
+		t.Errorf("Transfer() = %+v, %v", tr, err)
+	}
+	if !strings.Contains(string(params["transfer"]), `"get_tx_key":true`) {
+		t.Errorf("transfer params = %s", params["transfer"])
+	}
+	ts, err := c.GetTransfers(ctx, GetTransfersParams{In: true})
+	if err != nil || len(ts.In) != 1 || ts.In[0].SubaddrIndex.Minor != 2 {
+		t.Errorf("GetTransfers() = %+v, %v", ts, err)
+	}
+	rr, err := c.Refresh(ctx, 0)
+	if err != nil || rr.BlocksFetched != 24 || !rr.ReceivedMoney {
+		t.Errorf("Refresh() = %+v, %v", rr, err)
+	}
+}
+
+// TestPoolBalancing verifies calls rotate across idle members and avoid busy ones
+func TestPoolBalancing(t *testing.T) {
+	var counts [3]atomic.Int32
+	release := make(chan struct{})
+	var members []*WalletRPC
+	for i := range counts {
+		i := i
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counts[i].Add(1)
+			if i == 0 {
+				<-release
+			}
+			var req struct {
+				ID uint64 `json:"id"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			fmt.Fprintf(w, `{"id":%d,"result":{}}`, req.ID)
+		}))
+		defer srv.Close()
+		port := srv.Listener.Addr().(*net.TCPAddr).Port
+		members = append(members, &WalletRPC{rpcPort: port, rpcUser: "u", rpcPass: "p"})
+	}
+	p := newPool(members...)
+	ctx := context.Background()
+
+	// Occupy member 0 with a request that blocks until released
+	done := make(chan error, 1)
+	go func() { done <- members[0].rpcClient().Call(ctx, "get_version", nil, nil) }()
+	for members[0].RPCStats().InFlight == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 10; i++ {
+		if err := p.Call(ctx, "get_version", nil, nil); err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("blocked call error = %v", err)
+	}
+
+	if got := counts[0].Load(); got != 1 {
+		t.Errorf("busy member served %d requests, want only the blocked one", got)
+	}
+	if a, b := counts[1].Load(), counts[2].Load(); a+b != 10 || a == 0 || b == 0 {
+		t.Errorf("idle members served %d and %d requests, want all 10 shared between them", a, b)
+	}
+}
+
+// TestRemoteDaemonAddress verifies remote node URL normalization
+func TestRemoteDaemonAddress(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"node.example.com", "http://node.example.com:18081", false},
+		{"https://node.example.com:18089", "https://node.example.com:18089", false},
+		{"http://[::1]:18081/", "http://[::1]:18081", false},
+		{"ftp://node.example.com", "", true},
+		{"http://node.example.com:99999", "", true},
+		{"http://node.example.com/json_rpc", "", true},
+	}
+	for _, tt := range tests {
+		got, err := remoteDaemonAddress(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("remoteDaemonAddress(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestCheckRemoteNode verifies remote node probing and failover preconditions
+func TestCheckRemoteNode(t *testing.T) {
+	node := mockWallet(t, map[string]string{"get_info": `{"status":"OK","height":100}`}, nil)
+	defer node.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	w := &WalletRPC{remoteNodes: []string{node.URL, dead.URL}}
+	ctx := context.Background()
+	if err := w.CheckRemoteNode(ctx); err != nil {
+		t.Errorf("CheckRemoteNode() on live node error = %v", err)
+	}
+	w.nodeIndex.Store(1)
+	if got := w.RemoteNode(); got != dead.URL {
+		t.Errorf("RemoteNode() = %q, want %q", got, dead.URL)
+	}
+	if err := w.CheckRemoteNode(ctx); errors.GetKind(err) != errors.KindNetwork {
+		t.Errorf("CheckRemoteNode() on dead node error = %v, want KindNetwork", err)
+	}
+
+	local := &WalletRPC{}
+	if err := local.CheckRemoteNode(ctx); err != nil {
+		t.Errorf("CheckRemoteNode() without remote nodes error = %v", err)
+	}
+	if _, _, err := local.SwitchRemoteNode(ctx); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("SwitchRemoteNode() without fallbacks error = %v, want KindConfig", err)
+	}
+
+	// A wallet that is not running is left for the caller to restart
+	stopped := &WalletRPC{remoteNodes: []string{"http://a.example:18089", "http://b.example:18089"}}
+	next, restart, err := stopped.SwitchRemoteNode(ctx)
+	if err != nil || !restart || next != "http://b.example:18089" {
+		t.Errorf("SwitchRemoteNode() of a stopped wallet = %q, %v, %v, want the next node and a restart", next, restart, err)
+	}
+}
+
+// TestProxyArgs verifies the wallet's proxy selection for Tor and I2P
+func TestProxyArgs(t *testing.T) {
+	i2p := util.I2PConfig{Proxy: "127.0.0.1:4447"}
+	w := &WalletRPC{i2p: i2p}
+	if got := strings.Join(w.proxyArgs("http://node.b32.i2p:18081"), " "); got != "--proxy 127.0.0.1:4447" {
+		t.Errorf("proxyArgs(i2p node) = %q", got)
+	}
+	if got := w.proxyArgs("http://node.example.com:18081"); got != nil {
+		t.Errorf("proxyArgs(clearnet node) = %v, want nil", got)
+	}
+	w.tor = util.TorConfig{Proxy: "127.0.0.1:9050"}
+	if got := strings.Join(w.proxyArgs("http://node.b32.i2p:18081"), " "); got != "--proxy 127.0.0.1:9050" {
+		t.Errorf("proxyArgs() with Tor = %q, want the Tor proxy", got)
+	}
+}
+
+// TestCommandHidesSecrets verifies no credential appears in the process
+// arguments, where ps would show it
+func TestCommandHidesSecrets(t *testing.T) {
+	daemon := &monerod.MoneroDaemon{}
+	w := &WalletRPC{
+		daemon:    daemon,
+		walletDir: t.TempDir(),
+		rpcPort:   18083,
+		rpcUser:   "user",
+		rpcPass:   "wallet-secret",
+	}
+	cmd, err := w.command(context.Background(), "monero-wallet-rpc", "127.0.0.1:18081", "")
+	if err != nil {
+		t.Fatalf("command() error = %v", err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, secret := range []string{"wallet-secret", daemon.RPCPass()} {
+		if strings.Contains(args, secret) {
+			t.Errorf("cmd.Args contain %q: %s", secret, args)
+		}
+	}
+
+	path := filepath.Join(w.walletDir, fmt.Sprintf(optionsFileFormat, 18083))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("daemon-login=%s:%s\nrpc-login=user:wallet-secret\n", daemon.RPCUser(), daemon.RPCPass())
+	if string(data) != want {
+		t.Errorf("options file = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("options file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A remote node gets no daemon login
+	if _, err := w.command(context.Background(), "monero-wallet-rpc", "node.example.com:18081", "http://node.example.com:18081"); err != nil {
+		t.Fatalf("command() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "daemon-login") {
+		t.Errorf("options file for a remote node = %q, want no daemon-login", data)
+	}
+}
+
+// memCredentials is an in-memory util.CredentialProvider
+type memCredentials map[string]string
+
+func (m memCredentials) Get(account string) (string, error) {
+	secret, ok := m[account]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", account, util.ErrCredentialNotFound)
+	}
+	return secret, nil
+}
+
+func (m memCredentials) Set(account, secret string) error {
+	m[account] = secret
+	return nil
+}
+
+// TestCredentialStore verifies wallet passwords come from the configured
+// credential store instead of the "changeme" default
+func TestCredentialStore(t *testing.T) {
+	dir := t.TempDir()
+	store := memCredentials{util.WalletAccount(dir): "stored-wallet-pass"}
+	w := &WalletRPC{}
+	if err := w.configure(util.Config{WalletFile: dir, WalletPort: 18083, Credentials: store}); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if got := w.WalletPass(); got != "stored-wallet-pass" {
+		t.Errorf("WalletPass() = %q, want the stored password", got)
+	}
+	if pass := store[util.WalletRPCAccount(dir)]; pass == "" || pass != w.WalletRPCPass() {
+		t.Errorf("WalletRPCPass() = %q, want the generated password %q kept in the store", w.WalletRPCPass(), pass)
+	}
+
+	if err := w.SetWalletPass("new-pass"); err != nil {
+		t.Fatalf("SetWalletPass() error = %v", err)
+	}
+	if store[util.WalletAccount(dir)] != "new-pass" {
+		t.Errorf("SetWalletPass() did not update the store")
+	}
+
+	if got := (&WalletRPC{}).WalletPass(); got != "changeme" {
+		t.Errorf("WalletPass() without a store = %q, want changeme", got)
+	}
+}
+
+// TestCreateWallet verifies wallet creation parameters and name validation
+func TestCreateWallet(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	srv := mockWallet(t, map[string]string{"create_wallet": `{}`}, params)
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx := context.Background()
+	if err := w.CreateWallet(ctx, "shop", "secret", ""); err != nil {
+		t.Fatalf("CreateWallet() error = %v", err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(params["create_wallet"], &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"filename": "shop", "password": "secret", "language": DefaultWalletLanguage}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("create_wallet params = %v, want %v", got, want)
+	}
+
+	for _, name := range []string{"", "..", "../shop", "wallets/shop", `C:\shop`} {
+		if err := w.CreateWallet(ctx, name, "", ""); errors.GetKind(err) != errors.KindConfig {
+			t.Errorf("CreateWallet(%q) error = %v, want KindConfig", name, err)
+		}
+	}
+}
+
+// TestOpenCloseWallet verifies open wallet tracking, and that health
+// checks and saves accept having no wallet open
+func TestOpenCloseWallet(t *testing.T) {
+	var mu sync.Mutex
+	open := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params map[string]string `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		defer mu.Unlock()
+		notOpen := fmt.Sprintf(`{"id":%d,"error":{"code":-13,"message":"No wallet file"}}`, req.ID)
+		switch {
+		case req.Method == "open_wallet" && req.Params["password"] != "secret":
+			open = ""
+			fmt.Fprintf(rw, `{"id":%d,"error":{"code":-1,"message":"invalid password"}}`, req.ID)
+		case req.Method == "open_wallet":
+			open = req.Params["filename"]
+			fmt.Fprintf(rw, `{"id":%d,"result":{}}`, req.ID)
+		case (req.Method == "close_wallet" || req.Method == "get_height" || req.Method == "store") && open == "":
+			fmt.Fprint(rw, notOpen)
+		case req.Method == "close_wallet":
+			open = ""
+			fmt.Fprintf(rw, `{"id":%d,"result":{}}`, req.ID)
+		default:
+			fmt.Fprintf(rw, `{"id":%d,"result":{"version":65562,"height":10}}`, req.ID)
+		}
+	}))
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx := context.Background()
+	if err := w.checkHealth(ctx); err != nil {
+		t.Errorf("checkHealth() with no wallet open error = %v", err)
+	}
+	if err := w.OpenWallet(ctx, "shop", "secret"); err != nil {
+		t.Fatalf("OpenWallet() error = %v", err)
+	}
+	if got := w.CurrentWallet(); got != "shop" {
+		t.Errorf("CurrentWallet() = %q, want shop", got)
+	}
+	if err := w.checkHealth(ctx); err != nil {
+		t.Errorf("checkHealth() with a wallet open error = %v", err)
+	}
+
+	// Closed behind the manager's back: still healthy, no longer tracked
+	mu.Lock()
+	open = ""
+	mu.Unlock()
+	if err := w.checkHealth(ctx); err != nil {
+		t.Errorf("checkHealth() after an external close error = %v", err)
+	}
+	if got := w.CurrentWallet(); got != "" {
+		t.Errorf("CurrentWallet() after an external close = %q, want none", got)
+	}
+
+	if err := w.OpenWallet(ctx, "shop", "wrong"); errors.GetKind(err) != errors.KindWallet || w.CurrentWallet() != "" {
+		t.Errorf("OpenWallet() with a wrong password = %v, CurrentWallet() = %q", err, w.CurrentWallet())
+	}
+	if err := w.Client().RPC().Call(ctx, "get_height", nil, nil); errors.GetKind(err) != errors.KindWallet {
+		t.Errorf("get_height with no wallet open error = %v, want KindWallet", err)
+	}
+	if err := w.OpenWallet(ctx, "../shop", "secret"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("OpenWallet() with a path error = %v, want KindConfig", err)
+	}
+	if err := w.OpenWallet(ctx, "shop", "secret"); err != nil {
+		t.Fatalf("OpenWallet() error = %v", err)
+	}
+	if err := w.Store(ctx); err != nil {
+		t.Errorf("Store() error = %v", err)
+	}
+	if err := w.CloseWallet(ctx); err != nil || w.CurrentWallet() != "" {
+		t.Errorf("CloseWallet() = %v, CurrentWallet() = %q", err, w.CurrentWallet())
+	}
+	if err := w.CloseWallet(ctx); err != nil {
+		t.Errorf("CloseWallet() with no wallet open error = %v", err)
+	}
+	if err := w.Store(ctx); err != nil {
+		t.Errorf("Store() with no wallet open error = %v", err)
+	}
+	if err := w.Client().Store(ctx); errors.GetKind(err) != errors.KindWallet {
+		t.Errorf("Client.Store() with no wallet open error = %v, want KindWallet", err)
+	}
+}
+
+// TestHardwareWallet verifies device flags, creation from a device
+// through monero-wallet-cli, and that device failures get KindDevice
+func TestHardwareWallet(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	binDir := t.TempDir()
+	// The fake CLI creates the keys file unless told the device is gone
+	script := "#!/bin/sh\n" +
+		"if [ -e \"$MONEROGER_TEST_NO_DEVICE\" ]; then echo 'Error: No device found'; exit 1; fi\n" +
+		"touch \"$2.keys\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "monero-wallet-cli"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	noDevice := filepath.Join(t.TempDir(), "unplugged")
+	t.Setenv("MONEROGER_TEST_NO_DEVICE", noDevice)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64            `json:"id"`
+			Params map[string]string `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Params["filename"] == "unplugged" {
+			fmt.Fprintf(rw, `{"id":%d,"error":{"code":-1,"message":"Device not connected"}}`, req.ID)
+			return
+		}
+		fmt.Fprintf(rw, `{"id":%d,"result":{}}`, req.ID)
+	}))
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port, walletDir: t.TempDir()}
+	ctx := context.Background()
+	if err := w.CreateWalletFromDevice(ctx, "ledger", "secret", 3000000); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("CreateWalletFromDevice() without a device error = %v, want KindConfig", err)
+	}
+	w.hwDevice = util.HWDeviceConfig{Name: "Ledger"}
+	if err := w.CreateWalletFromDevice(ctx, "ledger", "secret", 3000000); err != nil {
+		t.Fatalf("CreateWalletFromDevice() error = %v", err)
+	}
+	if w.CurrentWallet() != "ledger" {
+		t.Errorf("CurrentWallet() = %q, want ledger", w.CurrentWallet())
+	}
+	if util.FileExists(filepath.Join(w.walletDir, ".ledger.device-options")) {
+		t.Error("CreateWalletFromDevice() left the password file behind")
+	}
+
+	if err := os.WriteFile(noDevice, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.CreateWalletFromDevice(ctx, "trezor", "secret", 0); errors.GetKind(err) != errors.KindDevice {
+		t.Errorf("CreateWalletFromDevice() without the device error = %v, want KindDevice", err)
+	}
+	if err := w.OpenWallet(ctx, "unplugged", "secret"); errors.GetKind(err) != errors.KindDevice {
+		t.Errorf("OpenWallet() with the device disconnected error = %v, want KindDevice", err)
+	}
+
+	args := util.HWDeviceConfig{Name: "Trezor", DerivationPath: "m/44'/128'/1'"}.WalletArgs()
+	if got := strings.Join(args, " "); got != "--hw-device Trezor --hw-device-deriv-path m/44'/128'/1'" {
+		t.Errorf("WalletArgs() = %q", got)
+	}
+}
+
+// TestBalanceAndAddress verifies the typed balance and address helpers
+func TestBalanceAndAddress(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	srv := mockWallet(t, map[string]string{
+		"get_balance": `{"balance":1500000000000,"unlocked_balance":250000000000,"blocks_to_unlock":7,"time_to_unlock":0}`,
+		"get_address": `{"address":"4primary","addresses":[{"address":"8sub","label":"customer","address_index":3,"used":true}]}`,
+	}, params)
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx := context.Background()
+	b, err := w.Balance(ctx)
+	if err != nil {
+		t.Fatalf("Balance() error = %v", err)
+	}
+	if b.Balance.String() != "1.500000000000" || b.UnlockedBalance.XMR() != 0.25 || b.BlocksToUnlock != 7 {
+		t.Errorf("Balance() = %+v", b)
+	}
+	if string(params["get_balance"]) != `{"all_accounts":true}` {
+		t.Errorf("get_balance params = %s", params["get_balance"])
+	}
+
+	a, err := w.Address(ctx, 1, 3)
+	if err != nil || a.Address != "8sub" || a.Label != "customer" || !a.Used {
+		t.Errorf("Address() = %+v, %v", a, err)
+	}
+	if string(params["get_address"]) != `{"account_index":1,"address_index":[3]}` {
+		t.Errorf("get_address params = %s", params["get_address"])
+	}
+	if _, err := w.Address(ctx, 1, 4); err == nil {
+		t.Errorf("Address() for a missing index succeeded")
+	}
+
+	if got := Piconero(1).String(); got != "0.000000000001" {
+		t.Errorf("Piconero(1).String() = %q", got)
+	}
+}
+
+// TestTransfer verifies transfer options reach wallet-rpc and invalid
+// requests are rejected locally
+func TestTransfer(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	srv := mockWallet(t, map[string]string{
+		"transfer": `{"amount":300,"fee":9,"tx_hash":"beef","tx_key":"k","tx_blob":"b","tx_metadata":"m"}`,
+	}, params)
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx := context.Background()
+	r, err := w.Transfer(ctx, TransferRequest{
+		Destinations:    []Destination{{Amount: 100, Address: "4a"}, {Amount: 200, Address: "4b"}},
+		Priority:        PriorityElevated,
+		AccountIndex:    2,
+		SubtractFeeFrom: []uint32{1},
+		DoNotRelay:      true,
+	})
+	if err != nil || r.TxHash != "beef" || r.TxKey != "k" || r.Fee != 9 || r.TxMetadata != "m" {
+		t.Fatalf("Transfer() = %+v, %v", r, err)
+	}
+	want := `{"destinations":[{"amount":100,"address":"4a"},{"amount":200,"address":"4b"}],` +
+		`"account_index":2,"subtract_fee_from_outputs":[1],"priority":3,"get_tx_key":true,` +
+		`"do_not_relay":true,"get_tx_hex":true,"get_tx_metadata":true}`
+	if string(params["transfer"]) != want {
+		t.Errorf("transfer params = %s, want %s", params["transfer"], want)
+	}
+
+	for name, req := range map[string]TransferRequest{
+		"no destinations":    {},
+		"no address":         {Destinations: []Destination{{Amount: 1}}},
+		"no amount":          {Destinations: []Destination{{Address: "4a"}}},
+		"unknown priority":   {Destinations: []Destination{{Amount: 1, Address: "4a"}}, Priority: 9},
+		"fee from undefined": {Destinations: []Destination{{Amount: 1, Address: "4a"}}, SubtractFeeFrom: []uint32{1}},
+	} {
+		if _, err := w.Transfer(ctx, req); errors.GetKind(err) != errors.KindConfig {
+			t.Errorf("Transfer(%s) error = %v, want KindConfig", name, err)
+		}
+	}
+}
+
+// TestEstimateTransfer verifies quotes build an unrelayed transaction
+// and price its weight at every priority
+func TestEstimateTransfer(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	// The same server answers as the wallet and as its remote node
+	srv := mockWallet(t, map[string]string{
+		"transfer":         `{"amount":300,"fee":30000000,"weight":1500}`,
+		"get_fee_estimate": `{"status":"OK","fee":20000,"fees":[20000,80000,320000,4000000],"quantization_mask":10000}`,
+	}, params)
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port, remoteNodes: []string{srv.URL}}
+	quote, err := w.EstimateTransfer(context.Background(), TransferRequest{
+		Destinations: []Destination{{Amount: 300, Address: "4a"}},
+	})
+	if err != nil {
+		t.Fatalf("EstimateTransfer() error = %v", err)
+	}
+	want := map[Priority]Piconero{
+		PriorityUnimportant: 30000000,
+		PriorityNormal:      120000000,
+		PriorityElevated:    480000000,
+		PriorityHighest:     6000000000,
+	}
+	if quote.Amount != 300 || quote.Fee != 30000000 || quote.Weight != 1500 || fmt.Sprint(quote.Fees) != fmt.Sprint(want) {
+		t.Errorf("EstimateTransfer() = %+v", quote)
+	}
+	if want := `{"destinations":[{"amount":300,"address":"4a"}],"do_not_relay":true}`; string(params["transfer"]) != want {
+		t.Errorf("transfer params = %s, want %s", params["transfer"], want)
+	}
+
+	if _, err := w.EstimateTransfer(context.Background(), TransferRequest{}); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("EstimateTransfer() without destinations error = %v, want KindConfig", err)
+	}
+}
+
+// TestOfflineSigning runs the offline signing loop between a view-only
+// and an offline wallet
+func TestOfflineSigning(t *testing.T) {
+	onlineParams := map[string]json.RawMessage{}
+	online := mockWallet(t, map[string]string{
+		"export_outputs":    `{"outputs_data_hex":"0a0b"}`,
+		"import_key_images": `{"height":3000,"spent":100,"unspent":900}`,
+		"transfer":          `{"amount":300,"fee":9,"unsigned_txset":"unsigned"}`,
+		"submit_transfer":   `{"tx_hash_list":["beef"]}`,
+	}, onlineParams)
+	defer online.Close()
+	offlineParams := map[string]json.RawMessage{}
+	offline := mockWallet(t, map[string]string{
+		"import_outputs":    `{"num_imported":2}`,
+		"export_key_images": `{"signed_key_images":[{"key_image":"ki","signature":"sig"}]}`,
+		"describe_transfer": `{"desc":[{"amount_out":300,"fee":9,"recipients":[{"address":"4a","amount":300}]}]}`,
+		"sign_transfer":     `{"signed_txset":"signed","tx_hash_list":["beef"]}`,
+	}, offlineParams)
+	defer offline.Close()
+
+	viewOnly := &WalletRPC{rpcPort: online.Listener.Addr().(*net.TCPAddr).Port}
+	signer := &WalletRPC{rpcPort: offline.Listener.Addr().(*net.TCPAddr).Port}
+	ctx := context.Background()
+
+	outputs, err := viewOnly.ExportOutputs(ctx, true)
+	if err != nil {
+		t.Fatalf("ExportOutputs() error = %v", err)
+	}
+	if n, err := signer.ImportOutputs(ctx, outputs); err != nil || n != 2 {
+		t.Fatalf("ImportOutputs() = %d, %v", n, err)
+	}
+	if got := string(offlineParams["import_outputs"]); got != `{"outputs_data_hex":"0a0b"}` {
+		t.Errorf("import_outputs params = %s", got)
+	}
+	images, err := signer.ExportKeyImages(ctx, true)
+	if err != nil {
+		t.Fatalf("ExportKeyImages() error = %v", err)
+	}
+	if r, err := viewOnly.ImportKeyImages(ctx, *images); err != nil || r.Unspent != 900 {
+		t.Fatalf("ImportKeyImages() = %+v, %v", r, err)
+	}
+
+	req := TransferRequest{Destinations: []Destination{{Amount: 300, Address: "4a"}}}
+	unsigned, err := viewOnly.PrepareOfflineTransfer(ctx, req)
+	if err != nil || unsigned != "unsigned" {
+		t.Fatalf("PrepareOfflineTransfer() = %q, %v", unsigned, err)
+	}
+	if got := string(onlineParams["transfer"]); !strings.Contains(got, `"do_not_relay":true`) {
+		t.Errorf("transfer params = %s, want do_not_relay", got)
+	}
+
+	refused := fmt.Errorf("refused")
+	if _, err := signer.SignOfflineTransfer(ctx, unsigned, func([]TransferDescription) error { return refused }); err != refused {
+		t.Errorf("SignOfflineTransfer() rejected error = %v", err)
+	}
+	if _, signed := offlineParams["sign_transfer"]; signed {
+		t.Error("SignOfflineTransfer() signed a rejected transfer")
+	}
+	var reviewed []TransferDescription
+	signed, err := signer.SignOfflineTransfer(ctx, unsigned, func(desc []TransferDescription) error {
+		reviewed = desc
+		return nil
+	})
+	if err != nil || signed.SignedTxset != "signed" || len(reviewed) != 1 || reviewed[0].Recipients[0].Address != "4a" {
+		t.Fatalf("SignOfflineTransfer() = %+v, %v, reviewed %+v", signed, err, reviewed)
+	}
+	if hashes, err := viewOnly.SubmitSignedTransfer(ctx, signed.SignedTxset); err != nil || len(hashes) != 1 {
+		t.Errorf("SubmitSignedTransfer() = %v, %v", hashes, err)
+	}
+
+	// A wallet holding the spend key signs itself and returns no set
+	full := mockWallet(t, map[string]string{"transfer": `{"amount":300,"fee":9,"tx_hash":"beef"}`}, nil)
+	defer full.Close()
+	w := &WalletRPC{rpcPort: full.Listener.Addr().(*net.TCPAddr).Port}
+	if _, err := w.PrepareOfflineTransfer(ctx, req); errors.GetKind(err) != errors.KindWallet {
+		t.Errorf("PrepareOfflineTransfer() on a full wallet error = %v, want KindWallet", err)
+	}
+	if _, err := viewOnly.ImportKeyImages(ctx, KeyImages{}); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("ImportKeyImages() without key images error = %v, want KindConfig", err)
+	}
+}
+
+// TestGetTransfers verifies filter mapping, typed decoding and the
+// client-side time range
+func TestGetTransfers(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	srv := mockWallet(t, map[string]string{
+		"get_transfers": `{"in":[` +
+			`{"txid":"old","amount":5,"fee":1,"height":100,"timestamp":1600000000,"type":"in"},` +
+			`{"txid":"new","amount":7,"fee":1,"height":200,"timestamp":1700000000,"type":"in"}],` +
+			`"pool":[{"txid":"mempool","amount":3,"timestamp":1700000500,"type":"pool"}]}`,
+	}, params)
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx := context.Background()
+	ts, err := w.GetTransfers(ctx, TransferFilter{
+		MinHeight:   150,
+		Since:       time.Unix(1650000000, 0),
+		AllAccounts: true,
+	})
+	if err != nil {
+		t.Fatalf("GetTransfers() error = %v", err)
+	}
+	if len(ts.In) != 1 || ts.In[0].TxID != "new" || ts.In[0].Amount != 7 || ts.In[0].Type != TransferIn {
+		t.Errorf("GetTransfers().In = %+v, want only the new transfer", ts.In)
+	}
+	if !ts.In[0].Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Timestamp = %v", ts.In[0].Timestamp)
+	}
+	if len(ts.Pool) != 1 || ts.Pool[0].Type != TransferPool {
+		t.Errorf("GetTransfers().Pool = %+v", ts.Pool)
+	}
+	want := `{"in":true,"out":true,"pending":true,"failed":true,"pool":true,` +
+		`"filter_by_height":true,"min_height":149,"max_height":500000000,"all_accounts":true}`
+	if string(params["get_transfers"]) != want {
+		t.Errorf("get_transfers params = %s, want %s", params["get_transfers"], want)
+	}
+
+	if _, err := w.GetTransfers(ctx, TransferFilter{MinHeight: 10, MaxHeight: 5}); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("GetTransfers() with an inverted range error = %v, want KindConfig", err)
+	}
+}
+
+// TestAccounts verifies the account and subaddress wrappers
+func TestAccounts(t *testing.T) {
+	params := map[string]json.RawMessage{}
+	srv := mockWallet(t, map[string]string{
+		"create_account": `{"account_index":1,"address":"8acct"}`,
+		"create_address": `{"address":"8cust","address_index":4}`,
+		"label_address":  `{}`,
+		"get_accounts":   `{"subaddress_accounts":[{"account_index":0,"base_address":"4base","balance":10,"unlocked_balance":5,"label":"Primary account"}],"total_balance":10,"total_unlocked_balance":5}`,
+	}, params)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", rpc.Options{})
+	ctx := context.Background()
+	acct, err := c.CreateAccount(ctx, "shop")
+	if err != nil || acct.AccountIndex != 1 || acct.Address != "8acct" {
+		t.Errorf("CreateAccount() = %+v, %v", acct, err)
+	}
+	addr, err := c.CreateAddress(ctx, 1, "customer 42")
+	if err != nil || addr.AddressIndex != 4 || addr.Address != "8cust" {
+		t.Errorf("CreateAddress() = %+v, %v", addr, err)
+	}
+	if string(params["create_address"]) != `{"account_index":1,"label":"customer 42"}` {
+		t.Errorf("create_address params = %s", params["create_address"])
+	}
+	if err := c.LabelAddress(ctx, SubaddrIndex{Major: 1, Minor: 4}, "paid"); err != nil {
+		t.Errorf("LabelAddress() error = %v", err)
+	}
+	if string(params["label_address"]) != `{"index":{"major":1,"minor":4},"label":"paid"}` {
+		t.Errorf("label_address params = %s", params["label_address"])
+	}
+	accts, err := c.GetAccounts(ctx, "")
+	if err != nil || len(accts.SubaddressAccounts) != 1 || accts.TotalUnlockedBalance != 5 {
+		t.Errorf("GetAccounts() = %+v, %v", accts, err)
+	}
+}
+
+// TestRefreshProgress verifies wallet and daemon heights are compared
+func TestRefreshProgress(t *testing.T) {
+	wallet := mockWallet(t, map[string]string{"get_height": `{"height":90}`}, nil)
+	defer wallet.Close()
+	node := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"status":"OK","height":100}`))
+	}))
+	defer node.Close()
+
+	w := &WalletRPC{
+		rpcPort:     wallet.Listener.Addr().(*net.TCPAddr).Port,
+		remoteNodes: []string{node.URL},
+	}
+	walletHeight, daemonHeight, err := w.RefreshProgress(context.Background())
+	if err != nil || walletHeight != 90 || daemonHeight != 100 {
+		t.Errorf("RefreshProgress() = %d, %d, %v; want 90, 100", walletHeight, daemonHeight, err)
+	}
+}
+
+// TestLifecycleState verifies state tracking around failed starts
+func TestLifecycleState(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	w := &WalletRPC{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	if got := w.State(); got != WalletStateUnknown {
+		t.Errorf("State() before Start = %s, want unknown", got)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Start(context.Background()); errors.GetKind(err) != errors.KindNetwork {
+				t.Errorf("Start() on a taken port error = %v, want KindNetwork", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := w.State(); got != WalletStateStopped {
+		t.Errorf("State() after failed Start = %s, want stopped", got)
+	}
+	if err := w.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() without a process error = %v", err)
+	}
+}
+
+// TestStopStale verifies leftover wallet-rpc processes are stopped on
+// ports other than those kept, and stale PID files removed
+func TestStopStale(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	// A monero-wallet-rpc linked to sleep runs the wallet executable
+	binDir := t.TempDir()
+	exe := filepath.Join(binDir, "monero-wallet-rpc")
+	if err := os.Symlink(sleep, exe); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cmd := exec.Command(exe, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	defer cmd.Process.Kill()
+
+	config := util.Config{DataDir: t.TempDir(), ShutdownTimeout: 5 * time.Second}
+	pidFile := func(port int) string {
+		return filepath.Join(config.DataDir, fmt.Sprintf(pidFileFormat, port))
+	}
+	// Port 1 runs a leftover wallet-rpc, port 2 a reused PID, port 3 is kept
+	for port, pid := range map[int]int{1: cmd.Process.Pid, 2: os.Getpid(), 3: cmd.Process.Pid} {
+		if err := util.WritePIDFile(pidFile(port), pid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := StopStale(ctx, config, 3); err != nil {
+		t.Fatalf("StopStale() error = %v", err)
+	}
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		t.Fatal("leftover wallet-rpc was not stopped")
+	}
+	for port, want := range map[int]bool{1: false, 2: false, 3: true} {
+		if got := util.FileExists(pidFile(port)); got != want {
+			t.Errorf("PID file for port %d exists = %v, want %v", port, got, want)
+		}
+	}
+}
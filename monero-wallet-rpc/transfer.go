@@ -0,0 +1,301 @@
+package monerowalletrpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/rpc"
+)
+
+// Operation names for errors from transfer methods
+const (
+	opTransfer     = errors.Op("WalletRPC.Transfer")
+	opEstimate     = errors.Op("WalletRPC.EstimateTransfer")
+	opGetTransfers = errors.Op("WalletRPC.GetTransfers")
+)
+
+// TransferRequest describes a payment from the open wallet.
+//
+// Fields:
+//   - Destinations: Recipients and amounts, at least one
+//   - Priority: Fee level, PriorityDefault for the wallet's default
+//   - AccountIndex: Account the funds are spent from
+//   - SubaddrIndices: Subaddresses of the account to spend from, nil for any
+//   - SubtractFeeFrom: Indices into Destinations whose amounts pay the
+//     fee, split evenly, instead of the sender paying it on top
+//   - DoNotRelay: Create and sign the transaction without broadcasting
+//     it; the result then carries the blob and metadata needed to relay
+//     it later
+type TransferRequest struct {
+	Destinations    []Destination
+	Priority        Priority
+	AccountIndex    uint32
+	SubaddrIndices  []uint32
+	SubtractFeeFrom []uint32
+	DoNotRelay      bool
+}
+
+// validate reports requests wallet-rpc would reject or misinterpret.
+func (r TransferRequest) validate() error {
+	if len(r.Destinations) == 0 {
+		return fmt.Errorf("transfer needs at least one destination")
+	}
+	for i, d := range r.Destinations {
+		if d.Address == "" {
+			return fmt.Errorf("destination %d has no address", i)
+		}
+		if d.Amount == 0 {
+			return fmt.Errorf("destination %d has no amount", i)
+		}
+	}
+	if r.Priority > PriorityHighest {
+		return fmt.Errorf("unknown priority %d", r.Priority)
+	}
+	for _, i := range r.SubtractFeeFrom {
+		if int(i) >= len(r.Destinations) {
+			return fmt.Errorf("subtract fee from destination %d, but there are only %d", i, len(r.Destinations))
+		}
+	}
+	return nil
+}
+
+// Transfer sends funds from the open wallet. The transaction key is
+// always returned, so payments can be proven to recipients.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - req: Destinations and transaction options
+//
+// Returns:
+//   - *TransferResult: Transaction hash, key, amount and fee, plus the
+//     blob and metadata when DoNotRelay is set
+//   - error: KindConfig for invalid requests, otherwise RPC failures such
+//     as insufficient unlocked funds
+//
+// Related:
+//   - Client.Transfer for the raw transfer parameters
+func (w *WalletRPC) Transfer(ctx context.Context, req TransferRequest) (*TransferResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, errors.E(opTransfer, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	params := TransferParams{
+		Destinations:           req.Destinations,
+		AccountIndex:           req.AccountIndex,
+		SubaddrIndices:         req.SubaddrIndices,
+		SubtractFeeFromOutputs: req.SubtractFeeFrom,
+		Priority:               req.Priority,
+		GetTxKey:               true,
+		DoNotRelay:             req.DoNotRelay,
+		GetTxHex:               req.DoNotRelay,
+		GetTxMetadata:          req.DoNotRelay,
+	}
+	r, err := w.Client().Transfer(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	w.log().Info("transfer created", "tx_hash", r.TxHash, "amount", r.Amount, "fee", r.Fee,
+		"relayed", !req.DoNotRelay)
+	return r, nil
+}
+
+// TransferQuote is the expected cost of a transfer, computed without
+// sending it.
+//
+// Fields:
+//   - Amount: Total sent to the destinations
+//   - Fee: Fee of the transaction as the wallet built it, at the
+//     requested priority
+//   - Weight: Transaction weight in bytes
+//   - Fees: Expected fee at each priority from PriorityUnimportant to
+//     PriorityHighest, from the daemon's current fee rates and Weight
+type TransferQuote struct {
+	Amount Piconero
+	Fee    Piconero
+	Weight uint64
+	Fees   map[Priority]Piconero
+}
+
+// EstimateTransfer reports what Transfer would cost, so the fee can be
+// shown or compared across priorities before sending. The wallet builds
+// the transaction without relaying it, which selects the same inputs
+// Transfer would and spends nothing.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - req: The transfer to quote; DoNotRelay is implied
+//
+// Returns:
+//   - *TransferQuote: Amount, fee and weight, plus the fee at every
+//     priority
+//   - error: KindConfig for invalid requests, otherwise wallet or daemon
+//     RPC failures such as insufficient unlocked funds
+//
+// Related:
+//   - monerod.Client.EstimateFee for fee rates without a wallet
+func (w *WalletRPC) EstimateTransfer(ctx context.Context, req TransferRequest) (*TransferQuote, error) {
+	if err := req.validate(); err != nil {
+		return nil, errors.E(opEstimate, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	r, err := w.Client().Transfer(ctx, TransferParams{
+		Destinations:           req.Destinations,
+		AccountIndex:           req.AccountIndex,
+		SubaddrIndices:         req.SubaddrIndices,
+		SubtractFeeFromOutputs: req.SubtractFeeFrom,
+		Priority:               req.Priority,
+		DoNotRelay:             true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Fee rates come from the daemon the wallet uses
+	var daemon *monerod.Client
+	if node := w.RemoteNode(); node != "" {
+		addr, err := remoteDaemonAddress(node)
+		if err != nil {
+			return nil, errors.E(opEstimate, errors.ComponentWalletRPC, errors.KindConfig, err)
+		}
+		daemon = monerod.NewClient(addr, "", "", rpc.Options{Component: errors.ComponentWalletRPC})
+		defer daemon.RPC().Close()
+	} else if w.daemon != nil {
+		daemon = w.daemon.Client()
+	} else {
+		return nil, errors.E(opEstimate, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("wallet has no daemon"))
+	}
+	estimate, err := daemon.GetFeeEstimate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quote := &TransferQuote{
+		Amount: r.Amount,
+		Fee:    r.Fee,
+		Weight: r.Weight,
+		Fees:   make(map[Priority]Piconero),
+	}
+	for p := PriorityUnimportant; p <= PriorityHighest; p++ {
+		fee, err := estimate.ForPriority(uint32(p))
+		if err != nil {
+			return nil, errors.E(opEstimate, errors.ComponentWalletRPC, errors.KindRPC, err)
+		}
+		quote.Fees[p] = Piconero(fee.Total(r.Weight))
+	}
+	return quote, nil
+}
+
+// maxBlockNumber is the highest height wallet-rpc accepts
+// (CRYPTONOTE_MAX_BLOCK_NUMBER), used as an open upper bound.
+const maxBlockNumber = 500000000
+
+// TransferFilter selects transfers from the wallet history.
+//
+// Fields:
+//   - In, Out, Pending, Failed, Pool: Categories to include; all of them
+//     when none is set
+//   - MinHeight, MaxHeight: Inclusive block height range, 0 for no bound;
+//     pending, failed and pool transfers have no height and are not
+//     affected
+//   - Since, Until: Inclusive time range, zero for no bound
+//   - AccountIndex: Account to query
+//   - SubaddrIndices: Subaddresses of the account, nil for all
+//   - AllAccounts: Query every account instead of AccountIndex
+type TransferFilter struct {
+	In             bool
+	Out            bool
+	Pending        bool
+	Failed         bool
+	Pool           bool
+	MinHeight      uint64
+	MaxHeight      uint64
+	Since          time.Time
+	Until          time.Time
+	AccountIndex   uint32
+	SubaddrIndices []uint32
+	AllAccounts    bool
+}
+
+// params converts the filter into get_transfers parameters. wallet-rpc
+// has no time filter, so Since and Until are applied by matches.
+func (f TransferFilter) params() GetTransfersParams {
+	p := GetTransfersParams{
+		In:             f.In,
+		Out:            f.Out,
+		Pending:        f.Pending,
+		Failed:         f.Failed,
+		Pool:           f.Pool,
+		AccountIndex:   f.AccountIndex,
+		SubaddrIndices: f.SubaddrIndices,
+		AllAccounts:    f.AllAccounts,
+	}
+	if !p.In && !p.Out && !p.Pending && !p.Failed && !p.Pool {
+		p.In, p.Out, p.Pending, p.Failed, p.Pool = true, true, true, true, true
+	}
+	if f.MinHeight > 0 || f.MaxHeight > 0 {
+		p.FilterByHeight = true
+		// wallet-rpc excludes min_height itself
+		if f.MinHeight > 0 {
+			p.MinHeight = f.MinHeight - 1
+		}
+		p.MaxHeight = f.MaxHeight
+		if p.MaxHeight == 0 {
+			p.MaxHeight = maxBlockNumber
+		}
+	}
+	return p
+}
+
+// matches reports whether t falls in the filter's time range.
+func (f TransferFilter) matches(t Transfer) bool {
+	if !f.Since.IsZero() && t.Timestamp.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || !t.Timestamp.After(f.Until)
+}
+
+// filter keeps the transfers in the filter's time range.
+func (f TransferFilter) filter(transfers []Transfer) []Transfer {
+	var kept []Transfer
+	for _, t := range transfers {
+		if f.matches(t) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// GetTransfers returns the transfer history of the open wallet.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - filter: Categories, height and time ranges, and accounts to include
+//
+// Returns:
+//   - *Transfers: Matching transfers grouped by category
+//   - error: KindConfig for inverted ranges, otherwise RPC failures
+//
+// Related:
+//   - Client.GetTransfers for the raw get_transfers parameters
+func (w *WalletRPC) GetTransfers(ctx context.Context, filter TransferFilter) (*Transfers, error) {
+	if filter.MaxHeight > 0 && filter.MinHeight > filter.MaxHeight {
+		return nil, errors.E(opGetTransfers, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("min height %d is above max height %d", filter.MinHeight, filter.MaxHeight))
+	}
+	if !filter.Until.IsZero() && filter.Since.After(filter.Until) {
+		return nil, errors.E(opGetTransfers, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("time range starts after it ends"))
+	}
+	t, err := w.Client().GetTransfers(ctx, filter.params())
+	if err != nil {
+		return nil, err
+	}
+	return &Transfers{
+		In:      filter.filter(t.In),
+		Out:     filter.filter(t.Out),
+		Pending: filter.filter(t.Pending),
+		Failed:  filter.filter(t.Failed),
+		Pool:    filter.filter(t.Pool),
+	}, nil
+}
@@ -2,9 +2,19 @@
 package monerowalletrpc
 
 import (
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"os"
 	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	moneroconst "github.com/opd-ai/moneroger/const"
+	"github.com/opd-ai/moneroger/errors"
 	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/rpc"
 	"github.com/opd-ai/moneroger/util"
 )
 
@@ -13,68 +23,148 @@ import (
 //
 // Fields:
 //   - cmd: Command instance for process management
-//   - walletFile: Path to the wallet file (.keys)
+//   - walletDir: Directory holding the wallet files (--wallet-dir)
+//   - walletName: Wallet opened once the process is ready, empty for none
 //   - rpcPort: Port number for RPC interface
 //   - rpcUser: Username for RPC authentication
 //   - rpcPass: Password for RPC authentication
+//   - rpcHost: Address the RPC server binds to, loopback when empty
+//   - autoPort: Whether a free port replaces an RPC port that is taken
+//   - portRange: Ports autoPort chooses from, any free port when empty
 //   - daemon: Reference to associated monerod instance
-//   - process: Reference to the running wallet RPC process
+//   - network: Monero network, which must match the daemon's
+//   - remoteNodes: Remote daemons in failover order, empty for the local daemon
+//   - nodeIndex: Index of the remote node currently in use
+//   - tor: Tor proxy settings for the daemon connection
+//   - i2p: I2P proxy used for .i2p remote nodes when Tor is disabled
+//   - tls: RPC server certificate, https when set
+//   - clientTLS: TLS settings for the wallet's own RPC client
+//   - walletPass: Password of the wallet files
+//   - credentials: Store holding the passwords, nil without one
+//   - openWallet: Name of the wallet file currently open, empty for none
+//   - openMu: Serializes open and close calls and guards openWallet
+//   - process: The running wallet RPC process, spawned or left running
+//     by an earlier manager
+//   - dataDir: Directory holding the PID file and, when detached, the
+//     process log; empty for neither
+//   - detach: Whether the process is spawned detached, logging to a file
+//   - driver: Runs the process, on the host or in a container
+//   - extraArgs: Flags appended to the generated command line
+//   - hwDevice: Hardware wallet holding the wallet keys, if any
+//   - startWait, stopWait: Bounds on becoming ready and on exiting, the
+//     moneroconst defaults when 0
+//   - stdout, stderr: Bounded capture of recent process output
+//   - logs: Combined output of both streams, kept across restarts
+//   - output: Optional sink receiving the full process output
+//   - logOutput: Whether process output is also logged at debug level
+//   - health: Coalescing, short-lived cache of health check results
+//   - client: Lazily created JSON-RPC client for the wallet service
+//   - logger: Structured logger tagged with the component name
+//   - exit: Tracks termination of the running process
+//   - state: Lifecycle state, see State
+//   - mu: Guards cmd, process, exit and state
+//   - startMu: Serializes Start and Reconfigure
 //
 // The WalletRPC instance maintains connection settings and process state,
 // coordinating with the Monero daemon for blockchain access.
 type WalletRPC struct {
-	cmd        *exec.Cmd
-	walletDir  string
-	rpcPort    int
-	rpcUser    string
-	rpcPass    string
-	rpcHost    string
-	remoteNode string
-	walletPass string
-	daemon     *monerod.MoneroDaemon
+	cmd         *exec.Cmd
+	process     *os.Process
+	dataDir     string
+	detach      bool
+	driver      util.Driver
+	extraArgs   []string
+	hwDevice    util.HWDeviceConfig
+	startWait   time.Duration
+	stopWait    time.Duration
+	walletDir   string
+	walletName  string
+	rpcPort     int
+	rpcUser     string
+	rpcPass     string
+	rpcHost     string
+	autoPort    bool
+	portRange   util.PortRange
+	remoteNodes []string
+	nodeIndex   atomic.Int32
+	tor         util.TorConfig
+	i2p         util.I2PConfig
+	tls         util.RPCTLS
+	clientTLS   *tls.Config
+	walletPass  string
+	credentials util.CredentialProvider
+	openWallet  string
+	openMu      sync.Mutex
+	daemon      *monerod.MoneroDaemon
+	network     util.Network
+	stdout      *util.RingBuffer
+	stderr      *util.RingBuffer
+	logs        *util.RingBuffer
+	output      io.Writer
+	logOutput   bool
+	health      *util.CachedCheck
+	healthOnce  sync.Once
+	client      *rpc.Client
+	clientOnce  sync.Once
+	logger      *slog.Logger
+	exit        *util.ProcessExit
+	state       WalletState
+	mu          sync.Mutex
+	startMu     sync.Mutex
+}
+
+// log returns the wallet's logger, falling back to slog.Default() for
+// instances not created through NewWalletRPC.
+func (w *WalletRPC) log() *slog.Logger {
+	if w.logger == nil {
+		return slog.Default()
+	}
+	return w.logger
+}
+
+// processDriver returns the wallet's driver, falling back to
+// util.LocalDriver for instances not created through NewWalletRPC.
+func (w *WalletRPC) processDriver() util.Driver {
+	if w.driver == nil {
+		return util.LocalDriver{}
+	}
+	return w.driver
+}
+
+// startupTimeout returns how long the wallet may take to become ready.
+func (w *WalletRPC) startupTimeout() time.Duration {
+	if w.startWait <= 0 {
+		return moneroconst.DefaultStartupTimeout
+	}
+	return w.startWait
+}
+
+// shutdownTimeout returns how long the wallet may take to exit once
+// interrupted.
+func (w *WalletRPC) shutdownTimeout() time.Duration {
+	if w.stopWait <= 0 {
+		return moneroconst.DefaultShutdownTimeout
+	}
+	return w.stopWait
 }
 
 // WalletState represents the current operational state of the wallet RPC service.
-// It provides a type-safe enumeration of possible wallet states.
-type WalletState uint8
+// It is the lifecycle state shared with the daemon; see util.ServiceState
+// for the allowed transitions.
+type WalletState = util.ServiceState
 
 // Wallet state constants define the possible states of a wallet RPC service.
 const (
-	WalletStateUnknown  WalletState = iota // Initial or unknown state
-	WalletStateStarting                    // Service is starting up
-	WalletStateRunning                     // Service is operational
-	WalletStateStopping                    // Service is shutting down
-	WalletStateStopped                     // Service has stopped
+	WalletStateUnknown  = util.StateUnknown  // Initial or unknown state
+	WalletStateStarting = util.StateStarting // Service is starting up
+	WalletStateRunning  = util.StateRunning  // Service is operational
+	WalletStateStopping = util.StateStopping // Service is shutting down
+	WalletStateStopped  = util.StateStopped  // Service has stopped
+	WalletStateCrashed  = util.StateCrashed  // Service exited unexpectedly
 )
 
-// String returns a human-readable representation of the wallet state.
-// This implements the Stringer interface for WalletState.
-//
-// Returns:
-//   - string: A lowercase string description of the current state
-//
-// States:
-//   - "starting": Wallet is initializing
-//   - "running": Wallet is operational
-//   - "stopping": Wallet is shutting down
-//   - "stopped": Wallet has terminated
-//   - "unknown": State cannot be determined
-func (s WalletState) String() string {
-	switch s {
-	case WalletStateStarting:
-		return "starting"
-	case WalletStateRunning:
-		return "running"
-	case WalletStateStopping:
-		return "stopping"
-	case WalletStateStopped:
-		return "stopped"
-	default:
-		return "unknown"
-	}
-}
-
-// WalletRPCPort returns the configured RPC port for the wallet service.
+// WalletRPCPort returns the RPC port of the wallet service: the configured
+// port, or the free port chosen when Config.AutoPort found it taken.
 //
 // Returns:
 //   - int: The RPC port number
@@ -115,14 +205,36 @@ func (m *WalletRPC) WalletRPCPass() string {
 	return m.rpcPass
 }
 
+// WalletPass returns the password of the wallet files. With a credential
+// store configured it is the one kept there, generated on first use;
+// otherwise it is "changeme" unless set with SetWalletPass.
+//
+// Returns:
+//   - string: The wallet password
+//
+// Related:
+//   - util.Config.CredentialStore
 func (m *WalletRPC) WalletPass() string {
 	if m.walletPass == "" {
 		m.walletPass = "changeme"
 	}
-	return m.rpcPass
+	return m.walletPass
 }
 
+// SetWalletPass sets the password of the wallet files, saving it to the
+// credential store when one is configured.
+//
+// Parameters:
+//   - pass: New wallet password
+//
+// Returns:
+//   - error: KindSystem if the credential store cannot be written
 func (m *WalletRPC) SetWalletPass(pass string) error {
+	if m.credentials != nil {
+		if err := m.credentials.Set(util.WalletAccount(m.walletDir), pass); err != nil {
+			return errors.E(opSetWalletPass, errors.ComponentWalletRPC, errors.KindSystem, err)
+		}
+	}
 	m.walletPass = pass
 	return nil
 }
@@ -2,24 +2,26 @@ package monerowalletrpc
 
 import (
 	"fmt"
-	"net/url"
+	"strings"
+
+	"github.com/opd-ai/moneroger/util"
 )
 
 // validateRemoteDaemon ensures that the remote daemon URL is correctly formed
-// and outputs the components of the URL.
+// and outputs the components of the URL. A bare "host:port" is accepted
+// and treated as http.
 func validateRemoteDaemon(uri string) (scheme, host, port string, err error) {
-	newUri, err := url.Parse(uri)
+	return util.ParseRemoteNode(uri)
+}
+
+// remoteDaemonAddress validates uri and formats it for --daemon-address.
+func remoteDaemonAddress(uri string) (string, error) {
+	scheme, host, port, err := validateRemoteDaemon(uri)
 	if err != nil {
-		return
-	}
-	host = newUri.Host
-	port = newUri.Port()
-	if port == "" {
-		port = "18081"
+		return "", err
 	}
-	if len(newUri.Path) > 1 {
-		err = fmt.Errorf("Remote node URLs may not contain a path: %s", newUri.Path)
-		return
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
 	}
-	return
+	return fmt.Sprintf("%s://%s:%s", scheme, host, port), nil
 }
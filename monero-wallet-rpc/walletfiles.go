@@ -0,0 +1,278 @@
+package monerowalletrpc
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// Operation names for errors from wallet file management
+const (
+	opCreateWallet = errors.Op("WalletRPC.CreateWallet")
+	opOpenWallet   = errors.Op("WalletRPC.OpenWallet")
+)
+
+// ErrCodeNotOpen is the wallet-rpc error code for methods that need an
+// open wallet while none is open (WALLET_RPC_ERROR_CODE_NOT_OPEN).
+const ErrCodeNotOpen = -13
+
+// ErrCodeDaemonBusy is the wallet-rpc error code for calls refused while
+// the daemon is busy syncing (WALLET_RPC_ERROR_CODE_DAEMON_IS_BUSY).
+const ErrCodeDaemonBusy = -3
+
+// errorKind classifies wallet-rpc error responses for rpc.Options:
+// hardware wallet failures by their message, the rest by code.
+func errorKind(e *rpc.Error) errors.Kind {
+	if isDeviceError(e.Message) {
+		return errors.KindDevice
+	}
+	switch e.Code {
+	case ErrCodeNotOpen:
+		return errors.KindWallet
+	case ErrCodeDaemonBusy:
+		return errors.KindSync
+	default:
+		return errors.KindRPC
+	}
+}
+
+// walletError marks a server-side failure of a wallet file operation,
+// such as a wrong password or a missing file, as KindWallet. Transport,
+// authentication and hardware wallet errors keep their kind.
+func walletError(op errors.Op, err error) error {
+	var rpcErr *rpc.Error
+	if err == nil || !stderrors.As(err, &rpcErr) || errors.GetKind(err) == errors.KindDevice {
+		return err
+	}
+	return errors.E(op, errors.ComponentWalletRPC, errors.KindWallet, err)
+}
+
+// DefaultWalletLanguage is the mnemonic seed language used when
+// CreateWallet is given none.
+const DefaultWalletLanguage = "English"
+
+// CreateWallet creates a wallet file with the create_wallet RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - filename: Wallet file name, created in the wallet directory
+//   - password: Password protecting the wallet file, may be empty
+//   - language: Mnemonic seed language, such as "English"
+//
+// Returns:
+//   - error: KindWallet when wallet-rpc refuses, e.g. because the file
+//     already exists, otherwise RPC failures
+func (c *Client) CreateWallet(ctx context.Context, filename, password, language string) error {
+	params := map[string]string{
+		"filename": filename,
+		"password": password,
+		"language": language,
+	}
+	return walletError(opCreateWallet, c.rpc.Call(ctx, "create_wallet", params, nil))
+}
+
+// CreateWallet creates a new wallet in the wallet directory and leaves it
+// open, so embedders can provision wallets without monero-wallet-cli.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - name: Wallet file name, without directory
+//   - password: Password protecting the wallet file; pass WalletPass()
+//     to use the managed wallet password
+//   - language: Mnemonic seed language, DefaultWalletLanguage when empty
+//
+// Returns:
+//   - error: KindConfig for names that are not plain file names,
+//     KindWallet when wallet-rpc refuses, e.g. when the wallet already
+//     exists, otherwise RPC failures
+//
+// Related:
+//   - Client.CreateWallet
+func (w *WalletRPC) CreateWallet(ctx context.Context, name, password, language string) error {
+	if err := util.ValidateWalletName(name); err != nil {
+		return errors.E(opCreateWallet, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	if language == "" {
+		language = DefaultWalletLanguage
+	}
+	w.openMu.Lock()
+	defer w.openMu.Unlock()
+	if err := w.Client().CreateWallet(ctx, name, password, language); err != nil {
+		return err
+	}
+	w.openWallet = name
+	w.log().Info("wallet created", "wallet", name)
+	return nil
+}
+
+// OpenWallet opens a wallet file with the open_wallet RPC method. The
+// wallet open before, if any, is saved and closed.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - filename: Wallet file name in the wallet directory
+//   - password: Password of the wallet file
+//
+// Returns:
+//   - error: KindWallet when wallet-rpc refuses, e.g. for a wrong password
+//     or missing file, otherwise RPC failures
+func (c *Client) OpenWallet(ctx context.Context, filename, password string) error {
+	params := map[string]string{
+		"filename": filename,
+		"password": password,
+	}
+	return walletError(opOpenWallet, c.rpc.Call(ctx, "open_wallet", params, nil))
+}
+
+// CloseWallet saves and closes the open wallet with the close_wallet RPC
+// method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - error: RPC failures; an *rpc.Error with code ErrCodeNotOpen when
+//     no wallet is open
+func (c *Client) CloseWallet(ctx context.Context) error {
+	params := map[string]bool{"autosave_current": true}
+	return c.rpc.Call(ctx, "close_wallet", params, nil)
+}
+
+// Store saves the open wallet to its file with the store RPC method.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - error: RPC failures; an *rpc.Error with code ErrCodeNotOpen when
+//     no wallet is open
+func (c *Client) Store(ctx context.Context) error {
+	return c.rpc.Call(ctx, "store", nil, nil)
+}
+
+// OpenWallet opens a wallet from the wallet directory, saving and closing
+// the one open before. The wallet stays open until CloseWallet, another
+// OpenWallet or CreateWallet, or a restart of the process.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - name: Wallet file name, without directory
+//   - password: Password of the wallet file; pass WalletPass() for the
+//     managed wallet password
+//
+// Returns:
+//   - error: KindConfig for names that are not plain file names,
+//     KindWallet when wallet-rpc refuses, such as for a wrong password,
+//     otherwise RPC failures
+//
+// Related:
+//   - CurrentWallet
+func (w *WalletRPC) OpenWallet(ctx context.Context, name, password string) error {
+	if err := util.ValidateWalletName(name); err != nil {
+		return errors.E(opOpenWallet, errors.ComponentWalletRPC, errors.KindConfig, err)
+	}
+	w.openMu.Lock()
+	defer w.openMu.Unlock()
+	if err := w.Client().OpenWallet(ctx, name, password); err != nil {
+		// open_wallet closes the previous wallet before opening the new one
+		w.openWallet = ""
+		return err
+	}
+	w.openWallet = name
+	w.log().Info("wallet opened", "wallet", name)
+	return nil
+}
+
+// CloseWallet saves and closes the open wallet, leaving the process
+// running with no wallet open. Closing when no wallet is open succeeds.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - error: RPC failures
+func (w *WalletRPC) CloseWallet(ctx context.Context) error {
+	w.openMu.Lock()
+	defer w.openMu.Unlock()
+	if err := w.Client().CloseWallet(ctx); err != nil && !isNotOpen(err) {
+		return err
+	}
+	if w.openWallet != "" {
+		w.log().Info("wallet closed", "wallet", w.openWallet)
+	}
+	w.openWallet = ""
+	return nil
+}
+
+// Store saves the open wallet to its file, so the blocks scanned and
+// transfers seen since the last save survive a crash of the process.
+// Saving when no wallet is open succeeds.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - error: RPC failures
+//
+// Related:
+//   - util.Config.AutosaveInterval for periodic saves by the manager
+func (w *WalletRPC) Store(ctx context.Context) error {
+	w.openMu.Lock()
+	defer w.openMu.Unlock()
+	if err := w.Client().Store(ctx); err != nil && !isNotOpen(err) {
+		return err
+	}
+	return nil
+}
+
+// CurrentWallet returns the name of the wallet file open in the process,
+// or "" when none is, as tracked by CreateWallet, OpenWallet and
+// CloseWallet. Wallets opened through Client directly are not tracked.
+func (w *WalletRPC) CurrentWallet() string {
+	w.openMu.Lock()
+	defer w.openMu.Unlock()
+	return w.openWallet
+}
+
+// setOpenWallet records name as the open wallet.
+func (w *WalletRPC) setOpenWallet(name string) {
+	w.openMu.Lock()
+	w.openWallet = name
+	w.openMu.Unlock()
+}
+
+// checkOpenWallet verifies the wallet recorded as open still answers.
+// No wallet being open is a valid state: when wallet-rpc reports none,
+// because it was closed behind the manager's back, the record is cleared
+// instead of failing the health check.
+func (w *WalletRPC) checkOpenWallet(ctx context.Context) error {
+	name := w.CurrentWallet()
+	if name == "" {
+		return nil
+	}
+	err := w.rpcClient().Call(ctx, "get_height", nil, nil)
+	if isNotOpen(err) {
+		w.log().Warn("wallet is no longer open", "wallet", name)
+		w.openMu.Lock()
+		if w.openWallet == name {
+			w.openWallet = ""
+		}
+		w.openMu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return errors.E(opCheckHealth, errors.ComponentWalletRPC, errors.KindNetwork,
+			fmt.Errorf("open wallet %s is not responding: %w", name, err))
+	}
+	return nil
+}
+
+// isNotOpen reports whether err is wallet-rpc's "no wallet open" error.
+func isNotOpen(err error) bool {
+	var rpcErr *rpc.Error
+	return stderrors.As(err, &rpcErr) && rpcErr.Code == ErrCodeNotOpen
+}
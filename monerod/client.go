@@ -0,0 +1,348 @@
+package monerod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/rpc"
+)
+
+// Operation constants for daemon RPC errors
+const (
+	opGetInfo                = errors.Op("Client.GetInfo")
+	opGetHeight              = errors.Op("Client.GetHeight")
+	opGetBlockHeaderByHeight = errors.Op("Client.GetBlockHeaderByHeight")
+	opGetVersion             = errors.Op("Client.GetVersion")
+	opSyncInfo               = errors.Op("Client.SyncInfo")
+	opSetLogLevel            = errors.Op("Client.SetLogLevel")
+	opSetPeerLimits          = errors.Op("Client.SetPeerLimits")
+)
+
+// Client is a typed JSON-RPC client for monerod.
+// It is safe for concurrent use and reuses connections between calls.
+//
+// Fields:
+//   - rpc: Underlying JSON-RPC client handling transport and authentication
+type Client struct {
+	rpc *rpc.Client
+}
+
+// NewClient creates a daemon client for an arbitrary monerod endpoint.
+//
+// Parameters:
+//   - endpoint: Base URL, e.g. "http://127.0.0.1:18081"
+//   - user: RPC username, empty if the daemon does not require login
+//   - pass: RPC password
+//   - opts: HTTP tuning options
+//
+// Returns:
+//   - *Client: Ready-to-use client
+//
+// Related:
+//   - MoneroDaemon.Client for a client bound to a managed daemon
+func NewClient(endpoint, user, pass string, opts rpc.Options) *Client {
+	if opts.Component == "" {
+		opts.Component = errors.ComponentMonerod
+	}
+	return &Client{rpc: rpc.NewClient(endpoint, user, pass, opts)}
+}
+
+// Client returns a typed RPC client for this daemon, authenticated with
+// the daemon's RPC credentials.
+//
+// Returns:
+//   - *Client: Client sharing connections with the manager's own probes
+func (m *MoneroDaemon) Client() *Client {
+	return &Client{rpc: m.rpcClient()}
+}
+
+// RPC returns the underlying JSON-RPC client, for methods without a typed
+// wrapper.
+func (c *Client) RPC() *rpc.Client {
+	return c.rpc
+}
+
+// Info is the response of the get_info RPC method.
+type Info struct {
+	Status                   string `json:"status"`
+	Height                   uint64 `json:"height"`
+	TargetHeight             uint64 `json:"target_height"`
+	Difficulty               uint64 `json:"difficulty"`
+	TxCount                  uint64 `json:"tx_count"`
+	TxPoolSize               uint64 `json:"tx_pool_size"`
+	AltBlocksCount           uint64 `json:"alt_blocks_count"`
+	OutgoingConnectionsCount uint64 `json:"outgoing_connections_count"`
+	IncomingConnectionsCount uint64 `json:"incoming_connections_count"`
+	WhitePeerlistSize        uint64 `json:"white_peerlist_size"`
+	GreyPeerlistSize         uint64 `json:"grey_peerlist_size"`
+	Mainnet                  bool   `json:"mainnet"`
+	Testnet                  bool   `json:"testnet"`
+	Stagenet                 bool   `json:"stagenet"`
+	Nettype                  string `json:"nettype"`
+	TopBlockHash             string `json:"top_block_hash"`
+	DatabaseSize             uint64 `json:"database_size"`
+	FreeSpace                uint64 `json:"free_space"`
+	Offline                  bool   `json:"offline"`
+	Synchronized             bool   `json:"synchronized"`
+	BusySyncing              bool   `json:"busy_syncing"`
+	Restricted               bool   `json:"restricted"`
+	Untrusted                bool   `json:"untrusted"`
+	Version                  string `json:"version"`
+	StartTime                uint64 `json:"start_time"`
+	BootstrapDaemonAddress   string `json:"bootstrap_daemon_address"`
+	WasBootstrapEverUsed     bool   `json:"was_bootstrap_ever_used"`
+}
+
+// LocalChainReady reports whether the answer came from a synchronized
+// local chain. While a bootstrap daemon is in use monerod relays its
+// get_info answer, which reports the bootstrap node as synchronized and
+// is marked untrusted.
+func (i *Info) LocalChainReady() bool {
+	return i.Synchronized && !i.Untrusted
+}
+
+// BlockHeader describes a block as returned by get_block_header_by_height.
+type BlockHeader struct {
+	Hash         string `json:"hash"`
+	Height       uint64 `json:"height"`
+	Timestamp    uint64 `json:"timestamp"`
+	PrevHash     string `json:"prev_hash"`
+	Nonce        uint64 `json:"nonce"`
+	Depth        uint64 `json:"depth"`
+	Difficulty   uint64 `json:"difficulty"`
+	Reward       uint64 `json:"reward"`
+	BlockSize    uint64 `json:"block_size"`
+	BlockWeight  uint64 `json:"block_weight"`
+	NumTxes      uint64 `json:"num_txes"`
+	OrphanStatus bool   `json:"orphan_status"`
+	MajorVersion uint8  `json:"major_version"`
+	MinorVersion uint8  `json:"minor_version"`
+}
+
+// Version is the response of the get_version RPC method.
+//
+// Fields:
+//   - Version: Packed RPC version, major in the upper 16 bits
+//   - Release: Whether the daemon is a tagged release build
+type Version struct {
+	Status  string `json:"status"`
+	Version uint32 `json:"version"`
+	Release bool   `json:"release"`
+}
+
+// Major returns the major RPC version.
+func (v Version) Major() uint32 {
+	return v.Version >> 16
+}
+
+// Minor returns the minor RPC version.
+func (v Version) Minor() uint32 {
+	return v.Version & 0xffff
+}
+
+// Peer is a connected peer as reported by sync_info.
+type Peer struct {
+	Address   string `json:"address"`
+	Height    uint64 `json:"height"`
+	Incoming  bool   `json:"incoming"`
+	State     string `json:"state"`
+	LiveTime  uint64 `json:"live_time"`
+	RecvCount uint64 `json:"recv_count"`
+	SendCount uint64 `json:"send_count"`
+}
+
+// Span is a block span being downloaded, as reported by sync_info.
+type Span struct {
+	StartBlockHeight uint64 `json:"start_block_height"`
+	NBlocks          uint64 `json:"nblocks"`
+	ConnectionID     string `json:"connection_id"`
+	Rate             uint64 `json:"rate"`
+	Speed            uint64 `json:"speed"`
+	Size             uint64 `json:"size"`
+}
+
+// SyncInfo is the response of the sync_info RPC method.
+type SyncInfo struct {
+	Status       string `json:"status"`
+	Height       uint64 `json:"height"`
+	TargetHeight uint64 `json:"target_height"`
+	Peers        []struct {
+		Info Peer `json:"info"`
+	} `json:"peers"`
+	Spans []Span `json:"spans"`
+}
+
+// GetInfo returns general information about the node and the network.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *Info: Node state including height, sync status and connection counts
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetInfo(ctx context.Context) (*Info, error) {
+	var info Info
+	if err := c.rpc.Call(ctx, "get_info", nil, &info); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetInfo, info.Status); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetHeight returns the current blockchain height of the node.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - uint64: Number of blocks in the local chain
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetHeight(ctx context.Context) (uint64, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Height uint64 `json:"height"`
+	}
+	if err := c.rpc.CallPath(ctx, "/get_height", nil, &resp); err != nil {
+		return 0, err
+	}
+	if err := checkStatus(opGetHeight, resp.Status); err != nil {
+		return 0, err
+	}
+	return resp.Height, nil
+}
+
+// GetBlockHeaderByHeight returns the header of the block at height.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - height: Block height to look up
+//
+// Returns:
+//   - *BlockHeader: The block header
+//   - error: RPC failures, unknown heights or a non-OK status
+func (c *Client) GetBlockHeaderByHeight(ctx context.Context, height uint64) (*BlockHeader, error) {
+	var resp struct {
+		Status      string      `json:"status"`
+		BlockHeader BlockHeader `json:"block_header"`
+	}
+	params := map[string]uint64{"height": height}
+	if err := c.rpc.Call(ctx, "get_block_header_by_height", params, &resp); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetBlockHeaderByHeight, resp.Status); err != nil {
+		return nil, err
+	}
+	return &resp.BlockHeader, nil
+}
+
+// GetVersion returns the daemon's RPC version.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *Version: RPC version information
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetVersion(ctx context.Context) (*Version, error) {
+	var v Version
+	if err := c.rpc.Call(ctx, "get_version", nil, &v); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetVersion, v.Status); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// SyncInfo returns synchronization progress, connected peers and the
+// block spans currently being downloaded.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *SyncInfo: Synchronization state
+//   - error: RPC failures or a non-OK status
+func (c *Client) SyncInfo(ctx context.Context) (*SyncInfo, error) {
+	var si SyncInfo
+	if err := c.rpc.Call(ctx, "sync_info", nil, &si); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opSyncInfo, si.Status); err != nil {
+		return nil, err
+	}
+	return &si, nil
+}
+
+// SetLogLevel changes the daemon's log verbosity without a restart.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - level: monerod log level, 0 (least) to 4 (most verbose)
+//
+// Returns:
+//   - error: RPC failures or a non-OK status
+func (c *Client) SetLogLevel(ctx context.Context, level int) error {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := c.rpc.CallPath(ctx, "/set_log_level", map[string]int{"level": level}, &resp); err != nil {
+		return err
+	}
+	return checkStatus(opSetLogLevel, resp.Status)
+}
+
+// SetPeerLimits changes the daemon's outgoing and incoming peer limits
+// without a restart.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - out: Maximum outgoing peers, negative to leave unchanged
+//   - in: Maximum incoming peers, negative to leave unchanged
+//
+// Returns:
+//   - error: RPC failures or a non-OK status
+func (c *Client) SetPeerLimits(ctx context.Context, out, in int) error {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if out >= 0 {
+		if err := c.rpc.CallPath(ctx, "/out_peers", map[string]int{"out_peers": out}, &resp); err != nil {
+			return err
+		}
+		if err := checkStatus(opSetPeerLimits, resp.Status); err != nil {
+			return err
+		}
+	}
+	if in >= 0 {
+		if err := c.rpc.CallPath(ctx, "/in_peers", map[string]int{"in_peers": in}, &resp); err != nil {
+			return err
+		}
+		if err := checkStatus(opSetPeerLimits, resp.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatus converts a non-OK monerod status string into an error:
+// KindSync for "BUSY", which monerod answers while syncing, otherwise
+// KindRPC.
+func checkStatus(op errors.Op, status string) error {
+	if status == "OK" {
+		return nil
+	}
+	kind := errors.KindRPC
+	if status == "BUSY" {
+		kind = errors.KindSync
+	}
+	return errors.E(
+		op,
+		errors.ComponentMonerod,
+		kind,
+		fmt.Errorf("daemon returned status %q", status),
+	)
+}
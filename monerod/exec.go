@@ -4,7 +4,6 @@ package monerod
 
 import (
 	"fmt"
-	"path/filepath"
 
 	"github.com/opd-ai/moneroger/util"
 )
@@ -35,14 +34,11 @@ import (
 //
 // Related:
 //   - util.Path() for search path generation
-//   - util.FileExists() for file checking
+//   - util.FindExecutable() for extension-aware lookup
 func MoneroDPath() (string, error) {
-	paths := util.Path()
-	for _, path := range paths {
-		monerodPath := filepath.Join(path, "monerod")
-		if util.FileExists(monerodPath) {
-			return monerodPath, nil
-		}
+	monerodPath, err := util.FindExecutable(util.Path(), "monerod")
+	if err != nil {
+		return "", fmt.Errorf("Monero daemon(monerod) not found")
 	}
-	return "", fmt.Errorf("Monero daemon(monerod) not found")
+	return monerodPath, nil
 }
@@ -0,0 +1,129 @@
+package monerod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// Operation names for fee estimation errors
+const (
+	opGetFeeEstimate = errors.Op("Client.GetFeeEstimate")
+	opEstimateFee    = errors.Op("Client.EstimateFee")
+)
+
+// maxFeePriority is the highest transaction priority wallets use
+const maxFeePriority = 4
+
+// FeeEstimate is the response of the get_fee_estimate RPC method.
+//
+// Fields:
+//   - Fee: Base fee per byte of transaction weight, in piconero
+//   - Fees: Fee per byte for priorities 1 to 4, from unimportant to
+//     highest; empty on daemons older than v0.18
+//   - QuantizationMask: Fees are rounded up to a multiple of this
+type FeeEstimate struct {
+	Status           string   `json:"status"`
+	Fee              uint64   `json:"fee"`
+	Fees             []uint64 `json:"fees"`
+	QuantizationMask uint64   `json:"quantization_mask"`
+}
+
+// Fee is the fee rate for one transaction priority.
+//
+// Fields:
+//   - PerByte: Fee per byte of transaction weight, in piconero
+//   - QuantizationMask: Fees are rounded up to a multiple of this, 1 or
+//     0 for no rounding
+type Fee struct {
+	PerByte          uint64
+	QuantizationMask uint64
+}
+
+// Total returns the fee of a transaction of the given weight, rounded up
+// the way wallets round it.
+//
+// Parameters:
+//   - weight: Transaction weight in bytes, as reported by transfer
+//
+// Returns:
+//   - uint64: Fee in piconero
+func (f Fee) Total(weight uint64) uint64 {
+	fee := weight * f.PerByte
+	if f.QuantizationMask > 1 {
+		fee = (fee + f.QuantizationMask - 1) / f.QuantizationMask * f.QuantizationMask
+	}
+	return fee
+}
+
+// ForPriority returns the fee rate of a transaction priority.
+//
+// Parameters:
+//   - priority: 1 (unimportant) to 4 (highest), 0 for the normal
+//     priority 2 wallets use by default
+//
+// Returns:
+//   - Fee: The rate; daemons without per-priority fees answer the base
+//     fee for every priority
+//   - error: For priorities above 4
+func (e *FeeEstimate) ForPriority(priority uint32) (Fee, error) {
+	if priority > maxFeePriority {
+		return Fee{}, fmt.Errorf("unknown priority %d", priority)
+	}
+	if priority == 0 {
+		priority = 2
+	}
+	fee := Fee{PerByte: e.Fee, QuantizationMask: e.QuantizationMask}
+	if int(priority) <= len(e.Fees) {
+		fee.PerByte = e.Fees[priority-1]
+	}
+	return fee, nil
+}
+
+// GetFeeEstimate returns the daemon's current fee rates.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *FeeEstimate: Fee per byte for each priority
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetFeeEstimate(ctx context.Context) (*FeeEstimate, error) {
+	var e FeeEstimate
+	if err := c.rpc.Call(ctx, "get_fee_estimate", nil, &e); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetFeeEstimate, e.Status); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// EstimateFee returns the current fee rate of a transaction priority.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - priority: 1 (unimportant) to 4 (highest), 0 for normal
+//
+// Returns:
+//   - Fee: Rate whose Total gives the fee of a transaction weight
+//   - error: KindConfig for unknown priorities, otherwise RPC failures or
+//     a non-OK status
+//
+// Example:
+//
+//	fee, err := client.EstimateFee(ctx, 2)
+//	// a typical two-output transaction weighs about 1500 bytes
+//	fmt.Println(fee.Total(1500))
+func (c *Client) EstimateFee(ctx context.Context, priority uint32) (Fee, error) {
+	if priority > maxFeePriority {
+		return Fee{}, errors.E(opEstimateFee, errors.ComponentMonerod, errors.KindConfig,
+			fmt.Errorf("unknown priority %d", priority))
+	}
+	e, err := c.GetFeeEstimate(ctx)
+	if err != nil {
+		return Fee{}, err
+	}
+	return e.ForPriority(priority)
+}
@@ -4,13 +4,26 @@ package monerod
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"time"
 
+	moneroconst "github.com/opd-ai/moneroger/const"
 	"github.com/opd-ai/moneroger/errors"
 	"github.com/opd-ai/moneroger/util"
 )
 
+// Operation names for lifecycle errors
+const (
+	opAttach      = errors.Op("Attach")
+	opReconfigure = errors.Op("Reconfigure")
+	opState       = errors.Op("State")
+	opCheckHealth = errors.Op("CheckHealth")
+)
+
 // NewMoneroDaemon creates or connects to a Monero daemon instance.
 //
 // Parameters:
@@ -18,41 +31,51 @@ import (
 //   - config: Configuration settings for the daemon including:
 //   - DataDir: Directory for blockchain and wallet data
 //   - MoneroPort: RPC port number
-//   - TestNet: Boolean flag for testnet operation
+//   - Network, TestNet: Network selection
 //
 // Returns:
 //   - *MoneroDaemon: Pointer to the daemon instance
 //   - error: Any error encountered during startup
 //
 // The function will:
-// 1. Check if a daemon is already running on the specified port
-// 2. If running, return a connection to the existing daemon
-// 3. If not running, start a new daemon process
+// 1. Adopt a daemon left running by an earlier manager, see the PID file
+// 2. Check if a daemon is already running on the specified port
+// 3. If running and it answers get_version, return a connection to it
+// 4. If not running, start a new daemon process
 //
 // Errors:
 //   - Process spawn failures
-//   - Port binding issues
+//   - KindNetwork if the port is taken by a program that is not monerod,
+//     unless Config.AutoPort selects a free port instead
 //   - Context cancellation
 //
 // Related:
 //   - util.Config for configuration options
-//   - util.IsPortInUse for port checking
+//   - util.IsPortAvailable for port checking
 func NewMoneroDaemon(ctx context.Context, config util.Config) (*MoneroDaemon, error) {
-	// Check if daemon is already running
-	if util.IsPortInUse(config.MoneroPort) {
-		return &MoneroDaemon{
-			rpcPort:       config.MoneroPort,
-			dataDir:       config.DataDir,
-			testnet:       config.TestNet,
-			useRemoteNode: (config.RemoteNode != ""),
-		}, nil
+	daemon := &MoneroDaemon{
+		logs: util.NewRingBuffer(config.OutputBufferSize()),
+	}
+	if err := daemon.configure(config); err != nil {
+		return nil, err
 	}
 
-	daemon := &MoneroDaemon{
-		dataDir:       config.DataDir,
-		rpcPort:       config.MoneroPort,
-		testnet:       config.TestNet,
-		useRemoteNode: (config.RemoteNode != ""),
+	// A remote node replaces the local daemon entirely
+	if daemon.useRemoteNode {
+		daemon.log().Info("using remote node, monerod will not be started", "nodes", config.RemoteNodeList())
+	} else if orphan, err := daemon.adoptOrphan(ctx, true); err != nil {
+		return nil, err
+	} else if !orphan && !util.IsPortAvailable(daemon.bindIP, daemon.RPCPort()) {
+		// A busy daemon may miss one probe; the client retries it before
+		// the port is treated as taken by another program
+		probeErr := daemon.probeMonerod(ctx)
+		if probeErr == nil {
+			// Adopt the daemon that is already running
+			daemon.log().Info("adopting daemon already listening", "port", config.EffectiveMoneroPort())
+			daemon.adopted = true
+		} else if err := daemon.portTaken(probeErr); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := daemon.Start(ctx); err != nil {
@@ -67,6 +90,175 @@ func NewMoneroDaemon(ctx context.Context, config util.Config) (*MoneroDaemon, er
 	return daemon, nil
 }
 
+// configure validates config and applies it to the daemon's settings.
+// Process state, captured output and the adopted flag are left alone.
+func (m *MoneroDaemon) configure(config util.Config) error {
+	if err := config.Tor.Validate(); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	if err := config.I2P.Validate(); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	if err := config.DaemonTLS.Validate(); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	if err := config.Bootstrap.Validate(); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	if err := util.ValidateBindIP(config.MoneroBindIP); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	if err := config.ValidateMonerodExtraArgs(); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	if err := OptionsFromConfig(config).Validate(); err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	clientTLS, err := config.DaemonTLS.ClientConfig()
+	if err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+	}
+	var banList []string
+	if config.BanList != "" {
+		if banList, err = util.ReadBanList(config.BanList); err != nil {
+			return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindConfig, err)
+		}
+	}
+
+	m.dataDir = config.NetworkDataDir()
+	m.rpcPort = config.EffectiveMoneroPort()
+	m.network = config.NetType()
+	m.useRemoteNode = len(config.RemoteNodeList()) > 0
+	m.zmqPubPort = config.ZMQPubPort
+	m.restrictedPort = config.EffectiveRestrictedPort()
+	m.publicArgs = config.PublicNodeArgs()
+	m.options = OptionsFromConfig(config)
+	m.startWait = config.EffectiveStartupTimeout()
+	m.stopWait = config.EffectiveShutdownTimeout()
+	m.tor = config.Tor
+	m.bootstrap = config.Bootstrap
+	m.i2p = config.I2P
+	m.bindIP = config.MoneroBindIP
+	m.autoPort = config.AutoPort
+	m.portRange = config.AutoPortRange
+	m.detach = config.Detach && config.DataDir != ""
+	m.driver = config.Driver()
+	m.extraArgs = config.MonerodExtraArgs
+	m.banList = banList
+	m.tls = config.DaemonTLS
+	m.clientTLS = clientTLS
+	m.rpcUser = config.MoneroRPCUser
+	m.rpcPass = config.MoneroRPCPass
+	m.output = config.DaemonOutput
+	m.logOutput = config.LogProcessOutput
+	m.logger = config.Log().With("component", errors.ComponentMonerod)
+	if m.rpcPass == "" && !m.useRemoteNode {
+		if store := config.CredentialStore(); store != nil {
+			pass, err := util.ResolveCredential(store, util.DaemonRPCAccount(m.dataDir))
+			if err != nil {
+				return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindSystem, err)
+			}
+			m.rpcPass = pass
+		} else {
+			m.restoreLogin()
+		}
+	}
+
+	// Endpoint and credentials may have changed
+	m.resetClient()
+	return nil
+}
+
+// Reconfigure applies a new configuration to a stopped daemon; the next
+// Start uses it. Moneroger.Reload uses this to restart monerod with
+// changed settings while keeping the instance, and its captured output,
+// in place.
+//
+// Parameters:
+//   - config: New configuration
+//
+// Returns:
+//   - error: KindProcess if the daemon is adopted or still running,
+//     KindConfig for invalid settings
+func (m *MoneroDaemon) Reconfigure(config util.Config) error {
+	m.startMu.Lock()
+	defer m.startMu.Unlock()
+	m.mu.Lock()
+	adopted, running := m.adopted, m.process != nil && !m.exit.Exited()
+	m.mu.Unlock()
+	if adopted {
+		return errors.E(opReconfigure, errors.ComponentMonerod, errors.KindProcess,
+			fmt.Errorf("an adopted daemon was not started by this manager and cannot be reconfigured"))
+	}
+	if running {
+		return errors.E(opReconfigure, errors.ComponentMonerod, errors.KindProcess,
+			fmt.Errorf("monerod must be stopped before it is reconfigured"))
+	}
+	return m.configure(config)
+}
+
+// Tune changes the log level and peer limits of the running daemon over
+// RPC, without a restart, and keeps them for later restarts. Only values
+// that differ from the current settings are sent.
+//
+// Parameters:
+//   - ctx: Context for the RPC calls
+//   - logLevel: monerod log level, 0 to 4
+//   - outPeers: Outgoing peer limit, 0 for monerod's default
+//   - inPeers: Incoming peer limit, 0 for monerod's default
+//
+// Returns:
+//   - error: RPC failures, once transient ones have been retried
+//
+// monerod has no RPC restoring the unlimited default for incoming peers,
+// so resetting inPeers to 0 takes effect only after a restart. Remote
+// node setups have no daemon to tune and return nil.
+func (m *MoneroDaemon) Tune(ctx context.Context, logLevel, outPeers, inPeers int) error {
+	if m.useRemoteNode {
+		return nil
+	}
+	// A restart may read the options concurrently, so they are only
+	// accessed under m.mu, never held across the RPCs
+	m.mu.Lock()
+	current := m.options
+	m.mu.Unlock()
+
+	client := m.Client()
+	policy := util.DefaultRetryPolicy()
+	if logLevel != current.LogLevel {
+		err := util.Retry(ctx, policy, func(ctx context.Context) error {
+			return client.SetLogLevel(ctx, logLevel)
+		})
+		if err != nil {
+			return err
+		}
+		m.mu.Lock()
+		m.options.LogLevel = logLevel
+		m.mu.Unlock()
+	}
+	out, in := -1, -1
+	if outPeers != current.OutPeers {
+		out = outPeers
+		if out == 0 {
+			out = defaultOutPeers
+		}
+	}
+	if inPeers != current.InPeers && inPeers > 0 {
+		in = inPeers
+	}
+	err := util.Retry(ctx, policy, func(ctx context.Context) error {
+		return client.SetPeerLimits(ctx, out, in)
+	})
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.options.OutPeers = outPeers
+	m.options.InPeers = inPeers
+	m.mu.Unlock()
+	return nil
+}
+
 // Start launches the monerod process with appropriate configuration.
 // This is an internal method used by NewMoneroDaemon.
 //
@@ -77,28 +269,106 @@ func NewMoneroDaemon(ctx context.Context, config util.Config) (*MoneroDaemon, er
 //   - error: Any error encountered during startup
 //
 // The method will:
-// 1. Configure daemon arguments
-// 2. Launch the monerod process
-// 3. Wait for RPC port availability
+// 1. Return immediately if the daemon is already running
+// 2. Configure daemon arguments
+// 3. Launch the monerod process
+// 4. Wait for RPC port availability
+//
+// When a healthy, synchronized daemon already answers on the RPC port it
+// is adopted directly, skipping process spawn and port polling so warm
+// starts go straight to wallet startup.
+//
+// Start is safe for concurrent use: calls are serialized, and a call
+// finding the daemon running returns nil. Starting a daemon that is
+// still stopping fails with KindProcess. A daemon that does not become
+// ready is shut down again.
 //
 // Related:
 //   - MoneroDPath for executable location
-//   - util.WaitForPort for startup confirmation
+//   - waitReady for startup confirmation
+//   - State for the lifecycle state
 func (m *MoneroDaemon) Start(ctx context.Context) error {
-	if m.useRemoteNode {
+	m.startMu.Lock()
+	defer m.startMu.Unlock()
+
+	m.mu.Lock()
+	if m.state == util.StateRunning {
+		m.mu.Unlock()
 		return nil
 	}
-	args := []string{
-		"--data-dir", m.dataDir,
-		"--rpc-bind-port", fmt.Sprintf("%d", m.RPCPort()),
-		"--rpc-login", fmt.Sprintf("%s:%s", m.RPCUser(), m.RPCPass()),
-		"--non-interactive",
+	err := m.setState(util.StateStarting)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	err = m.start(ctx)
+	if err == nil {
+		m.applyBanList(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		if m.state == util.StateStarting {
+			_ = m.setState(util.StateStopped)
+		}
+		return err
+	}
+	if m.state != util.StateStarting {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindProcess,
+			fmt.Errorf("monerod %s during startup", m.state))
 	}
+	return m.setState(util.StateRunning)
+}
 
-	if m.testnet {
-		args = append(args, "--testnet")
+// start adopts or spawns the daemon for Start.
+func (m *MoneroDaemon) start(ctx context.Context) error {
+	m.mu.Lock()
+	adopted, running := m.adopted, m.process != nil && !m.exit.Exited()
+	m.mu.Unlock()
+	if m.useRemoteNode || adopted || running {
+		return nil
+	}
+	if orphan, err := m.adoptOrphan(ctx, true); err != nil || orphan {
+		return err
+	}
+	if !util.IsPortAvailable(m.bindIP, m.RPCPort()) {
+		probeCtx, cancel := context.WithTimeout(ctx, adoptProbeTimeout)
+		synced := m.isSynced(probeCtx)
+		cancel()
+		if synced {
+			m.log().Info("adopting synchronized daemon", "port", m.RPCPort())
+			m.mu.Lock()
+			m.adopted = true
+			m.mu.Unlock()
+			return nil
+		}
+		// A daemon still syncing is adopted as NewMoneroDaemon does, since
+		// a second one could not bind the port
+		probeErr := m.probeMonerod(ctx)
+		if probeErr == nil {
+			m.log().Info("adopting daemon already listening", "port", m.RPCPort())
+			m.mu.Lock()
+			m.adopted = true
+			m.mu.Unlock()
+			return nil
+		}
+		if err := m.portTaken(probeErr); err != nil {
+			return err
+		}
+	}
+	if err := m.i2p.CheckProxy(ctx); err != nil {
+		return errors.E(
+			errors.OpProcessSpawn,
+			errors.ComponentMonerod,
+			errors.KindNetwork,
+			err,
+		)
 	}
-	moneroD, err := MoneroDPath()
+	if err := m.removeStaleLock(); err != nil {
+		return err
+	}
+	moneroD, err := m.processDriver().Executable(MoneroDPath)
 	if err != nil {
 		return errors.E(
 			errors.OpProcessSpawn,
@@ -107,7 +377,50 @@ func (m *MoneroDaemon) Start(ctx context.Context) error {
 			err,
 		)
 	}
-	cmd := exec.CommandContext(ctx, moneroD, args...)
+	m.log().Info("starting monerod", "path", moneroD, "port", m.RPCPort(), "network", m.network)
+	cmdCtx := ctx
+	if m.detach {
+		// A detached daemon outlives the context that started it
+		cmdCtx = context.WithoutCancel(ctx)
+	}
+	cmd, err := m.command(cmdCtx, moneroD)
+	if err != nil {
+		return errors.E(
+			errors.OpProcessSpawn,
+			errors.ComponentMonerod,
+			errors.KindSystem,
+			err,
+		)
+	}
+
+	// Capture the tail of stdout/stderr for error reports, optionally
+	// streaming everything to the configured output
+	if m.logs == nil {
+		m.logs = util.NewRingBuffer(moneroconst.DefaultOutputBufferSize)
+	}
+	stdout := util.NewRingBuffer(moneroconst.DefaultOutputBufferSize)
+	stderr := util.NewRingBuffer(moneroconst.DefaultOutputBufferSize)
+	m.stdout, m.stderr = stdout, stderr
+	if m.detach {
+		// The output must not depend on pipes read by this process
+		logFile, err := util.OpenProcessLog(filepath.Join(m.dataDir, logFileName))
+		if err != nil {
+			return errors.E(
+				errors.OpProcessSpawn,
+				errors.ComponentMonerod,
+				errors.KindSystem,
+				err,
+			)
+		}
+		defer logFile.Close()
+		cmd.Stdout, cmd.Stderr = logFile, logFile
+		util.PrepareDetachedCommand(cmd)
+	} else {
+		cmd.Stdout = m.outputWriter(stdout, "stdout")
+		cmd.Stderr = m.outputWriter(stderr, "stderr")
+		util.PrepareCommand(cmd)
+	}
+
 	if err := cmd.Start(); err != nil {
 		return errors.E(
 			errors.OpProcessSpawn,
@@ -117,22 +430,110 @@ func (m *MoneroDaemon) Start(ctx context.Context) error {
 		)
 	}
 
+	m.mu.Lock()
 	m.cmd = cmd
-	m.cmd.Process = cmd.Process
+	m.mu.Unlock()
+	m.track(cmd.Process, util.WatchProcess(cmd))
+	if pidFile := m.pidFile(); pidFile != "" {
+		if err := util.WritePIDFile(pidFile, cmd.Process.Pid); err != nil {
+			m.log().Warn("could not write PID file, a restarted manager cannot adopt the daemon", "path", pidFile, "error", err)
+		}
+	}
 
 	// Wait for RPC to become available
-	if err := util.WaitForPort(ctx, m.RPCPort()); err != nil {
+	if err := m.waitReady(ctx); err != nil {
+		_ = m.Shutdown(ctx)
 		return errors.E(
 			errors.OpPortBinding,
 			errors.ComponentMonerod,
 			errors.KindNetwork,
-			err,
+			fmt.Errorf("%w\nOutput: %s\nError: %s", err, stdout.String(), stderr.String()),
 		)
 	}
 
 	return nil
 }
 
+// command builds the monerod command line. Secrets are written to a
+// --config-file readable only by the owner instead of appearing in the
+// arguments, where any local user could read them with ps.
+//
+// Parameters:
+//   - ctx: Context killing the process when done
+//   - path: Executable returned by the driver
+//
+// Returns:
+//   - *exec.Cmd: The unstarted command
+//   - error: If the options file cannot be written
+//
+// Passing --config-file means monerod no longer reads bitmonero.conf
+// from the data directory. The driver decides how the command runs
+// monerod, so the arguments only name paths it mounts.
+func (m *MoneroDaemon) command(ctx context.Context, path string) (*exec.Cmd, error) {
+	options := map[string]string{
+		"rpc-login": fmt.Sprintf("%s:%s", m.RPCUser(), m.RPCPass()),
+	}
+	for key, value := range m.bootstrap.DaemonOptions() {
+		options[key] = value
+	}
+	optionsPath := filepath.Join(m.dataDir, optionsFile)
+	if err := util.WriteOptionsFile(optionsPath, options); err != nil {
+		return nil, fmt.Errorf("failed to write monerod options: %w", err)
+	}
+	// Tune may change the options while the daemon restarts
+	m.mu.Lock()
+	tuning := m.options
+	m.mu.Unlock()
+	var logDir string
+	if tuning.LogFile != "" {
+		// The log file's directory must exist before monerod opens it
+		logDir = filepath.Dir(tuning.LogFile)
+		if err := os.MkdirAll(logDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create monerod log directory: %w", err)
+		}
+	}
+
+	args := []string{
+		"--data-dir", m.dataDir,
+		"--config-file", optionsPath,
+		"--rpc-bind-port", fmt.Sprintf("%d", m.RPCPort()),
+		"--non-interactive",
+	}
+	if flag := m.network.Flag(); flag != "" {
+		args = append(args, flag)
+	}
+	if m.zmqPubPort > 0 {
+		args = append(args, "--zmq-pub", m.zmqPubEndpoint())
+	}
+	ports := []int{m.RPCPort()}
+	if m.restrictedPort > 0 {
+		args = append(args, "--rpc-restricted-bind-port", fmt.Sprintf("%d", m.restrictedPort))
+		ports = append(ports, m.restrictedPort)
+	}
+	if m.tor.Enabled() {
+		// The Tor flags already include --no-igd
+		tuning.NoIGD = false
+	}
+	args = append(args, tuning.Args()...)
+	driver := m.processDriver()
+	args = append(args, util.BindArgs(driver.BindIP(m.bindIP))...)
+	args = append(args, m.tls.ServerArgs()...)
+	args = append(args, m.tor.DaemonArgs()...)
+	args = append(args, m.bootstrap.DaemonArgs(m.tor.Proxy)...)
+	args = append(args, m.i2p.DaemonArgs()...)
+	args = append(args, m.publicArgs...)
+	args = append(args, m.extraArgs...)
+	return driver.Command(ctx, util.ProcessSpec{
+		Path:      path,
+		Program:   "monerod",
+		Name:      "monerod",
+		Args:      args,
+		Mounts:    []string{m.dataDir, logDir, m.tls.CertFile, m.tls.KeyFile, m.tls.CAFile},
+		Ports:     ports,
+		PublishIP: m.bindIP,
+	})
+}
+
 // Shutdown gracefully stops the Monero daemon.
 //
 // Parameters:
@@ -141,27 +542,196 @@ func (m *MoneroDaemon) Start(ctx context.Context) error {
 // Returns:
 //   - error: Any error encountered during shutdown
 //
-// The method sends an interrupt signal (SIGINT) to the daemon process,
+// The method asks the daemon process to exit (SIGINT, or a console break
+// event on Windows; see util.InterruptProcess),
 // allowing it to clean up and shut down gracefully. If the process
-// isn't running, the method returns nil.
+// isn't running, or the daemon was adopted rather than spawned, the
+// method returns nil; a daemon left running by an earlier manager and
+// adopted through its PID file is stopped like a spawned one. Concurrent
+// and repeated calls are safe; only the first one signals the process.
 //
 // Errors:
 //   - Signal delivery failures
 //   - Context cancellation
 func (m *MoneroDaemon) Shutdown(ctx context.Context) error {
-	if m.cmd.Process != nil {
-		if err := m.cmd.Process.Signal(os.Interrupt); err != nil {
-			return fmt.Errorf("failed to send interrupt to monerod: %w", err)
-		}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.process == nil {
+		return nil
+	}
+	if m.state != util.StateStarting && m.state != util.StateRunning {
+		return nil
+	}
+	if err := m.setState(util.StateStopping); err != nil {
+		return err
+	}
+	m.log().Info("stopping monerod", "pid", m.process.Pid)
+	if err := util.InterruptProcess(m.process); err != nil {
+		return fmt.Errorf("failed to send interrupt to monerod: %w", err)
 	}
 	return nil
 }
 
+// outputWriter combines a capture buffer with the optional output sink
+// and debug logging for one output stream.
+func (m *MoneroDaemon) outputWriter(buf *util.RingBuffer, stream string) io.Writer {
+	writers := []io.Writer{buf, util.NewPrefixWriter(m.logs, "")}
+	if m.output != nil {
+		writers = append(writers, util.NewPrefixWriter(m.output, "["+string(errors.ComponentMonerod)+"] "))
+	}
+	if m.logOutput {
+		writers = append(writers, util.NewLogWriter(m.log(), slog.LevelDebug, stream))
+	}
+	return io.MultiWriter(writers...)
+}
+
+// SetOutput configures a writer that receives the stdout and stderr
+// stream of the monerod process while it runs, each line prefixed with
+// "[monerod] ". It must be called before Start and has no effect on
+// adopted daemons.
+//
+// Parameters:
+//   - out: Destination for process output, or nil to disable streaming
+func (m *MoneroDaemon) SetOutput(out io.Writer) {
+	m.output = out
+}
+
+// RecentLogs returns the most recent combined stdout and stderr output
+// of the process, including output from before the last restart, up to
+// the configured LogBufferSize.
+//
+// Returns:
+//   - string: Retained output lines, oldest first, empty if never started
+func (m *MoneroDaemon) RecentLogs() string {
+	if m.logs == nil {
+		return ""
+	}
+	return m.logs.String()
+}
+
+// RecentOutput returns the most recent stdout and stderr output of the
+// monerod process, bounded by moneroconst.DefaultOutputBufferSize each.
+//
+// Returns:
+//   - stdout: Retained standard output, empty if never spawned
+//   - stderr: Retained standard error, empty if never spawned
+func (m *MoneroDaemon) RecentOutput() (stdout, stderr string) {
+	if m.stdout != nil {
+		stdout = m.stdout.String()
+	}
+	if m.stderr != nil {
+		stderr = m.stderr.String()
+	}
+	return
+}
+
+// Alive reports whether the daemon is still running. Spawned daemons are
+// probed via their process, adopted daemons with a get_version call
+// bounded by adoptProbeTimeout, and remote node setups are always
+// considered alive.
+func (m *MoneroDaemon) Alive() bool {
+	if m.useRemoteNode {
+		return true
+	}
+	m.mu.Lock()
+	adopted, process, exit := m.adopted, m.process, m.exit
+	m.mu.Unlock()
+	if adopted {
+		return m.probeMonerod(context.Background()) == nil
+	}
+	return process != nil && !exit.Exited()
+}
+
+// processExit returns the exit tracker of the current process.
+func (m *MoneroDaemon) processExit() *util.ProcessExit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.exit
+}
+
+// Exited returns a channel closed when a spawned daemon process exits.
+// For adopted daemons and remote node setups the channel is nil, which
+// blocks forever.
+func (m *MoneroDaemon) Exited() <-chan struct{} {
+	return m.processExit().Done()
+}
+
+// ExitErr returns the exit error of the last spawned process, or nil if it
+// is still running or exited cleanly.
+func (m *MoneroDaemon) ExitErr() error {
+	return m.processExit().Err()
+}
+
+// Uptime returns how long the current daemon process has been running.
+func (m *MoneroDaemon) Uptime() time.Duration {
+	return m.processExit().Uptime()
+}
+
+// Stopping reports whether the process exit was requested via Shutdown,
+// as opposed to an unexpected crash.
+func (m *MoneroDaemon) Stopping() bool {
+	state := m.State()
+	return state == util.StateStopping || state == util.StateStopped
+}
+
+// Adopted reports whether this instance attached to a daemon that was
+// already running rather than spawning its own process.
+func (m *MoneroDaemon) Adopted() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.adopted
+}
+
 func (m *MoneroDaemon) PID() string {
-	if m.cmd != nil {
-		if m.cmd.Process != nil {
-			return fmt.Sprintf("%d", m.cmd.Process.Pid)
-		}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.process != nil {
+		return fmt.Sprintf("%d", m.process.Pid)
 	}
 	return "-1"
 }
+
+// State returns the lifecycle state of the daemon. Adopted daemons and
+// remote node setups are running once started; Shutdown leaves them
+// running, as their process is not owned by this manager.
+//
+// Returns:
+//   - util.ServiceState: Current state, StateCrashed after an exit that
+//     Shutdown did not request
+func (m *MoneroDaemon) State() util.ServiceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// setState validates and applies a state change. m.mu must be held.
+func (m *MoneroDaemon) setState(to util.ServiceState) error {
+	if err := util.CheckTransition(m.state, to); err != nil {
+		return errors.E(opState, errors.ComponentMonerod, errors.KindProcess, err)
+	}
+	m.log().Debug("state changed", "from", m.state, "to", to)
+	m.state = to
+	return nil
+}
+
+// processExited records the exit of the daemon process: stopped if
+// Shutdown requested it, crashed otherwise. Exits of processes that have
+// since been replaced are ignored.
+func (m *MoneroDaemon) processExited(exit *util.ProcessExit) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.exit != exit {
+		return
+	}
+	if pidFile := m.pidFile(); pidFile != "" {
+		if err := util.RemovePIDFile(pidFile); err != nil {
+			m.log().Warn("could not remove PID file", "path", pidFile, "error", err)
+		}
+	}
+	switch m.state {
+	case util.StateStopping:
+		_ = m.setState(util.StateStopped)
+	case util.StateStarting, util.StateRunning:
+		_ = m.setState(util.StateCrashed)
+	}
+}
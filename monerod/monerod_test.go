@@ -2,11 +2,24 @@ package monerod
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/rpc"
+	"github.com/opd-ai/moneroger/testutil"
 	"github.com/opd-ai/moneroger/util"
 )
 
@@ -76,12 +89,10 @@ func TestRPCCredentials(t *testing.T) {
 
 // TestNewMoneroDaemon tests daemon creation and configuration
 func TestNewMoneroDaemon(t *testing.T) {
-	// Create temporary data directory
-	dataDir, err := os.MkdirTemp("", "monero-data-*")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(dataDir)
+	// Use isolated directories and ports so a real node does not interfere
+	base := testutil.Config(t)
+	dataDir := base.DataDir
+	ports := testutil.FreePorts(t, 2)
 
 	tests := []struct {
 		name    string
@@ -92,7 +103,7 @@ func TestNewMoneroDaemon(t *testing.T) {
 			name: "basic mainnet config",
 			config: util.Config{
 				DataDir:    dataDir,
-				MoneroPort: 18081,
+				MoneroPort: ports[0],
 			},
 			wantErr: false,
 		},
@@ -100,7 +111,7 @@ func TestNewMoneroDaemon(t *testing.T) {
 			name: "testnet config",
 			config: util.Config{
 				DataDir:    dataDir,
-				MoneroPort: 28081,
+				MoneroPort: ports[1],
 				TestNet:    true,
 			},
 			wantErr: false,
@@ -130,8 +141,8 @@ func TestNewMoneroDaemon(t *testing.T) {
 				if daemon.dataDir != tt.config.DataDir {
 					t.Errorf("dataDir = %v, want %v", daemon.dataDir, tt.config.DataDir)
 				}
-				if daemon.testnet != tt.config.TestNet {
-					t.Errorf("testnet = %v, want %v", daemon.testnet, tt.config.TestNet)
+				if daemon.network != tt.config.NetType() {
+					t.Errorf("network = %v, want %v", daemon.network, tt.config.NetType())
 				}
 				// Clean up
 				_ = daemon.Shutdown(ctx)
@@ -171,3 +182,721 @@ func TestDefaultConstants(t *testing.T) {
 		})
 	}
 }
+
+// TestStartAdoptsSyncedDaemon verifies the warm-start fast path
+func TestStartAdoptsSyncedDaemon(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"result":{"status":"OK","synchronized":true,"height":100,"target_height":100}}`))
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	d := &MoneroDaemon{rpcPort: port}
+	start := time.Now()
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !d.Adopted() {
+		t.Error("Start() should adopt a synced daemon")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("adoption took %v, expected fast path", elapsed)
+	}
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() of adopted daemon error = %v", err)
+	}
+}
+
+// TestStartAdoptsSyncingDaemon verifies a daemon still syncing on the
+// port is adopted rather than competing with a second process
+func TestStartAdoptsSyncingDaemon(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"get_info":    `{"status":"OK","synchronized":false,"height":10,"target_height":100}`,
+		"get_version": `{"status":"OK","version":196613}`,
+	})
+	defer srv.Close()
+
+	d := &MoneroDaemon{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	if err := d.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !d.Adopted() {
+		t.Error("Start() should adopt a daemon answering get_version")
+	}
+}
+
+// TestAliveAdopted verifies adopted daemons are probed over RPC, and that
+// a daemon that stopped answering fails the probe within adoptProbeTimeout
+func TestAliveAdopted(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"get_version": `{"status":"OK","version":196613}`,
+	})
+	defer srv.Close()
+	d := &MoneroDaemon{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port, adopted: true}
+	if !d.Alive() {
+		t.Error("Alive() = false for an adopted daemon answering get_version")
+	}
+
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hung.Close()
+	defer close(release)
+	d = &MoneroDaemon{rpcPort: hung.Listener.Addr().(*net.TCPAddr).Port, adopted: true}
+	start := time.Now()
+	if d.Alive() {
+		t.Error("Alive() = true for an adopted daemon that does not answer")
+	}
+	if elapsed := time.Since(start); elapsed > adoptProbeTimeout+time.Second {
+		t.Errorf("Alive() took %v, want at most %v", elapsed, adoptProbeTimeout)
+	}
+}
+
+// TestWaitReadyZMQ verifies readiness detection when ZMQ is enabled
+func TestWaitReadyZMQ(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"result":{"status":"OK","synchronized":false}}`))
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	// Nothing listens on the ZMQ port, so readiness must come from get_version
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zmqPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	d := &MoneroDaemon{rpcPort: port, zmqPubPort: zmqPort}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := d.waitReady(ctx); err != nil {
+		t.Errorf("waitReady() error = %v", err)
+	}
+}
+
+// TestWaitReadyRPC verifies that an open port alone is not taken as
+// readiness while monerod cannot answer RPC yet
+func TestWaitReadyRPC(t *testing.T) {
+	var serving atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !serving.Load() {
+			http.Error(w, "initializing", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"id":1,"result":{"status":"OK","version":196621}}`))
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	d := &MoneroDaemon{rpcPort: port, startWait: 200 * time.Millisecond}
+	if err := d.waitReady(context.Background()); err == nil {
+		t.Fatal("waitReady() succeeded before get_version did")
+	}
+
+	time.AfterFunc(100*time.Millisecond, func() { serving.Store(true) })
+	d.startWait = 5 * time.Second
+	if err := d.waitReady(context.Background()); err != nil {
+		t.Errorf("waitReady() error = %v", err)
+	}
+}
+
+// mockDaemon serves canned JSON-RPC results keyed by method name, and
+// canned bodies for other endpoints keyed by path
+func mockDaemon(t *testing.T, results map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json_rpc" {
+			body, ok := results[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(body))
+			return
+		}
+		var req struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		result, ok := results[req.Method]
+		if !ok {
+			fmt.Fprintf(w, `{"id":%d,"error":{"code":-32601,"message":"Method not found"}}`, req.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%d,"result":%s}`, req.ID, result)
+	}))
+}
+
+// TestClient verifies the typed daemon RPC wrappers
+func TestClient(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"get_info":                   `{"status":"OK","height":3000000,"target_height":3000010,"synchronized":false,"nettype":"mainnet"}`,
+		"/get_height":                `{"status":"OK","height":3000000}`,
+		"get_block_header_by_height": `{"status":"OK","block_header":{"hash":"abc","height":42,"num_txes":3}}`,
+		"get_version":                `{"status":"OK","version":196621,"release":true}`,
+		"sync_info":                  `{"status":"OK","height":10,"target_height":20,"peers":[{"info":{"address":"1.2.3.4:18080","height":20}}]}`,
+	})
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", rpc.Options{})
+	ctx := context.Background()
+
+	info, err := c.GetInfo(ctx)
+	if err != nil || info.Height != 3000000 || info.Nettype != "mainnet" {
+		t.Errorf("GetInfo() = %+v, %v", info, err)
+	}
+	height, err := c.GetHeight(ctx)
+	if err != nil || height != 3000000 {
+		t.Errorf("GetHeight() = %d, %v", height, err)
+	}
+	header, err := c.GetBlockHeaderByHeight(ctx, 42)
+	if err != nil || header.Hash != "abc" || header.NumTxes != 3 {
+		t.Errorf("GetBlockHeaderByHeight() = %+v, %v", header, err)
+	}
+	version, err := c.GetVersion(ctx)
+	if err != nil || version.Major() != 3 || version.Minor() != 13 {
+		t.Errorf("GetVersion() = %+v, %v", version, err)
+	}
+	si, err := c.SyncInfo(ctx)
+	if err != nil || len(si.Peers) != 1 || si.Peers[0].Info.Height != 20 {
+		t.Errorf("SyncInfo() = %+v, %v", si, err)
+	}
+}
+
+// TestPeers verifies the peer and ban wrappers, and that the ban list
+// is applied when a daemon is adopted
+func TestPeers(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"/get_peer_list": `{"status":"OK","white_list":[{"host":"1.2.3.4","port":18080,"last_seen":1700000000}],"gray_list":[{"host":"5.6.7.8","port":18080}]}`,
+		"get_bans":       `{"status":"OK","bans":[{"host":"9.9.9.0/24","seconds":3600}]}`,
+		"set_bans":       `{"status":"OK"}`,
+		"get_info":       `{"status":"OK","incoming_connections_count":3,"outgoing_connections_count":12,"white_peerlist_size":500,"grey_peerlist_size":4000}`,
+	})
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", rpc.Options{})
+	ctx := context.Background()
+	peers, err := c.GetPeerList(ctx)
+	if err != nil || len(peers.WhiteList) != 1 || peers.WhiteList[0].Host != "1.2.3.4" || len(peers.GrayList) != 1 {
+		t.Errorf("GetPeerList() = %+v, %v", peers, err)
+	}
+	bans, err := c.GetBans(ctx)
+	if err != nil || len(bans) != 1 || bans[0].Host != "9.9.9.0/24" || bans[0].Seconds != 3600 {
+		t.Errorf("GetBans() = %+v, %v", bans, err)
+	}
+	if err := c.SetBans(ctx, []Ban{{Host: "9.9.9.0/24", Ban: false}}); err != nil {
+		t.Errorf("SetBans() error = %v", err)
+	}
+	counts, err := c.GetConnectionCounts(ctx)
+	if err != nil || *counts != (ConnectionCounts{Incoming: 3, Outgoing: 12, WhiteList: 500, GreyList: 4000}) {
+		t.Errorf("GetConnectionCounts() = %+v, %v", counts, err)
+	}
+
+	var mu sync.Mutex
+	var setBans string
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64          `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "set_bans" {
+			mu.Lock()
+			setBans = string(req.Params)
+			mu.Unlock()
+		}
+		fmt.Fprintf(w, `{"id":%d,"result":{"status":"OK","synchronized":true}}`, req.ID)
+	}))
+	defer daemon.Close()
+
+	banList := filepath.Join(t.TempDir(), "ban.txt")
+	if err := os.WriteFile(banList, []byte("# spy nodes\n10.0.0.1\n\n192.168.0.0/16 # lab\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d := &MoneroDaemon{}
+	config := util.Config{
+		DataDir:    t.TempDir(),
+		MoneroPort: daemon.Listener.Addr().(*net.TCPAddr).Port,
+		BanList:    banList,
+	}
+	if err := d.configure(config); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if err := d.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	want := `{"bans":[{"host":"10.0.0.1","ban":true,"seconds":4294967295},{"host":"192.168.0.0/16","ban":true,"seconds":4294967295}]}`
+	mu.Lock()
+	defer mu.Unlock()
+	if setBans != want {
+		t.Errorf("set_bans params = %s, want %s", setBans, want)
+	}
+}
+
+// TestEstimateFee verifies fee rates per priority and the rounding of
+// fee totals
+func TestEstimateFee(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"get_fee_estimate": `{"status":"OK","fee":20001,"fees":[20001,80000,320000,4000000],"quantization_mask":10000}`,
+	})
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", rpc.Options{})
+	ctx := context.Background()
+	fee, err := c.EstimateFee(ctx, 1)
+	if err != nil || fee != (Fee{PerByte: 20001, QuantizationMask: 10000}) {
+		t.Fatalf("EstimateFee(1) = %+v, %v", fee, err)
+	}
+	if total := fee.Total(1501); total != 30030000 {
+		t.Errorf("Total(1501) = %d, want 30030000", total)
+	}
+	if fee, err := c.EstimateFee(ctx, 0); err != nil || fee.PerByte != 80000 {
+		t.Errorf("EstimateFee(0) = %+v, %v, want the normal rate", fee, err)
+	}
+	if _, err := c.EstimateFee(ctx, 5); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("EstimateFee(5) error = %v, want KindConfig", err)
+	}
+
+	// Daemons before v0.18 only report the base fee
+	old := &FeeEstimate{Fee: 20000}
+	if fee, err := old.ForPriority(4); err != nil || fee.PerByte != 20000 || fee.Total(1500) != 30000000 {
+		t.Errorf("ForPriority(4) without fees = %+v, %v", fee, err)
+	}
+}
+
+// TestClientBadStatus verifies that non-OK statuses are errors
+func TestClientBadStatus(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"get_info": `{"status":"BUSY"}`,
+	})
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", rpc.Options{})
+	if _, err := c.GetInfo(context.Background()); err == nil {
+		t.Error("GetInfo() should fail on BUSY status")
+	}
+}
+
+// TestRecentLogs verifies both output streams land in the shared log buffer
+func TestRecentLogs(t *testing.T) {
+	m := &MoneroDaemon{logs: util.NewRingBuffer(1024)}
+	stdout := m.outputWriter(util.NewRingBuffer(1024), "stdout")
+	stderr := m.outputWriter(util.NewRingBuffer(1024), "stderr")
+
+	fmt.Fprint(stdout, "Loading blockchain\nSynchronized")
+	fmt.Fprint(stderr, "Error: peer dropped\n")
+	fmt.Fprint(stdout, " OK\n")
+
+	want := "Loading blockchain\nError: peer dropped\nSynchronized OK\n"
+	if got := m.RecentLogs(); got != want {
+		t.Errorf("RecentLogs() = %q, want %q", got, want)
+	}
+}
+
+// TestTune verifies hot tuning only sends changed settings
+func TestTune(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.URL.Path+" "+string(body))
+		mu.Unlock()
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer srv.Close()
+	port := srv.Listener.Addr().(*net.TCPAddr).Port
+
+	d := &MoneroDaemon{rpcPort: port, options: Options{OutPeers: 32, InPeers: 16}}
+	if err := d.Tune(context.Background(), 2, 0, 0); err != nil {
+		t.Fatalf("Tune() error = %v", err)
+	}
+	want := []string{
+		`/set_log_level {"level":2}`,
+		`/out_peers {"out_peers":12}`,
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+	if o := d.options; o.LogLevel != 2 || o.OutPeers != 0 || o.InPeers != 0 {
+		t.Errorf("settings = %d/%d/%d, want 2/0/0", o.LogLevel, o.OutPeers, o.InPeers)
+	}
+
+	calls = nil
+	if err := d.Tune(context.Background(), 2, 0, 0); err != nil {
+		t.Fatalf("Tune() error = %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("unchanged Tune() made calls %q", calls)
+	}
+
+	// A restart building the command line races a reload's Tune; the
+	// race detector reports unguarded options
+	d.dataDir = t.TempDir()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.command(context.Background(), "monerod")
+	}()
+	if err := d.Tune(context.Background(), 3, 8, 8); err != nil {
+		t.Errorf("Tune() during command() error = %v", err)
+	}
+	<-done
+}
+
+// TestOptions verifies the flags generated for each option and the
+// rejection of values monerod would refuse
+func TestOptions(t *testing.T) {
+	if args := (Options{}).Args(); len(args) != 0 {
+		t.Errorf("zero Options.Args() = %q, want none", args)
+	}
+	options := Options{
+		LogLevel:       1,
+		LogFile:        "/logs/bitmonero.log",
+		MaxLogFileSize: 1000000,
+		OutPeers:       8,
+		InPeers:        4,
+		LimitRate:      2048,
+		LimitRateUp:    512,
+		LimitRateDown:  4096,
+		DBSyncMode:     "fast:async:1000blocks",
+		MaxConcurrency: 2,
+		BlockSyncSize:  10,
+		NoIGD:          true,
+		Offline:        true,
+		Prune:          true,
+	}
+	want := "--log-level 1 --max-log-file-size 1000000 --out-peers 8 --in-peers 4 --limit-rate 2048 " +
+		"--limit-rate-up 512 --limit-rate-down 4096 --max-concurrency 2 " +
+		"--block-sync-size 10 --log-file /logs/bitmonero.log --db-sync-mode fast:async:1000blocks --no-igd --offline --prune-blockchain"
+	if got := strings.Join(options.Args(), " "); got != want {
+		t.Errorf("Args() = %s, want %s", got, want)
+	}
+	if err := options.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	for _, invalid := range []Options{
+		{LogLevel: 5},
+		{OutPeers: -1},
+		{MaxLogFileSize: -1},
+		{LimitRate: -1},
+		{LimitRateUp: -1},
+		{DBSyncMode: "slow"},
+		{DBSyncMode: "safe:later"},
+		{DBSyncMode: "fast:async:0bytes"},
+		{DBSyncMode: "fast:async:1:2"},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate() accepted %+v", invalid)
+		}
+	}
+
+	d := &MoneroDaemon{
+		dataDir: t.TempDir(),
+		rpcPort: 18081,
+		options: Options{NoIGD: true},
+		tor:     util.TorConfig{Proxy: "127.0.0.1:9050"},
+	}
+	cmd, err := d.command(context.Background(), "monerod")
+	if err != nil {
+		t.Fatalf("command() error = %v", err)
+	}
+	if n := strings.Count(strings.Join(cmd.Args, " "), "--no-igd"); n != 1 {
+		t.Errorf("cmd.Args contain --no-igd %d times with Tor, want 1", n)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), "--rpc-restricted-bind-port") {
+		t.Error("cmd.Args contain --rpc-restricted-bind-port without a restricted port")
+	}
+
+	d.options.LogFile = filepath.Join(d.dataDir, "logs", "bitmonero.log")
+	if _, err := d.command(context.Background(), "monerod"); err != nil {
+		t.Fatalf("command() with a log file error = %v", err)
+	}
+	if !util.DirExists(filepath.Dir(d.options.LogFile)) {
+		t.Error("command() did not create the log file's directory")
+	}
+
+	d.restrictedPort = 18089
+	d.publicArgs = util.Config{PublicNode: true}.PublicNodeArgs()
+	if cmd, err = d.command(context.Background(), "monerod"); err != nil {
+		t.Fatalf("command() error = %v", err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	if !strings.Contains(args, "--rpc-restricted-bind-port 18089") || !strings.Contains(args, "--public-node") {
+		t.Errorf("cmd.Args = %v, want --rpc-restricted-bind-port 18089 and --public-node", cmd.Args)
+	}
+}
+
+// TestAutoPort verifies a port taken by another program is replaced,
+// while a port serving monerod is recognized
+func TestAutoPort(t *testing.T) {
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+	otherPort := other.Listener.Addr().(*net.TCPAddr).Port
+
+	d := &MoneroDaemon{rpcPort: otherPort, autoPort: true}
+	if d.probeMonerod(context.Background()) == nil {
+		t.Fatal("probeMonerod() = nil for a server that is not monerod")
+	}
+	if err := d.movePort(); err != nil {
+		t.Fatalf("movePort() error = %v", err)
+	}
+	if d.RPCPort() == otherPort || d.RPCPort() == 0 {
+		t.Errorf("RPCPort() = %d after movePort, want a new port", d.RPCPort())
+	}
+
+	srv := mockDaemon(t, map[string]string{"get_version": `{"status":"OK","version":196613}`})
+	defer srv.Close()
+	d = &MoneroDaemon{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	if err := d.probeMonerod(context.Background()); err != nil {
+		t.Errorf("probeMonerod() = %v for a server answering get_version", err)
+	}
+}
+
+// TestNewMoneroDaemonRejectsForeignPort verifies only monerod is adopted
+func TestNewMoneroDaemonRejectsForeignPort(t *testing.T) {
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+	config := util.Config{DataDir: t.TempDir(), MoneroPort: other.Listener.Addr().(*net.TCPAddr).Port}
+	if _, err := NewMoneroDaemon(context.Background(), config); errors.GetKind(err) != errors.KindNetwork {
+		t.Errorf("NewMoneroDaemon() error = %v, want KindNetwork", err)
+	}
+
+	srv := mockDaemon(t, map[string]string{"get_version": `{"status":"OK","version":196613}`})
+	defer srv.Close()
+	config.MoneroPort = srv.Listener.Addr().(*net.TCPAddr).Port
+	d, err := NewMoneroDaemon(context.Background(), config)
+	if err != nil {
+		t.Fatalf("NewMoneroDaemon() error = %v", err)
+	}
+	if !d.Adopted() {
+		t.Error("NewMoneroDaemon() should adopt a daemon answering get_version")
+	}
+}
+
+// TestRestoreLogin verifies generated credentials survive a new instance
+func TestRestoreLogin(t *testing.T) {
+	config := util.Config{DataDir: t.TempDir(), MoneroPort: 18081}
+	first := &MoneroDaemon{}
+	if err := first.configure(config); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	path := filepath.Join(config.DataDir, rpcLoginFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("credentials not saved: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("credentials file mode = %v, want 0600", perm)
+	}
+
+	second := &MoneroDaemon{}
+	if err := second.configure(config); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if second.RPCUser() != first.RPCUser() || second.RPCPass() != first.RPCPass() {
+		t.Error("a new instance did not reuse the saved credentials")
+	}
+
+	config.MoneroRPCUser = "someone-else"
+	third := &MoneroDaemon{}
+	if err := third.configure(config); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+	if third.RPCPass() == first.RPCPass() {
+		t.Error("credentials saved for another user were reused")
+	}
+}
+
+// TestCommandHidesSecrets verifies no credential appears in the process
+// arguments, where ps would show it
+func TestCommandHidesSecrets(t *testing.T) {
+	d := &MoneroDaemon{
+		dataDir:   t.TempDir(),
+		rpcPort:   18081,
+		rpcUser:   "user",
+		rpcPass:   "rpc-secret",
+		bootstrap: util.BootstrapConfig{Address: "node.example.com:18081", Login: "boot:boot-secret"},
+	}
+	cmd, err := d.command(context.Background(), "monerod")
+	if err != nil {
+		t.Fatalf("command() error = %v", err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, secret := range []string{"rpc-secret", "boot-secret"} {
+		if strings.Contains(args, secret) {
+			t.Errorf("cmd.Args contain %q: %s", secret, args)
+		}
+	}
+
+	path := filepath.Join(d.dataDir, optionsFile)
+	if !strings.Contains(args, "--config-file "+path) {
+		t.Errorf("cmd.Args = %s, want --config-file %s", args, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "bootstrap-daemon-login=boot:boot-secret\nrpc-login=user:rpc-secret\n"
+	if string(data) != want {
+		t.Errorf("options file = %q, want %q", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("options file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+// TestSyncProgress verifies sync percentages and the watch ending once synced
+func TestSyncProgress(t *testing.T) {
+	for name, tt := range map[string]struct {
+		info    Info
+		percent float64
+		target  uint64
+	}{
+		"syncing":          {Info{Height: 750, TargetHeight: 3000}, 25, 3000},
+		"no target yet":    {Info{Height: 10}, 0, 0},
+		"synced":           {Info{Height: 3000, Synchronized: true}, 100, 3000},
+		"bootstrap answer": {Info{Height: 3000, TargetHeight: 3000, Synchronized: true, Untrusted: true}, 100, 3000},
+	} {
+		s := syncStatus(&tt.info)
+		if s.Percent != tt.percent || s.TargetHeight != tt.target {
+			t.Errorf("%s: syncStatus() = %+v, want %v%% of %d", name, s, tt.percent, tt.target)
+		}
+		if want := tt.info.LocalChainReady(); s.Synced != want {
+			t.Errorf("%s: Synced = %v, want %v", name, s.Synced, want)
+		}
+	}
+
+	srv := mockDaemon(t, map[string]string{
+		"get_info": `{"status":"OK","height":3000,"target_height":0,"synchronized":true}`,
+	})
+	defer srv.Close()
+	d := &MoneroDaemon{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var statuses []SyncStatus
+	for s := range d.WatchSync(ctx) {
+		statuses = append(statuses, s)
+	}
+	if len(statuses) != 1 || !statuses[0].Synced || statuses[0].Height != 3000 {
+		t.Errorf("WatchSync() = %+v, want a single synced status", statuses)
+	}
+
+	remote := &MoneroDaemon{useRemoteNode: true}
+	if _, err := remote.SyncProgress(ctx); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("SyncProgress() with a remote node error = %v, want KindConfig", err)
+	}
+}
+
+// TestTxPool verifies pool entries are parsed and WatchTxPool reports
+// additions, relays and removals
+func TestTxPool(t *testing.T) {
+	srv := mockDaemon(t, map[string]string{
+		"/get_transaction_pool": `{"status":"OK","transactions":[` +
+			`{"id_hash":"bb","fee":30000000,"blob_size":1500,"weight":1500,"receive_time":1700000100,"relayed":true,"last_relayed_time":1700000110},` +
+			`{"id_hash":"aa","fee":90000000,"blob_size":2500,"weight":2600,"receive_time":1700000000,"do_not_relay":true}]}`,
+	})
+	defer srv.Close()
+	d := &MoneroDaemon{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	txs, err := d.TxPool(ctx)
+	if err != nil || len(txs) != 2 {
+		t.Fatalf("TxPool() = %+v, %v", txs, err)
+	}
+	if tx := txs[0]; tx.Hash != "bb" || tx.Fee != 30000000 || tx.BlobSize != 1500 ||
+		!tx.ReceiveTime.Equal(time.Unix(1700000100, 0)) || !tx.Relayed || !tx.LastRelayedTime.Equal(time.Unix(1700000110, 0)) {
+		t.Errorf("TxPool()[0] = %+v", tx)
+	}
+	if tx := txs[1]; !tx.DoNotRelay || tx.Relayed || !tx.LastRelayedTime.IsZero() {
+		t.Errorf("TxPool()[1] = %+v", tx)
+	}
+
+	// The first update lists the pool in the order it was received
+	u := <-d.WatchTxPool(ctx)
+	if u.Err != nil || len(u.Added) != 2 || u.Added[0].Hash != "aa" || u.Added[1].Hash != "bb" {
+		t.Errorf("first WatchTxPool() update = %+v", u)
+	}
+
+	old := map[string]PoolTx{"aa": {Hash: "aa"}, "bb": {Hash: "bb"}}
+	cur := map[string]PoolTx{"bb": {Hash: "bb", Relayed: true}, "cc": {Hash: "cc"}}
+	u = diffTxPool(old, cur)
+	if len(u.Added) != 1 || u.Added[0].Hash != "cc" || len(u.Relayed) != 1 || u.Relayed[0].Hash != "bb" ||
+		fmt.Sprint(u.Removed) != "[aa]" {
+		t.Errorf("diffTxPool() = %+v", u)
+	}
+	if u := diffTxPool(cur, cur); !u.empty() {
+		t.Errorf("diffTxPool() of an unchanged pool = %+v", u)
+	}
+
+	remote := &MoneroDaemon{useRemoteNode: true}
+	var updates []TxPoolUpdate
+	for u := range remote.WatchTxPool(ctx) {
+		updates = append(updates, u)
+	}
+	if len(updates) != 1 || errors.GetKind(updates[0].Err) != errors.KindConfig {
+		t.Errorf("WatchTxPool() with a remote node = %+v, want one KindConfig error", updates)
+	}
+}
+
+// TestState verifies lifecycle tracking and concurrent Start and Shutdown
+func TestState(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"result":{"status":"OK","synchronized":true,"height":100,"target_height":100}}`))
+	}))
+	defer srv.Close()
+
+	d := &MoneroDaemon{rpcPort: srv.Listener.Addr().(*net.TCPAddr).Port}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := d.Start(context.Background()); err != nil {
+				t.Errorf("Start() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = d.Shutdown(context.Background())
+			_ = d.State()
+		}()
+	}
+	wg.Wait()
+	if got := d.State(); got != util.StateRunning {
+		t.Errorf("State() of adopted daemon = %s, want running", got)
+	}
+
+	// A process exiting without Shutdown is a crash
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("cmd.Start() error = %v", err)
+	}
+	exit := util.WatchProcess(cmd)
+	spawned := &MoneroDaemon{dataDir: t.TempDir(), process: cmd.Process, exit: exit, state: util.StateRunning}
+	if err := util.WritePIDFile(spawned.pidFile(), cmd.Process.Pid); err != nil {
+		t.Fatalf("WritePIDFile() error = %v", err)
+	}
+	<-exit.Done()
+	spawned.processExited(new(util.ProcessExit))
+	if got := spawned.State(); got != util.StateRunning {
+		t.Errorf("State() after a stale exit = %s, want running", got)
+	}
+	spawned.processExited(exit)
+	if got := spawned.State(); got != util.StateCrashed || spawned.Stopping() {
+		t.Errorf("State() after exit = %s, Stopping() = %v; want crashed, false", got, spawned.Stopping())
+	}
+	if util.FileExists(spawned.pidFile()) {
+		t.Error("PID file was kept after the process exited")
+	}
+	if err := spawned.Shutdown(context.Background()); err != nil || spawned.State() != util.StateCrashed {
+		t.Errorf("Shutdown() of crashed daemon = %v, state %s", err, spawned.State())
+	}
+}
@@ -0,0 +1,36 @@
+package monerod
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/zmq"
+)
+
+// opNotifications is the operation name for notification subscription errors
+const opNotifications = errors.Op("Notifications")
+
+// Notifications subscribes to the daemon's ZMQ publisher for new blocks
+// and transaction pool additions, replacing get_info height polling.
+//
+// Parameters:
+//   - ctx: Context ending the subscription and closing the channels
+//
+// Returns:
+//   - *zmq.Notifications: Block and transaction channels
+//   - error: KindConfig if the publisher is disabled or a remote node is used
+//
+// The subscription survives daemon restarts by reconnecting; check
+// Notifications.Err for the current connection state.
+func (m *MoneroDaemon) Notifications(ctx context.Context) (*zmq.Notifications, error) {
+	if m.useRemoteNode {
+		return nil, errors.E(opNotifications, errors.ComponentMonerod, errors.KindConfig,
+			fmt.Errorf("notifications are unavailable with a remote node"))
+	}
+	if m.zmqPubPort <= 0 {
+		return nil, errors.E(opNotifications, errors.ComponentMonerod, errors.KindConfig,
+			fmt.Errorf("ZMQ publisher is disabled"))
+	}
+	return zmq.Watch(ctx, m.zmqPubEndpoint()), nil
+}
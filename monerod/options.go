@@ -0,0 +1,149 @@
+package monerod
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/opd-ai/moneroger/util"
+)
+
+// maxLogLevel is the most verbose monerod log level
+const maxLogLevel = 4
+
+// Options are the monerod flags tuning its behaviour, as opposed to the
+// ones locating its data and RPC server. The zero value leaves every
+// setting at monerod's default.
+//
+// Fields:
+//   - LogLevel: Log verbosity, 0 to 4
+//   - LogFile: Path of monerod's log file, empty for the default
+//   - MaxLogFileSize: Bytes at which the log file is rotated, 0 for the
+//     default
+//   - OutPeers, InPeers: Outgoing and incoming peer limits, 0 for the
+//     defaults
+//   - LimitRate: Upload and download limit in kB/s, 0 for the default
+//   - LimitRateUp, LimitRateDown: Upload and download limits in kB/s
+//     overriding LimitRate, 0 for none
+//   - DBSyncMode: Database sync mode such as "safe" or
+//     "fast:async:250000000bytes", empty for the default
+//   - MaxConcurrency: Worker threads, 0 for one per CPU
+//   - BlockSyncSize: Blocks requested per batch while syncing, 0 for
+//     the default
+//   - NoIGD: Disable UPnP port mapping on the router
+//   - Offline: Do not connect to peers
+//   - Prune: Keep a pruned blockchain, about a third of its full size
+type Options struct {
+	LogLevel       int
+	LogFile        string
+	MaxLogFileSize int
+	OutPeers       int
+	InPeers        int
+	LimitRate      int
+	LimitRateUp    int
+	LimitRateDown  int
+	DBSyncMode     string
+	MaxConcurrency int
+	BlockSyncSize  int
+	NoIGD          bool
+	Offline        bool
+	Prune          bool
+}
+
+// OptionsFromConfig collects the monerod options of a configuration.
+//
+// Parameters:
+//   - config: Configuration holding DaemonLogLevel, the peer limits and
+//     the other tuning fields; a public node's profile fills in the limits
+//     left at 0
+//
+// Returns:
+//   - Options: The options
+func OptionsFromConfig(config util.Config) Options {
+	return Options{
+		LogLevel:       config.DaemonLogLevel,
+		LogFile:        config.EffectiveDaemonLogFile(),
+		MaxLogFileSize: config.DaemonMaxLogFileSize,
+		OutPeers:       config.EffectiveOutPeers(),
+		InPeers:        config.EffectiveInPeers(),
+		LimitRate:      config.EffectiveLimitRate(),
+		LimitRateUp:    config.LimitRateUp,
+		LimitRateDown:  config.LimitRateDown,
+		DBSyncMode:     config.DBSyncMode,
+		MaxConcurrency: config.MaxConcurrency,
+		BlockSyncSize:  config.BlockSyncSize,
+		NoIGD:          config.NoIGD,
+		Offline:        config.Offline,
+		Prune:          config.Prune,
+	}
+}
+
+// Validate checks the options for values monerod would reject.
+//
+// Returns:
+//   - error: Description of the first invalid option, nil if valid
+func (o Options) Validate() error {
+	if o.LogLevel < 0 || o.LogLevel > maxLogLevel {
+		return fmt.Errorf("invalid log level %d: must be 0 to %d", o.LogLevel, maxLogLevel)
+	}
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"max-log-file-size", o.MaxLogFileSize},
+		{"out-peers", o.OutPeers},
+		{"in-peers", o.InPeers},
+		{"limit-rate", o.LimitRate},
+		{"limit-rate-up", o.LimitRateUp},
+		{"limit-rate-down", o.LimitRateDown},
+		{"max-concurrency", o.MaxConcurrency},
+		{"block-sync-size", o.BlockSyncSize},
+	} {
+		if limit.value < 0 {
+			return fmt.Errorf("invalid %s %d: must not be negative", limit.name, limit.value)
+		}
+	}
+	return util.ValidateDBSyncMode(o.DBSyncMode)
+}
+
+// Args generates the monerod flags for the options, leaving out those
+// at their defaults.
+//
+// Returns:
+//   - []string: Command line arguments
+func (o Options) Args() []string {
+	var args []string
+	for _, flag := range []struct {
+		name  string
+		value int
+	}{
+		{"--log-level", o.LogLevel},
+		{"--max-log-file-size", o.MaxLogFileSize},
+		{"--out-peers", o.OutPeers},
+		{"--in-peers", o.InPeers},
+		{"--limit-rate", o.LimitRate},
+		{"--limit-rate-up", o.LimitRateUp},
+		{"--limit-rate-down", o.LimitRateDown},
+		{"--max-concurrency", o.MaxConcurrency},
+		{"--block-sync-size", o.BlockSyncSize},
+	} {
+		if flag.value > 0 {
+			args = append(args, flag.name, strconv.Itoa(flag.value))
+		}
+	}
+	if o.LogFile != "" {
+		args = append(args, "--log-file", o.LogFile)
+	}
+	if o.DBSyncMode != "" {
+		args = append(args, "--db-sync-mode", o.DBSyncMode)
+	}
+	if o.NoIGD {
+		args = append(args, "--no-igd")
+	}
+	if o.Offline {
+		args = append(args, "--offline")
+	}
+	if o.Prune {
+		args = append(args, "--prune-blockchain")
+	}
+	return args
+}
@@ -0,0 +1,147 @@
+package monerod
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// pidFile returns the path of the daemon's PID file, empty without a
+// data directory.
+func (m *MoneroDaemon) pidFile() string {
+	if m.dataDir == "" {
+		return ""
+	}
+	return filepath.Join(m.dataDir, pidFileName)
+}
+
+// track makes p the daemon's process and records its exit when it
+// terminates.
+func (m *MoneroDaemon) track(p *os.Process, exit *util.ProcessExit) {
+	m.mu.Lock()
+	m.process = p
+	m.exit = exit
+	m.mu.Unlock()
+	go func() {
+		<-exit.Done()
+		m.processExited(exit)
+	}()
+}
+
+// adoptOrphan looks for a monerod left running by an earlier manager,
+// found through the PID file. A process that runs the monerod executable
+// and answers RPC with this daemon's credentials is adopted and managed
+// as if spawned, including being stopped by Shutdown; with cleanup one
+// that does not answer is stopped so a new daemon can take its port.
+//
+// Parameters:
+//   - ctx: Context bounding the probe and the cleanup
+//   - cleanup: Whether an unresponsive orphan is stopped
+//
+// Returns:
+//   - bool: Whether an orphan was adopted
+//   - error: KindProcess if an unresponsive orphan could not be stopped
+func (m *MoneroDaemon) adoptOrphan(ctx context.Context, cleanup bool) (bool, error) {
+	pidFile := m.pidFile()
+	if pidFile == "" {
+		return false, nil
+	}
+	exe, err := m.processDriver().Executable(MoneroDPath)
+	if err != nil {
+		// Reported when spawning
+		return false, nil
+	}
+	p, err := util.FindOrphan(pidFile, exe)
+	if err != nil {
+		m.log().Warn("ignoring unreadable PID file", "path", pidFile, "error", err)
+		return false, nil
+	}
+	if p == nil {
+		return false, nil
+	}
+	if err := m.probeMonerod(ctx); err == nil {
+		m.log().Info("adopting monerod left running by a previous manager", "pid", p.Pid, "port", m.RPCPort())
+		m.track(p, util.WatchOrphan(p))
+		return true, nil
+	}
+	if !cleanup {
+		return false, nil
+	}
+	m.log().Warn("stopping unresponsive monerod left running by a previous manager", "pid", p.Pid)
+	if err := util.StopProcess(ctx, p, m.shutdownTimeout()); err != nil {
+		return false, errors.E(errors.OpProcessSpawn, errors.ComponentMonerod, errors.KindProcess, err)
+	}
+	if err := util.RemovePIDFile(pidFile); err != nil {
+		m.log().Warn("could not remove PID file", "path", pidFile, "error", err)
+	}
+	return false, nil
+}
+
+// removeStaleLock removes the database lock file a crashed monerod left
+// in the data directory, and refuses to start while another process has
+// the database open. Databases in containers are left to monerod, since
+// their locks may not be visible from the host.
+//
+// Returns:
+//   - error: KindSystem if the database is in use or its lock file
+//     cannot be checked
+func (m *MoneroDaemon) removeStaleLock() error {
+	if _, docker := m.processDriver().(*util.DockerDriver); docker || m.dataDir == "" {
+		return nil
+	}
+	removed, err := util.RemoveStaleLMDBLock(m.dataDir)
+	if stderrors.Is(err, util.ErrDatabaseInUse) {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindSystem,
+			fmt.Errorf("another monerod is using %s, stop it first: %w", m.dataDir, err))
+	}
+	if err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindSystem, err)
+	}
+	if removed {
+		m.log().Debug("removed stale database lock", "path", util.LMDBLockFile(m.dataDir))
+	}
+	return nil
+}
+
+// AttachMoneroDaemon reconnects to a daemon left running detached by an
+// earlier manager, see util.Config.Detach. Unlike NewMoneroDaemon it never
+// spawns monerod: the daemon recorded in the PID file must still be
+// running and answer RPC. An unresponsive daemon is left alone.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - config: The configuration the daemon was started with
+//
+// Returns:
+//   - *MoneroDaemon: The attached daemon, managed as if spawned
+//   - error: KindProcess if no running daemon was found
+//
+// Related:
+//   - NewMoneroDaemon for starting a daemon
+func AttachMoneroDaemon(ctx context.Context, config util.Config) (*MoneroDaemon, error) {
+	daemon := &MoneroDaemon{
+		logs: util.NewRingBuffer(config.OutputBufferSize()),
+	}
+	if err := daemon.configure(config); err != nil {
+		return nil, err
+	}
+	if !daemon.useRemoteNode {
+		orphan, err := daemon.adoptOrphan(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		if !orphan {
+			return nil, errors.E(opAttach, errors.ComponentMonerod, errors.KindProcess,
+				fmt.Errorf("no monerod answering RPC recorded in %s", daemon.pidFile()))
+		}
+	}
+	if err := daemon.Start(ctx); err != nil {
+		return nil, errors.E(opAttach, errors.ComponentMonerod, errors.KindProcess, err)
+	}
+	return daemon, nil
+}
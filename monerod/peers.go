@@ -0,0 +1,179 @@
+package monerod
+
+import (
+	"context"
+	"math"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// Operation constants for peer management errors
+const (
+	opGetPeerList = errors.Op("Client.GetPeerList")
+	opGetBans     = errors.Op("Client.GetBans")
+	opSetBans     = errors.Op("Client.SetBans")
+)
+
+// banListSeconds is the duration of bans from Config.BanList. set_bans
+// has no permanent bans, so the longest one it accepts is used; the list
+// is applied again at every start anyway.
+const banListSeconds = math.MaxUint32
+
+// PeerListEntry is a known peer as reported by get_peer_list.
+//
+// Fields:
+//   - Host: Address in dotted or bracketed form
+//   - IP: IPv4 address packed in network byte order, 0 for other hosts
+//   - LastSeen: Unix time the peer was last connected, 0 if never
+//   - PruningSeed: Pruning seed of the peer, 0 for full nodes
+type PeerListEntry struct {
+	ID          uint64 `json:"id"`
+	Host        string `json:"host"`
+	IP          uint32 `json:"ip"`
+	Port        uint16 `json:"port"`
+	RPCPort     uint16 `json:"rpc_port"`
+	LastSeen    uint64 `json:"last_seen"`
+	PruningSeed uint32 `json:"pruning_seed"`
+}
+
+// PeerList is the response of the get_peer_list RPC method.
+//
+// Fields:
+//   - WhiteList: Peers the daemon has connected to
+//   - GrayList: Peers only announced by other peers
+type PeerList struct {
+	Status    string          `json:"status"`
+	WhiteList []PeerListEntry `json:"white_list"`
+	GrayList  []PeerListEntry `json:"gray_list"`
+}
+
+// Ban is a banned host, as listed by get_bans and sent to set_bans.
+//
+// Fields:
+//   - Host: IP address or CIDR subnet
+//   - IP: IPv4 address packed in network byte order, an alternative to
+//     Host in set_bans
+//   - Ban: true to ban, false to lift a ban; unset in get_bans answers
+//   - Seconds: Ban duration in set_bans, the time left in get_bans
+type Ban struct {
+	Host    string `json:"host,omitempty"`
+	IP      uint32 `json:"ip,omitempty"`
+	Ban     bool   `json:"ban"`
+	Seconds uint32 `json:"seconds"`
+}
+
+// ConnectionCounts are the daemon's peer connection and peer list
+// sizes, taken from get_info.
+type ConnectionCounts struct {
+	Incoming  uint64
+	Outgoing  uint64
+	WhiteList uint64
+	GreyList  uint64
+}
+
+// GetPeerList returns the peers the daemon knows about.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *PeerList: White and gray peer lists
+//   - error: RPC failures or a non-OK status; restricted RPC servers
+//     refuse the method
+func (c *Client) GetPeerList(ctx context.Context) (*PeerList, error) {
+	var list PeerList
+	if err := c.rpc.CallPath(ctx, "/get_peer_list", nil, &list); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetPeerList, list.Status); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetBans returns the hosts the daemon currently refuses.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - []Ban: Banned hosts with the seconds left on each ban
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetBans(ctx context.Context) ([]Ban, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Bans   []Ban  `json:"bans"`
+	}
+	if err := c.rpc.Call(ctx, "get_bans", nil, &resp); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetBans, resp.Status); err != nil {
+		return nil, err
+	}
+	return resp.Bans, nil
+}
+
+// SetBans bans hosts or lifts their bans. Banning drops existing
+// connections to the host.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - bans: Hosts to change, with Ban and Seconds set
+//
+// Returns:
+//   - error: RPC failures, malformed hosts or a non-OK status
+func (c *Client) SetBans(ctx context.Context, bans []Ban) error {
+	var resp struct {
+		Status string `json:"status"`
+	}
+	params := map[string][]Ban{"bans": bans}
+	if err := c.rpc.Call(ctx, "set_bans", params, &resp); err != nil {
+		return err
+	}
+	return checkStatus(opSetBans, resp.Status)
+}
+
+// GetConnectionCounts returns how many peers the daemon is connected to
+// and how many it knows about.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *ConnectionCounts: Connection and peer list sizes
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetConnectionCounts(ctx context.Context) (*ConnectionCounts, error) {
+	info, err := c.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ConnectionCounts{
+		Incoming:  info.IncomingConnectionsCount,
+		Outgoing:  info.OutgoingConnectionsCount,
+		WhiteList: info.WhitePeerlistSize,
+		GreyList:  info.GreyPeerlistSize,
+	}, nil
+}
+
+// applyBanList bans the hosts of Config.BanList on the running daemon.
+// A failure is logged rather than returned, since the daemon runs
+// either way.
+func (m *MoneroDaemon) applyBanList(ctx context.Context) {
+	if m.useRemoteNode || len(m.banList) == 0 {
+		return
+	}
+	bans := make([]Ban, len(m.banList))
+	for i, host := range m.banList {
+		bans[i] = Ban{Host: host, Ban: true, Seconds: banListSeconds}
+	}
+	client := m.Client()
+	err := util.Retry(ctx, util.DefaultRetryPolicy(), func(ctx context.Context) error {
+		return client.SetBans(ctx, bans)
+	})
+	if err != nil {
+		m.log().Warn("could not apply ban list", "hosts", len(bans), "error", err)
+		return
+	}
+	m.log().Info("ban list applied", "hosts", len(bans))
+}
@@ -0,0 +1,87 @@
+package monerod
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/moneroger/util"
+	"github.com/opd-ai/moneroger/zmq"
+)
+
+// zmqPubEndpoint returns the ZMQ publisher endpoint passed to --zmq-pub.
+func (m *MoneroDaemon) zmqPubEndpoint() string {
+	return fmt.Sprintf("tcp://127.0.0.1:%d", m.zmqPubPort)
+}
+
+// waitReady blocks until a freshly spawned daemon is actually serving,
+// for at most the configured startup timeout.
+//
+// Parameters:
+//   - ctx: Context for cancellation
+//
+// Returns:
+//   - error: nil once ready, otherwise a timeout or cancellation error
+//
+// Without ZMQ a successful get_version is the readiness signal. With ZMQ
+// enabled the first chain_main notification or a successful get_version,
+// whichever comes first, is taken as the readiness signal. Both are more
+// accurate than an open TCP port, which monerod accepts long before RPC
+// is usable, while it initializes its database.
+func (m *MoneroDaemon) waitReady(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.startupTimeout())
+	defer cancel()
+	if m.zmqPubPort <= 0 {
+		if err := util.WaitReady(ctx, m.probeMonerod); err != nil {
+			return fmt.Errorf("timeout waiting for monerod readiness on port %d: %w", m.RPCPort(), err)
+		}
+		return nil
+	}
+
+	ready := make(chan struct{}, 2)
+	go m.watchZMQReady(ctx, ready)
+	go m.pollRPCReady(ctx, ready)
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timeout waiting for monerod readiness on port %d: %w", m.RPCPort(), ctx.Err())
+	}
+}
+
+// watchZMQReady signals ready when the first chain_main message arrives.
+func (m *MoneroDaemon) watchZMQReady(ctx context.Context, ready chan<- struct{}) {
+	for ctx.Err() == nil {
+		sub, err := zmq.Dial(ctx, m.zmqPubEndpoint(), zmq.TopicChainMain)
+		if err != nil {
+			sleepContext(ctx, readyPollInterval)
+			continue
+		}
+		stop := context.AfterFunc(ctx, func() { sub.Close() })
+		_, err = sub.Recv()
+		stop()
+		sub.Close()
+		if err == nil {
+			ready <- struct{}{}
+			return
+		}
+	}
+}
+
+// pollRPCReady signals ready once get_version succeeds.
+func (m *MoneroDaemon) pollRPCReady(ctx context.Context, ready chan<- struct{}) {
+	if util.WaitReady(ctx, m.probeMonerod) == nil {
+		ready <- struct{}{}
+	}
+}
+
+// sleepContext sleeps for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
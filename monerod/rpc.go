@@ -0,0 +1,180 @@
+package monerod
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// rpcClient returns the JSON-RPC client for this daemon, creating it on
+// first use. The client is shared so that connections are reused. It
+// retries transient failures of safe calls and stops calling a daemon
+// that keeps failing until it recovers.
+func (m *MoneroDaemon) rpcClient() *rpc.Client {
+	m.clientOnce.Do(func() {
+		m.client = rpc.NewClient(
+			m.RPCAddress(),
+			m.RPCUser(),
+			m.RPCPass(),
+			rpc.Options{
+				Component:        errors.ComponentMonerod,
+				TLS:              m.clientTLS,
+				Retry:            util.RetryRPC,
+				BreakerThreshold: rpc.DefaultBreakerThreshold,
+			},
+		)
+	})
+	return m.client
+}
+
+// restoreLogin reuses RPC credentials generated by an earlier run, so a
+// daemon that outlived its manager can be adopted again. Without saved
+// credentials for the configured user it generates new ones and saves
+// them under the data directory.
+func (m *MoneroDaemon) restoreLogin() {
+	path := filepath.Join(m.dataDir, rpcLoginFile)
+	user, pass, err := util.LoadRPCLogin(path)
+	switch {
+	case err == nil && (m.rpcUser == "" || m.rpcUser == user):
+		m.rpcUser, m.rpcPass = user, pass
+		return
+	case err != nil && !stderrors.Is(err, fs.ErrNotExist):
+		m.log().Warn("ignoring unreadable RPC credentials", "path", path, "error", err)
+	}
+
+	m.rpcPass = util.SecurePassword()
+	if err := util.SaveRPCLogin(path, m.RPCUser(), m.rpcPass); err != nil {
+		m.log().Warn("could not save RPC credentials, a restarted manager cannot reattach", "path", path, "error", err)
+	}
+}
+
+// resetClient drops the cached RPC client, so the next call connects
+// with the current endpoint and credentials.
+func (m *MoneroDaemon) resetClient() {
+	if m.client != nil {
+		m.client.Close()
+	}
+	m.client = nil
+	m.clientOnce = sync.Once{}
+}
+
+// probeMonerod checks that the server on the RPC port answers
+// get_version the way monerod does, with this daemon's credentials,
+// before it is adopted and while waiting for it to become ready.
+//
+// Parameters:
+//   - ctx: Context bounding the probe, further limited to adoptProbeTimeout
+//
+// Returns:
+//   - error: nil for a usable monerod, otherwise why the probe failed
+func (m *MoneroDaemon) probeMonerod(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, adoptProbeTimeout)
+	defer cancel()
+	_, err := m.Client().GetVersion(ctx)
+	return err
+}
+
+// portTaken handles an RPC port held by a program that cannot be
+// adopted: with autoPort a free port is used instead, otherwise a
+// KindNetwork error is returned.
+//
+// Parameters:
+//   - probeErr: Why probeMonerod rejected the program on the port
+func (m *MoneroDaemon) portTaken(probeErr error) error {
+	if m.autoPort {
+		return m.movePort()
+	}
+	return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindNetwork,
+		fmt.Errorf("port %d is in use by a program that does not answer like monerod: %w", m.rpcPort, probeErr))
+}
+
+// movePort switches the RPC port to a free one after the configured port
+// turned out to be taken by another program.
+//
+// Returns:
+//   - error: KindNetwork if no free port could be allocated
+func (m *MoneroDaemon) movePort() error {
+	ports, err := util.GetFreePorts(m.bindIP, 1, m.portRange)
+	if err != nil {
+		return errors.E(errors.OpStart, errors.ComponentMonerod, errors.KindNetwork,
+			fmt.Errorf("port %d is in use and no free port was found: %w", m.rpcPort, err))
+	}
+	m.log().Warn("RPC port taken by another program, using a free port", "port", m.rpcPort, "chosen", ports[0])
+	m.rpcPort = ports[0]
+	m.resetClient()
+	return nil
+}
+
+// RPCAddress returns the base URL of the local daemon's RPC server,
+// using https when TLS is configured.
+func (m *MoneroDaemon) RPCAddress() string {
+	return fmt.Sprintf("%s://%s", m.tls.Scheme(), net.JoinHostPort(m.RPCHost(), strconv.Itoa(m.RPCPort())))
+}
+
+// RPCHost returns the host the daemon's RPC server is reached on:
+// localhost unless a specific bind address is configured.
+func (m *MoneroDaemon) RPCHost() string {
+	return util.DialHost(m.bindIP)
+}
+
+// TLS returns the daemon's RPC TLS settings, so clients such as the
+// wallet can connect over https and verify the certificate.
+func (m *MoneroDaemon) TLS() util.RPCTLS {
+	return m.tls
+}
+
+// isSynced reports whether the daemon answers get_info and considers
+// itself synchronized with the network.
+//
+// Parameters:
+//   - ctx: Context bounding the probe
+//
+// Returns:
+//   - bool: true only if the daemon responded with status OK and synchronized
+func (m *MoneroDaemon) isSynced(ctx context.Context) bool {
+	info, err := m.Client().GetInfo(ctx)
+	return err == nil && info.LocalChainReady()
+}
+
+// CheckHealth reports whether the daemon answers RPC.
+//
+// Parameters:
+//   - ctx: Context bounding the check, further limited to healthCheckTimeout
+//
+// Returns:
+//   - error: nil if healthy or remote nodes are used, otherwise a
+//     KindNetwork error
+//
+// The check issues an authenticated get_version call, so a daemon that
+// still holds its port but has stopped serving requests is reported.
+// Remote nodes are probed by the wallets using them instead.
+func (m *MoneroDaemon) CheckHealth(ctx context.Context) error {
+	if m.useRemoteNode {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	if _, err := m.Client().GetVersion(ctx); err != nil {
+		return errors.E(opCheckHealth, errors.ComponentMonerod, errors.KindNetwork,
+			fmt.Errorf("monerod is not responding on port %d: %w", m.RPCPort(), err))
+	}
+	return nil
+}
+
+// RPCStats reports in-flight, queued and completed daemon RPC requests
+// made through this instance, broken down by method.
+//
+// Returns:
+//   - rpc.Stats: Snapshot of request activity
+func (m *MoneroDaemon) RPCStats() rpc.Stats {
+	return m.rpcClient().Stats()
+}
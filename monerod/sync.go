@@ -0,0 +1,123 @@
+package monerod
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// opSyncProgress is the operation name for errors from SyncProgress
+const opSyncProgress = errors.Op("SyncProgress")
+
+// syncPollInterval is how often WatchSync polls the daemon
+const syncPollInterval = 5 * time.Second
+
+// SyncStatus is a snapshot of blockchain synchronization.
+//
+// Fields:
+//   - Height: Blocks in the local chain
+//   - TargetHeight: Height of the network as reported by peers, 0 while
+//     no peer has reported it yet
+//   - Percent: Height as a percentage of TargetHeight, 100 when synced
+//   - Synced: Whether the local chain is synchronized, see
+//     Info.LocalChainReady
+//   - Err: Why the status could not be read, leaving the other fields
+//     zero; only set by WatchSync
+type SyncStatus struct {
+	Height       uint64
+	TargetHeight uint64
+	Percent      float64
+	Synced       bool
+	Err          error
+}
+
+// syncStatus derives a SyncStatus from get_info. monerod reports a
+// target height of 0 once synchronized.
+func syncStatus(info *Info) SyncStatus {
+	s := SyncStatus{
+		Height:       info.Height,
+		TargetHeight: info.TargetHeight,
+		Synced:       info.LocalChainReady(),
+	}
+	if s.Synced && s.TargetHeight < s.Height {
+		s.TargetHeight = s.Height
+	}
+	switch {
+	case s.Synced:
+		s.Percent = 100
+	case s.TargetHeight > 0:
+		s.Percent = 100 * float64(s.Height) / float64(s.TargetHeight)
+		if s.Percent > 100 {
+			s.Percent = 100
+		}
+	}
+	return s
+}
+
+// SyncProgress reports how far the daemon's chain is from the network.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - *SyncStatus: Height, target height and percentage
+//   - error: KindConfig with a remote node, which has no local chain,
+//     otherwise RPC failures
+//
+// Related:
+//   - WatchSync to follow progress until synchronized
+func (m *MoneroDaemon) SyncProgress(ctx context.Context) (*SyncStatus, error) {
+	if m.useRemoteNode {
+		return nil, errors.E(opSyncProgress, errors.ComponentMonerod, errors.KindConfig,
+			fmt.Errorf("sync progress is unavailable with a remote node"))
+	}
+	info, err := m.Client().GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := syncStatus(info)
+	return &s, nil
+}
+
+// WatchSync polls the daemon's sync progress until the chain is
+// synchronized, so callers can show progress during the hours an
+// initial sync takes.
+//
+// Parameters:
+//   - ctx: Context ending the watch
+//
+// Returns:
+//   - <-chan SyncStatus: One status per poll, the first immediately.
+//     Failed polls carry Err. The channel is closed after the first
+//     synchronized status, when ctx is done, or after the error status
+//     for a remote node
+func (m *MoneroDaemon) WatchSync(ctx context.Context) <-chan SyncStatus {
+	ch := make(chan SyncStatus)
+	go func() {
+		defer close(ch)
+		for {
+			var s SyncStatus
+			progress, err := m.SyncProgress(ctx)
+			if err != nil {
+				s.Err = err
+			} else {
+				s = *progress
+			}
+			select {
+			case ch <- s:
+			case <-ctx.Done():
+				return
+			}
+			if s.Synced || errors.GetKind(err) == errors.KindConfig {
+				return
+			}
+			sleepContext(ctx, syncPollInterval)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
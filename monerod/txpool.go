@@ -0,0 +1,217 @@
+package monerod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// Operation names for transaction pool errors
+const (
+	opGetTransactionPool = errors.Op("Client.GetTransactionPool")
+	opTxPool             = errors.Op("TxPool")
+)
+
+// txPoolPollInterval is how often WatchTxPool polls the daemon
+const txPoolPollInterval = 5 * time.Second
+
+// PoolTx is a transaction waiting in the daemon's pool, as reported by
+// get_transaction_pool.
+//
+// Fields:
+//   - Hash: Transaction hash
+//   - Fee: Fee in piconero
+//   - BlobSize: Serialized size in bytes
+//   - Weight: Weight in bytes, which the fee is charged on
+//   - ReceiveTime: When the daemon received the transaction
+//   - Relayed: Whether the daemon has relayed it to peers
+//   - LastRelayedTime: When it was last relayed, zero if never
+//   - DoNotRelay: Whether it was submitted without relaying
+//   - KeptByBlock: Whether it returned to the pool from a popped block
+//   - DoubleSpendSeen: Whether another transaction spends the same inputs
+type PoolTx struct {
+	Hash            string    `json:"id_hash"`
+	Fee             uint64    `json:"fee"`
+	BlobSize        uint64    `json:"blob_size"`
+	Weight          uint64    `json:"weight"`
+	ReceiveTime     time.Time `json:"-"`
+	Relayed         bool      `json:"relayed"`
+	LastRelayedTime time.Time `json:"-"`
+	DoNotRelay      bool      `json:"do_not_relay"`
+	KeptByBlock     bool      `json:"kept_by_block"`
+	DoubleSpendSeen bool      `json:"double_spend_seen"`
+}
+
+// UnmarshalJSON decodes a pool entry, converting the Unix times monerod
+// reports into time.Time values.
+func (tx *PoolTx) UnmarshalJSON(data []byte) error {
+	type plain PoolTx
+	raw := struct {
+		*plain
+		ReceiveTime     int64 `json:"receive_time"`
+		LastRelayedTime int64 `json:"last_relayed_time"`
+	}{plain: (*plain)(tx)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	tx.ReceiveTime, tx.LastRelayedTime = time.Time{}, time.Time{}
+	if raw.ReceiveTime > 0 {
+		tx.ReceiveTime = time.Unix(raw.ReceiveTime, 0)
+	}
+	if raw.LastRelayedTime > 0 {
+		tx.LastRelayedTime = time.Unix(raw.LastRelayedTime, 0)
+	}
+	return nil
+}
+
+// GetTransactionPool returns the transactions in the daemon's pool.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - []PoolTx: Pool entries, without the transaction bodies
+//   - error: RPC failures or a non-OK status
+func (c *Client) GetTransactionPool(ctx context.Context) ([]PoolTx, error) {
+	var resp struct {
+		Status       string   `json:"status"`
+		Transactions []PoolTx `json:"transactions"`
+	}
+	if err := c.rpc.CallPath(ctx, "/get_transaction_pool", nil, &resp); err != nil {
+		return nil, err
+	}
+	if err := checkStatus(opGetTransactionPool, resp.Status); err != nil {
+		return nil, err
+	}
+	return resp.Transactions, nil
+}
+
+// TxPool returns the transactions waiting in the daemon's pool.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - []PoolTx: Hash, fee, size and receive time of each transaction
+//   - error: KindConfig with a remote node, otherwise RPC failures
+//
+// Related:
+//   - WatchTxPool to follow changes to the pool
+func (m *MoneroDaemon) TxPool(ctx context.Context) ([]PoolTx, error) {
+	if m.useRemoteNode {
+		return nil, errors.E(opTxPool, errors.ComponentMonerod, errors.KindConfig,
+			fmt.Errorf("transaction pool is unavailable with a remote node"))
+	}
+	return m.Client().GetTransactionPool(ctx)
+}
+
+// TxPoolUpdate is a change to the transaction pool seen by WatchTxPool.
+//
+// Fields:
+//   - Added: Transactions that entered the pool; the first update lists
+//     the whole pool
+//   - Relayed: Transactions the daemon relayed to peers since the last
+//     update, having been in the pool unrelayed before
+//   - Removed: Hashes of transactions that left the pool, because they
+//     were mined or dropped
+//   - Err: Why the pool could not be read, leaving the other fields
+//     empty
+type TxPoolUpdate struct {
+	Added   []PoolTx
+	Relayed []PoolTx
+	Removed []string
+	Err     error
+}
+
+// empty reports whether the update carries nothing.
+func (u TxPoolUpdate) empty() bool {
+	return len(u.Added) == 0 && len(u.Relayed) == 0 && len(u.Removed) == 0 && u.Err == nil
+}
+
+// diffTxPool compares two pool snapshots keyed by hash. Transactions are
+// listed in the order the daemon received them.
+func diffTxPool(old, cur map[string]PoolTx) TxPoolUpdate {
+	var u TxPoolUpdate
+	for hash, tx := range cur {
+		prev, ok := old[hash]
+		switch {
+		case !ok:
+			u.Added = append(u.Added, tx)
+		case tx.Relayed && !prev.Relayed:
+			u.Relayed = append(u.Relayed, tx)
+		}
+	}
+	for hash := range old {
+		if _, ok := cur[hash]; !ok {
+			u.Removed = append(u.Removed, hash)
+		}
+	}
+	byReceipt := func(txs []PoolTx) func(i, j int) bool {
+		return func(i, j int) bool {
+			if !txs[i].ReceiveTime.Equal(txs[j].ReceiveTime) {
+				return txs[i].ReceiveTime.Before(txs[j].ReceiveTime)
+			}
+			return txs[i].Hash < txs[j].Hash
+		}
+	}
+	sort.Slice(u.Added, byReceipt(u.Added))
+	sort.Slice(u.Relayed, byReceipt(u.Relayed))
+	sort.Strings(u.Removed)
+	return u
+}
+
+// WatchTxPool polls the transaction pool and reports what changed, so
+// services can follow their own transactions from submission through
+// relay until they are mined.
+//
+// Parameters:
+//   - ctx: Context ending the watch
+//
+// Returns:
+//   - <-chan TxPoolUpdate: One update per poll that found changes, the
+//     first listing the transactions already in the pool. Failed polls
+//     carry Err. The channel is closed when ctx is done, or after the
+//     error update for a remote node
+//
+// A removed transaction was either mined or dropped; its confirmations
+// tell which.
+func (m *MoneroDaemon) WatchTxPool(ctx context.Context) <-chan TxPoolUpdate {
+	ch := make(chan TxPoolUpdate)
+	go func() {
+		defer close(ch)
+		var known map[string]PoolTx
+		for {
+			var u TxPoolUpdate
+			txs, err := m.TxPool(ctx)
+			if err != nil {
+				u.Err = err
+			} else {
+				cur := make(map[string]PoolTx, len(txs))
+				for _, tx := range txs {
+					cur[tx.Hash] = tx
+				}
+				u = diffTxPool(known, cur)
+				known = cur
+			}
+			if !u.empty() {
+				select {
+				case ch <- u:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if errors.GetKind(err) == errors.KindConfig {
+				return
+			}
+			sleepContext(ctx, txPoolPollInterval)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
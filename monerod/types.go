@@ -3,9 +3,15 @@
 package monerod
 
 import (
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"os"
 	"os/exec"
+	"sync"
 	"time"
 
+	"github.com/opd-ai/moneroger/rpc"
 	"github.com/opd-ai/moneroger/util"
 )
 
@@ -19,6 +25,30 @@ const (
 
 	// defaultShutdownTimeout is the maximum time to wait for graceful shutdown
 	defaultShutdownTimeout = 10 * time.Second
+
+	// adoptProbeTimeout bounds the get_info probe made against an existing daemon
+	adoptProbeTimeout = 2 * time.Second
+
+	// healthCheckTimeout bounds the get_info call made by CheckHealth
+	healthCheckTimeout = 5 * time.Second
+
+	// defaultOutPeers is monerod's default outgoing peer limit
+	defaultOutPeers = 12
+
+	// rpcLoginFile is the file under the data directory holding generated RPC credentials
+	rpcLoginFile = "monerod.rpc-login"
+
+	// optionsFile is the --config-file under the data directory passing secrets to monerod
+	optionsFile = "moneroger-monerod.conf"
+
+	// pidFileName is the file under the data directory holding the spawned daemon's process ID
+	pidFileName = "monerod.pid"
+
+	// logFileName is the file under the data directory receiving a detached daemon's output
+	logFileName = "monerod.log"
+
+	// readyPollInterval is the delay between ZMQ subscription attempts during readiness detection
+	readyPollInterval = 250 * time.Millisecond
 )
 
 // MoneroDaemon represents a running monerod instance and manages its lifecycle.
@@ -30,23 +60,121 @@ const (
 //   - rpcPort: Port number for RPC interface
 //   - rpcUser: Username for RPC authentication
 //   - rpcPass: Password for RPC authentication
-//   - testnet: Boolean flag for testnet operation
-//   - process: Reference to the running daemon process
+//   - network: Monero network, selecting the --testnet or --stagenet flag
+//   - process: The running daemon process, spawned or left running by an
+//     earlier manager
+//   - zmqPubPort: Port for the ZMQ publisher, 0 if disabled
+//   - restrictedPort: Port of the restricted RPC server, 0 if disabled
+//   - publicArgs: Flags of the public node profile, nil for a private node
+//   - options: Tuning flags such as the log level and peer limits
+//   - startWait, stopWait: Bounds on becoming ready and on
+//     exiting, the package defaults when 0
+//   - tor: Tor proxy and onion service settings
+//   - i2p: I2P proxy and inbound tunnel settings
+//   - bootstrap: Node serving wallet queries while the daemon syncs
+//   - bindIP: Address the RPC server binds to, loopback when empty
+//   - autoPort: Whether a free port replaces an RPC port taken by another program
+//   - portRange: Ports autoPort chooses from, any free port when empty
+//   - detach: Whether the process is spawned detached, logging to a file
+//   - driver: Runs the process, on the host or in a container
+//   - extraArgs: Flags appended to the generated command line
+//   - banList: Hosts banned over RPC once the daemon runs
+//   - tls: RPC server certificate, https when set
+//   - clientTLS: TLS settings for the daemon's own RPC client
+//   - adopted: Whether an already-running daemon was adopted instead of spawned
+//   - client: Lazily created JSON-RPC client for the daemon
+//   - stdout, stderr: Bounded capture of recent process output
+//   - logs: Combined output of both streams, kept across restarts
+//   - output: Optional sink receiving the full process output
+//   - logOutput: Whether process output is also logged at debug level
+//   - logger: Structured logger tagged with the component name
+//   - exit: Tracks termination of the spawned process
+//   - state: Lifecycle state, see State
+//   - mu: Guards cmd, process, exit, adopted, state and, once the daemon
+//     is configured, options
+//   - startMu: Serializes Start and Reconfigure
 //
-// The daemon can be configured for either mainnet or testnet operation,
+// The daemon can be configured for mainnet, testnet or stagenet operation,
 // with appropriate default ports and network settings applied automatically.
 type MoneroDaemon struct {
-	cmd           *exec.Cmd
-	dataDir       string
-	rpcPort       int
-	rpcUser       string
-	rpcPass       string
-	testnet       bool
-	useRemoteNode bool
+	cmd            *exec.Cmd
+	process        *os.Process
+	dataDir        string
+	rpcPort        int
+	rpcUser        string
+	rpcPass        string
+	network        util.Network
+	useRemoteNode  bool
+	zmqPubPort     int
+	restrictedPort int
+	publicArgs     []string
+	options        Options
+	startWait      time.Duration
+	stopWait       time.Duration
+	tor            util.TorConfig
+	i2p            util.I2PConfig
+	bootstrap      util.BootstrapConfig
+	bindIP         string
+	autoPort       bool
+	portRange      util.PortRange
+	detach         bool
+	driver         util.Driver
+	extraArgs      []string
+	banList        []string
+	tls            util.RPCTLS
+	clientTLS      *tls.Config
+	adopted        bool
+	client         *rpc.Client
+	clientOnce     sync.Once
+	stdout         *util.RingBuffer
+	stderr         *util.RingBuffer
+	logs           *util.RingBuffer
+	output         io.Writer
+	logOutput      bool
+	logger         *slog.Logger
+	exit           *util.ProcessExit
+	state          util.ServiceState
+	mu             sync.Mutex
+	startMu        sync.Mutex
+}
+
+// log returns the daemon's logger, falling back to slog.Default() for
+// instances not created through NewMoneroDaemon.
+func (m *MoneroDaemon) log() *slog.Logger {
+	if m.logger == nil {
+		return slog.Default()
+	}
+	return m.logger
+}
+
+// processDriver returns the daemon's driver, falling back to
+// util.LocalDriver for instances not created through NewMoneroDaemon.
+func (m *MoneroDaemon) processDriver() util.Driver {
+	if m.driver == nil {
+		return util.LocalDriver{}
+	}
+	return m.driver
+}
+
+// startupTimeout returns how long the daemon may take to become ready.
+func (m *MoneroDaemon) startupTimeout() time.Duration {
+	if m.startWait <= 0 {
+		return defaultStartupTimeout
+	}
+	return m.startWait
+}
+
+// shutdownTimeout returns how long the daemon may take to exit once
+// interrupted.
+func (m *MoneroDaemon) shutdownTimeout() time.Duration {
+	if m.stopWait <= 0 {
+		return defaultShutdownTimeout
+	}
+	return m.stopWait
 }
 
-// RPCPort returns the configured RPC port for the daemon.
-// If no port was explicitly set, returns the default port (18081 for mainnet).
+// RPCPort returns the RPC port of the daemon: the configured port, or the
+// free port chosen when Config.AutoPort found it taken.
 //
 // Returns:
 //   - int: The RPC port number
@@ -57,6 +185,15 @@ func (m *MoneroDaemon) RPCPort() int {
 	return m.rpcPort
 }
 
+// RestrictedPort returns the port of the daemon's restricted RPC server,
+// which answers the calls safe for the public without a login.
+//
+// Returns:
+//   - int: Port number, 0 when no restricted server is configured
+func (m *MoneroDaemon) RestrictedPort() int {
+	return m.restrictedPort
+}
+
 // RPCUser returns the RPC authentication username.
 // If no username was set, initializes it to the default "gouser".
 //
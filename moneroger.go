@@ -5,9 +5,15 @@ package moneroger
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
 
+	"github.com/opd-ai/moneroger/errors"
 	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
 	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/rpc"
 	"github.com/opd-ai/moneroger/util"
 )
 
@@ -18,62 +24,222 @@ import (
 // Fields:
 //   - monerod: The Monero daemon instance
 //   - monerowalletrpc: The wallet RPC service instance
+//   - config: Current configuration, replaced by Reload; guarded by configMu
+//   - cancel: Stops background tasks such as supervision and suspend/resume detection
+//   - events: Fans lifecycle events out to subscribers
+//   - log: Logger for manager-level diagnostics
+//   - bgCtx: Lifetime context for background tasks, cancelled by cancel
+//   - wallets: Additional wallets added with AddWallet, keyed by name
+//   - walletsClosed: Set by Shutdown so no wallets are added afterwards
+//   - daemonSup, walletSup: Supervision of monerod and the default wallet
+//   - reloadMu: Serializes Reload with wallet changes and shutdown
+//   - versions: Releases of the executables checked at startup
+//   - health: Outcome of the periodic health checks and their callbacks
+//   - notify: Block and transaction callbacks registered with OnBlock and OnTx
+//   - statusServer: HTTP server for Config.StatusAddress, nil when disabled
+//   - usage: Measures the CPU use of the services between Status calls
 //
 // The Moneroger instance maintains references to both services
 // and handles their coordination. It ensures the daemon is available
 // before starting the wallet service, and handles graceful shutdown
 // in the correct order.
 type Moneroger struct {
-	monerod         monerod.MoneroDaemon
-	monerowalletrpc monerowalletrpc.WalletRPC
+	monerod         *monerod.MoneroDaemon
+	monerowalletrpc *monerowalletrpc.WalletRPC
+	config          util.Config
+	configMu        sync.RWMutex
+	cancel          context.CancelFunc
+	events          eventBus
+	log             *slog.Logger
+	bgCtx           context.Context
+	walletsMu       sync.RWMutex
+	wallets         map[string]*managedWallet
+	walletsClosed   bool
+	tenantsMu       sync.RWMutex
+	tenants         map[string]*Tenant
+	walletTenants   map[string]string
+	daemonSup       *supervision
+	walletSup       *supervision
+	reloadMu        sync.Mutex
+	versions        Versions
+	health          healthMonitor
+	notify          notifyHooks
+	statusServer    *http.Server
+	usage           util.UsageMeter
 }
 
-// NewMoneroger creates a new instance managing both Monero services.
+// NewMoneroger creates a new instance managing both Monero services,
+// without a bound on startup. It is NewMonerogerContext with
+// context.Background().
 //
 // Parameters:
+//   - config: Configuration settings for both services
+//
+// Returns:
+//   - *Moneroger: Configured manager instance
+//   - error: Any error during setup
+//
+// Related:
+//   - NewMonerogerContext for the startup steps and errors
+func NewMoneroger(config util.Config) (*Moneroger, error) {
+	return NewMonerogerContext(context.Background(), config)
+}
+
+// NewMonerogerContext creates a new instance managing both Monero
+// services, giving up when ctx ends first.
+//
+// Parameters:
+//   - ctx: Context bounding startup. The processes outlive it once the
+//     manager is returned; ending it earlier stops those already started.
 //   - config: Configuration settings for both services including:
 //     DataDir: Base directory for blockchain and wallet data
-//     WalletFile: Path to wallet file
+//     WalletDir: Directory holding the wallet files
+//     WalletName: Wallet opened at startup, if any
 //     MoneroPort: Daemon RPC port
 //     WalletPort: Wallet RPC port
-//     TestNet: Network selection flag
+//     Network, TestNet: Network selection
 //
 // Returns:
 //   - *Moneroger: Configured manager instance
 //   - error: Any error during setup
 //
 // The function:
-// 1. Starts the Monero daemon
-// 2. Starts the wallet RPC service
-// 3. Returns a manager coordinating both services
+// 1. Validates the configuration with util.Config.Validate
+// 2. Checks the releases of the executables against config.Versions
+// 3. Stops wallet-rpc processes a crashed manager left running
+// 4. Starts the Monero daemon, removing a stale database lock first
+// 5. Starts the wallet RPC service
+// 6. Starts supervision, restarting crashed services and failing over remote nodes
+// 7. Starts periodic health checks (see OnHealthChange) and disk space monitoring
+// 8. Serves /healthz, /readyz and /status when StatusAddress is set
+// 9. Returns a manager coordinating both services
+//
+// A daemon started before the wallet fails is stopped again, so a failed
+// call leaves no processes behind other than an adopted daemon.
 //
 // Errors:
+//   - Configuration validation errors, all reported together
+//   - OpCheckVersions errors for executables too old or from different
+//     releases
+//   - OpStatusServer errors if StatusAddress cannot be bound
+//   - KindProcess errors if leftover processes cannot be stopped, and
+//     KindSystem errors if another monerod uses the data directory
 //   - Daemon startup failures
 //   - Wallet service startup failures
-//   - Configuration validation errors
+//   - KindTimeout errors wrapping ctx.Err() if ctx ended during startup
 //
 // Related:
 //   - monerod.NewMoneroDaemon
 //   - monerowalletrpc.NewWalletRPC
 //   - util.Config
-func NewMoneroger(config util.Config) (*Moneroger, error) {
-	ctx := context.Background()
+func NewMonerogerContext(ctx context.Context, config util.Config) (*Moneroger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	// The processes are bound to startCtx, which only ends with ctx
+	// while startup is in progress
+	startCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+
+	versions, err := checkVersions(startCtx, config, config.Log())
+	if err != nil {
+		return nil, startupError(ctx, err)
+	}
+	statusListener, err := listenStatus(config)
+	if err != nil {
+		return nil, startupError(ctx, err)
+	}
+
+	if err := stopStale(startCtx, config); err != nil {
+		closeListener(statusListener)
+		return nil, startupError(ctx, err)
+	}
+
 	// Start Monero daemon
-	daemon, err := monerod.NewMoneroDaemon(ctx, config)
+	daemon, err := monerod.NewMoneroDaemon(startCtx, config)
 	if err != nil {
-		return nil, err
+		closeListener(statusListener)
+		return nil, startupError(ctx, err)
 	}
 
 	// Start wallet RPC service
-	wallet, err := monerowalletrpc.NewWalletRPC(ctx, config, daemon)
+	wallet, err := monerowalletrpc.NewWalletRPC(startCtx, config, daemon)
 	if err != nil {
-		return nil, err
+		closeListener(statusListener)
+		abortStartup(config, daemon, nil)
+		return nil, startupError(ctx, err)
 	}
 
-	return &Moneroger{
-		monerod:         *daemon,
-		monerowalletrpc: *wallet,
-	}, nil
+	if !stop() {
+		// ctx ended just as startup completed
+		closeListener(statusListener)
+		abortStartup(config, daemon, wallet)
+		return nil, startupError(ctx, ctx.Err())
+	}
+	m := newManager(config, daemon, wallet)
+	m.versions = versions
+	m.serveStatus(statusListener, config.StatusToken)
+	return m, nil
+}
+
+// startupError reports a startup failure, as KindTimeout when ctx
+// ended first.
+func startupError(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	if err == ctx.Err() {
+		return errors.E(errors.OpStart, errors.KindTimeout, err)
+	}
+	return errors.E(errors.OpStart, errors.KindTimeout, fmt.Errorf("startup interrupted: %w: %v", ctx.Err(), err))
+}
+
+// stopStale stops the wallet-rpc processes a crashed manager left running
+// from the data directory. The wallet-rpc on the configured port is left
+// to NewWalletRPC, which adopts it if it answers. Nothing is stopped while
+// a running manager or detached services use the data directory.
+func stopStale(ctx context.Context, config util.Config) error {
+	instance, err := FindInstance(config.DataDir)
+	if err != nil || instance != nil {
+		return err
+	}
+	return monerowalletrpc.StopStale(ctx, config, config.EffectiveWalletPort())
+}
+
+// abortStartup stops the services of a failed startup, the wallet
+// first. An adopted daemon was running before and is left alone.
+func abortStartup(config util.Config, daemon *monerod.MoneroDaemon, wallet *monerowalletrpc.WalletRPC) {
+	var nodes []shutdownNode
+	if !daemon.Adopted() {
+		nodes = append(nodes, shutdownNode{name: errors.ComponentMonerod, svc: daemon})
+	}
+	if wallet != nil {
+		nodes = append(nodes, shutdownNode{name: DefaultWalletName, svc: wallet, dependsOn: []string{errors.ComponentMonerod}})
+	}
+	_ = stopInOrder(context.Background(), config.Log(), config.EffectiveShutdownTimeout(), nodes)
+}
+
+// newManager wraps running services in a manager and starts its
+// background tasks.
+func newManager(config util.Config, daemon *monerod.MoneroDaemon, wallet *monerowalletrpc.WalletRPC) *Moneroger {
+	bgCtx, cancel := context.WithCancel(context.Background())
+	m := &Moneroger{
+		monerod:         daemon,
+		monerowalletrpc: wallet,
+		config:          config,
+		cancel:          cancel,
+		log:             config.Log(),
+		bgCtx:           bgCtx,
+	}
+	m.startSupervisors(bgCtx)
+	go m.watchResume(bgCtx)
+	go m.watchRemoteNodes(bgCtx)
+	go m.watchBootstrap(bgCtx)
+	go m.watchHealth(bgCtx)
+	go m.watchDisk(bgCtx)
+	go m.watchAutosave(bgCtx)
+	go m.watchPayments(bgCtx)
+	return m
 }
 
 // start initializes both Monero services in the correct order.
@@ -97,7 +263,12 @@ func (m *Moneroger) Start(ctx context.Context) error {
 	if err := m.monerod.Start(ctx); err != nil {
 		return err
 	}
-	return m.monerowalletrpc.Start(ctx)
+	m.emit(EventDaemonStarted, errors.ComponentMonerod, nil)
+	if err := m.monerowalletrpc.Start(ctx); err != nil {
+		return err
+	}
+	m.emitWallet(EventWalletReady, DefaultWalletName, nil)
+	return nil
 }
 
 // Shutdown gracefully stops both Monero services in the correct order.
@@ -108,21 +279,70 @@ func (m *Moneroger) Start(ctx context.Context) error {
 // Returns:
 //   - error: Any error during shutdown sequence
 //
-// The method:
-// 1. Stops the wallet RPC service first
-// 2. Stops the Monero daemon after
-//
-// This order ensures proper cleanup and prevents wallet
-// errors due to daemon unavailability.
+// Services stop in dependency order: every wallet, including those
+// added with AddWallet, stops concurrently, and monerod is interrupted
+// only once all of them have exited, so no wallet loses its daemon while
+// saving. Each stage is bounded by Config.ShutdownTimeout. If a wallet
+// fails to stop, the daemon is left running and the error returned.
 //
 // Related:
 //   - WalletRPC.Shutdown
 //   - MoneroDaemon.Shutdown
 func (m *Moneroger) Shutdown(ctx context.Context) error {
-	if err := m.monerowalletrpc.Shutdown(ctx); err != nil {
+	m.emit(EventShutdownBegan, "", nil)
+	err := m.shutdown(ctx)
+	m.emit(EventShutdownComplete, "", err)
+	m.events.close()
+	return err
+}
+
+// shutdown stops background tasks and both services in order.
+func (m *Moneroger) shutdown(ctx context.Context) error {
+	// Wait for a running Reload, which replaces supervision handles
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.closeStatus(ctx)
+	config := m.currentConfig()
+	m.saveBeforeShutdown(ctx, config)
+	nodes := []shutdownNode{
+		{name: errors.ComponentMonerod, svc: m.monerod},
+		{name: DefaultWalletName, svc: m.monerowalletrpc, dependsOn: []string{errors.ComponentMonerod}},
+	}
+	for name, w := range m.closeWallets() {
+		nodes = append(nodes, shutdownNode{name: name, svc: w.rpc, dependsOn: []string{errors.ComponentMonerod}})
+	}
+	if err := stopInOrder(ctx, m.logger(), config.EffectiveShutdownTimeout(), nodes); err != nil {
 		return err
 	}
-	return m.monerod.Shutdown(ctx)
+	m.removeInstance()
+	return nil
+}
+
+// currentConfig returns the configuration most recently applied by
+// NewMoneroger or Reload.
+func (m *Moneroger) currentConfig() util.Config {
+	m.configMu.RLock()
+	defer m.configMu.RUnlock()
+	return m.config
+}
+
+// logger returns the manager's logger, slog.Default() if none was set.
+func (m *Moneroger) logger() *slog.Logger {
+	if m.log == nil {
+		return slog.Default()
+	}
+	return m.log
+}
+
+// backgroundContext returns the lifetime context for background tasks.
+func (m *Moneroger) backgroundContext() context.Context {
+	if m.bgCtx == nil {
+		return context.Background()
+	}
+	return m.bgCtx
 }
 
 func (m *Moneroger) MoneroDaemonPID() string {
@@ -132,3 +352,106 @@ func (m *Moneroger) MoneroDaemonPID() string {
 func (m *Moneroger) RPCWalletPID() string {
 	return m.monerowalletrpc.PID()
 }
+
+// DaemonRPCPort returns the port monerod's RPC server listens on, which
+// differs from Config.MoneroPort when Config.AutoPort chose a free port.
+func (m *Moneroger) DaemonRPCPort() int {
+	return m.monerod.RPCPort()
+}
+
+// WalletRPCPort returns the port the default wallet's RPC server listens
+// on, which differs from Config.WalletPort when Config.AutoPort chose a
+// free port.
+func (m *Moneroger) WalletRPCPort() int {
+	return m.monerowalletrpc.WalletRPCPort()
+}
+
+// WalletRPCStats reports request activity between this manager and the
+// wallet RPC service, so operators can see when the wallet is the bottleneck.
+//
+// Returns:
+//   - rpc.Stats: In-flight, queued and per-method latency statistics
+func (m *Moneroger) WalletRPCStats() rpc.Stats {
+	return m.monerowalletrpc.RPCStats()
+}
+
+// DaemonClient returns a typed JSON-RPC client for the managed monerod,
+// authenticated with the daemon's RPC credentials.
+func (m *Moneroger) DaemonClient() *monerod.Client {
+	return m.monerod.Client()
+}
+
+// WatchSync follows the managed daemon's blockchain synchronization
+// until it completes.
+//
+// Parameters:
+//   - ctx: Context ending the watch
+//
+// Returns:
+//   - <-chan monerod.SyncStatus: Progress updates, closed once synced
+//
+// Related:
+//   - monerod.MoneroDaemon.WatchSync
+func (m *Moneroger) WatchSync(ctx context.Context) <-chan monerod.SyncStatus {
+	return m.monerod.WatchSync(ctx)
+}
+
+// TxPool returns the transactions waiting in the managed daemon's pool.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//
+// Returns:
+//   - []monerod.PoolTx: Hash, fee, size and receive time of each
+//   - error: KindConfig with a remote node, otherwise RPC failures
+func (m *Moneroger) TxPool(ctx context.Context) ([]monerod.PoolTx, error) {
+	return m.monerod.TxPool(ctx)
+}
+
+// WatchTxPool follows changes to the managed daemon's transaction pool.
+//
+// Parameters:
+//   - ctx: Context ending the watch
+//
+// Returns:
+//   - <-chan monerod.TxPoolUpdate: Added, relayed and removed
+//     transactions, closed when ctx is done
+//
+// Related:
+//   - monerod.MoneroDaemon.WatchTxPool
+func (m *Moneroger) WatchTxPool(ctx context.Context) <-chan monerod.TxPoolUpdate {
+	return m.monerod.WatchTxPool(ctx)
+}
+
+// WalletClient returns a typed JSON-RPC client for the managed
+// monero-wallet-rpc, authenticated with the wallet's RPC credentials.
+func (m *Moneroger) WalletClient() *monerowalletrpc.Client {
+	return m.monerowalletrpc.Client()
+}
+
+// RecentLogs returns the most recent output of a managed child process,
+// for crash diagnostics and dashboards without full log streaming.
+//
+// Parameters:
+//   - component: errors.ComponentMonerod or errors.ComponentWalletRPC
+//
+// Returns:
+//   - string: Combined stdout and stderr lines, oldest first
+//   - error: KindConfig error for an unknown component
+//
+// Output survives restarts, so the lines leading up to a crash remain
+// available after the supervisor has restarted the process. Adopted
+// daemons were not spawned by the manager and have no output.
+func (m *Moneroger) RecentLogs(component string) (string, error) {
+	switch component {
+	case errors.ComponentMonerod:
+		return m.monerod.RecentLogs(), nil
+	case errors.ComponentWalletRPC:
+		return m.monerowalletrpc.RecentLogs(), nil
+	}
+	return "", errors.E(
+		errors.Op("RecentLogs"),
+		errors.KindConfig,
+		fmt.Errorf("unknown component %q", component),
+	)
+}
@@ -2,38 +2,34 @@ package moneroger
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	moneroconst "github.com/opd-ai/moneroger/const"
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/testutil"
 	"github.com/opd-ai/moneroger/util"
+	"github.com/opd-ai/moneroger/zmq"
 )
 
-// createTestConfig creates a test configuration with temporary directories
+// createTestConfig creates an isolated test configuration with temporary
+// directories, free ports and unique credentials
 func createTestConfig(t *testing.T) util.Config {
 	t.Helper()
-
-	// Create temporary directory for data
-	dataDir, err := os.MkdirTemp("", "moneroger-test-*")
-	if err != nil {
-		t.Fatal(err)
-	}
-	t.Cleanup(func() { os.RemoveAll(dataDir) })
-
-	// Create temporary wallet file
-	walletFile, err := os.CreateTemp(dataDir, "wallet-*.keys")
-	if err != nil {
-		t.Fatal(err)
-	}
-	walletFile.Close()
-
-	return util.Config{
-		DataDir:    dataDir,
-		WalletFile: walletFile.Name(),
-		MoneroPort: 18081,
-		WalletPort: 18082,
-		TestNet:    true, // Use testnet for tests
-	}
+	return testutil.Config(t)
 }
 
 // TestNewMoneroger tests the creation of a new Moneroger instance
@@ -90,6 +86,20 @@ func TestNewMoneroger(t *testing.T) {
 	}
 }
 
+// TestNewMonerogerContextCancelled verifies startup gives up once its
+// context has ended
+func TestNewMonerogerContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m, err := NewMonerogerContext(ctx, createTestConfig(t))
+	if m != nil {
+		t.Error("NewMonerogerContext() returned a manager for a cancelled context")
+	}
+	if errors.GetKind(err) != errors.KindTimeout {
+		t.Errorf("NewMonerogerContext() error = %v, want KindTimeout", err)
+	}
+}
+
 // TestStartupShutdownSequence tests the proper ordering of operations
 func TestStartupShutdownSequence(t *testing.T) {
 	config := createTestConfig(t)
@@ -163,3 +173,1019 @@ func TestConcurrentAccess(t *testing.T) {
 		t.Errorf("Concurrent Start() error = %v", err)
 	}
 }
+
+// fakeService is a supervised service whose process exits on demand
+type fakeService struct {
+	mu       sync.Mutex
+	exited   chan struct{}
+	starts   int
+	failNext int
+	stopping bool
+}
+
+func newFakeService() *fakeService {
+	return &fakeService{exited: make(chan struct{})}
+}
+
+func (f *fakeService) Start(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.starts++
+	if f.failNext > 0 {
+		f.failNext--
+		return fmt.Errorf("start failed")
+	}
+	f.exited = make(chan struct{})
+	return nil
+}
+
+func (f *fakeService) crash() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	close(f.exited)
+}
+
+func (f *fakeService) Exited() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.exited
+}
+
+func (f *fakeService) ExitErr() error        { return fmt.Errorf("exit status 1") }
+func (f *fakeService) Uptime() time.Duration { return 0 }
+func (f *fakeService) Stopping() bool        { return f.stopping }
+func (f *fakeService) startCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.starts
+}
+
+// TestSupervise verifies restart with backoff and giving up
+func TestSupervise(t *testing.T) {
+	policy := util.RestartPolicy{
+		MaxRetries:   3,
+		MinUptime:    time.Hour,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     5 * time.Millisecond,
+	}
+
+	t.Run("restarts crashed service", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		svc := newFakeService()
+		restarted := make(chan struct{}, 1)
+		go supervise(ctx, slog.Default(), svc, policy, nil, func(context.Context) { restarted <- struct{}{} })
+
+		svc.crash()
+		select {
+		case <-restarted:
+		case <-time.After(time.Second):
+			t.Fatal("service was not restarted")
+		}
+		if n := svc.startCount(); n != 1 {
+			t.Errorf("Start called %d times, want 1", n)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		svc := newFakeService()
+		svc.failNext = 100
+		done := make(chan struct{})
+		go func() {
+			supervise(ctx, slog.Default(), svc, policy, nil, nil)
+			close(done)
+		}()
+
+		svc.crash()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("supervisor did not give up")
+		}
+		if n := svc.startCount(); n != policy.MaxRetries {
+			t.Errorf("Start called %d times, want %d", n, policy.MaxRetries)
+		}
+	})
+
+	t.Run("ignores requested shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		svc := newFakeService()
+		svc.stopping = true
+		done := make(chan struct{})
+		go func() {
+			supervise(ctx, slog.Default(), svc, policy, nil, nil)
+			close(done)
+		}()
+
+		svc.crash()
+		<-done
+		if n := svc.startCount(); n != 0 {
+			t.Errorf("Start called %d times after intentional stop", n)
+		}
+	})
+}
+
+// TestRestartPolicyDelay verifies exponential backoff with a cap
+func TestRestartPolicyDelay(t *testing.T) {
+	p := util.DefaultRestartPolicy()
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	for i, w := range want {
+		if got := p.Delay(i); got != w {
+			t.Errorf("Delay(%d) = %v, want %v", i, got, w)
+		}
+	}
+	if got := p.Delay(20); got != p.MaxDelay {
+		t.Errorf("Delay(20) = %v, want cap %v", got, p.MaxDelay)
+	}
+}
+
+// stopRecorder is a stoppable service recording when it stopped
+type stopRecorder struct {
+	name   string
+	order  *[]string
+	mu     *sync.Mutex
+	exited chan struct{}
+	fail   bool
+}
+
+func newStopRecorder(name string, order *[]string, mu *sync.Mutex) *stopRecorder {
+	return &stopRecorder{name: name, order: order, mu: mu, exited: make(chan struct{})}
+}
+
+func (r *stopRecorder) Shutdown(ctx context.Context) error {
+	if r.fail {
+		return fmt.Errorf("%s failed to stop", r.name)
+	}
+	// Exit a moment later, as a process would
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.mu.Lock()
+		*r.order = append(*r.order, r.name)
+		r.mu.Unlock()
+		close(r.exited)
+	}()
+	return nil
+}
+
+func (r *stopRecorder) Exited() <-chan struct{} { return r.exited }
+
+// TestStopInOrder verifies dependents exit before the services they use,
+// and that a failed stage leaves later stages running
+func TestStopInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	daemon := newStopRecorder("monerod", &order, &mu)
+	nodes := []shutdownNode{
+		{name: "monerod", svc: daemon},
+		{name: "default", svc: newStopRecorder("default", &order, &mu), dependsOn: []string{"monerod"}},
+		{name: "shop", svc: newStopRecorder("shop", &order, &mu), dependsOn: []string{"monerod"}},
+	}
+	if err := stopInOrder(context.Background(), slog.Default(), time.Second, nodes); err != nil {
+		t.Fatalf("stopInOrder() error = %v", err)
+	}
+	if len(order) != 3 || order[2] != "monerod" {
+		t.Errorf("stop order = %v, want monerod last", order)
+	}
+
+	order = nil
+	daemon = newStopRecorder("monerod", &order, &mu)
+	failing := newStopRecorder("default", &order, &mu)
+	failing.fail = true
+	nodes = []shutdownNode{
+		{name: "monerod", svc: daemon},
+		{name: "default", svc: failing, dependsOn: []string{"monerod"}},
+	}
+	if err := stopInOrder(context.Background(), slog.Default(), time.Second, nodes); err == nil {
+		t.Error("stopInOrder() with a failing wallet should fail")
+	}
+	select {
+	case <-daemon.exited:
+		t.Error("monerod stopped although a wallet using it failed to stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cycle := []shutdownNode{
+		{name: "a", dependsOn: []string{"b"}},
+		{name: "b", dependsOn: []string{"a"}},
+	}
+	if _, err := shutdownStages(cycle); err == nil {
+		t.Error("shutdownStages() accepted a dependency cycle")
+	}
+}
+
+// TestSubscribeShutdownEvents verifies lifecycle events around Shutdown
+func TestSubscribeShutdownEvents(t *testing.T) {
+	m := &Moneroger{
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	events := m.Subscribe()
+	ignored := m.Subscribe()
+	m.Unsubscribe(ignored)
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	var got []EventType
+	for ev := range events {
+		got = append(got, ev.Type)
+	}
+	want := []EventType{EventShutdownBegan, EventShutdownComplete}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if _, ok := <-ignored; ok {
+		t.Error("unsubscribed channel should be closed")
+	}
+	if _, ok := <-m.Subscribe(); ok {
+		t.Error("Subscribe() after shutdown should return a closed channel")
+	}
+}
+
+// TestRecentLogsUnknownComponent verifies unknown components are rejected
+func TestRecentLogsUnknownComponent(t *testing.T) {
+	m := &Moneroger{
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	if logs, err := m.RecentLogs(errors.ComponentMonerod); err != nil || logs != "" {
+		t.Errorf("RecentLogs(monerod) = %q, %v; want empty output for an unstarted daemon", logs, err)
+	}
+	if _, err := m.RecentLogs("bitcoind"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("RecentLogs(unknown) error = %v, want KindConfig", err)
+	}
+}
+
+// TestWalletRegistry verifies wallet name handling without spawning processes
+func TestWalletRegistry(t *testing.T) {
+	ctx := context.Background()
+	m := &Moneroger{
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+
+	if w, err := m.Wallet(DefaultWalletName); err != nil || w != m.monerowalletrpc {
+		t.Errorf("Wallet(default) = %v, %v; want the default wallet", w, err)
+	}
+	if infos := m.Wallets(); len(infos) != 1 || infos[0].Name != DefaultWalletName {
+		t.Errorf("Wallets() = %+v, want only the default wallet", infos)
+	}
+
+	for _, name := range []string{"", DefaultWalletName} {
+		if _, err := m.AddWallet(ctx, WalletConfig{Name: name, WalletDir: t.TempDir(), Port: 1}); errors.GetKind(err) != errors.KindConfig {
+			t.Errorf("AddWallet(%q) error = %v, want KindConfig", name, err)
+		}
+	}
+	if _, err := m.AddWallet(ctx, WalletConfig{Name: "customer-1", Port: 1}); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("AddWallet() without a directory error = %v, want KindConfig", err)
+	}
+	if err := m.RemoveWallet(ctx, DefaultWalletName); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("RemoveWallet(default) error = %v, want KindConfig", err)
+	}
+	if _, err := m.Wallet("customer-1"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("Wallet(unknown) error = %v, want KindConfig", err)
+	}
+
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if _, err := m.AddWallet(ctx, WalletConfig{Name: "customer-1", WalletDir: t.TempDir(), Port: 1}); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("AddWallet() after Shutdown error = %v, want KindConfig", err)
+	}
+}
+
+// fakeWalletRPCEnv makes the test binary act as monero-wallet-rpc,
+// answering every JSON-RPC call on its --rpc-bind-port
+const fakeWalletRPCEnv = "MONEROGER_TEST_FAKE_WALLET_RPC"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeWalletRPCEnv) != "" {
+		fakeWalletRPC()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeWalletRPC serves get_version and any other call until killed.
+func fakeWalletRPC() {
+	var port string
+	for i, arg := range os.Args {
+		if arg == "--rpc-bind-port" && i+1 < len(os.Args) {
+			port = os.Args[i+1]
+		}
+	}
+	http.ListenAndServe("127.0.0.1:"+port, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{"version":65562}}`, req.ID)
+	}))
+	os.Exit(1)
+}
+
+// newFakeWalletManager returns a manager using a remote node whose
+// monero-wallet-rpc is the fake served by TestMain, and a free port for it
+func newFakeWalletManager(t *testing.T) (*Moneroger, int) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as monero-wallet-rpc")
+	}
+	binDir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\n%s=1 exec %q \"$@\"\n", fakeWalletRPCEnv, os.Args[0])
+	if err := os.WriteFile(filepath.Join(binDir, "monero-wallet-rpc"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	port, err := util.GetFreePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Moneroger{
+		config:          util.Config{DataDir: t.TempDir(), RemoteNode: "http://127.0.0.1:18081"},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+		wallets:         map[string]*managedWallet{},
+	}, port
+}
+
+// TestAddWalletOutlivesContext verifies a wallet keeps running after the
+// context bounding AddWallet ends
+func TestAddWalletOutlivesContext(t *testing.T) {
+	m, port := newFakeWalletManager(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	w, err := m.AddWallet(ctx, WalletConfig{Name: "customer-1", WalletDir: t.TempDir(), Port: port})
+	if err != nil {
+		t.Fatalf("AddWallet() error = %v", err)
+	}
+	defer m.Shutdown(context.Background())
+
+	<-ctx.Done()
+	select {
+	case <-w.Exited():
+		t.Fatal("wallet-rpc exited when the AddWallet context ended")
+	case <-time.After(500 * time.Millisecond):
+	}
+	if err := w.CheckHealth(context.Background()); err != nil {
+		t.Errorf("CheckHealth() after the context ended error = %v", err)
+	}
+}
+
+// TestAddTenantOutlivesContext verifies a tenant's wallet keeps running
+// after the context bounding AddTenant ends
+func TestAddTenantOutlivesContext(t *testing.T) {
+	m, port := newFakeWalletManager(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	tenant, err := m.AddTenant(ctx, TenantConfig{
+		ID:     "acme",
+		Wallet: WalletConfig{WalletDir: t.TempDir(), Port: port},
+	})
+	if err != nil {
+		t.Fatalf("AddTenant() error = %v", err)
+	}
+	defer m.Shutdown(context.Background())
+
+	<-ctx.Done()
+	w, err := m.Wallet(tenant.WalletName())
+	if err != nil {
+		t.Fatalf("Wallet() error = %v", err)
+	}
+	select {
+	case <-w.Exited():
+		t.Fatal("tenant wallet-rpc exited when the AddTenant context ended")
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// TestPlanReload verifies which services a configuration change restarts
+func TestPlanReload(t *testing.T) {
+	base := util.RecommendConfig(t.TempDir())
+	base.RemoteNode, base.RemoteNodes = "", nil
+
+	tests := []struct {
+		name   string
+		change func(c *util.Config)
+		want   reloadPlan
+	}{
+		{"unchanged", func(c *util.Config) {}, reloadPlan{}},
+		{"log level", func(c *util.Config) { c.DaemonLogLevel = 2 }, reloadPlan{tune: true}},
+		{"peer limits", func(c *util.Config) { c.OutPeers = 32 }, reloadPlan{tune: true}},
+		{"restart policy", func(c *util.Config) { c.Restart.MaxRetries = 9 }, reloadPlan{policy: true}},
+		{"db sync mode", func(c *util.Config) { c.DBSyncMode = "safe" }, reloadPlan{daemon: true, wallet: true, extraWallets: true}},
+		{"wallet port", func(c *util.Config) { c.WalletPort++ }, reloadPlan{wallet: true}},
+		{"wallet name", func(c *util.Config) { c.WalletName = "shop" }, reloadPlan{wallet: true}},
+		{"deprecated wallet directory", func(c *util.Config) { c.WalletFile = c.EffectiveWalletDir() }, reloadPlan{}},
+		{"wallet TLS", func(c *util.Config) { c.WalletTLS.CertFile = "cert.pem" }, reloadPlan{wallet: true, extraWallets: true}},
+		{"daemon port", func(c *util.Config) { c.MoneroPort++ }, reloadPlan{daemon: true, wallet: true, extraWallets: true}},
+		{"restricted port", func(c *util.Config) { c.RestrictedPort = 18089 }, reloadPlan{daemon: true, wallet: true, extraWallets: true}},
+		{"public node", func(c *util.Config) { c.PublicNode = true }, reloadPlan{daemon: true, wallet: true, extraWallets: true, tune: true}},
+		{"remote node", func(c *util.Config) { c.RemoteNode = "node.example.com:18089" }, reloadPlan{daemon: true, wallet: true, extraWallets: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := base
+			tt.change(&changed)
+			if got := planReload(base, changed); got != tt.want {
+				t.Errorf("planReload() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestReloadRejectsInvalidConfig verifies an invalid configuration is not applied
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	config := util.RecommendConfig(t.TempDir())
+	m := &Moneroger{
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+		config:          config,
+	}
+	events := m.Subscribe()
+
+	bad := config
+	bad.WalletPort = bad.MoneroPort
+	if err := m.Reload(context.Background(), bad); err == nil {
+		t.Fatal("Reload() with colliding ports should fail")
+	}
+	if m.currentConfig().WalletPort != config.WalletPort {
+		t.Error("Reload() applied an invalid configuration")
+	}
+	if ev := <-events; ev.Type != EventConfigReloaded || ev.Err == nil {
+		t.Errorf("event = %v, %v; want config-reloaded with an error", ev.Type, ev.Err)
+	}
+}
+
+// TestWaitForSync verifies wallets without an open wallet count as synced
+// and the wait respects its context
+func TestWaitForSync(t *testing.T) {
+	m := &Moneroger{
+		config:          util.Config{RemoteNode: "http://node.example.com:18081"},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+		wallets:         map[string]*managedWallet{},
+	}
+	if err := m.WaitForSync(context.Background()); err != nil {
+		t.Errorf("WaitForSync() with no wallet open error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.WaitForSync(ctx); errors.GetKind(err) != errors.KindTimeout {
+		t.Errorf("WaitForSync() with a done context error = %v, want KindTimeout", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	m := &Moneroger{
+		config:          util.Config{RemoteNode: "http://node.example.com:18081"},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+		wallets:         map[string]*managedWallet{},
+	}
+	status := m.Status(context.Background())
+	if status.Daemon.State != StateRemote {
+		t.Errorf("Daemon.State = %q, want %q", status.Daemon.State, StateRemote)
+	}
+	if len(status.Wallets) != 1 {
+		t.Fatalf("len(Wallets) = %d, want 1", len(status.Wallets))
+	}
+	w := status.Wallets[0]
+	if w.Name != DefaultWalletName || w.State != "unknown" || w.PID != 0 || w.WalletOpen {
+		t.Errorf("Wallets[0] = %+v, want never started %q without PID or open wallet", w, DefaultWalletName)
+	}
+	if w.Error == "" {
+		t.Error("Wallets[0].Error is empty for an unreachable wallet")
+	}
+	// The RPC statistics include the queries of the previous call
+	if stats := m.Status(context.Background()).Wallets[0].RPC; len(stats.Methods) == 0 {
+		t.Errorf("Wallets[0].RPC = %+v, want the previous get_version query", stats)
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	for _, key := range []string{"daemon", "wallets"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("JSON %s has no %q key", data, key)
+		}
+	}
+	if !strings.Contains(string(data), `"rpc":{"in_flight"`) {
+		t.Errorf("JSON %s has no RPC statistics", data)
+	}
+}
+
+// TestDetach verifies Detach requires detached services and Attach
+// requires a record of them.
+func TestDetach(t *testing.T) {
+	m := &Moneroger{
+		config:          util.Config{DataDir: t.TempDir()},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	if err := m.Detach(); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("Detach() without Config.Detach error = %v, want KindConfig", err)
+	}
+
+	dataDir := t.TempDir()
+	if _, err := Attach(util.Config{DataDir: dataDir}); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("Attach() without detached services error = %v, want KindProcess", err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, instanceFile), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Attach(util.Config{DataDir: dataDir}); errors.GetKind(err) != errors.KindSystem {
+		t.Errorf("Attach() with a corrupt record error = %v, want KindSystem", err)
+	}
+	if got := EventDetached.String(); got != "detached" {
+		t.Errorf("EventDetached.String() = %q, want %q", got, "detached")
+	}
+}
+
+// TestInstance verifies Register records the manager for FindInstance,
+// and records of managers that are gone are dropped.
+func TestInstance(t *testing.T) {
+	dataDir := t.TempDir()
+	m := &Moneroger{
+		config:          util.Config{DataDir: dataDir},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	if instance, err := FindInstance(dataDir); instance != nil || err != nil {
+		t.Fatalf("FindInstance() before Register = %+v, %v, want nil, nil", instance, err)
+	}
+	if err := m.Register(); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	instance, err := FindInstance(dataDir)
+	if err != nil || instance == nil {
+		t.Fatalf("FindInstance() = %+v, %v, want the registered manager", instance, err)
+	}
+	if instance.PID != os.Getpid() || instance.Detached() {
+		t.Errorf("FindInstance() = %+v, want PID %d", instance, os.Getpid())
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, managerPIDFile)); err != nil {
+		t.Errorf("manager PID file missing: %v", err)
+	}
+	m.removeInstance()
+
+	// A record of a manager that exited is stale
+	data, _ := json.Marshal(Instance{PID: 1 << 30, DaemonPort: 18081, WalletPort: 18083})
+	if err := os.WriteFile(filepath.Join(dataDir, instanceFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if instance, err := FindInstance(dataDir); instance != nil || err != nil {
+		t.Errorf("FindInstance() with a stale record = %+v, %v, want nil, nil", instance, err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, instanceFile)); !os.IsNotExist(err) {
+		t.Errorf("stale record not removed: %v", err)
+	}
+
+	ctx := context.Background()
+	config := util.Config{DataDir: dataDir}
+	if _, err := InspectInstance(ctx, config); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("InspectInstance() without an instance error = %v, want KindProcess", err)
+	}
+	if err := StopInstance(ctx, config); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("StopInstance() without an instance error = %v, want KindProcess", err)
+	}
+}
+
+// TestRestartRejects verifies Restart refuses components it cannot
+// restart before touching any process.
+func TestRestartRejects(t *testing.T) {
+	ctx := context.Background()
+	m := &Moneroger{
+		config:          util.Config{RemoteNode: "http://node.example.com:18081"},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	if err := m.Restart(ctx, "bitcoind"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("Restart(bitcoind) error = %v, want KindConfig", err)
+	}
+	if err := m.Restart(ctx, errors.ComponentMonerod); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("Restart(monerod) with remote nodes error = %v, want KindConfig", err)
+	}
+	m.walletsClosed = true
+	if err := m.Restart(ctx, errors.ComponentWalletRPC); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("Restart(wallet-rpc) after shutdown error = %v, want KindProcess", err)
+	}
+}
+
+// TestCheckVersions verifies executables older than the minimum or from
+// different releases are refused unless WarnOnly is set
+func TestCheckVersions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts")
+	}
+	binDir := t.TempDir()
+	t.Setenv(util.EnvPrefix+"_BIN_DIR", binDir)
+	install := func(name, version string) {
+		script := fmt.Sprintf("#!/bin/sh\necho \"Monero 'Fluorine Fermi' (v%s-release)\"\n", version)
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	install("monerod", "0.18.3.4")
+	install("monero-wallet-rpc", "0.18.3.4")
+
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config := util.Config{Versions: util.VersionPolicy{Minimum: "0.18.3.1"}}
+	versions, err := checkVersions(ctx, config, logger)
+	if err != nil || versions != (Versions{Daemon: "0.18.3.4", Wallet: "0.18.3.4"}) {
+		t.Errorf("checkVersions() = %+v, %v", versions, err)
+	}
+
+	install("monero-wallet-rpc", "0.18.2.0")
+	if _, err := checkVersions(ctx, config, logger); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("checkVersions() of an old wallet error = %v, want KindConfig", err)
+	}
+	config.Versions.WarnOnly = true
+	if _, err := checkVersions(ctx, config, logger); err != nil {
+		t.Errorf("checkVersions() with WarnOnly error = %v", err)
+	}
+
+	// Remote nodes need no monerod
+	config = util.Config{RemoteNode: "http://node.example.com:18081"}
+	install("monero-wallet-rpc", "0.18.3.4")
+	os.Remove(filepath.Join(binDir, "monerod"))
+	versions, err = checkVersions(ctx, config, logger)
+	if err != nil || versions != (Versions{Wallet: "0.18.3.4"}) {
+		t.Errorf("checkVersions() with a remote node = %+v, %v", versions, err)
+	}
+}
+
+// TestHealthChanges verifies only transitions between passing and failing
+// health checks are reported, to callbacks and subscribers alike
+func TestHealthChanges(t *testing.T) {
+	m := &Moneroger{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	events := m.Subscribe()
+	var got []EventType
+	remove := m.OnHealthChange(func(ev Event) {
+		got = append(got, ev.Type)
+	})
+
+	daemon := healthKey{component: errors.ComponentMonerod}
+	wallet := healthKey{component: errors.ComponentWalletRPC, wallet: DefaultWalletName}
+	failure := fmt.Errorf("not responding")
+	m.recordHealth(daemon, nil)
+	m.recordHealth(daemon, failure)
+	m.recordHealth(daemon, failure)
+	m.recordHealth(wallet, failure)
+	m.recordHealth(daemon, nil)
+	remove()
+	m.recordHealth(wallet, nil)
+
+	want := []EventType{EventServiceDegraded, EventServiceDegraded, EventServiceRecovered}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("callback events = %v, want %v", got, want)
+	}
+	for _, wantType := range append(want, EventServiceRecovered) {
+		if ev := <-events; ev.Type != wantType {
+			t.Errorf("subscriber event = %v, want %v", ev.Type, wantType)
+		}
+	}
+
+	if d := healthInterval(util.Config{}); d != moneroconst.DefaultHealthInterval {
+		t.Errorf("healthInterval() default = %v", d)
+	}
+	if d := healthInterval(util.Config{HealthInterval: -1}); d != 0 {
+		t.Errorf("healthInterval() of a negative interval = %v, want disabled", d)
+	}
+}
+
+// TestAutosaveInterval verifies the default and disabled wallet save
+// periods
+func TestAutosaveInterval(t *testing.T) {
+	if d := autosaveInterval(util.Config{}); d != moneroconst.DefaultAutosaveInterval {
+		t.Errorf("autosaveInterval() default = %v", d)
+	}
+	if d := autosaveInterval(util.Config{AutosaveInterval: -1}); d != 0 {
+		t.Errorf("autosaveInterval() of a negative interval = %v, want disabled", d)
+	}
+	if d := autosaveInterval(util.Config{AutosaveInterval: time.Minute}); d != time.Minute {
+		t.Errorf("autosaveInterval() = %v, want 1m", d)
+	}
+}
+
+// TestPayments verifies only payments arriving after the first poll are
+// reported, pool payments again once mined, and the event carrying them
+func TestPayments(t *testing.T) {
+	if d := paymentInterval(util.Config{}); d != moneroconst.DefaultPaymentInterval {
+		t.Errorf("paymentInterval() default = %v", d)
+	}
+	if d := paymentInterval(util.Config{PaymentInterval: -1}); d != 0 {
+		t.Errorf("paymentInterval() of a negative interval = %v, want disabled", d)
+	}
+
+	in := func(txid string, minor uint32, height uint64) monerowalletrpc.Transfer {
+		return monerowalletrpc.Transfer{
+			TxID:          txid,
+			Amount:        1000,
+			SubaddrIndex:  monerowalletrpc.SubaddrIndex{Minor: minor},
+			Height:        height,
+			Confirmations: 1,
+		}
+	}
+	pool := func(txid string, minor uint32) monerowalletrpc.Transfer {
+		return monerowalletrpc.Transfer{TxID: txid, Amount: 500, SubaddrIndex: monerowalletrpc.SubaddrIndex{Minor: minor}}
+	}
+	var watch paymentWatch
+	if got := watch.update(&monerowalletrpc.Transfers{In: []monerowalletrpc.Transfer{in("old", 0, 100)}}); len(got) != 0 {
+		t.Errorf("first update() = %+v, want the history unreported", got)
+	}
+	if watch.minHeight != 90 {
+		t.Errorf("minHeight = %d, want 90", watch.minHeight)
+	}
+	got := watch.update(&monerowalletrpc.Transfers{
+		In:   []monerowalletrpc.Transfer{in("old", 0, 100), in("new", 1, 105), in("new", 2, 105)},
+		Pool: []monerowalletrpc.Transfer{pool("mempool", 3)},
+	})
+	if len(got) != 3 || got[0].TxHash != "new" || got[1].Subaddress.Minor != 2 || got[2].TxHash != "mempool" || got[2].Confirmations != 0 {
+		t.Errorf("second update() = %+v, want two subaddress payments and a pool payment", got)
+	}
+	got = watch.update(&monerowalletrpc.Transfers{
+		In: []monerowalletrpc.Transfer{in("new", 1, 105), in("new", 2, 105), in("mempool", 3, 106)},
+	})
+	if len(got) != 1 || got[0].TxHash != "mempool" || got[0].Height != 106 || got[0].Confirmations != 1 {
+		t.Errorf("update() after mining = %+v, want the mined pool payment", got)
+	}
+	if got := watch.update(&monerowalletrpc.Transfers{In: []monerowalletrpc.Transfer{in("mempool", 3, 106)}}); len(got) != 0 {
+		t.Errorf("unchanged update() = %+v, want none", got)
+	}
+
+	m := &Moneroger{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	events := m.Subscribe()
+	m.emitPayment("shop", PaymentReceived{TxHash: "beef", Amount: 1000})
+	ev := <-events
+	if ev.Type != EventPaymentReceived || ev.Wallet != "shop" || ev.Payment == nil || ev.Payment.TxHash != "beef" {
+		t.Errorf("payment event = %+v", ev)
+	}
+}
+
+// TestNotify verifies block and transaction callbacks receive what each
+// poll adds, and removed callbacks nothing
+func TestNotify(t *testing.T) {
+	var p notifyPoll
+	if from, to := p.newBlocks(100); from != to {
+		t.Errorf("first newBlocks() = %d..%d, want none", from, to)
+	}
+	if from, to := p.newBlocks(103); from != 100 || to != 103 {
+		t.Errorf("newBlocks() = %d..%d, want 100..103", from, to)
+	}
+	if from, to := p.newBlocks(1000); from != 1000-notifyMaxBlocks || to != 1000 {
+		t.Errorf("newBlocks() far behind = %d..%d, want the last %d", from, to, notifyMaxBlocks)
+	}
+	if from, to := p.newBlocks(990); from != to || p.height != 990 {
+		t.Errorf("newBlocks() after a drop = %d..%d, want none", from, to)
+	}
+
+	if got := p.newTxs([]monerod.PoolTx{{Hash: "old"}}); len(got) != 0 {
+		t.Errorf("first newTxs() = %+v, want the pool unreported", got)
+	}
+	got := p.newTxs([]monerod.PoolTx{{Hash: "old"}, {Hash: "new", Fee: 30, Weight: 1500}})
+	if len(got) != 1 || got[0].ID != "new" || got[0].Fee != 30 || got[0].Weight != 1500 {
+		t.Errorf("newTxs() = %+v, want the new transaction", got)
+	}
+	if got := p.newTxs([]monerod.PoolTx{{Hash: "new"}}); len(got) != 0 {
+		t.Errorf("newTxs() after a removal = %+v, want none", got)
+	}
+
+	// A remote node stops the notification goroutine at once
+	m := &Moneroger{
+		config: util.Config{RemoteNode: "http://node.example.com:18081"},
+		log:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	var blocks []uint64
+	var txs []string
+	removeBlock := m.OnBlock(func(b zmq.Block) { blocks = append(blocks, b.Height) })
+	removeTx := m.OnTx(func(tx zmq.Tx) { txs = append(txs, tx.ID) })
+	m.notify.block(zmq.Block{Height: 7})
+	m.notify.tx(zmq.Tx{ID: "beef"})
+	removeBlock()
+	removeTx()
+	m.notify.block(zmq.Block{Height: 8})
+	m.notify.tx(zmq.Tx{ID: "cafe"})
+	if fmt.Sprint(blocks) != "[7]" || fmt.Sprint(txs) != "[beef]" {
+		t.Errorf("callbacks received blocks %v and transactions %v, want [7] and [beef]", blocks, txs)
+	}
+}
+
+// TestDiskWatch verifies each threshold is reported once until free space
+// recovers, and a fallback without remote nodes fails with KindConfig
+func TestDiskWatch(t *testing.T) {
+	var state diskState
+	gb := util.Gigabyte
+	if got := state.lowThresholds(30*gb, []int{50, 20}); fmt.Sprint(got) != "[50]" {
+		t.Errorf("lowThresholds(30 GB) = %v, want [50]", got)
+	}
+	if got := state.lowThresholds(10*gb, []int{50, 20}); fmt.Sprint(got) != "[20]" {
+		t.Errorf("lowThresholds(10 GB) = %v, want [20]", got)
+	}
+	if got := state.lowThresholds(60*gb, []int{50, 20}); len(got) != 0 {
+		t.Errorf("lowThresholds(60 GB) = %v, want none", got)
+	}
+	if got := state.lowThresholds(40*gb, []int{50, 20}); fmt.Sprint(got) != "[50]" {
+		t.Errorf("lowThresholds() after recovery = %v, want [50]", got)
+	}
+	if !state.fallbackDue(5*gb, 10) || state.fallbackDue(5*gb, 10) {
+		t.Error("fallbackDue() should report once below the threshold")
+	}
+	if state.fallbackDue(15*gb, 10) || !state.fallbackDue(5*gb, 10) {
+		t.Error("fallbackDue() should re-arm after recovery")
+	}
+
+	// No disk has this much space, so every threshold is crossed
+	m := &Moneroger{
+		config: util.Config{
+			DataDir:   t.TempDir(),
+			Network:   util.NetworkTestnet,
+			DiskWatch: util.DiskWatchConfig{WarnGB: []int{1 << 30}, FallbackGB: 1 << 30},
+		},
+		log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	events := m.Subscribe()
+	m.checkDisk(context.Background(), &diskState{})
+	if ev := <-events; ev.Type != EventDiskSpaceLow || ev.Err == nil {
+		t.Errorf("first event = %v, %v; want disk-space-low", ev.Type, ev.Err)
+	}
+	if ev := <-events; ev.Type != EventDiskFallback || errors.GetKind(ev.Err) != errors.KindConfig {
+		t.Errorf("second event = %v, %v; want disk-fallback with KindConfig", ev.Type, ev.Err)
+	}
+}
+
+// TestTenants verifies tenant lookup by credentials, quota enforcement
+// and that tenants only receive the events of their own wallet
+func TestTenants(t *testing.T) {
+	m := &Moneroger{log: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	dir := t.TempDir()
+	acme := &Tenant{m: m, id: "acme", user: "acme", pass: "secret", wallet: "tenant-acme", walletDir: dir,
+		quota: TenantQuota{CallsPerMinute: 2, MaxWallets: 1}}
+	other := &Tenant{m: m, id: "other", user: "other", pass: "secret", wallet: "tenant-other"}
+	for _, tenant := range []*Tenant{acme, other} {
+		if err := m.reserveTenant(tenant); err != nil {
+			t.Fatalf("reserveTenant(%s) error = %v", tenant.id, err)
+		}
+	}
+	if err := m.reserveTenant(&Tenant{id: "copy", user: "acme"}); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("reserveTenant() with a taken user error = %v, want KindConfig", err)
+	}
+	if got := m.Tenants(); fmt.Sprint(got) != "[acme other]" {
+		t.Errorf("Tenants() = %v", got)
+	}
+	if got, err := m.AuthenticateTenant("acme", "secret"); err != nil || got != acme {
+		t.Errorf("AuthenticateTenant() = %v, %v; want acme", got, err)
+	}
+	if _, err := m.AuthenticateTenant("acme", "wrong"); errors.GetKind(err) != errors.KindAuth {
+		t.Errorf("AuthenticateTenant() with a wrong password error = %v, want KindAuth", err)
+	}
+
+	if _, err := acme.route("stop_wallet"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("route(stop_wallet) error = %v, want KindConfig", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "shop.keys"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acme.route("create_wallet"); errors.GetKind(err) != errors.KindQuota {
+		t.Errorf("route(create_wallet) at MaxWallets error = %v, want KindQuota", err)
+	}
+	// The wallet is not running, so calls within the rate fail with KindConfig
+	if _, err := acme.route("get_balance"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("route() within the call rate error = %v, want KindConfig", err)
+	}
+	_, err := acme.route("get_balance")
+	if errors.GetKind(err) != errors.KindQuota || !errors.IsRetryable(err) {
+		t.Errorf("route() over the call rate error = %v, want transient KindQuota", err)
+	}
+	if !acme.allow(time.Now().Add(time.Minute)) {
+		t.Error("allow() refused a call in the next minute")
+	}
+
+	events := acme.Subscribe()
+	m.emitWallet(EventWalletCrashed, other.wallet, nil)
+	m.emitWallet(EventWalletCrashed, acme.wallet, nil)
+	if ev := <-events; ev.Tenant != "acme" || ev.Wallet != acme.wallet {
+		t.Errorf("tenant event = %+v, want acme's wallet", ev)
+	}
+	m.releaseTenant(acme)
+	if _, err := m.Tenant("acme"); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("Tenant() after release error = %v, want KindConfig", err)
+	}
+}
+
+// TestProbes verifies liveness only needs running processes, while
+// readiness needs a synchronized daemon and refreshed wallets
+func TestProbes(t *testing.T) {
+	running := util.StateRunning.String()
+	status := &Status{
+		Daemon: DaemonStatus{State: util.StateStarting.String()},
+		Wallets: []WalletStatus{
+			{Name: DefaultWalletName, State: running, WalletOpen: true, Height: 100},
+		},
+	}
+	if err := status.Live(); err != nil {
+		t.Errorf("Live() of a starting daemon error = %v", err)
+	}
+	if err := status.Ready(); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("Ready() of a starting daemon error = %v, want KindProcess", err)
+	}
+	status.Daemon = DaemonStatus{State: running, Height: 100, TargetHeight: 100, Synchronized: true}
+	if err := status.Ready(); err == nil {
+		t.Error("Ready() accepted a refreshing wallet")
+	}
+	status.Wallets[0].Refreshed = true
+	if err := status.Ready(); err != nil {
+		t.Errorf("Ready() error = %v", err)
+	}
+	status.Daemon.State = util.StateCrashed.String()
+	if err := status.Live(); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("Live() of a crashed daemon error = %v, want KindProcess", err)
+	}
+	status.Daemon.State = StateRemote
+	if err := status.Live(); err != nil {
+		t.Errorf("Live() with a remote node error = %v", err)
+	}
+
+	m := &Moneroger{
+		config:          util.Config{RemoteNode: "http://node.example.com:18081"},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	if err := m.Live(); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("Live() of an unstarted wallet error = %v, want KindProcess", err)
+	}
+	if err := m.Ready(context.Background()); errors.GetKind(err) != errors.KindProcess {
+		t.Errorf("Ready() of an unstarted wallet error = %v, want KindProcess", err)
+	}
+}
+
+// TestStatusHandler verifies the probe and status endpoints
+func TestStatusHandler(t *testing.T) {
+	m := &Moneroger{
+		config:          util.Config{RemoteNode: "http://node.example.com:18081"},
+		monerod:         &monerod.MoneroDaemon{},
+		monerowalletrpc: &monerowalletrpc.WalletRPC{},
+	}
+	server := httptest.NewServer(m.StatusHandler())
+	defer server.Close()
+
+	for path, want := range map[string]int{
+		"/healthz": http.StatusServiceUnavailable,
+		"/readyz":  http.StatusServiceUnavailable,
+		"/status":  http.StatusOK,
+		"/metrics": http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if path == "/status" {
+			var status Status
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil || status.Daemon.State != StateRemote {
+				t.Errorf("GET /status = %+v, %v", status, err)
+			}
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+	resp, err := http.Post(server.URL+"/readyz", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /readyz status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	secured := httptest.NewServer(requireToken("0123456789abcdef", m.StatusHandler()))
+	defer secured.Close()
+	for token, want := range map[string]int{
+		"":                 http.StatusUnauthorized,
+		"wrong-token-here": http.StatusUnauthorized,
+		"0123456789abcdef": http.StatusOK,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, secured.URL+"/status", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET /status with token %q status = %d, want %d", token, resp.StatusCode, want)
+		}
+	}
+}
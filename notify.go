@@ -0,0 +1,284 @@
+package moneroger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+	"github.com/opd-ai/moneroger/zmq"
+)
+
+const (
+	// notifyPollInterval is how often blocks and pool transactions are
+	// polled for when the ZMQ publisher is disabled
+	notifyPollInterval = 5 * time.Second
+
+	// notifyMaxBlocks bounds the blocks reported by one poll, so a daemon
+	// catching up does not flood the callbacks; older ones are skipped
+	notifyMaxBlocks = 100
+)
+
+// BlockFunc receives new main-chain blocks, the Go counterpart of
+// monerod's --block-notify. It is called from the notification
+// goroutine, so it should return quickly.
+type BlockFunc func(block zmq.Block)
+
+// TxFunc receives transactions entering the daemon's pool, the Go
+// counterpart of a --tx-notify hook. It is called from the notification
+// goroutine, so it should return quickly.
+type TxFunc func(tx zmq.Tx)
+
+// notifyHooks holds the callbacks registered with OnBlock and OnTx.
+// The zero value is ready to use.
+//
+// Fields:
+//   - blocks, txs: Registered callbacks by ID
+//   - nextID: ID of the next registered callback
+//   - started: Whether the notification goroutine runs
+type notifyHooks struct {
+	mu      sync.Mutex
+	blocks  map[int]BlockFunc
+	txs     map[int]TxFunc
+	nextID  int
+	started bool
+}
+
+// OnBlock registers fn to be called for every new main-chain block,
+// replacing a --block-notify script.
+//
+// Parameters:
+//   - fn: Callback receiving the height and hash of each block
+//
+// Returns:
+//   - func(): Removes the callback
+//
+// Blocks come from the daemon's ZMQ publisher as they are added. With
+// ZMQPubPort set to 0 the daemon is polled every five seconds instead.
+// Remote nodes are not watched.
+//
+// Example:
+//
+//	remove := manager.OnBlock(func(b zmq.Block) {
+//	    log.Printf("block %d: %s", b.Height, b.ID)
+//	})
+//	defer remove()
+func (m *Moneroger) OnBlock(fn BlockFunc) func() {
+	h := &m.notify
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.blocks == nil {
+		h.blocks = make(map[int]BlockFunc)
+	}
+	id := h.nextID
+	h.nextID++
+	h.blocks[id] = fn
+	m.startNotify()
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.blocks, id)
+	}
+}
+
+// OnTx registers fn to be called for every transaction entering the
+// daemon's pool.
+//
+// Parameters:
+//   - fn: Callback receiving the hash, size, weight and fee
+//
+// Returns:
+//   - func(): Removes the callback
+//
+// Transactions are delivered like OnBlock's blocks. Payments to the
+// managed wallets, which wallet-rpc reports to --tx-notify, are
+// EventPaymentReceived events instead.
+func (m *Moneroger) OnTx(fn TxFunc) func() {
+	h := &m.notify
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.txs == nil {
+		h.txs = make(map[int]TxFunc)
+	}
+	id := h.nextID
+	h.nextID++
+	h.txs[id] = fn
+	m.startNotify()
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.txs, id)
+	}
+}
+
+// startNotify starts the notification goroutine for the first callback.
+// Caller holds m.notify.mu.
+func (m *Moneroger) startNotify() {
+	if m.notify.started {
+		return
+	}
+	m.notify.started = true
+	go m.watchNotify(m.backgroundContext())
+}
+
+// callbacks returns the registered callbacks, so they run without the lock.
+func (h *notifyHooks) callbacks() ([]BlockFunc, []TxFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	blocks := make([]BlockFunc, 0, len(h.blocks))
+	for _, fn := range h.blocks {
+		blocks = append(blocks, fn)
+	}
+	txs := make([]TxFunc, 0, len(h.txs))
+	for _, fn := range h.txs {
+		txs = append(txs, fn)
+	}
+	return blocks, txs
+}
+
+// block delivers a block to every block callback.
+func (h *notifyHooks) block(b zmq.Block) {
+	blocks, _ := h.callbacks()
+	for _, fn := range blocks {
+		fn(b)
+	}
+}
+
+// tx delivers a transaction to every transaction callback.
+func (h *notifyHooks) tx(tx zmq.Tx) {
+	_, txs := h.callbacks()
+	for _, fn := range txs {
+		fn(tx)
+	}
+}
+
+// watchNotify feeds the callbacks from the ZMQ publisher, or by polling
+// when it is disabled, until ctx is done.
+//
+// Parameters:
+//   - ctx: Manager lifetime context
+func (m *Moneroger) watchNotify(ctx context.Context) {
+	if len(m.currentConfig().RemoteNodeList()) > 0 {
+		m.logger().Warn("block and transaction callbacks need a local daemon; none will be called")
+		return
+	}
+	n, err := m.monerod.Notifications(ctx)
+	if err != nil {
+		m.logger().Debug("ZMQ notifications unavailable, polling the daemon", "error", err)
+		m.pollNotify(ctx)
+		return
+	}
+	blocks, txs := n.Blocks, n.Txs
+	for blocks != nil || txs != nil {
+		select {
+		case b, ok := <-blocks:
+			if !ok {
+				blocks = nil
+				continue
+			}
+			m.notify.block(b)
+		case tx, ok := <-txs:
+			if !ok {
+				txs = nil
+				continue
+			}
+			m.notify.tx(tx)
+		}
+	}
+}
+
+// notifyPoll remembers what the polling fallback has reported.
+//
+// Fields:
+//   - height: Daemon height at the last poll, 0 before the first
+//   - pool: Hashes in the pool at the last poll, nil before the first
+type notifyPoll struct {
+	height uint64
+	pool   map[string]bool
+}
+
+// pollNotify polls the daemon for new blocks and pool transactions until
+// ctx is done. What exists at the first poll is not reported, as ZMQ
+// would not report it either.
+func (m *Moneroger) pollNotify(ctx context.Context) {
+	var p notifyPoll
+	for {
+		m.pollNotifyOnce(ctx, &p)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(util.Jitter(notifyPollInterval, 0.1)):
+		}
+	}
+}
+
+// newBlocks records the daemon height of a poll and returns the heights
+// of the blocks added since the previous one, from up to but excluding
+// to. The first poll, and one after the height dropped, report none.
+func (p *notifyPoll) newBlocks(height uint64) (from, to uint64) {
+	prev := p.height
+	p.height = height
+	if prev == 0 || height <= prev {
+		return height, height
+	}
+	// Heights count blocks, so the newest block is at height-1
+	from = prev
+	if height-from > notifyMaxBlocks {
+		from = height - notifyMaxBlocks
+	}
+	return from, height
+}
+
+// newTxs records the pool contents of a poll and returns the transactions
+// added since the previous one. The first poll reports none.
+func (p *notifyPoll) newTxs(txs []monerod.PoolTx) []zmq.Tx {
+	var added []zmq.Tx
+	pool := make(map[string]bool, len(txs))
+	for _, tx := range txs {
+		pool[tx.Hash] = true
+		if p.pool != nil && !p.pool[tx.Hash] {
+			added = append(added, zmq.Tx{ID: tx.Hash, BlobSize: tx.BlobSize, Weight: tx.Weight, Fee: tx.Fee})
+		}
+	}
+	p.pool = pool
+	return added
+}
+
+// pollNotifyOnce reports the blocks and pool transactions that appeared
+// since the last poll. Failures are logged at debug level; the next poll
+// catches up.
+func (m *Moneroger) pollNotifyOnce(ctx context.Context, p *notifyPoll) {
+	blockFns, txFns := m.notify.callbacks()
+	if len(blockFns) == 0 && len(txFns) == 0 {
+		// Nothing to report to; start over when callbacks are added
+		*p = notifyPoll{}
+		return
+	}
+	client := m.DaemonClient()
+
+	if height, err := client.GetHeight(ctx); err != nil {
+		m.logger().Debug("failed to poll for blocks", "error", err)
+	} else {
+		from, to := p.newBlocks(height)
+		for h := from; h < to; h++ {
+			header, err := client.GetBlockHeaderByHeight(ctx, h)
+			if err != nil {
+				m.logger().Debug("failed to read new block", "height", h, "error", err)
+				// Resume from this block at the next poll
+				p.height = h
+				break
+			}
+			m.notify.block(zmq.Block{Height: h, ID: header.Hash})
+		}
+	}
+
+	txs, err := client.GetTransactionPool(ctx)
+	if err != nil {
+		m.logger().Debug("failed to poll the transaction pool", "error", err)
+		return
+	}
+	for _, tx := range p.newTxs(txs) {
+		m.notify.tx(tx)
+	}
+}
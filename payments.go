@@ -0,0 +1,189 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	moneroconst "github.com/opd-ai/moneroger/const"
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// paymentReorgDepth is how many blocks below the newest payment seen are
+// queried again, so payments moved by a chain reorganization are not
+// missed
+const paymentReorgDepth = 10
+
+// PaymentReceived is an incoming transfer reported by
+// EventPaymentReceived. A transaction paying several subaddresses of the
+// wallet is reported once per subaddress.
+//
+// Fields:
+//   - TxHash: Hash of the paying transaction
+//   - Amount: Amount received by the subaddress
+//   - Address: The receiving subaddress
+//   - Subaddress: Account and index of the receiving subaddress
+//   - PaymentID: Payment ID of integrated addresses, empty or all zeros
+//     otherwise
+//   - Height: Block containing the transaction, 0 while in the pool
+//   - Confirmations: Blocks on top of and including Height, 0 while in
+//     the pool
+//   - Timestamp: Block time, or when the wallet saw a pool transaction
+type PaymentReceived struct {
+	TxHash        string
+	Amount        monerowalletrpc.Piconero
+	Address       string
+	Subaddress    monerowalletrpc.SubaddrIndex
+	PaymentID     string
+	Height        uint64
+	Confirmations uint64
+	Timestamp     time.Time
+}
+
+// paymentInterval returns the configured payment polling period, 0 when
+// polling is disabled.
+func paymentInterval(config util.Config) time.Duration {
+	switch {
+	case config.PaymentInterval < 0:
+		return 0
+	case config.PaymentInterval == 0:
+		return moneroconst.DefaultPaymentInterval
+	}
+	return config.PaymentInterval
+}
+
+// paymentWatch remembers the incoming transfers of one wallet service.
+//
+// Fields:
+//   - file: Wallet file the transfers belong to; opening another file
+//     starts over
+//   - seen: Height of each transfer seen, 0 for pool transfers, keyed by
+//     paymentKey
+//   - minHeight: Lowest block height queried
+//   - ready: Whether the transfers present at the start were recorded,
+//     so that only later ones are reported
+type paymentWatch struct {
+	file      string
+	seen      map[string]uint64
+	minHeight uint64
+	ready     bool
+}
+
+// paymentKey identifies the transfer of one transaction to one subaddress.
+func paymentKey(t monerowalletrpc.Transfer) string {
+	return fmt.Sprintf("%s/%d/%d", t.TxID, t.SubaddrIndex.Major, t.SubaddrIndex.Minor)
+}
+
+// update records the transfers of a poll and returns the payments to
+// report: transfers not seen before, and pool transfers that were mined.
+// Nothing is reported by the first update, which records the history.
+func (p *paymentWatch) update(t *monerowalletrpc.Transfers) []PaymentReceived {
+	var payments []PaymentReceived
+	seen := make(map[string]uint64, len(t.In)+len(t.Pool))
+	record := func(tr monerowalletrpc.Transfer) {
+		key := paymentKey(tr)
+		if _, dup := seen[key]; dup {
+			return
+		}
+		seen[key] = tr.Height
+		prev, known := p.seen[key]
+		if p.ready && (!known || (prev == 0 && tr.Height > 0)) {
+			payments = append(payments, PaymentReceived{
+				TxHash:        tr.TxID,
+				Amount:        tr.Amount,
+				Address:       tr.Address,
+				Subaddress:    tr.SubaddrIndex,
+				PaymentID:     tr.PaymentID,
+				Height:        tr.Height,
+				Confirmations: tr.Confirmations,
+				Timestamp:     tr.Timestamp,
+			})
+		}
+		if tr.Height > paymentReorgDepth && tr.Height-paymentReorgDepth > p.minHeight {
+			p.minHeight = tr.Height - paymentReorgDepth
+		}
+	}
+	// A transaction mined between the two lists counts as mined
+	for _, tr := range t.In {
+		record(tr)
+	}
+	for _, tr := range t.Pool {
+		record(tr)
+	}
+	p.seen, p.ready = seen, true
+	return payments
+}
+
+// watchPayments reports incoming payments to the open wallets as
+// EventPaymentReceived at the configured interval. Payments already in a
+// wallet when it is first seen open are history and not reported.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchPayments(ctx context.Context) {
+	interval := paymentInterval(m.currentConfig())
+	if interval == 0 {
+		return
+	}
+	watches := make(map[string]*paymentWatch)
+	for {
+		m.pollPayments(ctx, watches)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(util.Jitter(interval, 0.1)):
+		}
+	}
+}
+
+// pollPayments queries the recent incoming transfers of every running
+// wallet service with an open wallet and emits the new payments.
+// Failures are logged at debug level; the next poll tries again.
+func (m *Moneroger) pollPayments(ctx context.Context, watches map[string]*paymentWatch) {
+	present := make(map[string]bool)
+	m.eachWallet(func(name string, w *monerowalletrpc.WalletRPC) {
+		present[name] = true
+		file := w.CurrentWallet()
+		if w.State() != util.StateRunning || file == "" {
+			return
+		}
+		watch := watches[name]
+		if watch == nil || watch.file != file {
+			watch = &paymentWatch{file: file}
+			watches[name] = watch
+		}
+		transfers, err := w.GetTransfers(ctx, monerowalletrpc.TransferFilter{
+			In:          true,
+			Pool:        true,
+			MinHeight:   watch.minHeight,
+			AllAccounts: true,
+		})
+		if err != nil {
+			m.logger().Debug("failed to check for payments", "wallet", name, "error", err)
+			return
+		}
+		for _, p := range watch.update(transfers) {
+			m.emitPayment(name, p)
+		}
+	})
+	for name := range watches {
+		if !present[name] {
+			delete(watches, name)
+		}
+	}
+}
+
+// emitPayment publishes an EventPaymentReceived for the named wallet.
+func (m *Moneroger) emitPayment(name string, p PaymentReceived) {
+	m.logger().Info("payment received", "wallet", name, "tx_hash", p.TxHash,
+		"amount", p.Amount, "confirmations", p.Confirmations)
+	m.events.publish(Event{
+		Type:      EventPaymentReceived,
+		Component: errors.ComponentWalletRPC,
+		Wallet:    name,
+		Tenant:    m.walletTenant(name),
+		Payment:   &p,
+	})
+}
@@ -0,0 +1,369 @@
+// Package payments tracks payment requests: a fresh subaddress per
+// request, watched until the expected amount is received and confirmed,
+// or the request expires.
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+)
+
+const (
+	// DefaultConfirmations is the confirmation threshold when
+	// Options.Confirmations is 0, the depth at which Monero unlocks
+	// received outputs
+	DefaultConfirmations = 10
+
+	// DefaultPollInterval is how often pending requests are checked when
+	// Options.PollInterval is 0
+	DefaultPollInterval = 10 * time.Second
+
+	// addressLabel labels the subaddresses created for requests
+	addressLabel = "payment request"
+)
+
+// Operation names for payment request errors
+const (
+	opNewRequest = errors.Op("Payments.NewRequest")
+	opPoll       = errors.Op("Payments.Poll")
+	opWait       = errors.Op("Request.Wait")
+)
+
+// Status is the state of a payment request.
+type Status uint8
+
+// Payment request states
+const (
+	StatusPending   Status = iota // Waiting for funds or confirmations
+	StatusPaid                    // The amount was received and confirmed
+	StatusExpired                 // Nothing was received before the deadline
+	StatusUnderpaid               // Less than the amount was received before the deadline
+)
+
+// String returns a human-readable name for the status.
+func (s Status) String() string {
+	switch s {
+	case StatusPending:
+		return "pending"
+	case StatusPaid:
+		return "paid"
+	case StatusExpired:
+		return "expired"
+	case StatusUnderpaid:
+		return "underpaid"
+	default:
+		return "unknown"
+	}
+}
+
+// Final reports whether the request has been resolved and is no longer
+// watched.
+func (s Status) Final() bool {
+	return s != StatusPending
+}
+
+// Wallet is the wallet payment requests are made for.
+// *monerowalletrpc.Client implements it.
+type Wallet interface {
+	CreateAddress(ctx context.Context, accountIndex uint32, label string) (*monerowalletrpc.NewAddress, error)
+	GetTransfers(ctx context.Context, params monerowalletrpc.GetTransfersParams) (*monerowalletrpc.Transfers, error)
+}
+
+// Options configures a Tracker.
+//
+// Fields:
+//   - Account: Wallet account the subaddresses are created in
+//   - Confirmations: Confirmations a payment needs to count as paid,
+//     DefaultConfirmations when 0
+//   - PollInterval: How often pending requests are checked,
+//     DefaultPollInterval when 0
+type Options struct {
+	Account       uint32
+	Confirmations uint64
+	PollInterval  time.Duration
+}
+
+// Tracker creates payment requests for a wallet and watches them until
+// they are resolved. All pending requests are checked with one
+// get_transfers call per poll.
+//
+// Fields:
+//   - wallet: Wallet receiving the payments
+//   - opts: Account, confirmation threshold and poll interval
+//   - mu: Guards pending and err
+//   - pending: Unresolved requests by subaddress index
+//   - err: Error of the last poll, nil if it succeeded
+type Tracker struct {
+	wallet  Wallet
+	opts    Options
+	mu      sync.Mutex
+	pending map[uint32]*Request
+	err     error
+}
+
+// NewTracker starts watching payment requests made for wallet.
+//
+// Parameters:
+//   - ctx: Context ending the watch; requests still pending then stay
+//     pending
+//   - wallet: Wallet receiving the payments, such as Moneroger.WalletClient()
+//   - opts: Account, confirmation threshold and poll interval
+//
+// Returns:
+//   - *Tracker: Tracker creating requests with NewRequest
+func NewTracker(ctx context.Context, wallet Wallet, opts Options) *Tracker {
+	if opts.Confirmations == 0 {
+		opts.Confirmations = DefaultConfirmations
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	t := &Tracker{wallet: wallet, opts: opts, pending: make(map[uint32]*Request)}
+	go t.run(ctx)
+	return t
+}
+
+// Err returns the error of the last check of the pending requests, nil
+// if it succeeded. Failed checks are retried at the next poll.
+func (t *Tracker) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// NewRequest asks for a payment: it creates a fresh subaddress for the
+// payer to send amount to within timeout.
+//
+// Parameters:
+//   - ctx: Context for the create_address call
+//   - amount: Amount expected
+//   - timeout: How long the payer has to send it
+//
+// Returns:
+//   - *Request: The request, pending until resolved
+//   - error: KindConfig for a zero amount or timeout, otherwise RPC
+//     failures
+//
+// Funds seen by the first check after the deadline count. Once they add
+// up to amount the request stays pending until they are confirmed, even
+// past the deadline; otherwise it expires or is underpaid.
+func (t *Tracker) NewRequest(ctx context.Context, amount monerowalletrpc.Piconero, timeout time.Duration) (*Request, error) {
+	if amount == 0 || timeout <= 0 {
+		return nil, errors.E(opNewRequest, errors.ComponentPayments, errors.KindConfig,
+			fmt.Errorf("payment request needs an amount and a timeout, got %s and %v", amount, timeout))
+	}
+	addr, err := t.wallet.CreateAddress(ctx, t.opts.Account, addressLabel)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	r := &Request{
+		Address: addr.Address,
+		Index:   monerowalletrpc.SubaddrIndex{Major: t.opts.Account, Minor: addr.AddressIndex},
+		Amount:  amount,
+		Created: now,
+		Expires: now.Add(timeout),
+		done:    make(chan struct{}),
+	}
+	t.mu.Lock()
+	t.pending[addr.AddressIndex] = r
+	t.mu.Unlock()
+	return r, nil
+}
+
+// run polls the pending requests until ctx is done.
+func (t *Tracker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.opts.PollInterval):
+		}
+		err := t.poll(ctx, time.Now())
+		t.mu.Lock()
+		t.err = err
+		t.mu.Unlock()
+	}
+}
+
+// poll checks the pending requests against the wallet's incoming
+// transfers at time now, resolving those that are paid or expired.
+func (t *Tracker) poll(ctx context.Context, now time.Time) error {
+	t.mu.Lock()
+	pending := make(map[uint32]*Request, len(t.pending))
+	indices := make([]uint32, 0, len(t.pending))
+	for index, r := range t.pending {
+		pending[index] = r
+		indices = append(indices, index)
+	}
+	t.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	transfers, err := t.wallet.GetTransfers(ctx, monerowalletrpc.GetTransfersParams{
+		In:             true,
+		Pool:           true,
+		AccountIndex:   t.opts.Account,
+		SubaddrIndices: indices,
+	})
+	if err != nil {
+		return errors.E(opPoll, errors.ComponentPayments, errors.GetKind(err), err)
+	}
+	received := make(map[uint32][]monerowalletrpc.Transfer)
+	for _, list := range [][]monerowalletrpc.Transfer{transfers.In, transfers.Pool} {
+		for _, tr := range list {
+			if tr.SubaddrIndex.Major == t.opts.Account {
+				received[tr.SubaddrIndex.Minor] = append(received[tr.SubaddrIndex.Minor], tr)
+			}
+		}
+	}
+
+	for index, r := range pending {
+		if r.update(received[index], t.opts.Confirmations, now).Final() {
+			t.mu.Lock()
+			delete(t.pending, index)
+			t.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// Request is a payment request made with Tracker.NewRequest.
+//
+// Fields:
+//   - Address: Subaddress the payer sends to
+//   - Index: Account and index of the subaddress
+//   - Amount: Amount expected
+//   - Created, Expires: When the request was made and its deadline
+//   - mu: Guards the fields below
+//   - status: Current state
+//   - received, confirmed: Totals received, and received with enough
+//     confirmations
+//   - txs: Hashes of the paying transactions
+//   - counted: Transactions counted once the deadline passed, nil before
+//   - done: Closed when the request is resolved
+type Request struct {
+	Address string
+	Index   monerowalletrpc.SubaddrIndex
+	Amount  monerowalletrpc.Piconero
+	Created time.Time
+	Expires time.Time
+
+	mu        sync.Mutex
+	status    Status
+	received  monerowalletrpc.Piconero
+	confirmed monerowalletrpc.Piconero
+	txs       []string
+	counted   map[string]bool
+	done      chan struct{}
+}
+
+// update recomputes the request's totals from the transfers to its
+// subaddress and resolves it when due.
+//
+// Parameters:
+//   - transfers: Incoming and pool transfers to the subaddress
+//   - confirmations: Confirmations a transfer needs to count as confirmed
+//   - now: Time of the check
+//
+// Returns:
+//   - Status: The new state
+func (r *Request) update(transfers []monerowalletrpc.Transfer, confirmations uint64, now time.Time) Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status.Final() {
+		return r.status
+	}
+
+	var received, confirmed monerowalletrpc.Piconero
+	var txs []string
+	for _, tr := range transfers {
+		// Past the deadline, only the funds sent in time count
+		if r.counted != nil && !r.counted[tr.TxID] {
+			continue
+		}
+		received += tr.Amount
+		if tr.Height > 0 && tr.Confirmations >= confirmations {
+			confirmed += tr.Amount
+		}
+		txs = append(txs, tr.TxID)
+	}
+	r.received, r.confirmed, r.txs = received, confirmed, txs
+
+	switch {
+	case confirmed >= r.Amount:
+		r.status = StatusPaid
+	case now.Before(r.Expires):
+	case received >= r.Amount:
+		if r.counted == nil {
+			r.counted = make(map[string]bool, len(txs))
+			for _, tx := range txs {
+				r.counted[tx] = true
+			}
+		}
+	case received == 0:
+		r.status = StatusExpired
+	default:
+		r.status = StatusUnderpaid
+	}
+	if r.status.Final() {
+		close(r.done)
+	}
+	return r.status
+}
+
+// Status returns the current state of the request.
+func (r *Request) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Received returns the amounts received so far.
+//
+// Returns:
+//   - total: Everything sent to the subaddress, including the pool
+//   - confirmed: The part with enough confirmations
+func (r *Request) Received() (total, confirmed monerowalletrpc.Piconero) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received, r.confirmed
+}
+
+// TxHashes returns the hashes of the transactions paying the request.
+func (r *Request) TxHashes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.txs...)
+}
+
+// Done returns a channel closed once the request is resolved.
+func (r *Request) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait blocks until the request is resolved.
+//
+// Parameters:
+//   - ctx: Context bounding the wait
+//
+// Returns:
+//   - Status: StatusPaid, StatusExpired or StatusUnderpaid, or
+//     StatusPending if ctx ended first
+//   - error: KindTimeout if ctx ended first
+func (r *Request) Wait(ctx context.Context) (Status, error) {
+	select {
+	case <-r.done:
+		return r.Status(), nil
+	case <-ctx.Done():
+		return r.Status(), errors.E(opWait, errors.ComponentPayments, errors.KindTimeout, ctx.Err())
+	}
+}
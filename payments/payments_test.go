@@ -0,0 +1,143 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+)
+
+// fakeWallet hands out consecutive subaddresses and answers
+// get_transfers with the transfers set by the test
+type fakeWallet struct {
+	mu        sync.Mutex
+	next      uint32
+	transfers monerowalletrpc.Transfers
+	params    monerowalletrpc.GetTransfersParams
+}
+
+func (w *fakeWallet) CreateAddress(ctx context.Context, accountIndex uint32, label string) (*monerowalletrpc.NewAddress, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.next++
+	return &monerowalletrpc.NewAddress{Address: fmt.Sprintf("8sub%d", w.next), AddressIndex: w.next}, nil
+}
+
+func (w *fakeWallet) GetTransfers(ctx context.Context, params monerowalletrpc.GetTransfersParams) (*monerowalletrpc.Transfers, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.params = params
+	t := w.transfers
+	return &t, nil
+}
+
+// set replaces the transfers the wallet reports
+func (w *fakeWallet) set(in, pool []monerowalletrpc.Transfer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.transfers = monerowalletrpc.Transfers{In: in, Pool: pool}
+}
+
+// transfer returns an incoming transfer to subaddress minor of account 0
+func transfer(txid string, minor uint32, amount monerowalletrpc.Piconero, height, confirmations uint64) monerowalletrpc.Transfer {
+	return monerowalletrpc.Transfer{
+		TxID:          txid,
+		Amount:        amount,
+		SubaddrIndex:  monerowalletrpc.SubaddrIndex{Minor: minor},
+		Height:        height,
+		Confirmations: confirmations,
+	}
+}
+
+// TestRequests follows requests through every outcome: paid after
+// enough confirmations, expired, underpaid, and paid in time but
+// confirmed after the deadline
+func TestRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wallet := &fakeWallet{}
+	// The poll loop never runs; the test polls at chosen times
+	tracker := NewTracker(ctx, wallet, Options{Confirmations: 2, PollInterval: time.Hour})
+
+	if _, err := tracker.NewRequest(ctx, 0, time.Minute); errors.GetKind(err) != errors.KindConfig {
+		t.Errorf("NewRequest() without an amount error = %v, want KindConfig", err)
+	}
+	paid, _ := tracker.NewRequest(ctx, 1000, time.Minute)
+	expired, _ := tracker.NewRequest(ctx, 1000, time.Minute)
+	underpaid, _ := tracker.NewRequest(ctx, 1000, time.Minute)
+	late, err := tracker.NewRequest(ctx, 1000, time.Minute)
+	if err != nil || paid.Address != "8sub1" || late.Index.Minor != 4 {
+		t.Fatalf("NewRequest() = %+v, %v", late, err)
+	}
+
+	// Before the deadline: paid in two parts, one still unconfirmed
+	wallet.set(
+		[]monerowalletrpc.Transfer{transfer("a", 1, 600, 100, 2), transfer("c", 3, 400, 100, 5)},
+		[]monerowalletrpc.Transfer{transfer("b", 1, 400, 0, 0), transfer("d", 4, 1000, 0, 0)},
+	)
+	now := paid.Created.Add(30 * time.Second)
+	if err := tracker.poll(ctx, now); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if fmt.Sprint(wallet.params.SubaddrIndices) != "[1 2 3 4]" || !wallet.params.In || !wallet.params.Pool {
+		t.Errorf("get_transfers params = %+v", wallet.params)
+	}
+	if total, confirmed := paid.Received(); paid.Status() != StatusPending || total != 1000 || confirmed != 600 {
+		t.Errorf("partly confirmed request = %v, %d received, %d confirmed", paid.Status(), total, confirmed)
+	}
+
+	// The second part confirms
+	wallet.set(
+		[]monerowalletrpc.Transfer{transfer("a", 1, 600, 100, 3), transfer("b", 1, 400, 101, 2), transfer("c", 3, 400, 100, 6)},
+		[]monerowalletrpc.Transfer{transfer("d", 4, 1000, 0, 0)},
+	)
+	if err := tracker.poll(ctx, now.Add(10*time.Second)); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if status, err := paid.Wait(ctx); status != StatusPaid || err != nil {
+		t.Errorf("Wait() = %v, %v, want paid", status, err)
+	}
+	if fmt.Sprint(paid.TxHashes()) != "[a b]" {
+		t.Errorf("TxHashes() = %v", paid.TxHashes())
+	}
+
+	// The deadline passes; a payment arriving afterwards does not count
+	after := paid.Expires.Add(time.Second)
+	wallet.set(
+		[]monerowalletrpc.Transfer{transfer("c", 3, 400, 100, 7), transfer("d", 4, 1000, 102, 1)},
+		nil,
+	)
+	if err := tracker.poll(ctx, after); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if expired.Status() != StatusExpired || underpaid.Status() != StatusUnderpaid {
+		t.Errorf("statuses = %v and %v, want expired and underpaid", expired.Status(), underpaid.Status())
+	}
+	if late.Status() != StatusPending {
+		t.Errorf("request paid in time = %v, want pending until confirmed", late.Status())
+	}
+	wallet.set(
+		[]monerowalletrpc.Transfer{transfer("d", 4, 1000, 102, 2), transfer("e", 2, 1000, 103, 1)},
+		nil,
+	)
+	if err := tracker.poll(ctx, after.Add(time.Minute)); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if late.Status() != StatusPaid || expired.Status() != StatusExpired {
+		t.Errorf("statuses = %v and %v, want paid and still expired", late.Status(), expired.Status())
+	}
+	if len(tracker.pending) != 0 {
+		t.Errorf("%d requests still pending", len(tracker.pending))
+	}
+
+	waitCtx, cancelWait := context.WithCancel(ctx)
+	cancelWait()
+	pending, _ := tracker.NewRequest(ctx, 1000, time.Minute)
+	if status, err := pending.Wait(waitCtx); status != StatusPending || errors.GetKind(err) != errors.KindTimeout {
+		t.Errorf("Wait() with a cancelled context = %v, %v, want pending and KindTimeout", status, err)
+	}
+}
@@ -0,0 +1,140 @@
+package moneroger
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// Operation names for probe errors
+const (
+	// OpLive is the operation name for errors from Live
+	OpLive errors.Op = "Live"
+
+	// OpReady is the operation name for errors from Ready
+	OpReady errors.Op = "Ready"
+)
+
+// refreshSlack is how many blocks a wallet may trail its daemon and still
+// count as refreshed, as a block may arrive between the two queries
+const refreshSlack = 1
+
+// serviceLive reports whether a service in the given state has a
+// process that was started and has not exited.
+func serviceLive(state string) bool {
+	return state == util.StateStarting.String() || state == util.StateRunning.String()
+}
+
+// Live reports whether every managed process is running, whether or not
+// it is ready to serve, so a service busy syncing or refreshing is live;
+// wire it to liveness probes that restart the manager. Spawned processes
+// are checked without RPC calls; an adopted monerod, having no process
+// of its own, must answer get_version within two seconds.
+//
+// Returns:
+//   - error: nil if live, otherwise a KindProcess error naming every
+//     service whose process has exited
+//
+// Related:
+//   - Ready for whether the services can serve requests
+//   - Status.Live for a status read from another process
+func (m *Moneroger) Live() error {
+	var problems []error
+	if len(m.currentConfig().RemoteNodeList()) == 0 {
+		if state := m.monerod.State(); !serviceLive(state.String()) || !m.monerod.Alive() {
+			problems = append(problems, fmt.Errorf("monerod is %s", state))
+		}
+	}
+	m.eachWallet(func(name string, w *monerowalletrpc.WalletRPC) {
+		if state := w.State(); !serviceLive(state.String()) || !w.Alive() {
+			problems = append(problems, fmt.Errorf("wallet %s is %s", name, state))
+		}
+	})
+	if len(problems) > 0 {
+		return errors.E(OpLive, errors.KindProcess, stderrors.Join(problems...))
+	}
+	return nil
+}
+
+// Ready reports whether the services can serve requests: they are live,
+// answer RPC, the daemon is synchronized and every wallet has a wallet
+// file open and scanned up to its daemon's height. Wire it to readiness
+// probes that route traffic to the manager.
+//
+// Parameters:
+//   - ctx: Context bounding the RPC queries
+//
+// Returns:
+//   - error: nil if ready, otherwise a KindProcess error naming every
+//     service that is not
+//
+// Related:
+//   - Live for process liveness only
+//   - Status.Ready for a status read from another process
+func (m *Moneroger) Ready(ctx context.Context) error {
+	if err := m.Live(); err != nil {
+		return err
+	}
+	return m.Status(ctx).Ready()
+}
+
+// Live reports whether every service in the status has a running
+// process, like Moneroger.Live.
+//
+// Returns:
+//   - error: nil if live, otherwise a KindProcess error
+func (s *Status) Live() error {
+	var problems []error
+	if s.Daemon.State != StateRemote && !serviceLive(s.Daemon.State) {
+		problems = append(problems, fmt.Errorf("monerod is %s", s.Daemon.State))
+	}
+	for _, w := range s.Wallets {
+		if !serviceLive(w.State) {
+			problems = append(problems, fmt.Errorf("wallet %s is %s", w.Name, w.State))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.E(OpLive, errors.KindProcess, stderrors.Join(problems...))
+	}
+	return nil
+}
+
+// Ready reports whether the services in the status can serve requests,
+// like Moneroger.Ready.
+//
+// Returns:
+//   - error: nil if ready, otherwise a KindProcess error
+func (s *Status) Ready() error {
+	var problems []error
+	running := util.StateRunning.String()
+	if d := s.Daemon; d.State != StateRemote {
+		switch {
+		case d.State != running:
+			problems = append(problems, fmt.Errorf("monerod is %s", d.State))
+		case d.Error != "":
+			problems = append(problems, fmt.Errorf("monerod: %s", d.Error))
+		case !d.Synchronized:
+			problems = append(problems, fmt.Errorf("monerod is synchronizing, height %d of %d", d.Height, d.TargetHeight))
+		}
+	}
+	for _, w := range s.Wallets {
+		switch {
+		case w.State != running:
+			problems = append(problems, fmt.Errorf("wallet %s is %s", w.Name, w.State))
+		case w.Error != "":
+			problems = append(problems, fmt.Errorf("wallet %s: %s", w.Name, w.Error))
+		case !w.WalletOpen:
+			problems = append(problems, fmt.Errorf("wallet %s has no wallet file open", w.Name))
+		case !w.Refreshed:
+			problems = append(problems, fmt.Errorf("wallet %s is refreshing, height %d", w.Name, w.Height))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.E(OpReady, errors.KindProcess, stderrors.Join(problems...))
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// OpReload is the operation name for errors from Reload
+const OpReload errors.Op = "Reload"
+
+// reloadPlan lists what a configuration change requires.
+//
+// Fields:
+//   - daemon: monerod must be restarted, and every wallet with it
+//   - wallet: The default wallet must be restarted
+//   - extraWallets: Wallets added with AddWallet must be restarted
+//   - tune: Log level or peer limits changed, applied over RPC
+//   - policy: The restart policy changed, so supervision is restarted
+type reloadPlan struct {
+	daemon       bool
+	wallet       bool
+	extraWallets bool
+	tune         bool
+	policy       bool
+}
+
+// daemonSettings are the settings monerod is started with.
+type daemonSettings struct {
+	dataDir    string
+	port       int
+	bindIP     string
+	network    util.Network
+	remote     bool
+	zmqPubPort int
+	restricted int
+	publicNode bool
+	rpcUser    string
+	rpcPass    string
+	keyring    bool
+	tor        util.TorConfig
+	i2p        util.I2PConfig
+	bootstrap  util.BootstrapConfig
+	tls        util.RPCTLS
+	docker     util.DockerConfig
+	extraArgs  string
+	options    monerod.Options
+	banList    string
+}
+
+// sharedWalletSettings are the wallet settings inherited by every wallet,
+// including those added with AddWallet.
+type sharedWalletSettings struct {
+	remoteNodes string
+	bindIP      string
+	keyring     bool
+	tls         util.RPCTLS
+	tor         util.TorConfig
+	i2p         util.I2PConfig
+	docker      util.DockerConfig
+	hwDevice    util.HWDeviceConfig
+	extraArgs   string
+}
+
+// defaultWalletSettings are the settings only the default wallet uses.
+type defaultWalletSettings struct {
+	walletDir  string
+	walletName string
+	port       int
+	rpcUser    string
+	rpcPass    string
+}
+
+// restartOptions returns the monerod options changed only by a restart,
+// leaving out those Tune changes over RPC.
+func restartOptions(c util.Config) monerod.Options {
+	options := monerod.OptionsFromConfig(c)
+	options.LogLevel, options.OutPeers, options.InPeers = 0, 0, 0
+	return options
+}
+
+// planReload compares two configurations and decides which services a
+// change from old to new affects.
+func planReload(old, new util.Config) reloadPlan {
+	daemon := func(c util.Config) daemonSettings {
+		return daemonSettings{
+			dataDir:    c.NetworkDataDir(),
+			port:       c.EffectiveMoneroPort(),
+			bindIP:     c.MoneroBindIP,
+			network:    c.NetType(),
+			remote:     len(c.RemoteNodeList()) > 0,
+			zmqPubPort: c.ZMQPubPort,
+			restricted: c.EffectiveRestrictedPort(),
+			publicNode: c.PublicNode,
+			rpcUser:    c.MoneroRPCUser,
+			rpcPass:    c.MoneroRPCPass,
+			keyring:    c.CredentialStore() != nil,
+			tor:        c.Tor,
+			i2p:        c.I2P,
+			bootstrap:  c.Bootstrap,
+			tls:        c.DaemonTLS,
+			docker:     c.Docker,
+			extraArgs:  fmt.Sprintf("%q", c.MonerodExtraArgs),
+			options:    restartOptions(c),
+			banList:    c.BanList,
+		}
+	}
+	shared := func(c util.Config) sharedWalletSettings {
+		return sharedWalletSettings{
+			remoteNodes: fmt.Sprint(c.RemoteNodeList()),
+			bindIP:      c.WalletBindIP,
+			keyring:     c.CredentialStore() != nil,
+			tls:         c.WalletTLS,
+			tor:         c.Tor,
+			i2p:         c.I2P,
+			docker:      c.Docker,
+			hwDevice:    c.HWDevice,
+			extraArgs:   fmt.Sprintf("%q", c.WalletRPCExtraArgs),
+		}
+	}
+	wallet := func(c util.Config) defaultWalletSettings {
+		return defaultWalletSettings{
+			walletDir:  c.EffectiveWalletDir(),
+			walletName: c.WalletName,
+			port:       c.EffectiveWalletPort(),
+			rpcUser:    c.WalletRPCUser,
+			rpcPass:    c.WalletRPCPass,
+		}
+	}
+
+	var p reloadPlan
+	p.daemon = daemon(old) != daemon(new)
+	p.extraWallets = p.daemon || shared(old) != shared(new)
+	p.wallet = p.extraWallets || wallet(old) != wallet(new)
+	p.tune = old.DaemonLogLevel != new.DaemonLogLevel ||
+		old.EffectiveOutPeers() != new.EffectiveOutPeers() ||
+		old.EffectiveInPeers() != new.EffectiveInPeers()
+	p.policy = old.Restart != new.Restart
+	return p
+}
+
+// Reload applies a new configuration to the running services, restarting
+// only what the change requires. The command line tool calls it on SIGHUP.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdowns and RPC calls
+//   - config: New configuration; a nil Logger, DaemonOutput or
+//     WalletOutput keeps the current one
+//
+// Returns:
+//   - error: Validation errors, KindProcess if daemon settings changed
+//     but the daemon was adopted, otherwise any shutdown, startup or RPC
+//     error
+//
+// Changes are applied as follows:
+//   - DaemonLogLevel, OutPeers and InPeers are changed over RPC
+//   - Restart policy changes apply to supervision immediately
+//   - Wallet settings restart the default wallet, and wallets added
+//     with AddWallet when they inherit the setting
+//   - Daemon settings restart monerod, then every wallet
+//
+// Other settings, such as LogProcessOutput, are recorded but do not
+// affect processes that keep running. EventConfigReloaded is emitted
+// with the outcome.
+//
+// Related:
+//   - util.LoadConfig
+//   - monerod.MoneroDaemon.Reconfigure
+//   - monerowalletrpc.WalletRPC.Reconfigure
+func (m *Moneroger) Reload(ctx context.Context, config util.Config) error {
+	err := m.reload(ctx, config)
+	m.emit(EventConfigReloaded, "", err)
+	return err
+}
+
+// reload validates config, records it and applies it.
+func (m *Moneroger) reload(ctx context.Context, config util.Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	old := m.currentConfig()
+	if config.Logger == nil {
+		config.Logger = old.Logger
+	}
+	if config.DaemonOutput == nil {
+		config.DaemonOutput = old.DaemonOutput
+	}
+	if config.WalletOutput == nil {
+		config.WalletOutput = old.WalletOutput
+	}
+
+	plan := planReload(old, config)
+	if plan.daemon && m.monerod.Adopted() {
+		return errors.E(OpReload, errors.ComponentMonerod, errors.KindProcess,
+			fmt.Errorf("daemon settings changed, but the adopted daemon was not started by this manager"))
+	}
+
+	m.configMu.Lock()
+	m.config = config
+	m.configMu.Unlock()
+
+	if plan.tune && !plan.daemon {
+		outPeers, inPeers := config.EffectiveOutPeers(), config.EffectiveInPeers()
+		if err := m.monerod.Tune(ctx, config.DaemonLogLevel, outPeers, inPeers); err != nil {
+			return err
+		}
+		m.logger().Info("daemon tuning changed", "log_level", config.DaemonLogLevel,
+			"out_peers", outPeers, "in_peers", inPeers)
+	}
+	return m.restartServices(ctx, config, plan)
+}
+
+// restartServices restarts the services plan requires with config, and
+// restarts their supervision. Wallets stop before and start after the
+// daemon they depend on.
+func (m *Moneroger) restartServices(ctx context.Context, config util.Config, plan reloadPlan) error {
+	if !plan.daemon && !plan.wallet && !plan.extraWallets && !plan.policy {
+		return nil
+	}
+	logger := m.logger()
+
+	m.walletsMu.RLock()
+	extra := make(map[string]*managedWallet, len(m.wallets))
+	for name, w := range m.wallets {
+		if w != nil && (plan.extraWallets || plan.policy) {
+			extra[name] = w
+		}
+	}
+	m.walletsMu.RUnlock()
+
+	// Supervision stops first, so the intentional exits below are not
+	// treated as crashes, and resumes even if a restart fails
+	if plan.daemon || plan.policy {
+		m.daemonSup.stop()
+		defer func() { m.daemonSup = m.superviseDaemon(m.backgroundContext()) }()
+	}
+	if plan.wallet || plan.policy {
+		m.walletSup.stop()
+		defer func() {
+			m.walletSup = m.superviseWallet(m.backgroundContext(), DefaultWalletName, m.monerowalletrpc)
+		}()
+	}
+	for name, w := range extra {
+		name, w := name, w
+		w.sup.stop()
+		defer func() { w.sup = m.superviseWallet(m.backgroundContext(), name, w.rpc) }()
+	}
+
+	if plan.extraWallets {
+		for _, w := range extra {
+			if err := stopService(ctx, w.rpc.Shutdown, w.rpc); err != nil {
+				return err
+			}
+		}
+	}
+	if plan.wallet {
+		if err := stopService(ctx, m.monerowalletrpc.Shutdown, m.monerowalletrpc); err != nil {
+			return err
+		}
+	}
+
+	// Restarted processes must outlive ctx, which only bounds the reload
+	startCtx := context.WithoutCancel(ctx)
+	if plan.daemon {
+		logger.Info("restarting monerod")
+		if err := stopService(ctx, m.monerod.Shutdown, m.monerod); err != nil {
+			return err
+		}
+		if err := m.monerod.Reconfigure(config); err != nil {
+			return err
+		}
+		if err := m.monerod.Start(startCtx); err != nil {
+			return err
+		}
+		m.emit(EventDaemonStarted, errors.ComponentMonerod, nil)
+	}
+	if plan.wallet {
+		logger.Info("restarting wallet", "wallet", DefaultWalletName)
+		if err := m.monerowalletrpc.Reconfigure(config); err != nil {
+			return err
+		}
+		if err := m.monerowalletrpc.Start(startCtx); err != nil {
+			return err
+		}
+		m.emitWallet(EventWalletReady, DefaultWalletName, nil)
+	}
+	if plan.extraWallets {
+		for name, w := range extra {
+			logger.Info("restarting wallet", "wallet", name)
+			if err := w.rpc.Reconfigure(m.walletConfig(config, w.cfg)); err != nil {
+				return err
+			}
+			if err := w.rpc.Start(startCtx); err != nil {
+				return err
+			}
+			m.emitWallet(EventWalletReady, name, nil)
+		}
+	}
+	return nil
+}
+
+// stopService shuts a service down and waits for its process to exit.
+func stopService(ctx context.Context, shutdown func(context.Context) error, svc supervised) error {
+	if err := shutdown(ctx); err != nil {
+		return err
+	}
+	exited := svc.Exited()
+	if exited == nil {
+		// Adopted or remote services have no process to wait for
+		return nil
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return errors.E(OpReload, errors.KindTimeout, ctx.Err())
+	}
+}
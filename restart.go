@@ -0,0 +1,62 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// OpRestart is the operation name for errors from Restart
+const OpRestart errors.Op = "Restart"
+
+// Restart gracefully stops one component and starts it again with the
+// current configuration, without supervision treating the exit as a
+// crash.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdown; the restarted process outlives it
+//   - component: errors.ComponentMonerod or errors.ComponentWalletRPC
+//
+// Returns:
+//   - error: KindConfig for an unknown component or a daemon replaced
+//     by remote nodes, KindProcess for an adopted daemon or a manager
+//     that was shut down, otherwise any shutdown or startup error
+//
+// Restarting monerod restarts every wallet as well: they stop before
+// the daemon and start after it, since they connect to its address.
+// Restarting errors.ComponentWalletRPC affects the default wallet only.
+//
+// Related:
+//   - Reload for restarting with a new configuration
+func (m *Moneroger) Restart(ctx context.Context, component string) error {
+	config := m.currentConfig()
+	var plan reloadPlan
+	switch component {
+	case errors.ComponentMonerod:
+		if len(config.RemoteNodeList()) > 0 {
+			return errors.E(OpRestart, errors.ComponentMonerod, errors.KindConfig,
+				fmt.Errorf("remote nodes are used instead of a local daemon"))
+		}
+		plan = reloadPlan{daemon: true, wallet: true, extraWallets: true}
+	case errors.ComponentWalletRPC:
+		plan = reloadPlan{wallet: true}
+	default:
+		return errors.E(OpRestart, errors.KindConfig, fmt.Errorf("unknown component %q", component))
+	}
+
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	m.walletsMu.RLock()
+	closed := m.walletsClosed
+	m.walletsMu.RUnlock()
+	if closed {
+		return errors.E(OpRestart, errors.KindProcess, fmt.Errorf("manager was shut down"))
+	}
+	if plan.daemon && m.monerod.Adopted() {
+		return errors.E(OpRestart, errors.ComponentMonerod, errors.KindProcess,
+			fmt.Errorf("the adopted daemon was not started by this manager"))
+	}
+	m.logger().Info("restarting", "component", component)
+	return m.restartServices(ctx, m.currentConfig(), plan)
+}
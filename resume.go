@@ -0,0 +1,83 @@
+package moneroger
+
+import (
+	"context"
+	"time"
+
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// resumeCheckInterval is how often the clocks are sampled for suspend detection
+	resumeCheckInterval = 5 * time.Second
+
+	// resumeJumpThreshold is the smallest clock gap treated as a suspend/resume
+	resumeJumpThreshold = 10 * time.Second
+)
+
+// watchResume re-verifies the managed services after the host resumes from
+// suspend or a VM is unpaused, instead of letting stale connections and
+// expired deadlines surface as spurious failures.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, the watcher exits when it is done
+func (m *Moneroger) watchResume(ctx context.Context) {
+	for jump := range util.WatchClockJumps(ctx, resumeCheckInterval, resumeJumpThreshold) {
+		m.logger().Info("clock jump detected, re-verifying services", "jump", jump.Round(time.Second))
+		if err := m.recoverAfterResume(ctx); err != nil {
+			m.logger().Error("failed to recover services after resume", "error", err)
+		}
+	}
+}
+
+// recoverAfterResume checks process liveness and service health, restarting
+// the daemon if it died and each wallet that died, is unhealthy or lost
+// its daemon.
+//
+// Parameters:
+//   - ctx: Context for the recovery operations
+//
+// Returns:
+//   - error: The first error restarting a service
+//
+// Supervision of each restarted service is paused around its restart, so
+// the deliberate exit neither ends the supervision nor counts as a crash.
+func (m *Moneroger) recoverAfterResume(ctx context.Context) error {
+	// Serializes with Reload and shutdown, which also replace supervision
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if m.closed() {
+		return nil
+	}
+
+	// The processes own the context they are started with, and must
+	// outlive the watcher's so that shutdown stops them gracefully
+	startCtx := context.WithoutCancel(ctx)
+	daemonRestarted := false
+	if !m.monerod.Alive() {
+		m.logger().Warn("monerod is no longer running after resume, restarting")
+		resume := m.pauseDaemonSupervision()
+		err := m.monerod.Start(startCtx)
+		resume()
+		if err != nil {
+			return err
+		}
+		daemonRestarted = true
+	}
+
+	var firstErr error
+	m.eachWallet(func(name string, w *monerowalletrpc.WalletRPC) {
+		w.InvalidateHealth()
+		if daemonRestarted || !w.Alive() || w.CheckHealth(ctx) != nil {
+			m.logger().Info("reconnecting monero-wallet-rpc after resume", "wallet", name)
+			resume := m.pauseWalletSupervision(name)
+			defer resume()
+			_ = w.Shutdown(ctx)
+			if err := w.Start(startCtx); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	})
+	return firstErr
+}
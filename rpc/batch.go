@@ -0,0 +1,111 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// BatchCall is a single method invocation within a batch.
+//
+// Fields:
+//   - Method: JSON-RPC method name
+//   - Params: Request parameters, nil for none
+//   - Result: Pointer to decode the result into, nil to discard
+//   - Err: Set by Batch to the outcome of this call
+type BatchCall struct {
+	Method string
+	Params interface{}
+	Result interface{}
+	Err    error
+}
+
+// Batch sends several JSON-RPC calls in a single round trip.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - calls: Calls to perform, each receives its own result and error
+//
+// Returns:
+//   - error: Transport-level failure affecting the whole batch. Per-call
+//     failures are reported in BatchCall.Err
+//
+// Not every monerod build accepts JSON-RPC batch arrays. When the server
+// rejects a batch the client remembers this and falls back to issuing the
+// calls concurrently, so callers get the same semantics either way.
+func (c *Client) Batch(ctx context.Context, calls []*BatchCall) error {
+	if len(calls) == 0 {
+		return nil
+	}
+	native := !c.batchUnsupported.Load()
+	if native {
+		err := c.batch(ctx, calls)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, call := range calls {
+		wg.Add(1)
+		go func(call *BatchCall) {
+			defer wg.Done()
+			call.Err = c.Call(ctx, call.Method, call.Params, call.Result)
+		}(call)
+	}
+	wg.Wait()
+
+	// Only remember the server as batch-incapable once individual calls
+	// succeed, so a temporarily unreachable server is not misclassified
+	if native {
+		for _, call := range calls {
+			if call.Err == nil {
+				c.batchUnsupported.Store(true)
+				break
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// batch performs a native JSON-RPC batch request.
+func (c *Client) batch(ctx context.Context, calls []*BatchCall) error {
+	reqs := make([]request, len(calls))
+	byID := make(map[uint64]*BatchCall, len(calls))
+	for i, call := range calls {
+		reqs[i] = request{
+			JSONRPC: "2.0",
+			ID:      c.nextID.Add(1),
+			Method:  call.Method,
+			Params:  call.Params,
+		}
+		byID[reqs[i].ID] = call
+	}
+
+	var resps []response
+	if err := c.post(ctx, "batch", "/json_rpc", reqs, &resps); err != nil {
+		return c.wrap(transportKind(err), fmt.Errorf("batch: %w", err))
+	}
+	if len(resps) != len(calls) {
+		return c.wrap(errors.KindNetwork, fmt.Errorf("batch: got %d responses for %d calls", len(resps), len(calls)))
+	}
+
+	for _, resp := range resps {
+		call, ok := byID[resp.ID]
+		if !ok {
+			return c.wrap(errors.KindNetwork, fmt.Errorf("batch: unexpected response id %d", resp.ID))
+		}
+		switch {
+		case resp.Error != nil:
+			call.Err = c.wrap(c.opts.ErrorKind(resp.Error), fmt.Errorf("%s: %w", call.Method, resp.Error))
+		case call.Result != nil && len(resp.Result) > 0:
+			if err := json.Unmarshal(resp.Result, call.Result); err != nil {
+				call.Err = c.wrap(errors.KindRPC, fmt.Errorf("%s: decoding result: %w", call.Method, err))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package rpc
+
+import (
+	"context"
+	stderrors "errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// Default circuit breaker values
+const (
+	// DefaultBreakerThreshold is the number of consecutive failures
+	// reaching the endpoint suggested for Options.BreakerThreshold
+	DefaultBreakerThreshold = 5
+
+	// DefaultBreakerCooldown is how long an open breaker rejects calls
+	// when Options.BreakerCooldown is zero
+	DefaultBreakerCooldown = 5 * time.Second
+)
+
+// ErrCircuitOpen is returned, wrapped in a KindNetwork error, for calls
+// rejected without contacting the endpoint because its recent calls
+// failed.
+var ErrCircuitOpen = stderrors.New("circuit breaker open, endpoint recently unreachable")
+
+// breaker is a circuit breaker for one endpoint. After threshold
+// consecutive failures it opens and rejects calls for cooldown; then a
+// single probe call is let through, closing the breaker when it succeeds
+// and reopening it otherwise.
+//
+// Fields:
+//   - threshold: Consecutive failures opening the breaker, 0 disables it
+//   - cooldown: How long the breaker stays open before probing
+//   - mu: Guards the fields below
+//   - failures: Consecutive failures so far
+//   - openUntil: End of the current open period, zero when closed
+//   - probing: Whether the probe call after a cooldown is in flight
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a call may go ahead, returning ErrCircuitOpen
+// while the breaker is open or its probe call is in flight.
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts the outcome of a call let through by allow.
+//
+// Parameters:
+//   - failed: Whether the endpoint could not be reached or failed at
+//     the HTTP level; errors returned by the RPC server count as success
+func (b *breaker) record(failed bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failed {
+		b.failures, b.openUntil = 0, time.Time{}
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold || !b.openUntil.IsZero() {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// release ends a call let through by allow without counting it, such as
+// one cancelled by its caller.
+func (b *breaker) release() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// open reports whether the breaker is rejecting calls.
+func (b *breaker) open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openUntil.IsZero()
+}
+
+// endpointFailure reports whether an error from post means the endpoint
+// itself is failing: it could not be reached, or answered with a server
+// error status. Rejected credentials and undecodable bodies do not count.
+func endpointFailure(err error) bool {
+	var status *statusError
+	if stderrors.As(err, &status) {
+		return status.code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return stderrors.As(err, &netErr)
+}
+
+// notSent reports whether a call failed before its request reached the
+// server, so repeating it cannot apply it twice.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return stderrors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// readOnly is the default Options.Idempotent: methods and paths whose
+// name starts with get_, which only read state.
+func readOnly(method string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(method, "/"), "get_")
+}
+
+// withRetry runs a call under Options.Retry. A failed attempt is only
+// repeated when it is retryable and safe to repeat: it never reached the
+// server, or method is idempotent. Calls rejected by the open breaker are
+// not repeated.
+//
+// Parameters:
+//   - ctx: Context for the call
+//   - method: Method name or path, for Options.Idempotent
+//   - call: Performs one attempt
+//
+// Returns:
+//   - error: The error of the last attempt, or the context error when
+//     Options.Retry gave up waiting
+func (c *Client) withRetry(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	if c.opts.Retry == nil {
+		return call(ctx)
+	}
+	var last error
+	stopped := false
+	err := c.opts.Retry(ctx, func(ctx context.Context) error {
+		last = call(ctx)
+		if last == nil {
+			return nil
+		}
+		if stderrors.Is(last, ErrCircuitOpen) || !notSent(last) && !c.opts.Idempotent(method) {
+			stopped = true
+			return errors.Permanent(last)
+		}
+		return last
+	})
+	if stopped && err != nil {
+		// Return the error itself rather than the mark that stopped the
+		// retries, so callers classify it as before
+		return last
+	}
+	return err
+}
+
+// CircuitOpen reports whether the client's circuit breaker is open,
+// rejecting calls because the endpoint's recent calls failed. It is
+// always false when Options.BreakerThreshold is 0.
+func (c *Client) CircuitOpen() bool {
+	return c.breaker.open()
+}
@@ -0,0 +1,285 @@
+// Package rpc provides a JSON-RPC client for monerod and monero-wallet-rpc.
+// It handles digest authentication, connection reuse and bounded
+// concurrency so that frequent health checks and polling do not exhaust
+// sockets on either side.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// OpCall identifies RPC call operations in wrapped errors
+const OpCall = errors.Op("RPC.Call")
+
+// Client is a JSON-RPC client bound to a single monerod or wallet-rpc endpoint.
+// It is safe for concurrent use.
+//
+// Fields:
+//   - endpoint: Base URL of the service, e.g. http://127.0.0.1:18081
+//   - http: Underlying HTTP client with pooled keep-alive transport
+//   - sem: Concurrency limiter, nil when unlimited
+//   - opts: Effective client options
+//   - nextID: Source of JSON-RPC request IDs
+//   - batchUnsupported: Set once the server rejects a batch request
+//   - stats: Per-method request statistics
+//   - breaker: Circuit breaker for the endpoint
+type Client struct {
+	endpoint         string
+	http             *http.Client
+	sem              chan struct{}
+	opts             Options
+	nextID           atomic.Uint64
+	batchUnsupported atomic.Bool
+	stats            statsRecorder
+	breaker          breaker
+}
+
+// NewClient creates a client for the given endpoint and credentials.
+//
+// Parameters:
+//   - endpoint: Base URL, e.g. "http://127.0.0.1:18081"
+//   - user: Digest auth username, empty to disable authentication
+//   - pass: Digest auth password
+//   - opts: Tuning options, zero values use package defaults
+//
+// Returns:
+//   - *Client: Ready-to-use client
+//
+// Connections are kept alive and reused between calls unless
+// opts.DisableKeepAlives is set; call Close to release idle connections
+// when the client is no longer needed.
+func NewClient(endpoint, user, pass string, opts Options) *Client {
+	opts = opts.withDefaults()
+	transport := opts.Transport
+	if transport == nil {
+		transport = &http.Transport{
+			Proxy: opts.Proxy,
+			DialContext: (&net.Dialer{
+				Timeout:   opts.DialTimeout,
+				KeepAlive: opts.KeepAlive,
+			}).DialContext,
+			MaxIdleConns:          opts.MaxIdleConns,
+			MaxIdleConnsPerHost:   opts.MaxIdleConns,
+			MaxConnsPerHost:       opts.MaxConnsPerHost,
+			IdleConnTimeout:       opts.IdleConnTimeout,
+			ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+			DisableKeepAlives:     opts.DisableKeepAlives,
+			TLSClientConfig:       opts.TLS,
+		}
+	}
+	c := &Client{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		http: &http.Client{
+			Timeout: opts.Timeout,
+			Transport: &digestTransport{
+				base: transport,
+				user: user,
+				pass: pass,
+			},
+		},
+		opts:    opts,
+		breaker: breaker{threshold: opts.BreakerThreshold, cooldown: opts.BreakerCooldown},
+	}
+	if opts.MaxConcurrent > 0 {
+		c.sem = make(chan struct{}, opts.MaxConcurrent)
+	}
+	return c
+}
+
+// Endpoint returns the base URL the client talks to.
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Call invokes a JSON-RPC method on the /json_rpc endpoint.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - method: JSON-RPC method name, e.g. "get_info"
+//   - params: Request parameters, nil for none
+//   - result: Pointer to decode the result into, nil to discard
+//
+// Returns:
+//   - error: KindNetwork for transport and HTTP errors, KindAuth when
+//     the credentials are rejected, KindRPC for undecodable results, and
+//     for server-side errors the kind chosen by Options.ErrorKind; those
+//     unwrap to *Error. Calls rejected by the circuit breaker are
+//     KindNetwork errors wrapping ErrCircuitOpen
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	return c.withRetry(ctx, method, func(ctx context.Context) error {
+		return c.call(ctx, method, params, result)
+	})
+}
+
+// call makes a single attempt of Call.
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	req := request{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	}
+	var resp response
+	if err := c.post(ctx, method, "/json_rpc", req, &resp); err != nil {
+		return c.wrap(transportKind(err), fmt.Errorf("%s: %w", method, err))
+	}
+	if resp.Error != nil {
+		return c.wrap(c.opts.ErrorKind(resp.Error), fmt.Errorf("%s: %w", method, resp.Error))
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return c.wrap(errors.KindRPC, fmt.Errorf("%s: decoding result: %w", method, err))
+		}
+	}
+	return nil
+}
+
+// CallPath invokes one of monerod's non-JSON-RPC endpoints such as
+// /get_height or /get_transaction_pool.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - path: Endpoint path, e.g. "/get_height"
+//   - params: Request body, nil for an empty object
+//   - result: Pointer to decode the response into, nil to discard
+//
+// Returns:
+//   - error: Transport, HTTP or decoding errors, KindAuth when the
+//     credentials are rejected
+//
+// Calls are retried and rejected by the circuit breaker like those of
+// Call.
+func (c *Client) CallPath(ctx context.Context, path string, params, result interface{}) error {
+	if params == nil {
+		params = struct{}{}
+	}
+	return c.withRetry(ctx, path, func(ctx context.Context) error {
+		if err := c.post(ctx, path, path, params, result); err != nil {
+			return c.wrap(transportKind(err), fmt.Errorf("%s: %w", path, err))
+		}
+		return nil
+	})
+}
+
+// Close releases idle keep-alive connections held by the client.
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
+}
+
+// post sends body as JSON to path and decodes the response into out.
+// The call is recorded in the client statistics under label, and its
+// outcome in the circuit breaker.
+func (c *Client) post(ctx context.Context, label, path string, body, out interface{}) (err error) {
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, which says nothing about the endpoint
+			c.breaker.release()
+			return
+		}
+		c.breaker.record(endpointFailure(err))
+	}()
+
+	queued := c.sem != nil
+	if queued {
+		c.stats.queued(label)
+	}
+	if err := c.acquire(ctx); err != nil {
+		c.stats.dequeued(label)
+		return err
+	}
+	defer c.release()
+
+	c.stats.started(label, queued)
+	start := time.Now()
+	defer func() {
+		c.stats.finished(label, time.Since(start), err != nil)
+	}()
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Drain so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// acquire reserves a concurrency slot, blocking until one is free.
+func (c *Client) acquire(ctx context.Context) error {
+	if c.sem == nil {
+		return nil
+	}
+	select {
+	case c.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot reserved by acquire.
+func (c *Client) release() {
+	if c.sem != nil {
+		<-c.sem
+	}
+}
+
+// statusError is a non-200 HTTP response.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %s", e.status)
+}
+
+// transportKind classifies an error from post: KindAuth when the server
+// rejected the credentials, KindNetwork otherwise.
+func transportKind(err error) errors.Kind {
+	var status *statusError
+	if stderrors.As(err, &status) &&
+		(status.code == http.StatusUnauthorized || status.code == http.StatusForbidden) {
+		return errors.KindAuth
+	}
+	return errors.KindNetwork
+}
+
+// wrap converts err into a structured error for the client's component.
+func (c *Client) wrap(kind errors.Kind, err error) error {
+	return errors.E(OpCall, c.opts.Component, kind, err)
+}
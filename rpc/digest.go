@@ -0,0 +1,343 @@
+package rpc
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestChallenge holds the parameters of a WWW-Authenticate digest challenge.
+// A challenge is cached after the first 401 response and reused for
+// subsequent requests so that every call does not pay for an extra round trip.
+//
+// Fields:
+//   - realm, nonce, opaque: Values echoed back in every response
+//   - qop: Protection chosen from those offered, "auth", "auth-int" or
+//     empty for an RFC 2069 server
+//   - algorithm: Algorithm as named by the server, empty for MD5
+//   - hash: Hex digest function of the algorithm
+//   - sess: Whether the algorithm is a -sess variant, whose HA1 also
+//     covers the nonce and the client nonce
+//   - cnonce: Client nonce of -sess challenges, fixed for the challenge so
+//     the session key stays valid
+//   - nc: Requests authorized with the nonce so far
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	qop       string
+	algorithm string
+	hash      func(string) string
+	sess      bool
+	cnonce    string
+	nc        uint32
+}
+
+// digestTransport is an http.RoundTripper implementing HTTP digest
+// authentication (RFC 2617, with the SHA-256 algorithms of RFC 7616) as
+// used by monerod and monero-wallet-rpc.
+//
+// Fields:
+//   - base: Underlying transport performing the actual requests
+//   - user, pass: Credentials, authentication is skipped when user is empty
+//   - challenge: Cached challenge from the last 401 response
+type digestTransport struct {
+	base      http.RoundTripper
+	user      string
+	pass      string
+	mu        sync.Mutex
+	challenge *digestChallenge
+}
+
+// RoundTrip implements http.RoundTripper. It preemptively authenticates
+// using a cached challenge and retries once when the server issues a new
+// one, for instance because the cached nonce went stale.
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.user == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	first := req
+	auth, err := t.authorize(req)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		first = cloneRequest(req)
+		first.Header.Set("Authorization", auth)
+	}
+	resp, err := t.base.RoundTrip(first)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	ch := bestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if ch == nil || req.GetBody == nil && req.Body != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	t.mu.Lock()
+	t.challenge = ch
+	t.mu.Unlock()
+
+	retry := cloneRequest(req)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	if auth, err = t.authorize(req); err != nil {
+		return nil, err
+	}
+	retry.Header.Set("Authorization", auth)
+	return t.base.RoundTrip(retry)
+}
+
+// CloseIdleConnections closes the idle connections of the base transport,
+// so Client.Close reaches through the authentication layer.
+func (t *digestTransport) CloseIdleConnections() {
+	if c, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// authorize builds an Authorization header from the cached challenge,
+// returning an empty string when no challenge has been received yet.
+// An error is only possible for auth-int, which hashes the request body.
+func (t *digestTransport) authorize(req *http.Request) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := t.challenge
+	if ch == nil {
+		return "", nil
+	}
+	ch.nc++
+	nc := fmt.Sprintf("%08x", ch.nc)
+	cnonce := ch.cnonce
+	if cnonce == "" {
+		cnonce = newCnonce()
+	}
+	uri := req.URL.RequestURI()
+
+	ha1 := ch.hash(t.user + ":" + ch.realm + ":" + t.pass)
+	if ch.sess {
+		ha1 = ch.hash(ha1 + ":" + ch.nonce + ":" + cnonce)
+	}
+	a2 := req.Method + ":" + uri
+	if ch.qop == "auth-int" {
+		body, err := readBody(req)
+		if err != nil {
+			return "", err
+		}
+		a2 += ":" + ch.hash(string(body))
+	}
+	ha2 := ch.hash(a2)
+
+	var response string
+	if ch.qop != "" {
+		response = ch.hash(strings.Join([]string{ha1, ch.nonce, nc, cnonce, ch.qop, ha2}, ":"))
+	} else {
+		response = ch.hash(ha1 + ":" + ch.nonce + ":" + ha2)
+	}
+
+	parts := []string{
+		"username=" + quote(t.user),
+		"realm=" + quote(ch.realm),
+		"nonce=" + quote(ch.nonce),
+		"uri=" + quote(uri),
+		"response=" + quote(response),
+	}
+	if ch.algorithm != "" {
+		parts = append(parts, "algorithm="+ch.algorithm)
+	}
+	if ch.opaque != "" {
+		parts = append(parts, "opaque="+quote(ch.opaque))
+	}
+	if ch.qop != "" {
+		parts = append(parts, "qop="+ch.qop, "nc="+nc, "cnonce="+quote(cnonce))
+	}
+	return "Digest " + strings.Join(parts, ", "), nil
+}
+
+// readBody returns a copy of the request body without consuming it.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("digest auth-int needs a replayable request body")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// bestChallenge picks the strongest digest challenge among the
+// WWW-Authenticate headers of a response, preferring SHA-256 to MD5 as
+// RFC 7616 asks. It returns nil if none can be answered.
+func bestChallenge(headers []string) *digestChallenge {
+	var best *digestChallenge
+	for _, h := range headers {
+		ch := parseChallenge(h)
+		if ch == nil {
+			continue
+		}
+		if best == nil || ch.strong() && !best.strong() {
+			best = ch
+		}
+	}
+	return best
+}
+
+// strong reports whether the challenge uses a SHA-256 algorithm.
+func (ch *digestChallenge) strong() bool {
+	return strings.HasPrefix(strings.ToUpper(ch.algorithm), "SHA-256")
+}
+
+// parseChallenge parses a WWW-Authenticate header value, returning nil if it
+// is not a digest challenge or uses an algorithm or qop that is not
+// supported.
+func parseChallenge(header string) *digestChallenge {
+	const prefix = "digest "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return nil
+	}
+	ch := &digestChallenge{hash: md5Hex}
+	qopOffered := false
+	for _, field := range splitFields(header[len(prefix):]) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "realm":
+			ch.realm = value
+		case "nonce":
+			ch.nonce = value
+		case "opaque":
+			ch.opaque = value
+		case "algorithm":
+			ch.algorithm = value
+		case "qop":
+			qopOffered = true
+			for _, q := range strings.Split(value, ",") {
+				switch strings.TrimSpace(q) {
+				case "auth":
+					ch.qop = "auth"
+				case "auth-int":
+					if ch.qop == "" {
+						ch.qop = "auth-int"
+					}
+				}
+			}
+		}
+	}
+	switch strings.ToUpper(ch.algorithm) {
+	case "", "MD5":
+	case "MD5-SESS":
+		ch.sess = true
+	case "SHA-256":
+		ch.hash = sha256Hex
+	case "SHA-256-SESS":
+		ch.hash, ch.sess = sha256Hex, true
+	default:
+		return nil
+	}
+	if ch.nonce == "" || qopOffered && ch.qop == "" {
+		return nil
+	}
+	if ch.sess {
+		ch.cnonce = newCnonce()
+	}
+	return ch
+}
+
+// splitFields splits a comma separated parameter list, honouring quotes
+// and the backslash escapes inside them.
+func splitFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	quoted, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+			cur.WriteRune(r)
+		case r == '\\' && quoted:
+			escaped = true
+			cur.WriteRune(r)
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case r == ',' && !quoted:
+			fields = append(fields, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, strings.TrimSpace(cur.String()))
+	}
+	return fields
+}
+
+// cloneRequest returns a shallow copy of req with its own header map.
+func cloneRequest(req *http.Request) *http.Request {
+	return req.Clone(req.Context())
+}
+
+// quote returns s as a quoted-string, escaping quotes and backslashes.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// unquote strips the quotes of a quoted-string and resolves its escapes.
+// Tokens are returned unchanged.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range s[1 : len(s)-1] {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func newCnonce() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,538 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+const (
+	testUser  = "gouser"
+	testPass  = "secret"
+	testRealm = "monero-rpc"
+	testNonce = "abc123"
+)
+
+// digestServer returns a test server that enforces digest auth and echoes
+// the JSON-RPC method name as the result. challenges counts 401 responses.
+func digestServer(t *testing.T, challenges *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validDigest(r) {
+			atomic.AddInt32(challenges, 1)
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Digest qop="auth",algorithm=MD5,realm="%s",nonce="%s",stale=false`, testRealm, testNonce))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Method == "fail" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":    req.ID,
+				"error": map[string]interface{}{"code": -13, "message": "No wallet file"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     req.ID,
+			"result": map[string]string{"method": req.Method},
+		})
+	}))
+}
+
+// validDigest checks the Authorization header against the test credentials.
+func validDigest(r *http.Request) bool {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Digest ") {
+		return false
+	}
+	params := map[string]string{}
+	for _, f := range splitFields(h[len("Digest "):]) {
+		k, v, _ := strings.Cut(f, "=")
+		params[k] = strings.Trim(v, `"`)
+	}
+	ha1 := md5Hex(testUser + ":" + testRealm + ":" + testPass)
+	ha2 := md5Hex(r.Method + ":" + params["uri"])
+	want := md5Hex(strings.Join([]string{ha1, testNonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+	return params["username"] == testUser && params["response"] == want
+}
+
+// TestCallDigestAuth verifies authentication and challenge caching
+func TestCallDigestAuth(t *testing.T) {
+	var challenges int32
+	srv := digestServer(t, &challenges)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, testUser, testPass, Options{})
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		var out struct{ Method string }
+		if err := c.Call(context.Background(), "get_version", nil, &out); err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+		if out.Method != "get_version" {
+			t.Errorf("result method = %q, want get_version", out.Method)
+		}
+	}
+	if n := atomic.LoadInt32(&challenges); n != 1 {
+		t.Errorf("server issued %d challenges, want 1 (challenge should be cached)", n)
+	}
+}
+
+// TestCallBadCredentials verifies that wrong credentials fail
+func TestCallBadCredentials(t *testing.T) {
+	var challenges int32
+	srv := digestServer(t, &challenges)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, testUser, "wrong", Options{})
+	err := c.Call(context.Background(), "get_version", nil, nil)
+	if errors.GetKind(err) != errors.KindAuth {
+		t.Errorf("Call() with wrong password error = %v, want KindAuth", err)
+	}
+}
+
+// TestCallRPCError verifies JSON-RPC errors are surfaced
+func TestCallRPCError(t *testing.T) {
+	var challenges int32
+	srv := digestServer(t, &challenges)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, testUser, testPass, Options{})
+	err := c.Call(context.Background(), "fail", nil, nil)
+	if err == nil {
+		t.Fatal("Call() should return the server error")
+	}
+	if !strings.Contains(err.Error(), "No wallet file") {
+		t.Errorf("error %q should contain server message", err)
+	}
+	if errors.GetKind(err) != errors.KindRPC {
+		t.Errorf("Call() error kind = %v, want KindRPC", errors.GetKind(err))
+	}
+
+	c = NewClient(srv.URL, testUser, testPass, Options{
+		ErrorKind: func(*Error) errors.Kind { return errors.KindWallet },
+	})
+	if err := c.Call(context.Background(), "fail", nil, nil); errors.GetKind(err) != errors.KindWallet {
+		t.Errorf("Call() with ErrorKind error = %v, want KindWallet", err)
+	}
+}
+
+// TestMaxConcurrent verifies that in-flight calls are bounded
+func TestMaxConcurrent(t *testing.T) {
+	var inFlight, peak int32
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		mu.Lock()
+		if n > peak {
+			peak = n
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte(`{"id":1,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", Options{MaxConcurrent: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Call(context.Background(), "get_info", nil, nil); err != nil {
+				t.Errorf("Call() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+}
+
+// TestParseChallenge verifies WWW-Authenticate parsing
+func TestParseChallenge(t *testing.T) {
+	ch := parseChallenge(`Digest qop="auth,auth-int", realm="monero-rpc", nonce="n1", opaque="o,1"`)
+	if ch == nil {
+		t.Fatal("parseChallenge() returned nil")
+	}
+	if ch.realm != "monero-rpc" || ch.nonce != "n1" || ch.opaque != "o,1" || ch.qop != "auth" {
+		t.Errorf("parseChallenge() = %+v", ch)
+	}
+	if parseChallenge(`Basic realm="x"`) != nil {
+		t.Error("parseChallenge() should reject non-digest challenges")
+	}
+}
+
+// TestDigestKnownAnswers checks responses against the examples of RFC
+// 2617 section 3.5 and RFC 7616 section 3.9.1
+func TestDigestKnownAnswers(t *testing.T) {
+	tests := []struct {
+		header, pass, cnonce, want string
+	}{
+		{
+			`Digest realm="testrealm@host.com", qop="auth,auth-int", nonce="dcd98b7102dd2f0e8b11d0f600bfb0c093", opaque="5ccc069c403ebaf9f0171e9517f40e41"`,
+			"Circle Of Life", "0a4f113b", "6629fae49393a05397450978507c4ef1",
+		},
+		{
+			`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=MD5, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`,
+			"Circle of Life", "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ", "8ca523f5e9506fed4657c9700eebdbec",
+		},
+		{
+			`Digest realm="http-auth@example.org", qop="auth, auth-int", algorithm=SHA-256, nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`,
+			"Circle of Life", "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ", "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/dir/index.html", nil)
+	for _, tt := range tests {
+		ch := parseChallenge(tt.header)
+		if ch == nil {
+			t.Fatalf("parseChallenge(%s) returned nil", tt.header)
+		}
+		ch.cnonce = tt.cnonce
+		dt := &digestTransport{user: "Mufasa", pass: tt.pass, challenge: ch}
+		auth, err := dt.authorize(req)
+		if err != nil || !strings.Contains(auth, `response="`+tt.want+`"`) || !strings.Contains(auth, "nc=00000001") {
+			t.Errorf("authorize() = %s, %v, want response %s", auth, err, tt.want)
+		}
+	}
+}
+
+// TestDigestVariants authenticates against servers using the -sess
+// algorithms, auth-int, several challenges and credentials needing
+// escapes
+func TestDigestVariants(t *testing.T) {
+	tests := []struct {
+		name       string
+		challenges []string
+		user       string
+		algorithm  string
+	}{
+		{"md5-sess", []string{`Digest realm="r", nonce="n1", qop="auth", algorithm=MD5-sess`}, testUser, "MD5-sess"},
+		{"auth-int", []string{`Digest realm="r", nonce="n2", qop="auth-int"`}, testUser, ""},
+		{"rfc2069", []string{`Digest realm="r", nonce="n3"`}, testUser, ""},
+		{"prefers sha-256", []string{
+			`Digest realm="r", nonce="n4", qop="auth", algorithm=MD5`,
+			`Digest realm="r", nonce="n4", qop="auth", algorithm=SHA-256-sess`,
+		}, testUser, "SHA-256-sess"},
+		{"escaped", []string{`Digest realm="a \"quoted\" realm", nonce="n5", qop="auth"`}, `go"user\`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if !checkDigest(r, body, tt.user, testPass, tt.algorithm) {
+					for _, c := range tt.challenges {
+						w.Header().Add("WWW-Authenticate", c)
+					}
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"id":1,"result":{}}`)
+			}))
+			defer srv.Close()
+			c := NewClient(srv.URL, tt.user, testPass, Options{})
+			defer c.Close()
+			for i := 0; i < 2; i++ {
+				if err := c.Call(context.Background(), "get_version", nil, nil); err != nil {
+					t.Fatalf("Call() error = %v", err)
+				}
+			}
+		})
+	}
+	ch := parseChallenge(`Digest realm="r", nonce="n", algorithm=SHA-512-256`)
+	if ch != nil || parseChallenge(`Digest realm="r", nonce="n", qop="auth-conf"`) != nil {
+		t.Error("parseChallenge() should reject unsupported algorithms and qops")
+	}
+}
+
+// checkDigest verifies an Authorization header as a server would,
+// following RFC 2617 and RFC 7616
+func checkDigest(r *http.Request, body []byte, user, pass, algorithm string) bool {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Digest ") {
+		return false
+	}
+	p := map[string]string{}
+	for _, f := range splitFields(h[len("Digest "):]) {
+		k, v, _ := strings.Cut(f, "=")
+		p[k] = unquote(v)
+	}
+	if p["username"] != user || p["algorithm"] != algorithm || p["uri"] != r.URL.RequestURI() {
+		return false
+	}
+	hash := md5Hex
+	if strings.HasPrefix(algorithm, "SHA-256") {
+		hash = sha256Hex
+	}
+	ha1 := hash(user + ":" + p["realm"] + ":" + pass)
+	if strings.HasSuffix(algorithm, "-sess") {
+		ha1 = hash(ha1 + ":" + p["nonce"] + ":" + p["cnonce"])
+	}
+	a2 := r.Method + ":" + p["uri"]
+	if p["qop"] == "auth-int" {
+		a2 += ":" + hash(string(body))
+	}
+	want := hash(ha1 + ":" + p["nonce"] + ":" + hash(a2))
+	if p["qop"] != "" {
+		want = hash(strings.Join([]string{ha1, p["nonce"], p["nc"], p["cnonce"], p["qop"], hash(a2)}, ":"))
+	}
+	return p["response"] == want
+}
+
+// TestTransportOptions verifies that calls go through a configured proxy
+// or replacement transport
+func TestTransportOptions(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host == "node.invalid:18081" {
+			proxied.Add(1)
+		}
+		fmt.Fprint(w, `{"id":1,"result":{}}`)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	c := NewClient("http://node.invalid:18081", "", "", Options{Proxy: http.ProxyURL(proxyURL), DisableKeepAlives: true})
+	if err := c.Call(context.Background(), "get_info", nil, nil); err != nil || proxied.Load() != 1 {
+		t.Errorf("Call() through proxy = %v, %d proxied requests", err, proxied.Load())
+	}
+	c.Close()
+
+	var trips atomic.Int32
+	rt := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		trips.Add(1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"id":1,"result":{}}`)),
+			Request:    r,
+		}, nil
+	})
+	c = NewClient("http://node.invalid:18081", "", "", Options{Transport: rt})
+	defer c.Close()
+	if err := c.Call(context.Background(), "get_info", nil, nil); err != nil || trips.Load() != 1 {
+		t.Errorf("Call() with custom transport = %v, %d round trips", err, trips.Load())
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// TestRetry verifies that only calls safe to repeat are retried
+func TestRetry(t *testing.T) {
+	var failures, requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":1,"result":{}}`)
+	}))
+	defer srv.Close()
+
+	var attempts atomic.Int32
+	retry := func(ctx context.Context, fn func(ctx context.Context) error) error {
+		var err error
+		for i := 0; i < 3; i++ {
+			attempts.Add(1)
+			if err = fn(ctx); !errors.IsRetryable(err) {
+				return err
+			}
+		}
+		return err
+	}
+	c := NewClient(srv.URL, "", "", Options{Retry: retry})
+	defer c.Close()
+
+	failures.Store(2)
+	if err := c.Call(context.Background(), "get_info", nil, nil); err != nil || requests.Load() != 3 {
+		t.Errorf("get_info after two failures = %v, %d requests, want success after 3", err, requests.Load())
+	}
+	failures.Store(2)
+	requests.Store(0)
+	err := c.Call(context.Background(), "transfer", nil, nil)
+	if errors.GetKind(err) != errors.KindNetwork || !errors.IsRetryable(err) || requests.Load() != 1 {
+		t.Errorf("transfer = %v, %d requests, want one attempt and the original error", err, requests.Load())
+	}
+
+	// A refused connection never reached the server, so any call is retried
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	c = NewClient(closed.URL, "", "", Options{Retry: retry})
+	defer c.Close()
+	attempts.Store(0)
+	if err := c.Call(context.Background(), "transfer", nil, nil); err == nil || attempts.Load() != 3 {
+		t.Errorf("transfer to a closed port = %v after %d attempts, want 3", err, attempts.Load())
+	}
+}
+
+// TestCircuitBreaker verifies that a failing endpoint is left alone until
+// the cooldown has passed and a probe succeeds
+func TestCircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"id":1,"result":{}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", Options{BreakerThreshold: 2, BreakerCooldown: 50 * time.Millisecond})
+	defer c.Close()
+	ctx := context.Background()
+
+	down.Store(true)
+	for i := 0; i < 2; i++ {
+		if err := c.Call(ctx, "get_info", nil, nil); err == nil {
+			t.Fatal("Call() to a failing endpoint succeeded")
+		}
+	}
+	err := c.Call(ctx, "get_info", nil, nil)
+	if !stderrors.Is(err, ErrCircuitOpen) || errors.GetKind(err) != errors.KindNetwork || !c.CircuitOpen() || requests.Load() != 2 {
+		t.Errorf("Call() with the breaker open = %v, %d requests, want ErrCircuitOpen without a request", err, requests.Load())
+	}
+
+	// A failed probe reopens the breaker at once
+	time.Sleep(60 * time.Millisecond)
+	if err := c.CallPath(ctx, "/get_height", nil, nil); err == nil || stderrors.Is(err, ErrCircuitOpen) {
+		t.Errorf("probe = %v, want the endpoint's error", err)
+	}
+	if err := c.Call(ctx, "get_info", nil, nil); !stderrors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Call() after a failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	down.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if err := c.Call(ctx, "get_info", nil, nil); err != nil || c.CircuitOpen() {
+		t.Errorf("Call() after recovery = %v, breaker open %v", err, c.CircuitOpen())
+	}
+}
+
+// TestBatch verifies native batching and the per-call fallback
+func TestBatch(t *testing.T) {
+	for _, native := range []bool{true, false} {
+		t.Run(fmt.Sprintf("native=%v", native), func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				var raw json.RawMessage
+				json.NewDecoder(r.Body).Decode(&raw)
+				if raw[0] == '[' {
+					if !native {
+						http.Error(w, "batch not supported", http.StatusBadRequest)
+						return
+					}
+					var reqs []request
+					json.Unmarshal(raw, &reqs)
+					out := make([]map[string]interface{}, len(reqs))
+					for i, req := range reqs {
+						out[i] = map[string]interface{}{"id": req.ID, "result": map[string]string{"method": req.Method}}
+					}
+					json.NewEncoder(w).Encode(out)
+					return
+				}
+				var req request
+				json.Unmarshal(raw, &req)
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "result": map[string]string{"method": req.Method}})
+			}))
+			defer srv.Close()
+
+			c := NewClient(srv.URL, "", "", Options{})
+			methods := []string{"get_info", "sync_info", "get_connections"}
+			calls := make([]*BatchCall, len(methods))
+			results := make([]struct{ Method string }, len(methods))
+			for i, m := range methods {
+				calls[i] = &BatchCall{Method: m, Result: &results[i]}
+			}
+			if err := c.Batch(context.Background(), calls); err != nil {
+				t.Fatalf("Batch() error = %v", err)
+			}
+			for i, m := range methods {
+				if calls[i].Err != nil {
+					t.Errorf("%s: Err = %v", m, calls[i].Err)
+				}
+				if results[i].Method != m {
+					t.Errorf("result[%d] = %q, want %q", i, results[i].Method, m)
+				}
+			}
+			want := int32(1)
+			if !native {
+				want = int32(1 + len(methods))
+			}
+			if n := atomic.LoadInt32(&requests); n != want {
+				t.Errorf("server saw %d requests, want %d", n, want)
+			}
+		})
+	}
+}
+
+// TestStats verifies per-method request accounting
+func TestStats(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{"id":1,"result":{}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "", "", Options{MaxConcurrent: 1})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Call(context.Background(), "get_balance", nil, nil)
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s := c.Stats()
+		if s.InFlight == 1 && s.Queued == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats() = %+v, want 1 in flight and 2 queued", s)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	s := c.Stats()
+	m := s.Methods["get_balance"]
+	if s.InFlight != 0 || s.Queued != 0 || m.Calls != 3 || m.Errors != 0 {
+		t.Errorf("Stats() after completion = %+v", s)
+	}
+	if m.MaxLatency <= 0 || m.AvgLatency() <= 0 {
+		t.Errorf("latencies not recorded: %+v", m)
+	}
+}
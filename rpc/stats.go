@@ -0,0 +1,128 @@
+package rpc
+
+import (
+	"sync"
+	"time"
+)
+
+// MethodStats summarizes calls made for a single RPC method.
+//
+// Fields:
+//   - Calls: Completed calls, successful or not
+//   - Errors: Completed calls that failed at the transport or HTTP level
+//   - InFlight: Calls currently sent and awaiting a response
+//   - Queued: Calls waiting for a client concurrency slot
+//   - TotalLatency: Sum of round-trip latencies of completed calls
+//   - MaxLatency: Slowest completed call
+type MethodStats struct {
+	Calls        uint64        `json:"calls"`
+	Errors       uint64        `json:"errors"`
+	InFlight     int           `json:"in_flight"`
+	Queued       int           `json:"queued"`
+	TotalLatency time.Duration `json:"total_latency"`
+	MaxLatency   time.Duration `json:"max_latency"`
+}
+
+// AvgLatency returns the mean latency of completed calls.
+func (s MethodStats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// Stats is a point-in-time snapshot of a client's request activity.
+//
+// Fields:
+//   - InFlight: Total calls awaiting a response
+//   - Queued: Total calls waiting for a concurrency slot
+//   - Methods: Per-method breakdown keyed by method name or path
+//
+// monero-wallet-rpc handles requests one at a time, so an InFlight value
+// persistently above one means requests are queueing inside the wallet.
+type Stats struct {
+	InFlight int                    `json:"in_flight"`
+	Queued   int                    `json:"queued"`
+	Methods  map[string]MethodStats `json:"methods"`
+}
+
+// statsRecorder accumulates per-method request statistics.
+type statsRecorder struct {
+	mu      sync.Mutex
+	methods map[string]*MethodStats
+}
+
+// method returns the entry for name, creating it if needed. Caller holds mu.
+func (r *statsRecorder) method(name string) *MethodStats {
+	if r.methods == nil {
+		r.methods = make(map[string]*MethodStats)
+	}
+	s, ok := r.methods[name]
+	if !ok {
+		s = &MethodStats{}
+		r.methods[name] = s
+	}
+	return s
+}
+
+// queued records a call waiting for a concurrency slot.
+func (r *statsRecorder) queued(name string) {
+	r.mu.Lock()
+	r.method(name).Queued++
+	r.mu.Unlock()
+}
+
+// started records a call leaving the queue and being sent.
+func (r *statsRecorder) started(name string, wasQueued bool) {
+	r.mu.Lock()
+	s := r.method(name)
+	if wasQueued {
+		s.Queued--
+	}
+	s.InFlight++
+	r.mu.Unlock()
+}
+
+// dequeued records a queued call abandoned before being sent.
+func (r *statsRecorder) dequeued(name string) {
+	r.mu.Lock()
+	r.method(name).Queued--
+	r.mu.Unlock()
+}
+
+// finished records the completion of a sent call.
+func (r *statsRecorder) finished(name string, latency time.Duration, failed bool) {
+	r.mu.Lock()
+	s := r.method(name)
+	s.InFlight--
+	s.Calls++
+	if failed {
+		s.Errors++
+	}
+	s.TotalLatency += latency
+	if latency > s.MaxLatency {
+		s.MaxLatency = latency
+	}
+	r.mu.Unlock()
+}
+
+// snapshot returns a copy of the current statistics.
+func (r *statsRecorder) snapshot() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := Stats{Methods: make(map[string]MethodStats, len(r.methods))}
+	for name, s := range r.methods {
+		out.Methods[name] = *s
+		out.InFlight += s.InFlight
+		out.Queued += s.Queued
+	}
+	return out
+}
+
+// Stats returns a snapshot of in-flight, queued and completed requests.
+//
+// Returns:
+//   - Stats: Totals and a per-method breakdown
+func (c *Client) Stats() Stats {
+	return c.stats.snapshot()
+}
@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// Default client tuning values
+const (
+	// DefaultTimeout is the per-request timeout applied when Options.Timeout is zero
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultMaxIdleConns is the number of idle keep-alive connections kept per host
+	DefaultMaxIdleConns = 4
+
+	// DefaultIdleConnTimeout is how long an idle keep-alive connection is kept open
+	DefaultIdleConnTimeout = 90 * time.Second
+
+	// DefaultKeepAlive is the TCP keep-alive probe interval for RPC connections
+	DefaultKeepAlive = 30 * time.Second
+)
+
+// Options tunes the HTTP behaviour of a Client.
+//
+// Fields:
+//   - Timeout: Maximum duration of a single request, including retries for auth
+//   - MaxIdleConns: Idle keep-alive connections kept open to the endpoint
+//   - MaxConnsPerHost: Hard limit on simultaneous connections, 0 for no limit
+//   - IdleConnTimeout: How long idle connections are kept before closing
+//   - KeepAlive: TCP keep-alive probe interval
+//   - DialTimeout: Limit on establishing a connection, Timeout when zero
+//   - ResponseHeaderTimeout: Limit on waiting for the response headers
+//     once the request is sent, 0 for none beyond Timeout
+//   - DisableKeepAlives: Open a new connection for every request
+//   - Proxy: Chooses the proxy for a request, such as
+//     http.ProxyURL(socks5URL); nil connects directly, ignoring the
+//     HTTP_PROXY environment so local daemons are not proxied
+//   - Transport: Replaces the built transport entirely, with digest
+//     authentication still layered on top; the connection options above
+//     and TLS are then ignored
+//   - MaxConcurrent: Maximum in-flight calls, 0 for no limit. Further calls
+//     wait until a slot frees up or their context is cancelled
+//   - Component: Component name used when wrapping errors
+//   - TLS: Client TLS settings for https endpoints, nil for system defaults
+//   - ErrorKind: Classifies JSON-RPC errors returned by the server, such
+//     as wallet errors by code; nil reports them all as KindRPC
+//   - Retry: Repeats calls that failed transiently, such as a closure
+//     around util.Retry with jittered backoff; nil makes one attempt.
+//     A call is only repeated when it never reached the server or its
+//     method is idempotent, so a transfer is never sent twice
+//   - Idempotent: Reports whether a method or path is safe to repeat;
+//     nil accepts those starting with get_
+//   - BreakerThreshold: Consecutive failures reaching the endpoint after
+//     which calls are rejected with ErrCircuitOpen, 0 for no breaker
+//   - BreakerCooldown: How long calls are rejected before one is let
+//     through to probe the endpoint, DefaultBreakerCooldown when zero
+//
+// Zero values are replaced by the package defaults.
+type Options struct {
+	Timeout         time.Duration
+	MaxIdleConns    int
+	MaxConnsPerHost int
+	IdleConnTimeout time.Duration
+	KeepAlive       time.Duration
+
+	DialTimeout           time.Duration
+	ResponseHeaderTimeout time.Duration
+	DisableKeepAlives     bool
+	Proxy                 func(*http.Request) (*url.URL, error)
+	Transport             http.RoundTripper
+
+	MaxConcurrent int
+	Component     string
+	TLS           *tls.Config
+	ErrorKind     func(*Error) errors.Kind
+
+	Retry            func(ctx context.Context, fn func(ctx context.Context) error) error
+	Idempotent       func(method string) bool
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// withDefaults returns a copy of o with zero values replaced by defaults.
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if o.KeepAlive <= 0 {
+		o.KeepAlive = DefaultKeepAlive
+	}
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = o.Timeout
+	}
+	if o.Component == "" {
+		o.Component = errors.ComponentRPC
+	}
+	if o.ErrorKind == nil {
+		o.ErrorKind = func(*Error) errors.Kind { return errors.KindRPC }
+	}
+	if o.Idempotent == nil {
+		o.Idempotent = readOnly
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = DefaultBreakerCooldown
+	}
+	return o
+}
+
+// request is a JSON-RPC 2.0 request envelope.
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      uint64      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC 2.0 response envelope.
+type response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Error is a JSON-RPC error returned by monerod or monero-wallet-rpc.
+//
+// Fields:
+//   - Code: Numeric error code reported by the server
+//   - Message: Human-readable error description
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
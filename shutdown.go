@@ -0,0 +1,144 @@
+package moneroger
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// stoppable is a service stopped by stopInOrder.
+type stoppable interface {
+	Shutdown(ctx context.Context) error
+	Exited() <-chan struct{}
+}
+
+// shutdownNode is one service in the shutdown dependency graph.
+//
+// Fields:
+//   - name: Identifies the service in logs and in dependsOn
+//   - svc: The service
+//   - dependsOn: Names of the services this one uses, which stop after it
+type shutdownNode struct {
+	name      string
+	svc       stoppable
+	dependsOn []string
+}
+
+// shutdownStages orders nodes into stages by their dependencies. Each
+// stage holds the services no service of a later stage depends on, so
+// wallets come before the daemon they use.
+//
+// Returns:
+//   - [][]shutdownNode: Stages in the order they stop
+//   - error: If the dependencies form a cycle
+func shutdownStages(nodes []shutdownNode) ([][]shutdownNode, error) {
+	dependents := make(map[string]int)
+	for _, n := range nodes {
+		for _, dep := range n.dependsOn {
+			dependents[dep]++
+		}
+	}
+	var stages [][]shutdownNode
+	for remaining := nodes; len(remaining) > 0; {
+		var stage, rest []shutdownNode
+		for _, n := range remaining {
+			if dependents[n.name] == 0 {
+				stage = append(stage, n)
+			} else {
+				rest = append(rest, n)
+			}
+		}
+		if len(stage) == 0 {
+			names := make([]string, len(rest))
+			for i, n := range rest {
+				names[i] = n.name
+			}
+			return nil, fmt.Errorf("shutdown dependency cycle among %v", names)
+		}
+		for _, n := range stage {
+			for _, dep := range n.dependsOn {
+				dependents[dep]--
+			}
+		}
+		stages = append(stages, stage)
+		remaining = rest
+	}
+	return stages, nil
+}
+
+// stopInOrder shuts services down stage by stage. A stage begins only
+// once every service of the previous one has exited, and services within
+// a stage stop concurrently. Each stage has its own timeout, so a slow
+// wallet cannot use up the time the daemon needs to close its database.
+//
+// Parameters:
+//   - ctx: Context bounding the whole shutdown
+//   - logger: Destination for progress messages
+//   - stageTimeout: Time allowed for each stage
+//   - nodes: The services and their dependencies
+//
+// Returns:
+//   - error: The errors of the first stage that failed. The services of
+//     later stages are left running, since a service that failed to stop
+//     may still be using them
+func stopInOrder(ctx context.Context, logger *slog.Logger, stageTimeout time.Duration, nodes []shutdownNode) error {
+	stages, err := shutdownStages(nodes)
+	if err != nil {
+		return errors.E(errors.OpShutdown, errors.KindConfig, err)
+	}
+	for i, stage := range stages {
+		if err := stopStage(ctx, stageTimeout, stage); err != nil {
+			for _, later := range stages[i+1:] {
+				for _, n := range later {
+					logger.Warn("left running, a service using it failed to stop", "service", n.name)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// stopStage shuts down the services of one stage concurrently and waits
+// for their processes to exit.
+func stopStage(ctx context.Context, timeout time.Duration, stage []shutdownNode) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	errs := make([]error, len(stage))
+	var wg sync.WaitGroup
+	for i, n := range stage {
+		wg.Add(1)
+		go func(i int, n shutdownNode) {
+			defer wg.Done()
+			errs[i] = stopAndWait(ctx, n)
+		}(i, n)
+	}
+	wg.Wait()
+	return stderrors.Join(errs...)
+}
+
+// stopAndWait shuts a service down and waits until its process has
+// exited.
+func stopAndWait(ctx context.Context, n shutdownNode) error {
+	if err := n.svc.Shutdown(ctx); err != nil {
+		return err
+	}
+	exited := n.svc.Exited()
+	if exited == nil {
+		// Adopted or remote services have no process to wait for
+		return nil
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		return errors.E(errors.OpShutdown, errors.KindTimeout,
+			fmt.Errorf("%s did not exit: %w", n.name, ctx.Err()))
+	}
+}
@@ -0,0 +1,228 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// statusQueryTimeout bounds each RPC query made by Status
+const statusQueryTimeout = 5 * time.Second
+
+// StateRemote is the daemon state reported by Status when a remote node
+// replaces the local daemon.
+const StateRemote = "remote"
+
+// Status is a snapshot of all managed services, suitable for JSON
+// serialization.
+//
+// Fields:
+//   - Daemon: monerod, or the remote node replacing it
+//   - Wallets: The default wallet first, then wallets added with
+//     AddWallet sorted by name
+type Status struct {
+	Daemon  DaemonStatus   `json:"daemon"`
+	Wallets []WalletStatus `json:"wallets"`
+}
+
+// DaemonStatus describes monerod. Fields read over RPC are zero when the
+// daemon does not answer, with Error saying why.
+//
+// Fields:
+//   - State: Lifecycle state such as "running" or "crashed", see
+//     util.ServiceState, or StateRemote
+//   - PID: Process ID, 0 when not spawned by the manager
+//   - Port: RPC port
+//   - RestrictedPort: Port of the unauthenticated restricted RPC server,
+//     0 when disabled
+//   - Uptime: How long the process has been running
+//   - Adopted: Whether the manager attached to an already running daemon
+//   - Version: monerod version, such as "0.18.3.1"
+//   - Height, TargetHeight: Local chain height and network height
+//   - Synchronized: Whether the local chain is synchronized
+//   - Peers: Incoming plus outgoing peer connections
+//   - Usage: Resources the process uses, nil without a local process
+//   - RPC: In-flight, queued and per-method latency statistics of the
+//     manager's daemon RPC calls, taken before Status queries the daemon
+//   - Error: Why the RPC fields could not be read
+type DaemonStatus struct {
+	State          string             `json:"state"`
+	PID            int                `json:"pid,omitempty"`
+	Port           int                `json:"port"`
+	RestrictedPort int                `json:"restricted_port,omitempty"`
+	Uptime         time.Duration      `json:"uptime_ns"`
+	Adopted        bool               `json:"adopted,omitempty"`
+	Version        string             `json:"version,omitempty"`
+	Height         uint64             `json:"height,omitempty"`
+	TargetHeight   uint64             `json:"target_height,omitempty"`
+	Synchronized   bool               `json:"synchronized"`
+	Peers          uint64             `json:"peers"`
+	Usage          *util.ProcessUsage `json:"usage,omitempty"`
+	RPC            rpc.Stats          `json:"rpc"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// WalletStatus describes one monero-wallet-rpc process. Fields read over
+// RPC are zero when the wallet does not answer, with Error saying why.
+//
+// Fields:
+//   - Name: Wallet name, DefaultWalletName for the default wallet
+//   - State: Lifecycle state such as "running" or "crashed", see
+//     util.ServiceState
+//   - PID: Process ID, 0 when not running
+//   - Port: RPC port
+//   - Uptime: How long the process has been running
+//   - Version: wallet RPC interface version, such as "1.26"
+//   - WalletOpen: Whether a wallet file is open
+//   - Wallet: Name of the open wallet file
+//   - Height: Blocks scanned by the open wallet
+//   - Refreshed: Whether the open wallet has scanned up to its daemon's
+//     height
+//   - Usage: Resources the process uses, nil when not running
+//   - RPC: In-flight, queued and per-method latency statistics of the
+//     manager's wallet RPC calls, taken before Status queries the wallet
+//   - Error: Why the RPC fields could not be read
+type WalletStatus struct {
+	Name       string             `json:"name"`
+	State      string             `json:"state"`
+	PID        int                `json:"pid,omitempty"`
+	Port       int                `json:"port"`
+	Uptime     time.Duration      `json:"uptime_ns"`
+	Version    string             `json:"version,omitempty"`
+	WalletOpen bool               `json:"wallet_open"`
+	Wallet     string             `json:"wallet,omitempty"`
+	Height     uint64             `json:"height,omitempty"`
+	Refreshed  bool               `json:"refreshed"`
+	Usage      *util.ProcessUsage `json:"usage,omitempty"`
+	RPC        rpc.Stats          `json:"rpc"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// Status reports the state of every managed service, combining process
+// information and resource usage with what the services report over RPC.
+// CPU use is measured since the previous call, so callers polling Status
+// see current load rather than a lifetime average.
+//
+// Parameters:
+//   - ctx: Context bounding the RPC queries
+//
+// Returns:
+//   - *Status: Snapshot of the daemon and all wallets; services that do
+//     not answer are reported with Error set rather than failing the call
+//
+// Related:
+//   - Wallets for process information only, without RPC queries
+func (m *Moneroger) Status(ctx context.Context) *Status {
+	status := &Status{Daemon: m.daemonStatus(ctx)}
+	for _, info := range m.Wallets() {
+		w := m.monerowalletrpc
+		if info.Name != DefaultWalletName {
+			m.walletsMu.RLock()
+			managed := m.wallets[info.Name]
+			m.walletsMu.RUnlock()
+			if managed == nil {
+				continue
+			}
+			w = managed.rpc
+		}
+		ws := walletStatus(ctx, info, w)
+		ws.Usage = m.processUsage(ws.PID)
+		status.Wallets = append(status.Wallets, ws)
+	}
+	return status
+}
+
+// daemonStatus collects the daemon's process state and get_info answer.
+func (m *Moneroger) daemonStatus(ctx context.Context) DaemonStatus {
+	d := m.monerod
+	s := DaemonStatus{
+		State:          d.State().String(),
+		PID:            pid(d.PID()),
+		Port:           d.RPCPort(),
+		RestrictedPort: d.RestrictedPort(),
+		Uptime:         d.Uptime(),
+		Adopted:        d.Adopted(),
+		RPC:            d.RPCStats(),
+	}
+	if len(m.currentConfig().RemoteNodeList()) > 0 {
+		s.State, s.Port, s.RestrictedPort = StateRemote, 0, 0
+		return s
+	}
+	s.Usage = m.processUsage(s.PID)
+	ctx, cancel := context.WithTimeout(ctx, statusQueryTimeout)
+	defer cancel()
+	info, err := d.Client().GetInfo(ctx)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.Version = info.Version
+	s.Height = info.Height
+	s.TargetHeight = info.TargetHeight
+	s.Synchronized = info.LocalChainReady()
+	s.Peers = info.IncomingConnectionsCount + info.OutgoingConnectionsCount
+	return s
+}
+
+// walletStatus collects a wallet's process state, version and height,
+// and whether the height has caught up with its daemon.
+func walletStatus(ctx context.Context, info WalletInfo, w *monerowalletrpc.WalletRPC) WalletStatus {
+	s := WalletStatus{
+		Name:       info.Name,
+		State:      w.State().String(),
+		PID:        pid(info.PID),
+		Port:       info.Port,
+		Uptime:     info.Uptime,
+		WalletOpen: info.Wallet != "",
+		Wallet:     info.Wallet,
+		RPC:        w.RPCStats(),
+	}
+	ctx, cancel := context.WithTimeout(ctx, statusQueryTimeout)
+	defer cancel()
+	version, err := w.Client().GetVersion(ctx)
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.Version = fmt.Sprintf("%d.%d", version.Major(), version.Minor())
+	if s.WalletOpen {
+		height, daemonHeight, err := w.RefreshProgress(ctx)
+		if err != nil {
+			s.Error = err.Error()
+			return s
+		}
+		s.Height = height
+		s.Refreshed = height+refreshSlack >= daemonHeight
+	}
+	return s
+}
+
+// processUsage measures a service process, nil when there is none or it
+// cannot be inspected. Processes in containers are not measured: the
+// PID is that of the docker client.
+func (m *Moneroger) processUsage(pid int) *util.ProcessUsage {
+	if _, docker := m.currentConfig().Driver().(*util.DockerDriver); docker || pid == 0 {
+		return nil
+	}
+	usage, err := m.usage.Measure(pid)
+	if err != nil {
+		m.logger().Debug("could not measure process", "pid", pid, "error", err)
+		return nil
+	}
+	return &usage
+}
+
+// pid converts a PID string as returned by the services into a number,
+// 0 when there is no process.
+func pid(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
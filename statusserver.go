@@ -0,0 +1,168 @@
+package moneroger
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// OpStatusServer is the operation name for errors from the HTTP status server
+const OpStatusServer errors.Op = "StatusServer"
+
+const (
+	// statusRequestTimeout bounds the RPC queries made for one request
+	statusRequestTimeout = 30 * time.Second
+
+	// statusHeaderTimeout bounds how long a client may take to send headers
+	statusHeaderTimeout = 10 * time.Second
+)
+
+// StatusHandler returns an HTTP handler serving the manager's probes and
+// status, for embedding in an application's own server.
+//
+// Returns:
+//   - http.Handler: Serves the following paths to GET and HEAD requests:
+//     /healthz: 200 when Live passes, 503 with the reason otherwise
+//     /readyz: 200 when Ready passes, 503 with the reason otherwise
+//     /status: Status as JSON
+//
+// Related:
+//   - util.Config.StatusAddress to have the manager serve it itself
+func (m *Moneroger) StatusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", m.probeHandler(func(context.Context) error {
+		return m.Live()
+	}))
+	mux.HandleFunc("/readyz", m.probeHandler(m.Ready))
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if !readOnly(w, r) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), statusRequestTimeout)
+		defer cancel()
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(m.Status(ctx)); err != nil {
+			m.logger().Debug("status response not sent", "error", err)
+		}
+	})
+	return mux
+}
+
+// probeHandler serves the outcome of a probe as the HTTP status code.
+func (m *Moneroger) probeHandler(probe func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !readOnly(w, r) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), statusRequestTimeout)
+		defer cancel()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := probe(ctx); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// readOnly rejects requests other than GET and HEAD, reporting whether
+// the request may be served.
+func readOnly(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+// listenStatus opens the listener of the HTTP status server before the
+// services start, so a taken address fails startup early.
+//
+// Returns:
+//   - net.Listener: The listener, serving TLS when config.StatusTLS is
+//     set, nil when config.StatusAddress is empty
+//   - error: KindConfig if the TLS certificate cannot be loaded,
+//     KindNetwork if the address cannot be bound
+func listenStatus(config util.Config) (net.Listener, error) {
+	if config.StatusAddress == "" {
+		return nil, nil
+	}
+	tlsConfig, err := config.StatusTLS.ServerConfig()
+	if err != nil {
+		return nil, errors.E(OpStatusServer, errors.KindConfig, err)
+	}
+	ln, err := net.Listen("tcp", config.StatusAddress)
+	if err != nil {
+		return nil, errors.E(OpStatusServer, errors.KindNetwork, err)
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	return ln, nil
+}
+
+// requireToken rejects requests that do not carry token as a bearer
+// token, comparing in constant time so the token cannot be guessed from
+// response times. An empty token lets every request through.
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveStatus serves StatusHandler on ln until shutdown closes the
+// server, requiring token on every request when it is set. A nil
+// listener leaves the server disabled.
+func (m *Moneroger) serveStatus(ln net.Listener, token string) {
+	if ln == nil {
+		return
+	}
+	m.statusServer = &http.Server{
+		Handler:           requireToken(token, m.StatusHandler()),
+		ReadHeaderTimeout: statusHeaderTimeout,
+	}
+	m.logger().Info("serving status over HTTP", "address", ln.Addr().String())
+	go func(server *http.Server) {
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			m.logger().Error("status server stopped", "error", err)
+		}
+	}(m.statusServer)
+}
+
+// closeStatus stops the HTTP status server, if one is running.
+func (m *Moneroger) closeStatus(ctx context.Context) {
+	if m.statusServer == nil {
+		return
+	}
+	if err := m.statusServer.Shutdown(ctx); err != nil {
+		m.statusServer.Close()
+	}
+}
+
+// closeListener closes a listener from listenStatus that will not be
+// served, such as when startup failed.
+func closeListener(ln net.Listener) {
+	if ln != nil {
+		ln.Close()
+	}
+}
@@ -0,0 +1,218 @@
+package moneroger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// supervised is a managed service whose process can be watched and restarted.
+// Both monerod.MoneroDaemon and monerowalletrpc.WalletRPC implement it.
+type supervised interface {
+	Start(ctx context.Context) error
+	Exited() <-chan struct{}
+	ExitErr() error
+	Uptime() time.Duration
+	Stopping() bool
+}
+
+// supervise watches a service and restarts it according to policy when it
+// exits without Shutdown having been requested.
+//
+// Parameters:
+//   - ctx: Manager lifetime context, supervision stops when it is done
+//   - logger: Destination for crash and restart messages
+//   - svc: The service to watch
+//   - policy: Restart limits and backoff
+//   - onCrash: Optional hook run when an unexpected exit is detected
+//   - afterRestart: Optional hook run after each successful restart
+//
+// Consecutive failures back off exponentially; a process that stayed up
+// for at least policy.MinUptime resets the retry budget.
+func supervise(ctx context.Context, logger *slog.Logger, svc supervised, policy util.RestartPolicy, onCrash func(error), afterRestart func(context.Context)) {
+	attempt := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-svc.Exited():
+		}
+		if ctx.Err() != nil || svc.Stopping() {
+			return
+		}
+
+		uptime := svc.Uptime()
+		logger.Warn("process exited unexpectedly", "uptime", uptime.Round(time.Millisecond), "error", svc.ExitErr())
+		if onCrash != nil {
+			onCrash(svc.ExitErr())
+		}
+		if uptime >= policy.MinUptime {
+			attempt = 0
+		}
+
+		for {
+			if !policy.Enabled() || policy.Exhausted(attempt) {
+				logger.Error("giving up on restarts", "attempts", attempt)
+				return
+			}
+			sleepContext(ctx, policy.Delay(attempt))
+			if ctx.Err() != nil {
+				return
+			}
+			attempt++
+			// The restarted process must outlive the supervisor's context,
+			// otherwise stopping supervision would kill it
+			if err := svc.Start(context.WithoutCancel(ctx)); err != nil {
+				logger.Warn("restart failed", "attempt", attempt, "error", err)
+				continue
+			}
+			logger.Info("restarted", "attempt", attempt)
+			if afterRestart != nil {
+				afterRestart(ctx)
+			}
+			break
+		}
+	}
+}
+
+// supervision is a running supervise goroutine that can be stopped, so a
+// service can be restarted deliberately without its supervisor racing
+// the restart.
+type supervision struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startSupervision runs fn in a goroutine with a context derived from ctx.
+func startSupervision(ctx context.Context, fn func(ctx context.Context)) *supervision {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &supervision{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		fn(ctx)
+	}()
+	return s
+}
+
+// stop cancels the supervision and waits for its goroutine to return.
+// It is safe to call on a nil supervision.
+func (s *supervision) stop() {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+// startSupervisors launches supervision goroutines for both services.
+//
+// Parameters:
+//   - ctx: Manager lifetime context
+//
+// A restarted daemon invalidates the cached health of every wallet so
+// their reconnection is verified on the next check. Crashes and restarts
+// are published as lifecycle events.
+func (m *Moneroger) startSupervisors(ctx context.Context) {
+	m.daemonSup = m.superviseDaemon(ctx)
+	m.walletSup = m.superviseWallet(ctx, DefaultWalletName, m.monerowalletrpc)
+}
+
+// superviseDaemon launches a supervision goroutine for monerod.
+func (m *Moneroger) superviseDaemon(ctx context.Context) *supervision {
+	policy := m.currentConfig().Restart
+	return startSupervision(ctx, func(ctx context.Context) {
+		supervise(ctx, m.logger().With("component", errors.ComponentMonerod), m.monerod, policy,
+			func(err error) {
+				m.emit(EventDaemonCrashed, errors.ComponentMonerod, err)
+			},
+			func(context.Context) {
+				m.eachWallet(func(_ string, w *monerowalletrpc.WalletRPC) {
+					w.InvalidateHealth()
+				})
+				m.emit(EventDaemonRestarted, errors.ComponentMonerod, nil)
+			},
+		)
+	})
+}
+
+// superviseWallet launches a supervision goroutine for one wallet.
+//
+// Parameters:
+//   - ctx: Supervision lifetime, cancelled when the wallet is removed
+//   - name: Wallet name reported in events and logs
+//   - wallet: The wallet to supervise
+//
+// Returns:
+//   - *supervision: Handle stopping the supervision
+func (m *Moneroger) superviseWallet(ctx context.Context, name string, wallet *monerowalletrpc.WalletRPC) *supervision {
+	logger := m.logger().With("component", errors.ComponentWalletRPC, "wallet", name)
+	policy := m.currentConfig().Restart
+	return startSupervision(ctx, func(ctx context.Context) {
+		supervise(ctx, logger, wallet, policy,
+			func(err error) {
+				m.emitWallet(EventWalletCrashed, name, err)
+			},
+			func(context.Context) {
+				m.emitWallet(EventWalletRestarted, name, nil)
+			},
+		)
+	})
+}
+
+// pauseDaemonSupervision stops the supervision of monerod, so a
+// deliberate restart is not taken for a crash. Caller holds m.reloadMu.
+//
+// Returns:
+//   - func(): Supervises monerod again; call it even if the restart fails
+func (m *Moneroger) pauseDaemonSupervision() func() {
+	m.daemonSup.stop()
+	return func() { m.daemonSup = m.superviseDaemon(m.backgroundContext()) }
+}
+
+// pauseWalletSupervision stops the supervision of one wallet, so a
+// deliberate restart is not taken for a crash. Caller holds m.reloadMu.
+//
+// Parameters:
+//   - name: Wallet name, DefaultWalletName for the default wallet
+//
+// Returns:
+//   - func(): Supervises the wallet again; call it even if the restart
+//     fails. It does nothing for a wallet that is no longer registered
+func (m *Moneroger) pauseWalletSupervision(name string) func() {
+	if name == DefaultWalletName {
+		m.walletSup.stop()
+		return func() {
+			m.walletSup = m.superviseWallet(m.backgroundContext(), DefaultWalletName, m.monerowalletrpc)
+		}
+	}
+	m.walletsMu.RLock()
+	w := m.wallets[name]
+	m.walletsMu.RUnlock()
+	if w == nil {
+		return func() {}
+	}
+	w.sup.stop()
+	return func() { w.sup = m.superviseWallet(m.backgroundContext(), name, w.rpc) }
+}
+
+// closed reports whether the manager was shut down or detached, after
+// which services must not be restarted.
+func (m *Moneroger) closed() bool {
+	m.walletsMu.RLock()
+	defer m.walletsMu.RUnlock()
+	return m.walletsClosed
+}
+
+// sleepContext sleeps for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,86 @@
+package moneroger
+
+import (
+	"context"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+)
+
+// OpWaitForSync is the operation name for errors from WaitForSync
+const OpWaitForSync errors.Op = "WaitForSync"
+
+// walletSyncInterval is how often WaitForSync polls wallet heights
+const walletSyncInterval = 2 * time.Second
+
+// WaitForSync blocks until the services are usable rather than merely
+// listening: monerod has synchronized its chain, and every managed wallet
+// with a wallet open has scanned up to the daemon's height.
+//
+// Parameters:
+//   - ctx: Context bounding the wait
+//
+// Returns:
+//   - error: KindTimeout once ctx is done before everything is synced
+//
+// With a remote node only the wallets are waited for. Wallets without an
+// open wallet have nothing to scan and count as caught up. Failed polls,
+// such as a wallet busy refreshing, are retried until ctx is done.
+//
+// Related:
+//   - WatchSync for progress updates while waiting
+func (m *Moneroger) WaitForSync(ctx context.Context) error {
+	if len(m.currentConfig().RemoteNodeList()) == 0 {
+		for s := range m.monerod.WatchSync(ctx) {
+			if s.Synced {
+				break
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return errors.E(OpWaitForSync, errors.ComponentMonerod, errors.KindTimeout, err)
+	}
+
+	wallets := map[string]*monerowalletrpc.WalletRPC{DefaultWalletName: m.monerowalletrpc}
+	m.walletsMu.RLock()
+	for name, w := range m.wallets {
+		if w != nil {
+			wallets[name] = w.rpc
+		}
+	}
+	m.walletsMu.RUnlock()
+	for name, w := range wallets {
+		if err := m.waitWalletRefreshed(ctx, name, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitWalletRefreshed polls w until its open wallet has caught up with
+// the daemon's height.
+func (m *Moneroger) waitWalletRefreshed(ctx context.Context, name string, w *monerowalletrpc.WalletRPC) error {
+	for {
+		if w.CurrentWallet() == "" {
+			return nil
+		}
+		walletHeight, daemonHeight, err := w.RefreshProgress(ctx)
+		switch {
+		case err != nil:
+			m.logger().Debug("wallet refresh progress unavailable", "wallet", name, "error", err)
+		case walletHeight >= daemonHeight:
+			return nil
+		default:
+			m.logger().Debug("wallet refreshing", "wallet", name, "height", walletHeight, "target", daemonHeight)
+		}
+
+		t := time.NewTimer(walletSyncInterval)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return errors.E(OpWaitForSync, errors.ComponentWalletRPC, errors.KindTimeout, ctx.Err())
+		}
+	}
+}
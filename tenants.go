@@ -0,0 +1,424 @@
+package moneroger
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+const (
+	// OpAddTenant is the operation name for errors from AddTenant
+	OpAddTenant errors.Op = "AddTenant"
+
+	// OpRemoveTenant is the operation name for errors from RemoveTenant
+	OpRemoveTenant errors.Op = "RemoveTenant"
+
+	// OpTenant is the operation name for errors from Tenant and
+	// AuthenticateTenant
+	OpTenant errors.Op = "Tenant"
+
+	// OpTenantCall is the operation name for errors from the calls a
+	// Tenant routes to its wallet
+	OpTenantCall errors.Op = "Tenant.Call"
+)
+
+// tenantDeniedMethods are wallet-rpc methods tenants cannot call, as
+// they would bypass the manager's supervision of the process.
+var tenantDeniedMethods = map[string]bool{
+	"stop_wallet": true,
+}
+
+// walletFileMethods are wallet-rpc methods creating a wallet file,
+// counted against TenantQuota.MaxWallets.
+var walletFileMethods = map[string]bool{
+	"create_wallet":                true,
+	"generate_from_keys":           true,
+	"restore_deterministic_wallet": true,
+}
+
+// TenantQuota limits what a tenant may do with its wallet process.
+//
+// Fields:
+//   - CallsPerMinute: RPC calls accepted per minute, 0 for no limit
+//   - MaxWallets: Wallet files the tenant's wallet directory may hold,
+//     0 for no limit
+type TenantQuota struct {
+	CallsPerMinute int
+	MaxWallets     int
+}
+
+// TenantConfig describes a tenant of a hosted wallet service: a
+// customer given its own wallet-rpc process, credentials to reach it
+// through the manager, and a quota.
+//
+// Fields:
+//   - ID: Unique identifier of the tenant
+//   - User, Pass: Credentials the tenant presents to AuthenticateTenant;
+//     User defaults to ID and Pass is generated when empty. They are
+//     distinct from the wallet-rpc credentials, which stay with the
+//     manager so tenants cannot bypass their quota
+//   - Wallet: The tenant's wallet process; Name defaults to
+//     "tenant-" followed by ID
+//   - Quota: Limits applied to calls routed through the Tenant
+type TenantConfig struct {
+	ID     string
+	User   string
+	Pass   string
+	Wallet WalletConfig
+	Quota  TenantQuota
+}
+
+// Tenant routes a tenant's RPC calls and events to its wallet process,
+// enforcing its quota. It is safe for concurrent use.
+//
+// Fields:
+//   - m: The manager running the tenant's wallet
+//   - id: Tenant identifier
+//   - user, pass: Credentials checked by AuthenticateTenant
+//   - wallet: Name of the tenant's wallet in the manager
+//   - walletDir: Directory holding the tenant's wallet files
+//   - quota: Limits on the tenant's calls and wallet files
+//   - windowStart, calls: Start of the current one-minute rate window
+//     and the calls made in it
+type Tenant struct {
+	m         *Moneroger
+	id        string
+	user      string
+	pass      string
+	wallet    string
+	walletDir string
+	quota     TenantQuota
+
+	mu          sync.Mutex
+	windowStart time.Time
+	calls       int
+}
+
+// AddTenant starts a wallet-rpc process for a new tenant with AddWallet
+// and registers the tenant for routing.
+//
+// Parameters:
+//   - ctx: Context bounding the startup only; the tenant's wallet keeps
+//     running after it ends, until RemoveTenant or Shutdown
+//   - cfg: Identifier, credentials, wallet and quota of the tenant
+//
+// Returns:
+//   - *Tenant: Handle routing the tenant's calls and events
+//   - error: KindConfig for a missing or duplicate ID or credentials
+//     used by another tenant, otherwise any AddWallet error
+//
+// Example:
+//
+//	tenant, err := manager.AddTenant(ctx, moneroger.TenantConfig{
+//	    ID:     "acme",
+//	    Wallet: moneroger.WalletConfig{WalletDir: "/srv/wallets/acme", Port: 18090},
+//	    Quota:  moneroger.TenantQuota{CallsPerMinute: 600, MaxWallets: 10},
+//	})
+//
+// Related:
+//   - AuthenticateTenant for finding the tenant of incoming requests
+//   - RemoveTenant
+func (m *Moneroger) AddTenant(ctx context.Context, cfg TenantConfig) (*Tenant, error) {
+	if cfg.ID == "" {
+		return nil, errors.E(OpAddTenant, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("tenant ID cannot be empty"))
+	}
+	if cfg.User == "" {
+		cfg.User = cfg.ID
+	}
+	if cfg.Pass == "" {
+		cfg.Pass = util.SecurePassword()
+	}
+	if cfg.Wallet.Name == "" {
+		cfg.Wallet.Name = "tenant-" + cfg.ID
+	}
+	t := &Tenant{
+		m:         m,
+		id:        cfg.ID,
+		user:      cfg.User,
+		pass:      cfg.Pass,
+		wallet:    cfg.Wallet.Name,
+		walletDir: cfg.Wallet.WalletDir,
+		quota:     cfg.Quota,
+	}
+	if err := m.reserveTenant(t); err != nil {
+		return nil, err
+	}
+	if _, err := m.AddWallet(ctx, cfg.Wallet); err != nil {
+		m.releaseTenant(t)
+		return nil, err
+	}
+	m.logger().Info("tenant added", "tenant", t.id, "wallet", t.wallet)
+	return t, nil
+}
+
+// RemoveTenant stops a tenant's wallet process with RemoveWallet and
+// unregisters the tenant.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdown
+//   - id: ID passed to AddTenant
+//
+// Returns:
+//   - error: KindConfig for unknown IDs, otherwise any shutdown error
+func (m *Moneroger) RemoveTenant(ctx context.Context, id string) error {
+	t, err := m.Tenant(id)
+	if err != nil {
+		return errors.E(OpRemoveTenant, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("no tenant with ID %q", id))
+	}
+	err = m.RemoveWallet(ctx, t.wallet)
+	m.releaseTenant(t)
+	return err
+}
+
+// Tenant returns a tenant by ID.
+//
+// Parameters:
+//   - id: ID passed to AddTenant
+//
+// Returns:
+//   - *Tenant: The tenant
+//   - error: KindConfig if no tenant has that ID
+func (m *Moneroger) Tenant(id string) (*Tenant, error) {
+	m.tenantsMu.RLock()
+	defer m.tenantsMu.RUnlock()
+	if t := m.tenants[id]; t != nil {
+		return t, nil
+	}
+	return nil, errors.E(OpTenant, errors.ComponentWalletRPC, errors.KindConfig,
+		fmt.Errorf("no tenant with ID %q", id))
+}
+
+// AuthenticateTenant finds the tenant presenting the given credentials,
+// so a front end can route each request to the right wallet process.
+//
+// Parameters:
+//   - user, pass: Credentials from TenantConfig
+//
+// Returns:
+//   - *Tenant: The tenant they belong to
+//   - error: KindAuth when no tenant matches
+func (m *Moneroger) AuthenticateTenant(user, pass string) (*Tenant, error) {
+	m.tenantsMu.RLock()
+	defer m.tenantsMu.RUnlock()
+	for _, t := range m.tenants {
+		userOK := subtle.ConstantTimeCompare([]byte(user), []byte(t.user))
+		passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(t.pass))
+		if userOK&passOK == 1 {
+			return t, nil
+		}
+	}
+	return nil, errors.E(OpTenant, errors.ComponentWalletRPC, errors.KindAuth,
+		fmt.Errorf("invalid tenant credentials"))
+}
+
+// Tenants returns the IDs of the registered tenants, sorted.
+func (m *Moneroger) Tenants() []string {
+	m.tenantsMu.RLock()
+	defer m.tenantsMu.RUnlock()
+	ids := make([]string, 0, len(m.tenants))
+	for id := range m.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// reserveTenant claims the tenant's ID, user and wallet name, so
+// concurrent AddTenant calls cannot use the same ones.
+func (m *Moneroger) reserveTenant(t *Tenant) error {
+	m.tenantsMu.Lock()
+	defer m.tenantsMu.Unlock()
+	if _, taken := m.tenants[t.id]; taken {
+		return errors.E(OpAddTenant, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("tenant %q already exists", t.id))
+	}
+	for _, other := range m.tenants {
+		if other.user == t.user {
+			return errors.E(OpAddTenant, errors.ComponentWalletRPC, errors.KindConfig,
+				fmt.Errorf("tenant user %q is taken", t.user))
+		}
+	}
+	if m.tenants == nil {
+		m.tenants = make(map[string]*Tenant)
+		m.walletTenants = make(map[string]string)
+	}
+	m.tenants[t.id] = t
+	m.walletTenants[t.wallet] = t.id
+	return nil
+}
+
+// releaseTenant unregisters a tenant added by reserveTenant.
+func (m *Moneroger) releaseTenant(t *Tenant) {
+	m.tenantsMu.Lock()
+	defer m.tenantsMu.Unlock()
+	if m.tenants[t.id] == t {
+		delete(m.tenants, t.id)
+		delete(m.walletTenants, t.wallet)
+	}
+}
+
+// walletTenant returns the ID of the tenant owning the named wallet, or
+// "" when it belongs to none.
+func (m *Moneroger) walletTenant(name string) string {
+	m.tenantsMu.RLock()
+	defer m.tenantsMu.RUnlock()
+	return m.walletTenants[name]
+}
+
+// ID returns the tenant's identifier.
+func (t *Tenant) ID() string {
+	return t.id
+}
+
+// WalletName returns the name of the tenant's wallet in the manager, as
+// used with Moneroger.Wallet and in events.
+func (t *Tenant) WalletName() string {
+	return t.wallet
+}
+
+// Call forwards a JSON-RPC call to the tenant's wallet process after
+// checking the tenant's quota. Wallet files are best opened and created
+// with OpenWallet and CreateWallet, which the manager tracks.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - method: wallet-rpc method, such as "get_balance"
+//   - params: Request parameters, nil for none
+//   - result: Pointer receiving the decoded result, nil to discard it
+//
+// Returns:
+//   - error: KindConfig for methods tenants may not call; KindQuota,
+//     marked transient, when the call rate is exceeded, and KindQuota
+//     when a method creating a wallet file would exceed MaxWallets;
+//     otherwise the wallet's RPC errors
+func (t *Tenant) Call(ctx context.Context, method string, params, result interface{}) error {
+	w, err := t.route(method)
+	if err != nil {
+		return err
+	}
+	return w.Client().RPC().Call(ctx, method, params, result)
+}
+
+// CreateWallet creates a wallet file in the tenant's wallet directory
+// and opens it, like WalletRPC.CreateWallet, within the tenant's quota.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - name: Wallet file name, without directory
+//   - password: Password protecting the wallet file
+//   - language: Mnemonic seed language, English when empty
+//
+// Returns:
+//   - error: KindQuota when the call rate or MaxWallets is exceeded,
+//     otherwise any WalletRPC.CreateWallet error
+func (t *Tenant) CreateWallet(ctx context.Context, name, password, language string) error {
+	w, err := t.route("create_wallet")
+	if err != nil {
+		return err
+	}
+	return w.CreateWallet(ctx, name, password, language)
+}
+
+// OpenWallet opens a wallet file from the tenant's wallet directory, like
+// WalletRPC.OpenWallet, within the tenant's call rate.
+//
+// Parameters:
+//   - ctx: Context for cancellation and deadlines
+//   - name: Wallet file name, without directory
+//   - password: Password of the wallet file
+//
+// Returns:
+//   - error: KindQuota when the call rate is exceeded, otherwise any
+//     WalletRPC.OpenWallet error
+func (t *Tenant) OpenWallet(ctx context.Context, name, password string) error {
+	w, err := t.route("open_wallet")
+	if err != nil {
+		return err
+	}
+	return w.OpenWallet(ctx, name, password)
+}
+
+// Subscribe returns a channel receiving only the events of the tenant's
+// wallet, such as EventWalletCrashed and EventServiceDegraded.
+//
+// Returns:
+//   - <-chan Event: Buffered event stream; stop it with
+//     Moneroger.Unsubscribe, and it is closed after EventShutdownComplete
+func (t *Tenant) Subscribe() <-chan Event {
+	id := t.id
+	return t.m.events.subscribeFiltered(func(ev Event) bool {
+		return ev.Tenant == id
+	})
+}
+
+// route checks a call to method against the tenant's permissions and
+// quota, returning the wallet to send it to.
+func (t *Tenant) route(method string) (*monerowalletrpc.WalletRPC, error) {
+	if tenantDeniedMethods[method] {
+		return nil, errors.E(OpTenantCall, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("tenants cannot call %s", method))
+	}
+	if !t.allow(time.Now()) {
+		return nil, errors.Transient(errors.E(OpTenantCall, errors.ComponentWalletRPC, errors.KindQuota,
+			fmt.Errorf("tenant %q exceeded %d calls per minute", t.id, t.quota.CallsPerMinute)))
+	}
+	if walletFileMethods[method] {
+		if err := t.checkWallets(); err != nil {
+			return nil, err
+		}
+	}
+	return t.m.Wallet(t.wallet)
+}
+
+// allow counts a call made at now against the tenant's call rate,
+// reporting whether it is within the quota.
+func (t *Tenant) allow(now time.Time) bool {
+	if t.quota.CallsPerMinute <= 0 {
+		return true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now.Sub(t.windowStart) >= time.Minute {
+		t.windowStart = now
+		t.calls = 0
+	}
+	if t.calls >= t.quota.CallsPerMinute {
+		return false
+	}
+	t.calls++
+	return true
+}
+
+// checkWallets fails with KindQuota when the tenant's wallet directory
+// already holds MaxWallets wallet files, counted by their .keys files.
+func (t *Tenant) checkWallets() error {
+	if t.quota.MaxWallets <= 0 {
+		return nil
+	}
+	keys, err := filepath.Glob(filepath.Join(t.walletDir, "*.keys"))
+	if err != nil {
+		return errors.E(OpTenantCall, errors.ComponentWalletRPC, errors.KindSystem, err)
+	}
+	count := 0
+	for _, k := range keys {
+		if info, err := os.Stat(k); err == nil && info.Mode().IsRegular() {
+			count++
+		}
+	}
+	if count >= t.quota.MaxWallets {
+		return errors.E(OpTenantCall, errors.ComponentWalletRPC, errors.KindQuota,
+			fmt.Errorf("tenant %q already has %d wallets", t.id, count))
+	}
+	return nil
+}
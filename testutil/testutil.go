@@ -0,0 +1,73 @@
+// Package testutil provides helpers for tests that exercise moneroger
+// against real or mocked Monero services. Every generated configuration is
+// isolated, so tests can run with -parallel and alongside a real node.
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/moneroger/util"
+)
+
+// Config returns a configuration isolated to the calling test.
+//
+// Parameters:
+//   - tb: The test or benchmark; temporary resources are cleaned up with it
+//
+// Returns:
+//   - util.Config: Configuration with:
+//   - DataDir: A fresh temporary directory
+//   - WalletDir: A "wallets" directory inside DataDir
+//   - MoneroPort, WalletPort: Distinct, currently free ephemeral ports
+//   - TestNet: true
+//   - RPC credentials: Unique per call
+//
+// Ports are only guaranteed free at the time of the call; another process
+// may still claim them before the test binds them.
+func Config(tb testing.TB) util.Config {
+	tb.Helper()
+
+	dataDir := tb.TempDir()
+	walletDir := filepath.Join(dataDir, "wallets")
+	if err := os.MkdirAll(walletDir, 0o700); err != nil {
+		tb.Fatal(err)
+	}
+
+	ports := FreePorts(tb, 2)
+	suffix := util.SecurePassword()[:8]
+	return util.Config{
+		DataDir:       dataDir,
+		WalletDir:     walletDir,
+		MoneroPort:    ports[0],
+		WalletPort:    ports[1],
+		TestNet:       true,
+		MoneroRPCUser: fmt.Sprintf("monerod-%s", suffix),
+		MoneroRPCPass: util.SecurePassword(),
+		WalletRPCUser: fmt.Sprintf("wallet-%s", suffix),
+		WalletRPCPass: util.SecurePassword(),
+	}
+}
+
+// FreePorts returns n distinct TCP ports that are free on localhost.
+//
+// Parameters:
+//   - tb: The test or benchmark, failed if ports cannot be allocated
+//   - n: Number of ports to allocate
+//
+// Returns:
+//   - []int: Distinct port numbers
+//
+// Ports are distinct within one call, and parallel tests in this process
+// are not given the same ports, since util.GetFreePorts withholds ports
+// it has handed out for a while.
+func FreePorts(tb testing.TB, n int) []int {
+	tb.Helper()
+	ports, err := util.GetFreePorts("", n, util.PortRange{})
+	if err != nil {
+		tb.Fatalf("allocating free ports: %v", err)
+	}
+	return ports
+}
@@ -0,0 +1,29 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/opd-ai/moneroger/util"
+)
+
+// TestConfigIsolation verifies that generated configs never share resources
+func TestConfigIsolation(t *testing.T) {
+	a := Config(t)
+	b := Config(t)
+
+	if a.DataDir == b.DataDir {
+		t.Error("configs share DataDir")
+	}
+	if a.MoneroPort == a.WalletPort {
+		t.Error("daemon and wallet ports collide")
+	}
+	if a.MoneroRPCPass == b.MoneroRPCPass || a.WalletRPCUser == b.WalletRPCUser {
+		t.Error("configs share credentials")
+	}
+	if !util.DirExists(a.WalletDir) {
+		t.Errorf("wallet directory %s was not created", a.WalletDir)
+	}
+	if util.IsPortInUse(a.MoneroPort) || util.IsPortInUse(a.WalletPort) {
+		t.Error("allocated ports should be free")
+	}
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// ReadBanList reads a file of hosts for monerod to refuse peers from:
+// one IPv4 or IPv6 address or CIDR subnet per line, with blank lines and
+// text after # ignored. This is the format of monerod's --ban-list
+// files, so published block lists can be used unchanged.
+//
+// Parameters:
+//   - path: File to read
+//
+// Returns:
+//   - []string: Addresses and subnets in file order
+//   - error: If the file cannot be read, naming the line of the first
+//     entry that is neither an address nor a subnet
+func ReadBanList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ban list: %w", err)
+	}
+	defer f.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		host, _, _ := strings.Cut(scanner.Text(), "#")
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		if net.ParseIP(host) == nil {
+			if _, _, err := net.ParseCIDR(host); err != nil {
+				return nil, fmt.Errorf("ban list %s line %d: %q is not an IP address or subnet", path, line, host)
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ban list %s: %w", path, err)
+	}
+	return hosts, nil
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"fmt"
+	"net"
+)
+
+// ValidateBindIP checks an RPC bind address from the configuration.
+//
+// Parameters:
+//   - ip: IPv4 address to bind, empty for the default loopback address
+//
+// Returns:
+//   - error: If ip is set but not an IPv4 address
+func ValidateBindIP(ip string) error {
+	if ip == "" {
+		return nil
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() == nil {
+		return fmt.Errorf("invalid RPC bind address %q: must be an IPv4 address", ip)
+	}
+	return nil
+}
+
+// BindArgs returns the --rpc-bind-ip flags shared by monerod and
+// monero-wallet-rpc. Binding anything other than a loopback address also
+// passes --confirm-external-bind, without which both programs refuse to
+// start.
+//
+// Parameters:
+//   - ip: IPv4 address to bind, empty for the default
+//
+// Returns:
+//   - []string: Command line arguments, nil for the default
+func BindArgs(ip string) []string {
+	if ip == "" {
+		return nil
+	}
+	args := []string{"--rpc-bind-ip", ip}
+	if parsed := net.ParseIP(ip); parsed == nil || !parsed.IsLoopback() {
+		args = append(args, "--confirm-external-bind")
+	}
+	return args
+}
+
+// DialHost returns the host used to reach a server bound to ip. Servers
+// on the default or a wildcard address are reached over localhost.
+//
+// Parameters:
+//   - ip: Configured bind address
+//
+// Returns:
+//   - string: Host to dial
+func DialHost(ip string) string {
+	if parsed := net.ParseIP(ip); parsed == nil || parsed.IsUnspecified() {
+		return "localhost"
+	}
+	return ip
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BootstrapAuto lets monerod pick a bootstrap daemon from its public peers.
+const BootstrapAuto = "auto"
+
+// BootstrapConfig lets a freshly started local daemon forward wallet
+// queries to another node until its own chain has caught up.
+//
+// Fields:
+//   - Address: Bootstrap node as "host:port", or BootstrapAuto; empty
+//     disables bootstrapping
+//   - Login: Optional "user:pass" credentials for the bootstrap node
+//
+// Answers served by the bootstrap node are marked untrusted by monerod,
+// so a malicious bootstrap node can see which outputs the wallet asks
+// for. Prefer a node you operate.
+type BootstrapConfig struct {
+	Address string
+	Login   string
+}
+
+// Enabled reports whether a bootstrap daemon is configured.
+func (b BootstrapConfig) Enabled() bool {
+	return b.Address != ""
+}
+
+// Validate checks that the address and login are well formed.
+//
+// Returns:
+//   - error: Description of the first invalid field, nil if valid
+func (b BootstrapConfig) Validate() error {
+	if !b.Enabled() {
+		if b.Login != "" {
+			return fmt.Errorf("bootstrap login requires a bootstrap address")
+		}
+		return nil
+	}
+	if b.Address != BootstrapAuto {
+		if err := validateHostPort(b.Address); err != nil {
+			return fmt.Errorf("invalid bootstrap daemon %q: %w", b.Address, err)
+		}
+	}
+	if b.Login != "" && !strings.Contains(b.Login, ":") {
+		return fmt.Errorf("bootstrap login must be in user:pass form")
+	}
+	return nil
+}
+
+// DaemonOptions returns the secret monerod options of this
+// configuration, for WriteOptionsFile.
+//
+// Returns:
+//   - map[string]string: The bootstrap login, nil without one
+func (b BootstrapConfig) DaemonOptions() map[string]string {
+	if !b.Enabled() || b.Login == "" {
+		return nil
+	}
+	return map[string]string{"bootstrap-daemon-login": b.Login}
+}
+
+// DaemonArgs returns the monerod flags implementing this configuration.
+//
+// Parameters:
+//   - proxy: SOCKS proxy for the bootstrap connection, empty for a direct
+//     connection; pass the Tor proxy so bootstrapping does not leak
+//
+// Returns:
+//   - []string: Command line arguments, nil when bootstrapping is disabled
+//
+// The login is a secret and is left out; pass DaemonOptions through a
+// config file instead.
+func (b BootstrapConfig) DaemonArgs(proxy string) []string {
+	if !b.Enabled() {
+		return nil
+	}
+	args := []string{"--bootstrap-daemon-address", b.Address}
+	if proxy != "" {
+		args = append(args, "--bootstrap-daemon-proxy", proxy)
+	}
+	return args
+}
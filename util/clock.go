@@ -0,0 +1,67 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// WatchClockJumps reports suspend/resume events such as laptop sleep or a
+// paused VM. Go timers run on the monotonic clock, which on most platforms
+// stops while the system is suspended, so a suspend shows up as wall-clock
+// time passing faster than monotonic time. A stalled process shows up as
+// the ticker firing far later than scheduled. Either is reported.
+//
+// Parameters:
+//   - ctx: Context controlling the watcher's lifetime
+//   - interval: How often to sample the clocks
+//   - threshold: Minimum unexplained gap reported as a jump
+//
+// Returns:
+//   - <-chan time.Duration: Receives the size of each detected jump and is
+//     closed when ctx is done. Jumps are dropped if the receiver lags
+func WatchClockJumps(ctx context.Context, interval, threshold time.Duration) <-chan time.Duration {
+	jumps := make(chan time.Duration, 1)
+	go func() {
+		defer close(jumps)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				now := time.Now()
+				wall := now.Round(0).Sub(last.Round(0))
+				mono := now.Sub(last)
+				last = now
+				if jump, ok := detectClockJump(wall, mono, interval, threshold); ok {
+					select {
+					case jumps <- jump:
+					default:
+					}
+				}
+			}
+		}
+	}()
+	return jumps
+}
+
+// detectClockJump decides whether a sampling period hides a suspend.
+//
+// Parameters:
+//   - wall: Wall-clock time elapsed during the period
+//   - mono: Monotonic time elapsed during the period
+//   - interval: Expected period length
+//   - threshold: Minimum gap considered a jump
+//
+// Returns:
+//   - time.Duration: Size of the unexplained gap
+//   - bool: Whether the gap reaches threshold
+func detectClockJump(wall, mono, interval, threshold time.Duration) (time.Duration, bool) {
+	gap := wall - mono
+	if late := mono - interval; late > gap {
+		gap = late
+	}
+	return gap, gap >= threshold
+}
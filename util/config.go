@@ -1,19 +1,139 @@
 package util
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"math"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	moneroconst "github.com/opd-ai/moneroger/const"
 	"github.com/ricochet2200/go-disk-usage/du"
 	"github.com/spf13/viper"
 )
 
+// TwoHundredFiftyGigabytes was the free space RecommendConfig required
+// for a local node.
+//
+// Deprecated: Use RecommendThresholds; changing this has no effect.
 var TwoHundredFiftyGigabytes uint64 = uint64(250 * math.Pow(10, 9))
 
-func pickDefaultRemoteNode() string {
-	return "not enabled yet"
+// NodeMode is how RecommendConfig runs the blockchain.
+type NodeMode string
+
+const (
+	// NodeModeFull runs a local daemon keeping the whole blockchain
+	NodeModeFull NodeMode = "full"
+	// NodeModePruned runs a local daemon keeping a pruned blockchain
+	NodeModePruned NodeMode = "pruned"
+	// NodeModeRemote uses public remote nodes instead of a local daemon
+	NodeModeRemote NodeMode = "remote"
+)
+
+// RecommendThresholds are the free space limits RecommendConfig chooses
+// a NodeMode by. Zero fields take the DefaultRecommendThresholds values.
+//
+// Fields:
+//   - FullGB: Free gigabytes under DataDir needed for a full node
+//   - PrunedGB: Free gigabytes needed for a pruned node; with less a
+//     remote node is used
+type RecommendThresholds struct {
+	FullGB   int
+	PrunedGB int
+}
+
+// DefaultRecommendThresholds returns the thresholds used by
+// RecommendConfig: 250 GB for a full node and 100 GB for a pruned one,
+// leaving room for the blockchain to grow.
+func DefaultRecommendThresholds() RecommendThresholds {
+	return RecommendThresholds{FullGB: 250, PrunedGB: 100}
+}
+
+// Validate checks that the thresholds are not negative and that the
+// pruned one does not exceed the full one.
+//
+// Returns:
+//   - error: Description of the problem, nil if valid
+func (t RecommendThresholds) Validate() error {
+	if t.FullGB < 0 || t.PrunedGB < 0 {
+		return fmt.Errorf("invalid disk space thresholds %d GB and %d GB: must not be negative", t.FullGB, t.PrunedGB)
+	}
+	if full, pruned := t.effective(); pruned > full {
+		return fmt.Errorf("pruned node threshold %d GB exceeds full node threshold %d GB", pruned, full)
+	}
+	return nil
+}
+
+// effective returns the thresholds with zero fields replaced by their
+// defaults.
+func (t RecommendThresholds) effective() (full, pruned int) {
+	defaults := DefaultRecommendThresholds()
+	full, pruned = t.FullGB, t.PrunedGB
+	if full == 0 {
+		full = defaults.FullGB
+	}
+	if pruned == 0 {
+		pruned = min(defaults.PrunedGB, full)
+	}
+	return full, pruned
+}
+
+// Mode chooses how to run the blockchain with free bytes available.
+//
+// Parameters:
+//   - free: Bytes available under the data directory, see AvailableSpace
+//
+// Returns:
+//   - NodeMode: NodeModeFull from FullGB, NodeModePruned from PrunedGB,
+//     NodeModeRemote below that
+func (t RecommendThresholds) Mode(free uint64) NodeMode {
+	full, pruned := t.effective()
+	switch {
+	case free >= uint64(full)*Gigabyte:
+		return NodeModeFull
+	case free >= uint64(pruned)*Gigabyte:
+		return NodeModePruned
+	default:
+		return NodeModeRemote
+	}
+}
+
+// pickDefaultRemoteNodes benchmarks DefaultRemoteNodes and returns the
+// usable ones, best first. It returns nil, so a local daemon is used, when
+// none of them answers in time.
+func pickDefaultRemoteNodes() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteNodeSelectTimeout)
+	defer cancel()
+	nodes, err := SelectRemoteNodes(ctx, DefaultRemoteNodes)
+	if err != nil {
+		logger().Warn("remote node selection failed, falling back to a local daemon", "error", err)
+		return nil
+	}
+	logger().Info("selected remote node", "node", nodes[0], "fallbacks", len(nodes)-1)
+	return nodes
+}
+
+// AvailableSpace reports free disk space for path, measured on its
+// nearest existing ancestor so it works before the directory is created.
+//
+// Parameters:
+//   - path: Directory on the file system to measure
+//
+// Returns:
+//   - uint64: Bytes available to unprivileged users
+func AvailableSpace(path string) uint64 {
+	for !DirExists(path) {
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return du.NewDiskUsage(path).Available()
 }
 
 // Config holds the configuration parameters for both monerod and monero-wallet-rpc daemons.
@@ -23,26 +143,181 @@ func pickDefaultRemoteNode() string {
 //
 //   - DataDir: Base directory for blockchain data and wallet files
 //     Must be writable by the process
+//     Testnet and stagenet keep their state in its testnet and stagenet
+//     subdirectories; see NetworkDataDir
 //
-//   - WalletFile: Path to the Monero wallet file (.keys file)
-//     Can be absolute or relative to DataDir
+//   - WalletDir: Directory holding the wallet files, passed to
+//     monero-wallet-rpc as --wallet-dir
+//     Default: empty, using the network's data directory
+//
+//   - WalletName: Wallet file in WalletDir opened with the managed wallet
+//     password once wallet-rpc is ready; empty opens none
+//
+//   - WalletFile: Deprecated name of WalletDir, read when WalletDir is
+//     empty; despite its name it always held the directory
 //
 //   - MoneroPort: TCP port for monerod RPC service
-//     Default: 18081 (mainnet), 28081 (testnet)
+//     Default: 0, using 18081 (mainnet), 28081 (testnet), 38081 (stagenet)
 //     Must be available and accessible
 //
 //   - WalletPort: TCP port for monero-wallet-rpc service
-//     Default: 18082 (mainnet), 28082 (testnet)
+//     Default: 0, using 18083 (mainnet), 28083 (testnet), 38083 (stagenet)
 //     Must be available and accessible
 //
+//   - MoneroBindIP, WalletBindIP: IPv4 address each RPC server binds to
+//     Default: empty, binding loopback only
+//     Non-loopback addresses expose the RPC to the network
+//
+//   - AutoPort: Use a free port when a configured port is taken
+//     Default: false, failing with a port in use error
+//
+//   - AutoPortRange: Ports AutoPort chooses from, such as 20000-20999
+//     Default: empty, any free port the operating system offers
+//
 //   - TestNet: Flag to run services on Monero testnet
-//     true = testnet, false = mainnet
+//     true = testnet, false = mainnet; superseded by Network
+//
+//   - Network: NetworkMainnet, NetworkTestnet or NetworkStagenet
+//     Default: empty, selecting the network from TestNet
+//
+//   - MoneroRPCUser, MoneroRPCPass: Credentials for the monerod RPC
+//     Generated automatically when empty, and kept in the credential
+//     store, or else saved with mode 0600 to monerod.rpc-login in
+//     DataDir, so later runs can adopt the daemon
+//
+//   - WalletRPCUser, WalletRPCPass: Credentials for the wallet RPC
+//     Generated automatically when empty, and kept in the credential
+//     store when there is one
+//
+//   - Keyring: Keep generated RPC passwords and the wallet password in
+//     the OS keyring; see CredentialStore
+//
+//   - HealthInterval: Period of the RPC health checks reporting
+//     EventServiceDegraded and EventServiceRecovered;
+//     moneroconst.DefaultHealthInterval when 0, disabled when negative
+//
+//   - AutosaveInterval: Period of the store RPC calls saving open
+//     wallets, which are also saved before shutdown;
+//     moneroconst.DefaultAutosaveInterval when 0, disabled when negative
+//
+//   - PaymentInterval: Period of the get_transfers calls reporting
+//     incoming payments as EventPaymentReceived;
+//     moneroconst.DefaultPaymentInterval when 0, disabled when negative
+//
+//   - StartupTimeout, ShutdownTimeout: How long a service may take to
+//     become ready, and to exit once interrupted; the moneroconst
+//     defaults when 0. Creating a new blockchain database can take
+//     longer than the default startup timeout on slow disks
+//
+//   - StatusAddress: host:port serving /healthz, /readyz and /status
+//     over HTTP for container probes and load balancers; empty disables
+//     the server. Addresses other than loopback need StatusToken or
+//     StatusTLS
+//
+//   - StatusToken, StatusTLS: Bearer token every status request must
+//     carry, at least 16 characters, and certificate serving the status
+//     over https; both optional on loopback
+//
+//   - GRPC: Address, bearer token and TLS certificate of the gRPC
+//     management API served by cmd/moneroger; see package grpcapi
+//
+//   - Detach: Start monerod and wallet-rpc in their own session with
+//     output written to log files in DataDir, so they keep running
+//     after Moneroger.Detach and the manager exits
+//
+//   - MonerodExtraArgs, WalletRPCExtraArgs: Flags appended to the
+//     generated command lines, for options moneroger does not model;
+//     flags it sets itself are rejected by Validate
+//
+//   - Docker: Runs monerod and monero-wallet-rpc in containers of
+//     Docker.Image, mounting DataDir and publishing the RPC ports
+//     Default: empty, running them on the host
+//
+//   - ProcessDriver: Driver used instead of the one Docker selects
+//     Default: nil
+//
+//   - Credentials: CredentialProvider used instead of the OS keyring
+//     Default: nil
+//
+//   - Logger: Structured logger for all components
+//     Defaults to slog.Default() when nil
+//
+//   - DaemonOutput, WalletOutput: Writers receiving the live stdout and
+//     stderr of each child process, every line prefixed with the
+//     component name; nil disables streaming
+//
+//   - LogBufferSize: Bytes of combined output retained per child process
+//     for RecentLogs; moneroconst.DefaultOutputBufferSize when 0
+//
+//   - LogProcessOutput: Also forward every child process output line to
+//     Logger at debug level
+//
+//   - RemoteNode, RemoteNodes: Remote daemon and fallbacks; when any is
+//     set monerod is not started and wallet-rpc fails over between them
+//
+//   - Tor: Routes daemon and wallet traffic over a Tor SOCKS proxy;
+//     setting Tor.Proxy alone is enough
+//
+//   - I2P: Broadcasts transactions over I2P and optionally accepts
+//     anonymous inbound peers; the router must be running at startup
+//
+//   - DaemonLogLevel, OutPeers, InPeers: monerod tuning, 0 for defaults;
+//     Moneroger.Reload applies changes without restarting monerod
+//
+//   - DaemonLogFile, DaemonMaxLogFileSize: monerod's own log, kept in
+//     logs/bitmonero.log under the network's data directory by default
+//     and rotated at monerod's default size when 0; see
+//     EffectiveDaemonLogFile
+//
+//   - LimitRate, LimitRateUp, LimitRateDown: monerod bandwidth caps in
+//     kB/s, both directions and each direction overriding LimitRate; 0
+//     for the defaults, and changes restart monerod on reload
+//
+//   - DBSyncMode, MaxConcurrency, BlockSyncSize, NoIGD, Offline, Prune:
+//     Further monerod flags, zero values for defaults; changes restart
+//     monerod on reload
+//
+//   - BanList: File of IP addresses and subnets, one per line, that
+//     monerod refuses peers from; see ReadBanList. The hosts are banned
+//     over RPC each time the daemon starts or is adopted, and a changed
+//     list restarts monerod on reload
+//
+//   - Bootstrap: Node that answers wallet queries while the local daemon
+//     syncs; ignored when a remote node is used
+//
+//   - DiskWatch: Free space thresholds under DataDir for warnings, and
+//     for stopping the local daemon in favour of a remote node
+//
+//   - HWDevice: Ledger or Trezor holding the wallet keys; needs host
+//     processes, as containers cannot reach USB devices
+//
+//   - Versions: Minimum release of monerod and monero-wallet-rpc, and
+//     whether they may differ; checked before starting them
+//
+//   - DaemonTLS, WalletTLS: Certificates enabling https on each RPC
+//     server; the manager's clients and the wallet's daemon connection
+//     switch to https accordingly
+//
+//   - ZMQPubPort: TCP port for monerod's ZMQ publisher (--zmq-pub)
+//     0 disables the publisher; when set, it is also used for
+//     faster daemon readiness detection and MoneroDaemon.Notifications
+//
+//   - RestrictedPort: Second monerod RPC port (--rpc-restricted-bind-port)
+//     serving only the restricted method set without a login, for
+//     exposing a public node alongside the authenticated admin port;
+//     0 disables it
+//
+//   - PublicNode: Shares the node: monerod advertises its restricted RPC
+//     port to peers (--public-node), which listens on all interfaces and
+//     defaults to the network's 18089-style port, and peer limits and
+//     the rate limit left at 0 take the PublicNode* settings
 //
 // Usage:
 //
 //		config := &Config{
 //		    DataDir:    "/path/to/monero/data",
-//		    WalletFile: "wallet.keys",
+//		    WalletDir:  "/path/to/monero/wallets",
+//		    WalletName: "shop",
 //		    MoneroPort: 18081,
 //		    WalletPort: 18082,
 //		    TestNet:    false,
@@ -52,26 +327,228 @@ func pickDefaultRemoteNode() string {
 // Related:
 //   - moneroconst.DefaultMonerodPort
 //   - moneroconst.DefaultWalletRPCPort
-//   - util.IsPortInUse() for port validation
+//   - util.IsPortAvailable() for port validation
 //   - util.FileExists() for path validation
 type Config struct {
 	// DataDir is the base directory for blockchain data and wallet files
 	DataDir string
-	// WalletFile is the path to the Monero wallet file
+	// WalletDir is the directory holding the wallet files (--wallet-dir),
+	// the network's data directory when empty
+	WalletDir string
+	// WalletName is the wallet in WalletDir opened at startup, empty for none
+	WalletName string
+	// WalletFile is the wallet directory under its former name.
+	//
+	// Deprecated: Use WalletDir, which takes precedence when both are set.
 	WalletFile string
-	// MoneroPort is the TCP port for monerod RPC service
+	// MoneroPort is the TCP port for monerod RPC service, the network's
+	// default when 0
 	MoneroPort int
-	// WalletPort is the TCP port for monero-wallet-rpc service
+	// WalletPort is the TCP port for monero-wallet-rpc service, the
+	// network's default when 0
 	WalletPort int
+	// MoneroBindIP is the address the monerod RPC binds to, loopback when empty
+	MoneroBindIP string
+	// WalletBindIP is the address the wallet RPC binds to, loopback when empty
+	WalletBindIP string
+	// AutoPort picks a free port when MoneroPort or WalletPort is taken by
+	// a program other than monerod
+	AutoPort bool
+	// AutoPortRange bounds the ports AutoPort picks from, empty for any
+	// free port
+	AutoPortRange PortRange
 	// TestNet determines whether to run on testnet (true) or mainnet (false)
 	TestNet bool
-	// RemoteNode instructs the monero-wallet-rpc client to use a remote port
+	// Network selects mainnet, testnet or stagenet; when empty TestNet decides
+	Network Network
+	// RemoteNode is the URL of a remote daemon, such as "https://node.example:18089".
+	// When set, monerod is not started and monero-wallet-rpc connects to it instead
 	RemoteNode string
+	// RemoteNodes are fallback remote daemons, tried in order after RemoteNode
+	// when the current node becomes unreachable
+	RemoteNodes []string
+	// ZMQPubPort is the TCP port for monerod's ZMQ publisher, 0 disables it
+	ZMQPubPort int
+	// RestrictedPort is monerod's unauthenticated restricted RPC port, 0 disables it
+	RestrictedPort int
+	// PublicNode shares the node with the public through restricted RPC
+	PublicNode bool
+	// DaemonLogLevel is monerod's log verbosity, 0 (default) to 4
+	DaemonLogLevel int
+	// DaemonLogFile is monerod's --log-file, logs/bitmonero.log under the
+	// network's data directory when empty
+	DaemonLogFile string
+	// DaemonMaxLogFileSize is the size in bytes at which monerod rotates
+	// its log file, 0 for monerod's default of about 100 MB
+	DaemonMaxLogFileSize int
+	// OutPeers limits monerod's outgoing peer connections, 0 for monerod's default
+	OutPeers int
+	// InPeers limits monerod's incoming peer connections, 0 for monerod's default
+	InPeers int
+	// LimitRate caps monerod's upload and download rate in kB/s, 0 for monerod's default
+	LimitRate int
+	// LimitRateUp caps monerod's upload rate in kB/s, overriding LimitRate, 0 for none
+	LimitRateUp int
+	// LimitRateDown caps monerod's download rate in kB/s, overriding LimitRate, 0 for none
+	LimitRateDown int
+	// DBSyncMode is monerod's --db-sync-mode, such as "safe", empty for monerod's default
+	DBSyncMode string
+	// MaxConcurrency caps monerod's worker threads, 0 for one per CPU
+	MaxConcurrency int
+	// BlockSyncSize is the number of blocks monerod requests per batch, 0 for monerod's default
+	BlockSyncSize int
+	// NoIGD disables monerod's UPnP port mapping on the router
+	NoIGD bool
+	// Offline keeps monerod from connecting to peers
+	Offline bool
+	// Prune runs monerod with --prune-blockchain, keeping about a third
+	// of the blockchain
+	Prune bool
+	// BanList is a file of hosts monerod refuses peers from, empty for none
+	BanList string
+	// MoneroRPCUser is the monerod RPC username, "gouser" when empty
+	MoneroRPCUser string
+	// MoneroRPCPass is the monerod RPC password, generated when empty
+	MoneroRPCPass string
+	// WalletRPCUser is the wallet RPC username, "gouser" when empty
+	WalletRPCUser string
+	// WalletRPCPass is the wallet RPC password, generated when empty
+	WalletRPCPass string
+	// Keyring fetches passwords left empty, and the wallet password, from
+	// the OS keyring, storing generated ones there
+	Keyring bool
+	// Credentials replaces the OS keyring as the credential store when
+	// set. It is not read from configuration files
+	Credentials CredentialProvider `mapstructure:"-"`
+	// Restart controls automatic restarts of crashed services
+	Restart RestartPolicy
+	// HealthInterval is how often running services are checked over RPC,
+	// 0 for the default and negative to disable the checks
+	HealthInterval time.Duration
+	// AutosaveInterval is how often open wallets are saved to their
+	// files, 0 for the default and negative to disable saving
+	AutosaveInterval time.Duration
+	// PaymentInterval is how often open wallets are checked for incoming
+	// payments, 0 for the default and negative to disable the checks
+	PaymentInterval time.Duration
+	// StartupTimeout bounds how long each service may take to become
+	// ready, 0 for moneroconst.DefaultStartupTimeout
+	StartupTimeout time.Duration
+	// ShutdownTimeout bounds how long each service may take to exit once
+	// interrupted, 0 for moneroconst.DefaultShutdownTimeout
+	ShutdownTimeout time.Duration
+	// StatusAddress is the host:port of the HTTP server answering
+	// /healthz, /readyz and /status; empty disables it
+	StatusAddress string
+	// StatusToken is the bearer token status requests must carry, none
+	// when empty
+	StatusToken string
+	// StatusTLS serves the status over https when a certificate is set
+	StatusTLS RPCTLS
+	// GRPC serves the gRPC management API when GRPC.Address is set
+	GRPC GRPCConfig
+	// Detach starts services detached from the manager, writing their
+	// output to log files in DataDir
+	Detach bool
+	// Tor routes node traffic over Tor when Tor.Proxy is set
+	Tor TorConfig
+	// I2P broadcasts transactions over I2P when I2P.Proxy is set
+	I2P I2PConfig
+	// Bootstrap serves wallet queries from another node while monerod syncs
+	Bootstrap BootstrapConfig
+	// DiskWatch monitors free space under DataDir while running
+	DiskWatch DiskWatchConfig
+	// HWDevice selects a hardware wallet for every wallet-rpc process
+	HWDevice HWDeviceConfig
+	// Versions sets the accepted releases of monerod and monero-wallet-rpc
+	Versions VersionPolicy
+	// MonerodExtraArgs are appended to the monerod command line
+	MonerodExtraArgs []string
+	// WalletRPCExtraArgs are appended to every monero-wallet-rpc command line
+	WalletRPCExtraArgs []string
+	// Docker runs the services in containers when Docker.Image is set
+	Docker DockerConfig
+	// ProcessDriver replaces the driver running the services when set.
+	// It is not read from configuration files
+	ProcessDriver Driver `mapstructure:"-"`
+	// DaemonTLS serves the monerod RPC over https when a certificate is set
+	DaemonTLS RPCTLS
+	// WalletTLS serves the wallet RPC over https when a certificate is set
+	WalletTLS RPCTLS
+	// Logger receives structured diagnostics from all components,
+	// slog.Default() when nil. It is not read from configuration files
+	Logger *slog.Logger `mapstructure:"-"`
+	// DaemonOutput receives monerod's output line by line, prefixed
+	// with "[monerod] ". It is not read from configuration files
+	DaemonOutput io.Writer `mapstructure:"-"`
+	// WalletOutput receives monero-wallet-rpc's output line by line,
+	// prefixed with "[monero-wallet-rpc] ". It is not read from configuration files
+	WalletOutput io.Writer `mapstructure:"-"`
+	// LogProcessOutput forwards child process output to Logger at debug level
+	LogProcessOutput bool
+	// LogBufferSize is the number of bytes of recent output kept per process
+	LogBufferSize int
+}
+
+// RestartPolicy controls how the manager restarts services that exit
+// unexpectedly. The zero value disables automatic restarts.
+//
+// Fields:
+//   - MaxRetries: Consecutive restarts attempted before giving up,
+//     0 disables restarts, negative values retry forever
+//   - MinUptime: A process that ran at least this long is considered
+//     stable, resetting the consecutive retry counter
+//   - InitialDelay: Delay before the first restart attempt
+//   - MaxDelay: Upper bound for the exponentially growing delay
+//   - Multiplier: Factor applied to the delay after each attempt,
+//     values below 1 are treated as 2
+type RestartPolicy struct {
+	MaxRetries   int
+	MinUptime    time.Duration
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+}
+
+// DefaultRestartPolicy returns the restart policy used by RecommendConfig:
+// up to 5 consecutive restarts, starting at 1 second and doubling up to
+// 1 minute, with processes considered stable after 1 minute of uptime.
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxRetries:   5,
+		MinUptime:    time.Minute,
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+	}
+}
+
+// Enabled reports whether the policy allows any restarts.
+func (p RestartPolicy) Enabled() bool {
+	return p.MaxRetries != 0
+}
+
+// Delay returns how long to wait before restart attempt number attempt
+// (starting at 0).
+//
+// Parameters:
+//   - attempt: Zero-based count of consecutive restart attempts so far
+//
+// Returns:
+//   - time.Duration: InitialDelay * Multiplier^attempt, capped at MaxDelay
+func (p RestartPolicy) Delay(attempt int) time.Duration {
+	return backoff(p.InitialDelay, p.MaxDelay, p.Multiplier, attempt)
+}
+
+// Exhausted reports whether attempt exceeds the retry budget.
+func (p RestartPolicy) Exhausted(attempt int) bool {
+	return p.MaxRetries >= 0 && attempt >= p.MaxRetries
 }
 
 // RecommendConfig generates a recommended Monero configuration based on the provided data directory.
 // If no data directory is specified, it creates one in the current working directory under "moneroger".
-// It also checks available disk space to determine if full node functionality should be enabled.
+// It also checks available disk space to choose between a full node, a pruned node and a remote
+// node, using DefaultRecommendThresholds.
 //
 // Parameters:
 //   - dataDir: String path to desired data directory. If empty, defaults to ./moneroger
@@ -79,20 +556,39 @@ type Config struct {
 // Returns:
 //   - Config: A Config struct with recommended settings:
 //   - DataDir: Absolute path to data directory
-//   - WalletFile: Set to "wallet" in the data directory
+//   - WalletDir: Empty, keeping wallets in the network's data directory
 //   - MoneroPort: Default 18081
 //   - WalletPort: Default 18083
+//   - ZMQPubPort: moneroconst.DefaultZMQPubPort
 //   - TestNet: Set to false (mainnet)
-//   - RemoteNode: Empty string if enough disk space (>250GB), otherwise a remote node address
+//   - Prune: Set when the free space allows a pruned but not a full node
+//   - RemoteNode, RemoteNodes: Empty unless the free space is below the
+//     pruned node threshold, then the fastest usable public node and the
+//     rest as fallbacks. A pruned node is used when none answers
+//   - Restart: DefaultRestartPolicy()
 //
 // Panics:
 //   - If unable to get current working directory
 //
 // Related:
-//   - TwoHundredFiftyGigabytes constant for space requirement
-//   - DirExists() for directory validation
-//   - pickDefaultRemoteNode() for remote node selection
-func RecommendConfig(dataDir string) (config Config) {
+//   - RecommendConfigWithThresholds for other space requirements
+//   - AvailableSpace() for measuring a directory not yet created
+//   - SelectRemoteNodes() for remote node selection
+func RecommendConfig(dataDir string) Config {
+	return RecommendConfigWithThresholds(dataDir, DefaultRecommendThresholds())
+}
+
+// RecommendConfigWithThresholds is RecommendConfig choosing the node mode
+// by thresholds.
+//
+// Parameters:
+//   - dataDir: Data directory, ./moneroger when empty
+//   - thresholds: Free space needed for a full and for a pruned node;
+//     invalid thresholds are replaced by DefaultRecommendThresholds
+//
+// Returns:
+//   - Config: The recommended configuration, see RecommendConfig
+func RecommendConfigWithThresholds(dataDir string, thresholds RecommendThresholds) (config Config) {
 	if dataDir == "" {
 		wd, err := os.Getwd()
 		if err != nil {
@@ -100,20 +596,35 @@ func RecommendConfig(dataDir string) (config Config) {
 		}
 		dataDir = filepath.Join(wd, "moneroger")
 	}
+	if err := thresholds.Validate(); err != nil {
+		logger().Warn("using default disk space thresholds", "error", err)
+		thresholds = DefaultRecommendThresholds()
+	}
 	config.DataDir = dataDir
-	if !DirExists(config.DataDir) {
-		usage := du.NewDiskUsage(config.DataDir)
-		if usage.Available() > TwoHundredFiftyGigabytes {
-			log.Println("Greater than 250GB available space detected, full node functionality enabled")
+	free := AvailableSpace(config.DataDir)
+	mode := thresholds.Mode(free)
+	if mode == NodeModeRemote {
+		if nodes := pickDefaultRemoteNodes(); len(nodes) > 0 {
+			config.RemoteNode = nodes[0]
+			config.RemoteNodes = nodes[1:]
+		} else {
+			// A pruned node that may run short of space beats no node
+			mode = NodeModePruned
 		}
-		config.RemoteNode = ""
-	} else {
-		config.RemoteNode = pickDefaultRemoteNode()
 	}
+	config.Prune = mode == NodeModePruned
+	logger().Info("recommended node mode", "mode", mode, "free_gb", free/Gigabyte)
 	config.TestNet = false
-	config.WalletFile = filepath.Join(config.DataDir, "wallet")
 	config.MoneroPort = 18081
 	config.WalletPort = 18083
+	config.ZMQPubPort = moneroconst.DefaultZMQPubPort
+	config.Restart = DefaultRestartPolicy()
+	config.Versions.Minimum = DefaultMinimumVersion
+	config.HealthInterval = moneroconst.DefaultHealthInterval
+	config.AutosaveInterval = moneroconst.DefaultAutosaveInterval
+	config.PaymentInterval = moneroconst.DefaultPaymentInterval
+	config.StartupTimeout = moneroconst.DefaultStartupTimeout
+	config.ShutdownTimeout = moneroconst.DefaultShutdownTimeout
 	return
 }
 
@@ -121,35 +632,127 @@ func RecommendConfig(dataDir string) (config Config) {
 // It returns the parsed configuration and any error encountered.
 //
 // Parameters:
-//   - path: File path to the YAML configuration file
+//   - path: File path to a YAML (.yaml, .yml), TOML (.toml) or JSON (.json)
+//     configuration file; the format is chosen by extension
 //
+// MONEROGER_* environment variables take precedence over the file; see
+// Config.ApplyEnv for the list.
 // Returns:
 //   - *Config: Parsed configuration structure
 //   - error: Any error encountered during loading or parsing
 //
 // The function will return an error if:
+//   - The file extension is not a supported format
 //   - The configuration file cannot be read
-//   - The YAML is invalid
+//   - The file content is invalid for its format
 //   - Required fields are missing
 //
 // Related types:
 //   - Config: The configuration structure
 //   - viper.Viper: Underlying configuration parser
 func LoadConfig(path string) (*Config, error) {
+	format, err := configFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set the configuration file path and type
-	viper.SetConfigFile(path)
-	viper.SetConfigType("yaml")
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType(format)
+	bindEnv(v)
 
 	// Read the configuration file
-	if err := viper.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		return nil, err
 	}
 
 	// Parse into Config structure
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config); err != nil {
 		return nil, err
 	}
+	// Empty lists written by SaveConfig load as nil, like unset fields
+	for _, list := range []*[]string{&config.RemoteNodes, &config.MonerodExtraArgs, &config.WalletRPCExtraArgs, &config.DiskWatch.FallbackNodes} {
+		if len(*list) == 0 {
+			*list = nil
+		}
+	}
+	if len(config.DiskWatch.WarnGB) == 0 {
+		config.DiskWatch.WarnGB = nil
+	}
 
 	return &config, nil
 }
+
+// configFormat maps a configuration file extension to a viper config type.
+func configFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return "yaml", nil
+	case ".toml":
+		return "toml", nil
+	case ".json":
+		return "json", nil
+	default:
+		return "", fmt.Errorf("unsupported configuration format %q: use .yaml, .toml or .json", ext)
+	}
+}
+
+// EffectiveStartupTimeout returns StartupTimeout, or
+// moneroconst.DefaultStartupTimeout when it is 0.
+func (c Config) EffectiveStartupTimeout() time.Duration {
+	if c.StartupTimeout <= 0 {
+		return moneroconst.DefaultStartupTimeout
+	}
+	return c.StartupTimeout
+}
+
+// EffectiveShutdownTimeout returns ShutdownTimeout, or
+// moneroconst.DefaultShutdownTimeout when it is 0.
+func (c Config) EffectiveShutdownTimeout() time.Duration {
+	if c.ShutdownTimeout <= 0 {
+		return moneroconst.DefaultShutdownTimeout
+	}
+	return c.ShutdownTimeout
+}
+
+// EffectiveWalletDir returns WalletDir, or the deprecated WalletFile when
+// WalletDir is empty, or else the network's data directory.
+func (c Config) EffectiveWalletDir() string {
+	switch {
+	case c.WalletDir != "":
+		return c.WalletDir
+	case c.WalletFile != "":
+		return c.WalletFile
+	}
+	return c.NetworkDataDir()
+}
+
+// EffectiveDaemonLogFile returns DaemonLogFile, or logs/bitmonero.log
+// under the network's data directory when it is empty. Without a DataDir
+// it returns "", leaving monerod's default.
+func (c Config) EffectiveDaemonLogFile() string {
+	if c.DaemonLogFile != "" || c.DataDir == "" {
+		return c.DaemonLogFile
+	}
+	return filepath.Join(c.NetworkDataDir(), "logs", "bitmonero.log")
+}
+
+// RemoteNodeList returns RemoteNode followed by RemoteNodes, skipping empty
+// and duplicate entries.
+//
+// Returns:
+//   - []string: Remote daemons in failover order, empty for a local daemon
+func (c Config) RemoteNodeList() []string {
+	var nodes []string
+	seen := make(map[string]bool)
+	for _, node := range append([]string{c.RemoteNode}, c.RemoteNodes...) {
+		if node == "" || seen[node] {
+			continue
+		}
+		seen[node] = true
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,123 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoadRPCLogin reads RPC credentials written by SaveRPCLogin.
+//
+// Parameters:
+//   - path: File holding "user:pass"
+//
+// Returns:
+//   - user, pass: The stored credentials
+//   - error: fs.ErrNotExist if nothing was saved yet, or a malformed file
+func LoadRPCLogin(path string) (user, pass string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	user, pass, ok := strings.Cut(strings.TrimSpace(string(data)), ":")
+	if !ok || user == "" || pass == "" {
+		return "", "", fmt.Errorf("%s: want user:pass", path)
+	}
+	return user, pass, nil
+}
+
+// SaveRPCLogin stores RPC credentials as "user:pass", the format of
+// monerod's --rpc-login, so a later run can reattach to a process started
+// with them.
+//
+// Parameters:
+//   - path: Destination file; its directory is created if needed
+//   - user, pass: Credentials to store
+//
+// Returns:
+//   - error: Directory creation or write failures
+//
+// The file is written atomically with mode 0600, replacing any existing
+// file regardless of its permissions.
+func SaveRPCLogin(path, user, pass string) error {
+	return writePrivateFile(path, []byte(user+":"+pass+"\n"))
+}
+
+// WriteOptionsFile writes options in the format monerod and
+// monero-wallet-rpc read with --config-file, one "key=value" line per
+// option, so secrets such as RPC logins stay out of the command line
+// where ps would show them.
+//
+// Parameters:
+//   - path: Destination file; its directory is created if needed
+//   - options: Option names without the leading "--", and their values
+//
+// Returns:
+//   - error: For values spanning lines, or write failures
+//
+// Like SaveRPCLogin the file is written atomically with mode 0600.
+func WriteOptionsFile(path string, options map[string]string) error {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value := options[key]
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("option %s: value cannot span lines", key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+	return writePrivateFile(path, []byte(b.String()))
+}
+
+// ReadOptionsFile parses a file written by WriteOptionsFile, so a later
+// run can recover the settings of a process it did not start.
+//
+// Parameters:
+//   - path: File holding "key=value" lines
+//
+// Returns:
+//   - map[string]string: Options by name
+//   - error: fs.ErrNotExist if there is no such file, or read failures
+func ReadOptionsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	options := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSuffix(line, "\r"), "="); ok {
+			options[key] = value
+		}
+	}
+	return options, nil
+}
+
+// writePrivateFile atomically replaces path with data, readable only by
+// the owner, creating its directory if needed.
+func writePrivateFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// CreateTemp uses mode 0600
+	f, err := os.CreateTemp(dir, ".moneroger-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
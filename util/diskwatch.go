@@ -0,0 +1,106 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultDiskWatchInterval is how often free space is checked when
+	// DiskWatchConfig.Interval is 0
+	DefaultDiskWatchInterval = time.Minute
+
+	// Gigabyte is the unit of the DiskWatchConfig thresholds
+	Gigabyte uint64 = 1000 * 1000 * 1000
+)
+
+// DiskWatchConfig monitors free space under DataDir while the services
+// run, since the blockchain keeps growing after the check RecommendConfig
+// makes once.
+//
+// Fields:
+//   - Interval: How often free space is checked, 0 for
+//     DefaultDiskWatchInterval
+//   - WarnGB: Thresholds in gigabytes; a warning is logged and
+//     EventDiskSpaceLow emitted when free space drops below each, once
+//     until it rises above it again
+//   - FallbackGB: Free space in gigabytes below which the local daemon is
+//     stopped and the wallets switched to a remote node, before monerod
+//     fills the disk; 0 disables the fallback
+//   - FallbackNodes: Remote nodes used by the fallback, the fastest of
+//     DefaultRemoteNodes on mainnet when empty, see
+//     Config.DiskFallbackNodes
+//
+// The watchdog is disabled unless WarnGB or FallbackGB is set.
+type DiskWatchConfig struct {
+	Interval      time.Duration
+	WarnGB        []int
+	FallbackGB    int
+	FallbackNodes []string
+}
+
+// Enabled reports whether free space is monitored.
+func (d DiskWatchConfig) Enabled() bool {
+	return len(d.WarnGB) > 0 || d.FallbackGB > 0
+}
+
+// EffectiveInterval returns how often free space is checked.
+func (d DiskWatchConfig) EffectiveInterval() time.Duration {
+	if d.Interval <= 0 {
+		return DefaultDiskWatchInterval
+	}
+	return d.Interval
+}
+
+// Validate checks the interval, thresholds and fallback nodes.
+//
+// Returns:
+//   - error: Description of the first problem, nil if valid
+func (d DiskWatchConfig) Validate() error {
+	if d.Interval < 0 {
+		return fmt.Errorf("invalid disk watch interval %v", d.Interval)
+	}
+	for _, gb := range d.WarnGB {
+		if gb <= 0 {
+			return fmt.Errorf("invalid disk space warning threshold %d GB", gb)
+		}
+	}
+	if d.FallbackGB < 0 {
+		return fmt.Errorf("invalid disk space fallback threshold %d GB", d.FallbackGB)
+	}
+	if len(d.FallbackNodes) > 0 && d.FallbackGB == 0 {
+		return fmt.Errorf("disk fallback nodes given without a fallback threshold")
+	}
+	for _, node := range d.FallbackNodes {
+		if _, _, _, err := ParseRemoteNode(node); err != nil {
+			return fmt.Errorf("invalid disk fallback node %q: %w", node, err)
+		}
+	}
+	return nil
+}
+
+// DiskFallbackNodes returns the remote nodes the disk space fallback
+// switches to.
+//
+// Parameters:
+//   - ctx: Context bounding the selection among DefaultRemoteNodes
+//
+// Returns:
+//   - []string: DiskWatch.FallbackNodes when set, otherwise on mainnet
+//     the usable DefaultRemoteNodes best first. Over Tor the default
+//     nodes are not probed, since the probes would reveal the host's
+//     address, and nil is returned
+func (c Config) DiskFallbackNodes(ctx context.Context) []string {
+	if len(c.DiskWatch.FallbackNodes) > 0 || c.NetType() != NetworkMainnet || c.Tor.Enabled() {
+		return c.DiskWatch.FallbackNodes
+	}
+	ctx, cancel := context.WithTimeout(ctx, remoteNodeSelectTimeout)
+	defer cancel()
+	nodes, err := SelectRemoteNodes(ctx, DefaultRemoteNodes)
+	if err != nil {
+		logger().Warn("remote node selection failed", "error", err)
+		return nil
+	}
+	return nodes
+}
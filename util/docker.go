@@ -0,0 +1,200 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// DefaultDockerBinary is the container CLI used when
+	// DockerConfig.Binary is empty
+	DefaultDockerBinary = "docker"
+
+	// DockerHostNetwork shares the host's network with the containers
+	DockerHostNetwork = "host"
+)
+
+// DockerConfig runs monerod and monero-wallet-rpc in containers instead
+// of as host processes. Directories and files the services use are
+// mounted at the same paths inside the containers, and the containers
+// run as the manager's user, so files in DataDir stay readable.
+//
+// Fields:
+//   - Image: Image providing monerod and monero-wallet-rpc on its PATH;
+//     empty runs the services on the host
+//   - WalletImage: Image for monero-wallet-rpc when it differs from Image
+//   - Binary: Docker compatible CLI, such as "podman"; "docker" when empty
+//   - Network: Network the containers join, "host" when empty. Other
+//     networks must be user-defined networks, where the wallet reaches
+//     the daemon by container name; RPC ports are then published on the
+//     configured bind address. ZMQ notifications, and Tor and I2P proxies
+//     on the host's loopback address, need the host network.
+type DockerConfig struct {
+	Image       string
+	WalletImage string
+	Binary      string
+	Network     string
+}
+
+// Enabled reports whether the services run in containers.
+func (d DockerConfig) Enabled() bool {
+	return d.Image != ""
+}
+
+// Validate checks that the settings select an image and a platform
+// containers can mount the data directory on.
+//
+// Returns:
+//   - error: Description of the problem, nil if valid
+func (d DockerConfig) Validate() error {
+	if !d.Enabled() {
+		if d.WalletImage != "" || d.Binary != "" || d.Network != "" {
+			return fmt.Errorf("docker settings given without a docker image")
+		}
+		return nil
+	}
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("the docker driver is not supported on Windows")
+	}
+	return nil
+}
+
+// DockerDriver runs services with "docker run". The CLI stays in the
+// foreground and forwards signals to the container, so it is started,
+// interrupted and watched like a host process.
+//
+// Fields:
+//   - config: Image, CLI and network settings
+//   - prefix: Container name prefix, unique per data directory
+type DockerDriver struct {
+	config DockerConfig
+	prefix string
+}
+
+// NewDockerDriver creates the driver for a configuration.
+//
+// Parameters:
+//   - config: Docker settings, Image must be set
+//   - dataDir: Data directory, which names the containers so managers
+//     of different directories do not collide
+//
+// Returns:
+//   - *DockerDriver: The driver
+func NewDockerDriver(config DockerConfig, dataDir string) *DockerDriver {
+	sum := sha256.Sum256([]byte(dataDir))
+	return &DockerDriver{config: config, prefix: "moneroger-" + hex.EncodeToString(sum[:4])}
+}
+
+// Executable locates the container CLI; the Monero programs come from
+// the image, so find is not used.
+func (d *DockerDriver) Executable(find func() (string, error)) (string, error) {
+	binary := d.config.Binary
+	if binary == "" {
+		binary = DefaultDockerBinary
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("container CLI %s not found: %w", binary, err)
+	}
+	return path, nil
+}
+
+// Command builds the "docker run" command line for spec. Cancelling ctx
+// removes the container, which killing the CLI alone would leave
+// running.
+func (d *DockerDriver) Command(ctx context.Context, spec ProcessSpec) (*exec.Cmd, error) {
+	image := d.config.Image
+	if spec.Program == "monero-wallet-rpc" && d.config.WalletImage != "" {
+		image = d.config.WalletImage
+	}
+	args := []string{"run", "--rm", "--entrypoint", spec.Program, "--network", d.network()}
+	var name string
+	if spec.Name != "" {
+		name = d.container(spec.Name)
+		args = append(args, "--name", name)
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	if !d.hostNetwork() {
+		publishIP := spec.PublishIP
+		if publishIP == "" {
+			publishIP = "127.0.0.1"
+		}
+		for _, port := range spec.Ports {
+			args = append(args, "--publish", fmt.Sprintf("%s:%d:%d", publishIP, port, port))
+		}
+	}
+	mounted := make(map[string]bool)
+	for _, path := range spec.Mounts {
+		if path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		if !mounted[abs] {
+			mounted[abs] = true
+			args = append(args, "--volume", abs+":"+abs)
+		}
+	}
+	args = append(args, image)
+	args = append(args, spec.Args...)
+
+	cmd := exec.CommandContext(ctx, spec.Path, args...)
+	if name != "" {
+		cmd.Cancel = func() error {
+			_ = exec.Command(spec.Path, "rm", "--force", name).Run()
+			return cmd.Process.Kill()
+		}
+	}
+	return cmd, nil
+}
+
+// BindIP binds all of the container's addresses outside the host
+// network, so published ports reach the process.
+func (d *DockerDriver) BindIP(ip string) string {
+	if d.hostNetwork() {
+		return ip
+	}
+	return "0.0.0.0"
+}
+
+// DaemonAddress addresses the daemon container by name outside the
+// host network.
+func (d *DockerDriver) DaemonAddress(addr string) string {
+	if d.hostNetwork() {
+		return addr
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return net.JoinHostPort(d.container("monerod"), port)
+}
+
+// container returns the container name of a process.
+func (d *DockerDriver) container(name string) string {
+	return d.prefix + "-" + name
+}
+
+// network returns the network the containers join.
+func (d *DockerDriver) network() string {
+	if d.config.Network == "" {
+		return DockerHostNetwork
+	}
+	return d.config.Network
+}
+
+// hostNetwork reports whether the containers share the host's network.
+func (d *DockerDriver) hostNetwork() bool {
+	return d.network() == DockerHostNetwork
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Driver runs the monerod and monero-wallet-rpc processes. The services
+// start, signal and watch the command a driver builds the same way
+// whatever it runs in, so Start, Shutdown and health checks behave alike
+// for every driver.
+//
+// Related:
+//   - LocalDriver for processes run directly on the host
+//   - DockerDriver for processes run in containers
+//   - Config.Driver for the driver a configuration selects
+type Driver interface {
+	// Executable returns the host executable the started process runs,
+	// which PID files are checked against. find locates the Monero
+	// program itself, such as monerod.MoneroDPath.
+	Executable(find func() (string, error)) (string, error)
+
+	// Command builds the unstarted command running a service. The
+	// process must exit gracefully on SIGINT, as sent by
+	// InterruptProcess.
+	Command(ctx context.Context, spec ProcessSpec) (*exec.Cmd, error)
+
+	// BindIP returns the address the process binds its RPC server to
+	// for a configured bind address, empty for the program's default.
+	BindIP(ip string) string
+
+	// DaemonAddress returns the host:port a wallet process reaches the
+	// local daemon at, given the address the manager reaches it at.
+	DaemonAddress(addr string) string
+}
+
+// ProcessSpec describes a service process for Driver.Command.
+//
+// Fields:
+//   - Path: Executable returned by Driver.Executable
+//   - Program: Monero program name, such as "monerod"
+//   - Name: Name unique among the manager's processes, such as
+//     "monerod"; empty for short-lived commands such as --version
+//   - Args: Program arguments
+//   - Mounts: Host files and directories named in Args, which the
+//     process reads or writes
+//   - Ports: Ports the process listens on
+//   - PublishIP: Host address the ports are reached at, loopback when
+//     empty
+type ProcessSpec struct {
+	Path      string
+	Program   string
+	Name      string
+	Args      []string
+	Mounts    []string
+	Ports     []int
+	PublishIP string
+}
+
+// LocalDriver runs services as child processes of the manager. It is
+// the default driver.
+type LocalDriver struct{}
+
+// Executable returns the program found by find.
+func (LocalDriver) Executable(find func() (string, error)) (string, error) {
+	return find()
+}
+
+// Command runs spec.Path with spec.Args.
+func (LocalDriver) Command(ctx context.Context, spec ProcessSpec) (*exec.Cmd, error) {
+	return exec.CommandContext(ctx, spec.Path, spec.Args...), nil
+}
+
+// BindIP returns ip unchanged.
+func (LocalDriver) BindIP(ip string) string {
+	return ip
+}
+
+// DaemonAddress returns addr unchanged.
+func (LocalDriver) DaemonAddress(addr string) string {
+	return addr
+}
+
+// Driver returns the process driver the configuration selects:
+// ProcessDriver when set, a DockerDriver when Docker.Image is set,
+// otherwise LocalDriver.
+func (c Config) Driver() Driver {
+	if c.ProcessDriver != nil {
+		return c.ProcessDriver
+	}
+	if c.Docker.Enabled() {
+		return NewDockerDriver(c.Docker, c.DataDir)
+	}
+	return LocalDriver{}
+}
@@ -0,0 +1,136 @@
+package util
+
+import (
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is the prefix of every environment variable read by ApplyEnv.
+const EnvPrefix = "MONEROGER"
+
+// envBindings maps configuration keys to the environment variables that
+// override them, without the EnvPrefix.
+var envBindings = map[string]string{
+	"datadir":           "DATA_DIR",
+	"walletdir":         "WALLET_DIR",
+	"walletname":        "WALLET_NAME",
+	"moneroport":        "DAEMON_PORT",
+	"walletport":        "WALLET_PORT",
+	"monerobindip":      "DAEMON_BIND_IP",
+	"walletbindip":      "WALLET_BIND_IP",
+	"autoport":          "AUTO_PORT",
+	"testnet":           "TESTNET",
+	"network":           "NETWORK",
+	"remotenode":        "REMOTE_NODE",
+	"remotenodes":       "REMOTE_NODES",
+	"zmqpubport":        "ZMQ_PUB_PORT",
+	"restrictedport":    "RESTRICTED_PORT",
+	"publicnode":        "PUBLIC_NODE",
+	"monerorpcuser":     "DAEMON_RPC_USER",
+	"monerorpcpass":     "DAEMON_RPC_PASS",
+	"walletrpcuser":     "WALLET_RPC_USER",
+	"walletrpcpass":     "WALLET_RPC_PASS",
+	"keyring":           "KEYRING",
+	"detach":            "DETACH",
+	"daemonloglevel":    "DAEMON_LOG_LEVEL",
+	"daemonlogfile":     "DAEMON_LOG_FILE",
+	"healthinterval":    "HEALTH_INTERVAL",
+	"startuptimeout":    "STARTUP_TIMEOUT",
+	"shutdowntimeout":   "SHUTDOWN_TIMEOUT",
+	"statusaddress":     "STATUS_ADDRESS",
+	"statustoken":       "STATUS_TOKEN",
+	"grpc.address":      "GRPC_ADDRESS",
+	"grpc.token":        "GRPC_TOKEN",
+	"docker.image":      "DOCKER_IMAGE",
+	"docker.network":    "DOCKER_NETWORK",
+	"tor.proxy":         "TOR_PROXY",
+	"i2p.proxy":         "I2P_PROXY",
+	"bootstrap.address": "BOOTSTRAP_DAEMON",
+	"bootstrap.login":   "BOOTSTRAP_DAEMON_LOGIN",
+	"versions.minimum":  "MIN_VERSION",
+}
+
+// deprecatedEnv maps configuration keys to former names of their
+// environment variables, read when the current variable is unset.
+var deprecatedEnv = map[string]string{
+	"walletdir": "WALLET_FILE",
+}
+
+// bindEnv registers the MONEROGER_* environment variables with v.
+func bindEnv(v *viper.Viper) {
+	for key, name := range envBindings {
+		args := []string{key, EnvPrefix + "_" + name}
+		if old, ok := deprecatedEnv[key]; ok {
+			args = append(args, EnvPrefix+"_"+old)
+		}
+		// BindEnv only fails without a key, which cannot happen here
+		_ = v.BindEnv(args...)
+	}
+}
+
+// ApplyEnv overrides configuration values with the MONEROGER_*
+// environment variables that are set, leaving all other fields alone.
+// It is meant to run last, after file and flag configuration, for
+// containerized deployments.
+//
+// Recognized variables:
+//   - MONEROGER_DATA_DIR, MONEROGER_WALLET_DIR, MONEROGER_WALLET_NAME
+//   - MONEROGER_WALLET_FILE: Deprecated name of MONEROGER_WALLET_DIR
+//   - MONEROGER_DAEMON_PORT, MONEROGER_WALLET_PORT, MONEROGER_ZMQ_PUB_PORT
+//   - MONEROGER_RESTRICTED_PORT
+//   - MONEROGER_PUBLIC_NODE: "true" or "false"
+//   - MONEROGER_DAEMON_BIND_IP, MONEROGER_WALLET_BIND_IP
+//   - MONEROGER_AUTO_PORT: "true" or "false"
+//   - MONEROGER_TESTNET: "true" or "false"
+//   - MONEROGER_NETWORK: "mainnet", "testnet" or "stagenet", moving ports
+//     still at the previous network's defaults as SetNetwork does
+//   - MONEROGER_REMOTE_NODE, MONEROGER_REMOTE_NODES (comma separated)
+//   - MONEROGER_DAEMON_RPC_USER, MONEROGER_DAEMON_RPC_PASS
+//   - MONEROGER_WALLET_RPC_USER, MONEROGER_WALLET_RPC_PASS
+//   - MONEROGER_KEYRING: "true" or "false"
+//   - MONEROGER_DETACH: "true" or "false"
+//   - MONEROGER_DAEMON_LOG_LEVEL (0 to 4), MONEROGER_DAEMON_LOG_FILE
+//   - MONEROGER_HEALTH_INTERVAL: Duration such as "30s", negative disables
+//   - MONEROGER_STARTUP_TIMEOUT, MONEROGER_SHUTDOWN_TIMEOUT: Durations
+//     such as "5m"
+//   - MONEROGER_STATUS_ADDRESS: host:port of the HTTP status server
+//   - MONEROGER_STATUS_TOKEN: Bearer token of the HTTP status server
+//   - MONEROGER_GRPC_ADDRESS, MONEROGER_GRPC_TOKEN
+//   - MONEROGER_DOCKER_IMAGE, MONEROGER_DOCKER_NETWORK
+//   - MONEROGER_TOR_PROXY, MONEROGER_I2P_PROXY
+//   - MONEROGER_BOOTSTRAP_DAEMON, MONEROGER_BOOTSTRAP_DAEMON_LOGIN
+//   - MONEROGER_MIN_VERSION: Oldest acceptable Monero release
+//
+// Returns:
+//   - error: If a variable cannot be converted to its field's type
+func (c *Config) ApplyEnv() error {
+	v := viper.New()
+	bindEnv(v)
+	network, testNet := c.Network, c.TestNet
+	prev := c.NetType()
+	// Unset variables are absent from the settings, so only fields with a
+	// variable are decoded over the existing values
+	if err := v.Unmarshal(c); err != nil {
+		return err
+	}
+
+	// MONEROGER_NETWORK brings that network's default ports along, except
+	// for ports the environment sets as well
+	if next := c.NetType(); next != prev && v.IsSet("network") {
+		env := *c
+		c.Network, c.TestNet = network, testNet
+		c.SetNetwork(next)
+		if v.IsSet("moneroport") {
+			c.MoneroPort = env.MoneroPort
+		}
+		if v.IsSet("walletport") {
+			c.WalletPort = env.WalletPort
+		}
+		if v.IsSet("zmqpubport") {
+			c.ZMQPubPort = env.ZMQPubPort
+		}
+		if v.IsSet("remotenode") || v.IsSet("remotenodes") {
+			c.RemoteNode, c.RemoteNodes = env.RemoteNode, env.RemoteNodes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// monerodFlags are the monerod flags the manager always sets or models
+// with a Config field, and flags that would take the process out of its
+// control
+var monerodFlags = []string{
+	"data-dir", "config-file", "rpc-bind-port", "rpc-restricted-bind-port",
+	"rpc-login", "non-interactive",
+	"testnet", "stagenet", "zmq-pub", "log-level", "log-file",
+	"max-log-file-size", "out-peers", "in-peers",
+	"limit-rate", "limit-rate-up", "limit-rate-down", "db-sync-mode", "max-concurrency", "block-sync-size",
+	"no-igd", "offline", "prune-blockchain", "detach", "pidfile",
+}
+
+// walletRPCFlags are the monero-wallet-rpc flags the manager always sets
+// or models with a Config field, and flags that would take the process
+// out of its control
+var walletRPCFlags = []string{
+	"wallet-dir", "wallet-file", "generate-from-json", "config-file",
+	"rpc-bind-port", "rpc-login", "daemon-address", "daemon-host",
+	"daemon-port", "daemon-login", "prompt-for-password", "password",
+	"password-file", "testnet", "stagenet", "detach", "pidfile",
+}
+
+// repeatableFlags are monerod flags given once per value, which extra
+// arguments may add to
+var repeatableFlags = map[string]bool{"tx-proxy": true, "anonymous-inbound": true}
+
+// ValidateMonerodExtraArgs checks that MonerodExtraArgs does not repeat
+// a flag the manager passes to monerod with this configuration.
+//
+// Returns:
+//   - error: Description of the first duplicated flag, nil if none
+func (c Config) ValidateMonerodExtraArgs() error {
+	managed := append([]string(nil), monerodFlags...)
+	for _, args := range [][]string{
+		BindArgs(c.Driver().BindIP(c.MoneroBindIP)),
+		c.DaemonTLS.ServerArgs(),
+		c.Tor.DaemonArgs(),
+		c.Bootstrap.DaemonArgs(c.Tor.Proxy),
+		c.I2P.DaemonArgs(),
+		c.PublicNodeArgs(),
+	} {
+		managed = append(managed, flagNames(args)...)
+	}
+	for key := range c.Bootstrap.DaemonOptions() {
+		managed = append(managed, key)
+	}
+	return checkExtraArgs("monerod", c.MonerodExtraArgs, managed)
+}
+
+// ValidateWalletRPCExtraArgs checks that WalletRPCExtraArgs does not
+// repeat a flag the manager passes to monero-wallet-rpc with this
+// configuration.
+//
+// Returns:
+//   - error: Description of the first duplicated flag, nil if none
+func (c Config) ValidateWalletRPCExtraArgs() error {
+	managed := append([]string(nil), walletRPCFlags...)
+	managed = append(managed, flagNames(BindArgs(c.Driver().BindIP(c.WalletBindIP)))...)
+	managed = append(managed, flagNames(c.WalletTLS.ServerArgs())...)
+	managed = append(managed, flagNames(c.HWDevice.WalletArgs())...)
+	if c.Tor.Enabled() || c.I2P.Enabled() {
+		managed = append(managed, "proxy")
+	}
+	if c.DaemonTLS.Enabled() {
+		managed = append(managed, "daemon-ssl", "daemon-ssl-ca-certificates")
+	}
+	return checkExtraArgs("monero-wallet-rpc", c.WalletRPCExtraArgs, managed)
+}
+
+// checkExtraArgs reports the first flag in extra that is in managed.
+func checkExtraArgs(program string, extra, managed []string) error {
+	for _, name := range flagNames(extra) {
+		if repeatableFlags[name] {
+			continue
+		}
+		for _, m := range managed {
+			if name == m {
+				return fmt.Errorf("%s extra argument --%s is set by moneroger; use the configuration instead", program, name)
+			}
+		}
+	}
+	return nil
+}
+
+// flagNames returns the names of the flags in args, without leading
+// dashes or "=value" suffixes, skipping flag values.
+func flagNames(args []string) []string {
+	var names []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package util
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// fileLocked reports whether another process holds a POSIX record lock
+// on path, as LMDB does on its lock file while a database is open. The
+// lock is only queried, never taken.
+func fileLocked(path string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	lock := syscall.Flock_t{Type: syscall.F_WRLCK, Whence: io.SeekStart}
+	if err := syscall.FcntlFlock(f.Fd(), syscall.F_GETLK, &lock); err != nil {
+		return false, err
+	}
+	return lock.Type != syscall.F_UNLCK, nil
+}
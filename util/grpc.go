@@ -0,0 +1,64 @@
+package util
+
+import (
+	"fmt"
+	"net"
+)
+
+// minGRPCTokenLength is the shortest accepted gRPC bearer token
+const minGRPCTokenLength = 16
+
+// GRPCConfig enables the gRPC management API, which lets remote control
+// planes query status, restart services, open wallets and send
+// transfers.
+//
+// Fields:
+//   - Address: host:port the API listens on; empty disables it
+//   - Token: Bearer token every call must carry, at least 16 characters
+//   - TLS: Certificate served by the API; required unless Address is a
+//     loopback address, as the token would otherwise cross the network
+//     in plain text
+type GRPCConfig struct {
+	Address string
+	Token   string
+	TLS     RPCTLS
+}
+
+// Enabled reports whether the gRPC API is configured.
+func (g GRPCConfig) Enabled() bool {
+	return g.Address != ""
+}
+
+// Validate checks the address, token and TLS settings.
+//
+// Returns:
+//   - error: Description of the first problem, nil if valid
+func (g GRPCConfig) Validate() error {
+	if !g.Enabled() {
+		if g.Token != "" || g.TLS.Enabled() {
+			return fmt.Errorf("grpc token or TLS given without a grpc address")
+		}
+		return nil
+	}
+	if err := validateHostPort(g.Address); err != nil {
+		return fmt.Errorf("invalid grpc address %q: %w", g.Address, err)
+	}
+	if len(g.Token) < minGRPCTokenLength {
+		return fmt.Errorf("grpc token must be at least %d characters", minGRPCTokenLength)
+	}
+	if err := g.TLS.Validate(); err != nil {
+		return fmt.Errorf("grpc: %w", err)
+	}
+	if !g.TLS.Enabled() && !isLoopbackAddress(g.Address) {
+		return fmt.Errorf("grpc address %s is not loopback and requires TLS", g.Address)
+	}
+	return nil
+}
+
+// isLoopbackAddress reports whether a host:port names localhost or a
+// loopback IP, so a server on it is unreachable from the network.
+func isLoopbackAddress(addr string) bool {
+	host, _, _ := net.SplitHostPort(addr)
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
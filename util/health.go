@@ -0,0 +1,115 @@
+package util
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// CachedCheck coalesces concurrent health checks into a single call and
+// caches the outcome for a short TTL. Services such as wallet-rpc process
+// requests serially, so letting every caller (metrics, readiness probes,
+// supervisors) issue its own RPC would needlessly queue work behind real
+// requests.
+//
+// Fields:
+//   - check: The underlying health check function
+//   - ttl: How long a completed result is reused
+//   - checkedAt: Completion time of the cached result
+//   - err: Cached result
+//   - inflight: Channel closed when the running check completes, nil if idle
+type CachedCheck struct {
+	check     func(ctx context.Context) error
+	ttl       time.Duration
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+	inflight  chan struct{}
+}
+
+// NewCachedCheck wraps check with result caching and call coalescing.
+//
+// Parameters:
+//   - ttl: How long to reuse a result, zero disables caching but still coalesces
+//   - check: Health check to run
+//
+// Returns:
+//   - *CachedCheck: Wrapper safe for concurrent use
+func NewCachedCheck(ttl time.Duration, check func(ctx context.Context) error) *CachedCheck {
+	return &CachedCheck{check: check, ttl: ttl}
+}
+
+// Check returns the cached result if it is still fresh, joins a check that
+// is already running, or starts a new one.
+//
+// Parameters:
+//   - ctx: Context bounding how long this caller waits
+//
+// Returns:
+//   - error: Result of the health check, or ctx.Err() if the caller gave up
+//
+// A started check is not cancelled when the caller that triggered it gives
+// up, so that other waiters still receive its result.
+func (c *CachedCheck) Check(ctx context.Context) error {
+	c.mu.Lock()
+	if c.inflight == nil && !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	done := c.inflight
+	if done == nil {
+		done = make(chan struct{})
+		c.inflight = done
+		go c.run(context.WithoutCancel(ctx), done)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run executes the check and publishes its result.
+func (c *CachedCheck) run(ctx context.Context, done chan struct{}) {
+	err := c.check(ctx)
+	c.mu.Lock()
+	c.err = err
+	c.checkedAt = time.Now()
+	c.inflight = nil
+	c.mu.Unlock()
+	close(done)
+}
+
+// Invalidate discards the cached result so the next Check runs the check.
+func (c *CachedCheck) Invalidate() {
+	c.mu.Lock()
+	c.checkedAt = time.Time{}
+	c.mu.Unlock()
+}
+
+// Jitter randomizes d by up to ±fraction of its value. Periodic tasks use it
+// to avoid synchronized bursts when several schedulers share an interval.
+//
+// Parameters:
+//   - d: Base duration
+//   - fraction: Maximum relative deviation, clamped to [0, 1]
+//
+// Returns:
+//   - time.Duration: A duration in [d*(1-fraction), d*(1+fraction)]
+func Jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || d <= 0 {
+		return d
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	delta := (rand.Float64()*2 - 1) * fraction * float64(d)
+	return d + time.Duration(delta)
+}
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hwDevices are the hardware wallet types monero-wallet-rpc supports,
+// spelled as its --hw-device flag expects them
+var hwDevices = []string{"Ledger", "Trezor"}
+
+// HWDeviceConfig selects a hardware wallet holding the keys of the
+// managed wallets. monero-wallet-rpc then asks the device to sign, and
+// the device must stay connected while wallets created from it are open.
+//
+// Fields:
+//   - Name: Device type, "Ledger" or "Trezor"; empty for software wallets
+//   - DerivationPath: Wallet derivation path on the device, such as
+//     "m/44'/128'/1'" for a second account; empty for the device's
+//     default
+type HWDeviceConfig struct {
+	Name           string
+	DerivationPath string
+}
+
+// Enabled reports whether a hardware wallet is configured.
+func (h HWDeviceConfig) Enabled() bool {
+	return h.Name != ""
+}
+
+// Validate checks the device type and derivation path.
+//
+// Returns:
+//   - error: Description of the first problem, nil if valid
+func (h HWDeviceConfig) Validate() error {
+	if !h.Enabled() {
+		if h.DerivationPath != "" {
+			return fmt.Errorf("hardware wallet derivation path given without a device")
+		}
+		return nil
+	}
+	known := false
+	for _, name := range hwDevices {
+		known = known || h.Name == name
+	}
+	if !known {
+		return fmt.Errorf("unknown hardware wallet %q, use one of %s", h.Name, strings.Join(hwDevices, ", "))
+	}
+	if h.DerivationPath != "" && !strings.HasPrefix(h.DerivationPath, "m/") {
+		return fmt.Errorf("hardware wallet derivation path %q must start with m/", h.DerivationPath)
+	}
+	return nil
+}
+
+// WalletArgs returns the monero-wallet-rpc and monero-wallet-cli flags
+// selecting the device.
+//
+// Returns:
+//   - []string: --hw-device and --hw-device-deriv-path, nil when no
+//     device is configured
+func (h HWDeviceConfig) WalletArgs() []string {
+	if !h.Enabled() {
+		return nil
+	}
+	args := []string{"--hw-device", h.Name}
+	if h.DerivationPath != "" {
+		args = append(args, "--hw-device-deriv-path", h.DerivationPath)
+	}
+	return args
+}
@@ -0,0 +1,123 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultI2PInboundPort is the local port an I2P tunnel forwards to
+	DefaultI2PInboundPort = 18085
+
+	// DefaultI2PMaxConnections limits connections over I2P
+	DefaultI2PMaxConnections = 16
+
+	// proxyCheckTimeout bounds the reachability check of a proxy
+	proxyCheckTimeout = 3 * time.Second
+)
+
+// I2PConfig broadcasts transactions over I2P through the SOCKS proxy of an
+// I2P router, and optionally accepts anonymous inbound peers over an I2P
+// server tunnel.
+//
+// Fields:
+//   - Proxy: I2P router SOCKS address, such as "127.0.0.1:4447"; empty disables I2P
+//   - Address: This node's b32 address announced to peers, such as
+//     "abc...xyz.b32.i2p"; empty disables anonymous inbound
+//   - InboundPort: Local port the I2P server tunnel forwards to,
+//     DefaultI2PInboundPort when 0
+//   - MaxConnections: Connection limit for I2P, DefaultI2PMaxConnections when 0
+type I2PConfig struct {
+	Proxy          string
+	Address        string
+	InboundPort    int
+	MaxConnections int
+}
+
+// Enabled reports whether I2P is configured.
+func (c I2PConfig) Enabled() bool {
+	return c.Proxy != ""
+}
+
+// Validate checks that the proxy and b32 addresses are well formed.
+//
+// Returns:
+//   - error: Description of the first invalid field, nil if valid
+func (c I2PConfig) Validate() error {
+	if !c.Enabled() {
+		if c.Address != "" {
+			return fmt.Errorf("i2p address requires an i2p proxy")
+		}
+		return nil
+	}
+	if err := validateHostPort(c.Proxy); err != nil {
+		return fmt.Errorf("invalid i2p proxy %q: %w", c.Proxy, err)
+	}
+	if c.Address != "" && !strings.HasSuffix(c.Address, ".b32.i2p") {
+		return fmt.Errorf("invalid i2p address %q: must be a .b32.i2p address", c.Address)
+	}
+	if c.InboundPort < 0 || c.InboundPort > 65535 {
+		return fmt.Errorf("invalid i2p inbound port: %d", c.InboundPort)
+	}
+	if c.MaxConnections < 0 {
+		return fmt.Errorf("invalid i2p connection limit: %d", c.MaxConnections)
+	}
+	return nil
+}
+
+// CheckProxy verifies that the I2P router's SOCKS proxy accepts
+// connections, so a missing router fails startup instead of silently
+// leaving transactions unbroadcast.
+//
+// Parameters:
+//   - ctx: Context bounding the check
+//
+// Returns:
+//   - error: If the proxy is unreachable, nil when I2P is disabled
+func (c I2PConfig) CheckProxy(ctx context.Context) error {
+	if !c.Enabled() {
+		return nil
+	}
+	return checkProxyReachable(ctx, c.Proxy)
+}
+
+// DaemonArgs returns the monerod flags implementing this configuration:
+// --tx-proxy to broadcast transactions over I2P and --anonymous-inbound
+// when a b32 address is set.
+//
+// Returns:
+//   - []string: Command line arguments, nil when I2P is disabled
+func (c I2PConfig) DaemonArgs() []string {
+	if !c.Enabled() {
+		return nil
+	}
+	maxConns := c.MaxConnections
+	if maxConns == 0 {
+		maxConns = DefaultI2PMaxConnections
+	}
+	args := []string{"--tx-proxy", fmt.Sprintf("i2p,%s,%d", c.Proxy, maxConns)}
+	if c.Address != "" {
+		port := c.InboundPort
+		if port == 0 {
+			port = DefaultI2PInboundPort
+		}
+		args = append(args, "--anonymous-inbound",
+			fmt.Sprintf("%s,127.0.0.1:%d,%d", c.Address, port, maxConns))
+	}
+	return args
+}
+
+// checkProxyReachable dials addr to confirm a proxy is listening.
+func checkProxyReachable(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithTimeout(ctx, proxyCheckTimeout)
+	defer cancel()
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("proxy %s is not reachable: %w", addr, err)
+	}
+	return conn.Close()
+}
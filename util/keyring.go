@@ -0,0 +1,139 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KeyringService is the service name secrets are stored under in the OS
+// keyring.
+const KeyringService = "moneroger"
+
+// ErrCredentialNotFound is returned by CredentialProvider.Get for
+// accounts without a stored secret.
+var ErrCredentialNotFound = errors.New("credential not found")
+
+// CredentialProvider stores wallet passwords and RPC credentials outside
+// configuration files. Embedders may implement it to use their own
+// secret store; OSKeyring returns the operating system's.
+//
+// Related:
+//   - Config.Credentials, Config.Keyring
+//   - ResolveCredential
+type CredentialProvider interface {
+	// Get returns the secret stored for account, or an error wrapping
+	// ErrCredentialNotFound when there is none
+	Get(account string) (string, error)
+	// Set stores secret for account, replacing any previous secret
+	Set(account, secret string) error
+}
+
+// Keyring is a CredentialProvider backed by the operating system's
+// secret store:
+//   - Linux and BSD: the Secret Service (GNOME Keyring, KWallet), through
+//     the secret-tool command
+//   - macOS: the login Keychain, through the security command
+//   - Windows: files under the user configuration directory, encrypted
+//     for the current user with DPAPI
+//
+// Fields:
+//   - Service: Name the secrets are grouped under, KeyringService when
+//     empty
+type Keyring struct {
+	Service string
+}
+
+// OSKeyring returns the operating system keyring, storing secrets under
+// KeyringService.
+func OSKeyring() *Keyring {
+	return &Keyring{Service: KeyringService}
+}
+
+// service returns the service name, defaulting to KeyringService.
+func (k *Keyring) service() string {
+	if k.Service == "" {
+		return KeyringService
+	}
+	return k.Service
+}
+
+// Get returns the secret stored for account.
+//
+// Parameters:
+//   - account: Account name, such as DaemonRPCAccount(dataDir)
+//
+// Returns:
+//   - string: The stored secret
+//   - error: Wrapping ErrCredentialNotFound when nothing is stored, or
+//     keyring access failures
+func (k *Keyring) Get(account string) (string, error) {
+	return keyringGet(k.service(), account)
+}
+
+// Set stores secret for account, replacing any previous secret.
+//
+// Parameters:
+//   - account: Account name, such as WalletAccount(walletDir)
+//   - secret: Secret to store
+//
+// Returns:
+//   - error: Keyring access failures
+func (k *Keyring) Set(account, secret string) error {
+	return keyringSet(k.service(), account, secret)
+}
+
+// DaemonRPCAccount names the monerod RPC password of the daemon using
+// dataDir in a CredentialProvider.
+func DaemonRPCAccount(dataDir string) string {
+	return "monerod-rpc@" + dataDir
+}
+
+// WalletRPCAccount names the RPC password of the wallet-rpc serving
+// walletDir in a CredentialProvider.
+func WalletRPCAccount(walletDir string) string {
+	return "wallet-rpc@" + walletDir
+}
+
+// WalletAccount names the password of the wallet files in walletDir in
+// a CredentialProvider.
+func WalletAccount(walletDir string) string {
+	return "wallet@" + walletDir
+}
+
+// ResolveCredential returns the secret stored for account, generating
+// and storing a new one with SecurePassword when there is none yet.
+//
+// Parameters:
+//   - p: Credential store
+//   - account: Account name
+//
+// Returns:
+//   - string: The stored or newly generated secret
+//   - error: Read or write failures of the store
+func ResolveCredential(p CredentialProvider, account string) (string, error) {
+	secret, err := p.Get(account)
+	if err == nil {
+		return secret, nil
+	}
+	if !errors.Is(err, ErrCredentialNotFound) {
+		return "", fmt.Errorf("reading %s from credential store: %w", account, err)
+	}
+	secret = SecurePassword()
+	if err := p.Set(account, secret); err != nil {
+		return "", fmt.Errorf("saving %s to credential store: %w", account, err)
+	}
+	return secret, nil
+}
+
+// CredentialStore returns the credential provider the configuration
+// selects: Credentials when set, OSKeyring when Keyring is true,
+// otherwise nil.
+func (c Config) CredentialStore() CredentialProvider {
+	if c.Credentials != nil {
+		return c.Credentials
+	}
+	if c.Keyring {
+		return OSKeyring()
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build darwin
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// securityItemNotFound is the exit status of the security command when
+// no keychain item matches (errSecItemNotFound)
+const securityItemNotFound = 44
+
+// keyringGet reads the secret from the login Keychain.
+func keyringGet(service, account string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == securityItemNotFound {
+			return "", fmt.Errorf("%s: %w", account, ErrCredentialNotFound)
+		}
+		return "", fmt.Errorf("security find-generic-password: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// keyringSet stores the secret in the login Keychain. The command is fed
+// to "security -i" on stdin, keeping the secret off the command line.
+func keyringSet(service, account, secret string) error {
+	for _, s := range []string{service, account, secret} {
+		if strings.ContainsAny(s, "\"\\\r\n") {
+			return fmt.Errorf("keychain entries cannot contain quotes, backslashes or line breaks")
+		}
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s \"%s\" -a \"%s\" -w \"%s\"\n",
+		service, account, secret))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil || stderr.Len() > 0 {
+		// security -i reports command failures on stderr, not in its exit status
+		return fmt.Errorf("security add-generic-password: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
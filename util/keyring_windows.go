@@ -0,0 +1,85 @@
+//go:build windows
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// keyringPath returns the file holding the encrypted secret of account,
+// named by a hash since account names contain path separators.
+func keyringPath(service, account string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(account))
+	return filepath.Join(dir, service, "credentials", hex.EncodeToString(sum[:])), nil
+}
+
+// keyringGet reads and decrypts the secret with DPAPI.
+func keyringGet(service, account string) (string, error) {
+	path, err := keyringPath(service, account)
+	if err != nil {
+		return "", err
+	}
+	blob, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w", account, ErrCredentialNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	secret, err := dpapi(blob, []byte(account), false)
+	if err != nil {
+		return "", fmt.Errorf("CryptUnprotectData: %w", err)
+	}
+	return string(secret), nil
+}
+
+// keyringSet encrypts the secret for the current user with DPAPI and
+// writes it under the user configuration directory.
+func keyringSet(service, account, secret string) error {
+	path, err := keyringPath(service, account)
+	if err != nil {
+		return err
+	}
+	blob, err := dpapi([]byte(secret), []byte(account), true)
+	if err != nil {
+		return fmt.Errorf("CryptProtectData: %w", err)
+	}
+	return writePrivateFile(path, blob)
+}
+
+// dpapi encrypts or decrypts data for the current user, with entropy
+// binding the blob to its account.
+func dpapi(data, entropy []byte, protect bool) ([]byte, error) {
+	var in, salt windows.DataBlob
+	if len(data) > 0 {
+		in = windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+	}
+	if len(entropy) > 0 {
+		salt = windows.DataBlob{Size: uint32(len(entropy)), Data: &entropy[0]}
+	}
+	var out windows.DataBlob
+	var err error
+	if protect {
+		err = windows.CryptProtectData(&in, nil, &salt, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	} else {
+		err = windows.CryptUnprotectData(&in, nil, &salt, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
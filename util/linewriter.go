@@ -0,0 +1,118 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// maxLineLength bounds how much of an unterminated line is buffered before
+// it is emitted anyway, so a process that never writes a newline cannot
+// grow memory without limit.
+const maxLineLength = 64 * 1024
+
+// LineWriter is an io.Writer that splits its input into lines and hands
+// each complete line, without the trailing newline, to an emit function.
+// It is used to tag and route child process output line by line.
+//
+// Fields:
+//   - emit: Receives each complete line
+//   - partial: Bytes written since the last newline
+//
+// A LineWriter is safe for concurrent use, but each output stream should
+// have its own instance so partial lines from different streams are not
+// interleaved.
+type LineWriter struct {
+	mu      sync.Mutex
+	emit    func(line string)
+	partial []byte
+}
+
+// NewLineWriter creates a LineWriter that calls emit for each line.
+//
+// Parameters:
+//   - emit: Function receiving each complete line
+//
+// Returns:
+//   - *LineWriter: Writer ready for use
+func NewLineWriter(emit func(line string)) *LineWriter {
+	return &LineWriter{emit: emit}
+}
+
+// NewPrefixWriter creates a LineWriter that copies each line to w with
+// prefix prepended.
+//
+// Parameters:
+//   - w: Destination writer, must be safe for concurrent use if shared
+//   - prefix: Text placed before every line, such as "[monerod] "
+//
+// Returns:
+//   - *LineWriter: Writer tagging every line with prefix
+func NewPrefixWriter(w io.Writer, prefix string) *LineWriter {
+	return NewLineWriter(func(line string) {
+		_, _ = io.WriteString(w, prefix+line+"\n")
+	})
+}
+
+// NewLogWriter creates a LineWriter that logs each line as a separate
+// record at the given level.
+//
+// Parameters:
+//   - logger: Destination logger, typically tagged with the component
+//   - level: Level for every record
+//   - stream: Value of the "stream" attribute, such as "stdout"
+//
+// Returns:
+//   - *LineWriter: Writer logging every line
+func NewLogWriter(logger *slog.Logger, level slog.Level, stream string) *LineWriter {
+	return NewLineWriter(func(line string) {
+		logger.Log(context.Background(), level, line, "stream", stream)
+	})
+}
+
+// Write implements io.Writer. It never fails.
+//
+// Parameters:
+//   - p: Bytes to split into lines
+//
+// Returns:
+//   - int: Always len(p)
+//   - error: Always nil
+func (l *LineWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			l.partial = append(l.partial, p...)
+			if len(l.partial) >= maxLineLength {
+				l.flushLocked()
+			}
+			break
+		}
+		l.partial = append(l.partial, p[:i]...)
+		l.flushLocked()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Flush emits any buffered unterminated line.
+func (l *LineWriter) Flush() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.partial) > 0 {
+		l.flushLocked()
+	}
+}
+
+// flushLocked emits the buffered line; l.mu must be held.
+func (l *LineWriter) flushLocked() {
+	line := string(bytes.TrimSuffix(l.partial, []byte("\r")))
+	l.partial = l.partial[:0]
+	l.emit(line)
+}
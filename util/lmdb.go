@@ -0,0 +1,50 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrDatabaseInUse reports a blockchain database opened by a process
+// other than the one about to start.
+var ErrDatabaseInUse = errors.New("blockchain database is in use by another process")
+
+// LMDBLockFile returns the lock file of the blockchain database monerod
+// keeps in dataDir, which it is given as --data-dir.
+func LMDBLockFile(dataDir string) string {
+	return filepath.Join(dataDir, "lmdb", "lock.mdb")
+}
+
+// RemoveStaleLMDBLock checks the lock file of the blockchain database in
+// dataDir before monerod is started. A lock file no process holds, as
+// left by a crash, is removed so monerod starts with a fresh reader
+// table; one held by a running process means another monerod has the
+// database open.
+//
+// Parameters:
+//   - dataDir: monerod's data directory
+//
+// Returns:
+//   - bool: Whether a stale lock file was removed
+//   - error: ErrDatabaseInUse if a process holds the lock, otherwise
+//     failures reading or removing the lock file
+func RemoveStaleLMDBLock(dataDir string) (bool, error) {
+	path := LMDBLockFile(dataDir)
+	held, err := fileLocked(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking %s: %w", path, err)
+	}
+	if held {
+		return false, fmt.Errorf("%s: %w", path, ErrDatabaseInUse)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
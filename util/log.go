@@ -0,0 +1,52 @@
+package util
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	moneroconst "github.com/opd-ai/moneroger/const"
+)
+
+// pkgLogger is the logger used by package-level functions such as Path.
+var pkgLogger atomic.Pointer[slog.Logger]
+
+// SetLogger sets the logger used by this package's free functions.
+// Passing nil restores the default, slog.Default().
+//
+// Parameters:
+//   - l: Logger to use for diagnostics
+func SetLogger(l *slog.Logger) {
+	pkgLogger.Store(l)
+}
+
+// logger returns the package logger.
+func logger() *slog.Logger {
+	if l := pkgLogger.Load(); l != nil {
+		return l
+	}
+	return slog.Default()
+}
+
+// Log returns the configured logger, or the package logger if none was set.
+// Components derive their own logger from it, tagged with their name.
+//
+// Returns:
+//   - *slog.Logger: Logger for diagnostics, never nil
+func (c Config) Log() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return logger()
+}
+
+// OutputBufferSize returns how many bytes of recent output are retained
+// per child process.
+//
+// Returns:
+//   - int: LogBufferSize, or moneroconst.DefaultOutputBufferSize when unset
+func (c Config) OutputBufferSize() int {
+	if c.LogBufferSize > 0 {
+		return c.LogBufferSize
+	}
+	return moneroconst.DefaultOutputBufferSize
+}
@@ -0,0 +1,169 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+
+	moneroconst "github.com/opd-ai/moneroger/const"
+)
+
+// Network selects the Monero network the services run on.
+// The zero value defers to Config.TestNet, for configurations written
+// before the field existed.
+type Network string
+
+// Supported networks
+const (
+	NetworkMainnet  Network = "mainnet"
+	NetworkTestnet  Network = "testnet"
+	NetworkStagenet Network = "stagenet"
+)
+
+// networkPortOffset is the distance of each network's default ports from
+// the mainnet ones, following monerod's 18xxx, 28xxx and 38xxx ranges.
+var networkPortOffset = map[Network]int{
+	NetworkMainnet:  0,
+	NetworkTestnet:  10000,
+	NetworkStagenet: 20000,
+}
+
+// ParseNetwork converts a network name into a Network.
+//
+// Parameters:
+//   - name: "mainnet", "testnet" or "stagenet"
+//
+// Returns:
+//   - Network: The named network
+//   - error: For unknown names
+func ParseNetwork(name string) (Network, error) {
+	n := Network(name)
+	if n == "" {
+		return "", fmt.Errorf("network name cannot be empty")
+	}
+	if err := n.Validate(); err != nil {
+		return "", err
+	}
+	return n, nil
+}
+
+// Validate reports unknown network names. The empty Network is valid.
+func (n Network) Validate() error {
+	if _, ok := networkPortOffset[n]; !ok && n != "" {
+		return fmt.Errorf("unknown network %q, want mainnet, testnet or stagenet", string(n))
+	}
+	return nil
+}
+
+// Flag returns the command line flag selecting the network, shared by
+// monerod and monero-wallet-rpc, or "" for mainnet.
+func (n Network) Flag() string {
+	switch n {
+	case NetworkTestnet:
+		return "--testnet"
+	case NetworkStagenet:
+		return "--stagenet"
+	}
+	return ""
+}
+
+// DaemonPort returns the default monerod RPC port of the network:
+// 18081, 28081 or 38081.
+func (n Network) DaemonPort() int {
+	return moneroconst.DefaultMonerodPort + networkPortOffset[n]
+}
+
+// WalletPort returns the default wallet RPC port of the network:
+// 18083, 28083 or 38083.
+func (n Network) WalletPort() int {
+	return moneroconst.DefaultWalletRPCPort + networkPortOffset[n]
+}
+
+// ZMQPubPort returns the default ZMQ publisher port of the network:
+// 18086, 28086 or 38086.
+func (n Network) ZMQPubPort() int {
+	return moneroconst.DefaultZMQPubPort + networkPortOffset[n]
+}
+
+// RestrictedPort returns the default restricted RPC port of a public
+// node on the network: 18089, 28089 or 38089.
+func (n Network) RestrictedPort() int {
+	return moneroconst.DefaultRestrictedPort + networkPortOffset[n]
+}
+
+// NetType returns the network the configuration selects: Network when
+// set, otherwise testnet or mainnet according to TestNet.
+func (c Config) NetType() Network {
+	if c.Network != "" {
+		return c.Network
+	}
+	if c.TestNet {
+		return NetworkTestnet
+	}
+	return NetworkMainnet
+}
+
+// NetworkDataDir returns the directory holding the state of the selected
+// network: the blockchain, process logs, PID files and generated
+// credentials. Like monerod's own default layout, that is DataDir on
+// mainnet and its testnet or stagenet subdirectory otherwise, so
+// switching networks never mixes their state.
+//
+// Returns:
+//   - string: The network's directory, empty when DataDir is
+func (c Config) NetworkDataDir() string {
+	n := c.NetType()
+	if c.DataDir == "" || n == NetworkMainnet {
+		return c.DataDir
+	}
+	return filepath.Join(c.DataDir, string(n))
+}
+
+// EffectiveMoneroPort returns MoneroPort, or the selected network's
+// default daemon RPC port when it is 0.
+func (c Config) EffectiveMoneroPort() int {
+	if c.MoneroPort == 0 {
+		return c.NetType().DaemonPort()
+	}
+	return c.MoneroPort
+}
+
+// EffectiveWalletPort returns WalletPort, or the selected network's
+// default wallet RPC port when it is 0.
+func (c Config) EffectiveWalletPort() int {
+	if c.WalletPort == 0 {
+		return c.NetType().WalletPort()
+	}
+	return c.WalletPort
+}
+
+// SetNetwork switches the configuration to network n. Ports still at the
+// previous network's defaults move to n's defaults, and remote nodes
+// are dropped when leaving mainnet, since the curated public nodes are
+// mainnet only.
+//
+// Parameters:
+//   - n: Network to switch to
+//
+// Related:
+//   - Network.DaemonPort, Network.WalletPort, Network.ZMQPubPort,
+//     Network.RestrictedPort
+func (c *Config) SetNetwork(n Network) {
+	prev := c.NetType()
+	if c.MoneroPort == prev.DaemonPort() {
+		c.MoneroPort = n.DaemonPort()
+	}
+	if c.WalletPort == prev.WalletPort() {
+		c.WalletPort = n.WalletPort()
+	}
+	if c.ZMQPubPort == prev.ZMQPubPort() {
+		c.ZMQPubPort = n.ZMQPubPort()
+	}
+	if c.RestrictedPort == prev.RestrictedPort() {
+		c.RestrictedPort = n.RestrictedPort()
+	}
+	if prev == NetworkMainnet && n != NetworkMainnet {
+		c.RemoteNode, c.RemoteNodes = "", nil
+	}
+	c.Network = n
+	c.TestNet = n == NetworkTestnet
+}
@@ -0,0 +1,144 @@
+package util
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// Character classes used by SecurePasswordN. Symbols leave out ':', which
+// separates user and password in --rpc-login, as well as quotes,
+// backslashes, whitespace and characters shells expand.
+const (
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits  = "0123456789"
+	passwordSymbols = "-_.~+=,^"
+)
+
+// PasswordPolicy sets the minimum number of characters from each class
+// in a generated password.
+//
+// Fields:
+//   - MinLower, MinUpper, MinDigits: Minimum lowercase letters,
+//     uppercase letters and digits
+//   - MinSymbols: Minimum symbols; symbols appear only when above 0,
+//     since not every consumer of a password accepts them
+//
+// Related:
+//   - DefaultPasswordPolicy
+//   - SecurePasswordN
+type PasswordPolicy struct {
+	MinLower   int
+	MinUpper   int
+	MinDigits  int
+	MinSymbols int
+}
+
+// DefaultPasswordPolicy is the policy SecurePassword uses: letters of
+// both cases and digits, without symbols, so generated RPC passwords are
+// safe on command lines and in configuration files.
+var DefaultPasswordPolicy = PasswordPolicy{MinLower: 2, MinUpper: 2, MinDigits: 2}
+
+// SecurePassword generates a cryptographically secure random password.
+//
+// Returns:
+//   - string: A 20-character password following DefaultPasswordPolicy
+//
+// Panics:
+//   - If the system's random source fails (should be extremely rare)
+//
+// Related:
+//   - SecurePasswordN for other lengths and policies
+func SecurePassword() string {
+	res, err := SecurePasswordN(20, DefaultPasswordPolicy)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// SecurePasswordN generates a password of the given length from
+// crypto/rand, containing at least the characters policy requires. The
+// remaining characters are drawn from all allowed classes, and the result
+// is shuffled so required characters have no fixed position.
+//
+// Parameters:
+//   - length: Number of characters
+//   - policy: Minimum characters per class
+//
+// Returns:
+//   - string: The password
+//   - error: If a minimum is negative, the minimums exceed length, or the
+//     random source fails
+func SecurePasswordN(length int, policy PasswordPolicy) (string, error) {
+	classes := []struct {
+		chars string
+		min   int
+	}{
+		{passwordLower, policy.MinLower},
+		{passwordUpper, policy.MinUpper},
+		{passwordDigits, policy.MinDigits},
+		{passwordSymbols, policy.MinSymbols},
+	}
+	required := 0
+	for _, class := range classes {
+		if class.min < 0 {
+			return "", fmt.Errorf("invalid password policy: negative minimum %d", class.min)
+		}
+		required += class.min
+	}
+	if length < 1 || required > length {
+		return "", fmt.Errorf("invalid password length %d for a policy requiring %d characters", length, required)
+	}
+
+	all := passwordLower + passwordUpper + passwordDigits
+	if policy.MinSymbols > 0 {
+		all += passwordSymbols
+	}
+	res := make([]byte, 0, length)
+	for _, class := range classes {
+		for i := 0; i < class.min; i++ {
+			c, err := randomChar(class.chars)
+			if err != nil {
+				return "", err
+			}
+			res = append(res, c)
+		}
+	}
+	for len(res) < length {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		res = append(res, c)
+	}
+
+	// Fisher-Yates shuffle
+	for i := len(res) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		res[i], res[j] = res[j], res[i]
+	}
+	return string(res), nil
+}
+
+// randomChar returns a uniformly chosen character of chars.
+func randomChar(chars string) (byte, error) {
+	i, err := randomInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+	return chars[i], nil
+}
+
+// randomInt returns a uniform integer in [0, n) from crypto/rand.
+func randomInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("generating password: %w", err)
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,162 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// orphanPollInterval is how often WatchOrphan and StopProcess check
+// whether a process that is not our child is still running
+const orphanPollInterval = 500 * time.Millisecond
+
+// WritePIDFile records the ID of a spawned process, so a later run can
+// find it if this one exits without stopping it.
+//
+// Parameters:
+//   - path: PID file; its directory is created if needed
+//   - pid: Process ID
+//
+// Returns:
+//   - error: Directory creation or write failures
+func WritePIDFile(path string, pid int) error {
+	return writePrivateFile(path, []byte(strconv.Itoa(pid)+"\n"))
+}
+
+// ReadPIDFile reads a process ID written by WritePIDFile.
+//
+// Parameters:
+//   - path: PID file
+//
+// Returns:
+//   - int: Process ID
+//   - error: fs.ErrNotExist if there is no PID file, or a malformed file
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%s: malformed PID file", path)
+	}
+	return pid, nil
+}
+
+// RemovePIDFile deletes a PID file. A missing file is not an error.
+//
+// Parameters:
+//   - path: PID file
+//
+// Returns:
+//   - error: Removal failures
+func RemovePIDFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// FindOrphan looks for a process left running by an earlier run, as
+// recorded in a PID file. The process must still exist and run exe;
+// otherwise the PID file is stale, for example after a reboot reused the
+// ID, and is removed.
+//
+// Parameters:
+//   - pidFile: PID file written by WritePIDFile
+//   - exe: Executable the process must run
+//
+// Returns:
+//   - *os.Process: The orphaned process, nil if there is none
+//   - error: Unreadable PID files
+func FindOrphan(pidFile, exe string) (*os.Process, error) {
+	pid, err := ReadPIDFile(pidFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p, err := os.FindProcess(pid)
+	if err == nil && ProcessAlive(p) {
+		if path, err := ProcessExecutable(pid); err == nil && sameExecutable(path, exe) {
+			return p, nil
+		}
+	}
+	return nil, RemovePIDFile(pidFile)
+}
+
+// sameExecutable compares executable paths, resolving symbolic links.
+func sameExecutable(a, b string) bool {
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// WatchOrphan tracks the termination of a process that is not a child of
+// this one, such as one found by FindOrphan, by polling whether it is
+// alive. Its exit status cannot be observed, so Err always returns nil
+// and Uptime counts from the call.
+//
+// Parameters:
+//   - p: Running process
+//
+// Returns:
+//   - *ProcessExit: Handle reporting the process exit
+func WatchOrphan(p *os.Process) *ProcessExit {
+	e := &ProcessExit{done: make(chan struct{}), startedAt: time.Now()}
+	go func() {
+		for ProcessAlive(p) {
+			time.Sleep(orphanPollInterval)
+		}
+		e.exitedAt = time.Now()
+		close(e.done)
+	}()
+	return e
+}
+
+// StopProcess interrupts a process that is not a child of this one and
+// waits for it to exit, killing it if it is still running after timeout.
+//
+// Parameters:
+//   - ctx: Context bounding the wait
+//   - p: Process to stop
+//   - timeout: Grace period before the process is killed
+//
+// Returns:
+//   - error: If the process could not be stopped
+func StopProcess(ctx context.Context, p *os.Process, timeout time.Duration) error {
+	if err := InterruptProcess(p); err != nil && ProcessAlive(p) {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for ProcessAlive(p) {
+		if time.Now().After(deadline) {
+			if err := p.Kill(); err != nil && ProcessAlive(p) {
+				return fmt.Errorf("failed to kill process %d: %w", p.Pid, err)
+			}
+			deadline = time.Now().Add(timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(orphanPollInterval):
+		}
+	}
+	return nil
+}
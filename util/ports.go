@@ -0,0 +1,217 @@
+package util
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// portReservationTTL is how long a port handed out by ReservePorts is
+// withheld from later calls in this process, leaving the caller time
+// to start the server that binds it
+const portReservationTTL = time.Minute
+
+// portReservations records the ports recently handed out in this
+// process, so concurrent callers, such as parallel tests or several
+// services in auto-port mode, are never given the same port between
+// the check and the bind
+var portReservations = struct {
+	sync.Mutex
+	until map[int]time.Time
+}{until: make(map[int]time.Time)}
+
+// PortRange bounds the ports chosen automatically.
+//
+// Fields:
+//   - Min, Max: Inclusive bounds; both 0 let the operating system pick
+//     from its ephemeral range
+type PortRange struct {
+	Min int
+	Max int
+}
+
+// ParsePortRange parses a range written as "min-max", such as
+// "20000-20999".
+//
+// Parameters:
+//   - s: The range, empty for the operating system's choice
+//
+// Returns:
+//   - PortRange: The parsed range
+//   - error: If s is malformed or the range is invalid
+func ParsePortRange(s string) (PortRange, error) {
+	if s == "" {
+		return PortRange{}, nil
+	}
+	lo, hi, ok := strings.Cut(s, "-")
+	if !ok {
+		return PortRange{}, fmt.Errorf("invalid port range %q: want min-max", s)
+	}
+	var r PortRange
+	var err error
+	if r.Min, err = strconv.Atoi(strings.TrimSpace(lo)); err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+	if r.Max, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", s, err)
+	}
+	return r, r.Validate()
+}
+
+// Validate checks that the range is empty or lies within 1-65535 with
+// Min not above Max.
+//
+// Returns:
+//   - error: Description of the problem, nil if valid
+func (r PortRange) Validate() error {
+	if r.Min == 0 && r.Max == 0 {
+		return nil
+	}
+	if r.Min < 1 || r.Max > 65535 || r.Min > r.Max {
+		return fmt.Errorf("invalid port range %d-%d", r.Min, r.Max)
+	}
+	return nil
+}
+
+// PortReservation holds free ports open until the caller is about to
+// bind them.
+//
+// Fields:
+//   - ports: The reserved ports, in the order requested
+//   - listeners: Listeners keeping other processes off the ports
+type PortReservation struct {
+	ports     []int
+	listeners []net.Listener
+}
+
+// ReservePorts finds n distinct free ports and keeps them bound until
+// Release, so no other process can take them in the meantime. Released
+// ports are also withheld from later calls in this process for a
+// minute, giving the caller time to start its servers.
+//
+// Parameters:
+//   - addr: IPv4 address to bind, empty for the loopback address
+//     monerod and monero-wallet-rpc bind by default
+//   - n: Number of ports, such as 2 for a daemon and wallet pair
+//   - r: Range to choose from, empty for the operating system's choice
+//
+// Returns:
+//   - *PortReservation: The reserved ports
+//   - error: If the range is invalid or has too few free ports
+//
+// Related:
+//   - GetFreePorts when the ports are bound right away
+func ReservePorts(addr string, n int, r PortRange) (*PortReservation, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	if addr == "" {
+		addr = "127.0.0.1"
+	}
+	res := &PortReservation{}
+	for len(res.ports) < n {
+		ln, err := res.listen(addr, r)
+		if err != nil {
+			res.Release()
+			return nil, err
+		}
+		res.listeners = append(res.listeners, ln)
+		res.ports = append(res.ports, ln.Addr().(*net.TCPAddr).Port)
+	}
+	return res, nil
+}
+
+// listen binds one port not reserved yet, from r or the ephemeral range.
+func (res *PortReservation) listen(addr string, r PortRange) (net.Listener, error) {
+	if r.Min == 0 {
+		// Ephemeral ports rarely repeat, but a reserved one is skipped
+		for attempt := 0; attempt < 16; attempt++ {
+			ln, err := net.Listen("tcp", net.JoinHostPort(addr, "0"))
+			if err != nil {
+				return nil, err
+			}
+			if reservePort(ln.Addr().(*net.TCPAddr).Port) {
+				return ln, nil
+			}
+			ln.Close()
+		}
+		return nil, fmt.Errorf("no unreserved ephemeral port found on %s", addr)
+	}
+	size := r.Max - r.Min + 1
+	start := rand.Intn(size)
+	for i := 0; i < size; i++ {
+		port := r.Min + (start+i)%size
+		if !reservePort(port) {
+			continue
+		}
+		ln, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+		if err == nil {
+			return ln, nil
+		}
+		releasePort(port)
+	}
+	return nil, fmt.Errorf("no free port in range %d-%d on %s", r.Min, r.Max, addr)
+}
+
+// Ports returns the reserved ports.
+func (res *PortReservation) Ports() []int {
+	return append([]int(nil), res.ports...)
+}
+
+// Release closes the listeners, so the caller can bind the ports. The
+// ports stay withheld from ReservePorts in this process for a while.
+func (res *PortReservation) Release() {
+	for _, ln := range res.listeners {
+		ln.Close()
+	}
+	res.listeners = nil
+}
+
+// GetFreePorts finds n distinct free ports, like ReservePorts, and
+// releases them for the caller to bind right away.
+//
+// Parameters:
+//   - addr: IPv4 address to bind, empty for loopback
+//   - n: Number of ports
+//   - r: Range to choose from, empty for the operating system's choice
+//
+// Returns:
+//   - []int: Distinct port numbers
+//   - error: If the range is invalid or has too few free ports
+func GetFreePorts(addr string, n int, r PortRange) ([]int, error) {
+	res, err := ReservePorts(addr, n, r)
+	if err != nil {
+		return nil, err
+	}
+	res.Release()
+	return res.Ports(), nil
+}
+
+// reservePort records port as handed out, reporting false if it already
+// is.
+func reservePort(port int) bool {
+	portReservations.Lock()
+	defer portReservations.Unlock()
+	now := time.Now()
+	if until, ok := portReservations.until[port]; ok && now.Before(until) {
+		return false
+	}
+	for p, until := range portReservations.until {
+		if !now.Before(until) {
+			delete(portReservations.until, p)
+		}
+	}
+	portReservations.until[port] = now.Add(portReservationTTL)
+	return true
+}
+
+// releasePort forgets a reservation for a port that could not be bound.
+func releasePort(port int) {
+	portReservations.Lock()
+	defer portReservations.Unlock()
+	delete(portReservations.until, port)
+}
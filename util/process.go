@@ -0,0 +1,145 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// ProcessAlive reports whether p refers to a running process.
+//
+// Parameters:
+//   - p: Process to probe, nil is reported as not alive
+//
+// Returns:
+//   - bool: true if the process exists and has not terminated
+//
+// Note: An exited child that has not yet been waited for is still
+// reported as alive by the operating system.
+func ProcessAlive(p *os.Process) bool {
+	if p == nil {
+		return false
+	}
+	return processAlive(p)
+}
+
+// FindExecutable searches dirs in order for an executable called name,
+// trying the platform's executable extensions (".exe" and the rest of
+// PATHEXT on Windows).
+//
+// Parameters:
+//   - dirs: Directories to search, typically Path()
+//   - name: Executable name without extension, such as "monerod"
+//
+// Returns:
+//   - string: Full path of the first match
+//   - error: If no directory contains the executable
+//
+// Related:
+//   - Path for the default search directories
+func FindExecutable(dirs []string, name string) (string, error) {
+	for _, dir := range dirs {
+		for _, ext := range executableExtensions() {
+			candidate := filepath.Join(dir, name+ext)
+			if FileExists(candidate) {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s not found", name)
+}
+
+// OpenProcessLog opens the log file a detached process writes its output
+// to, appending to earlier output.
+//
+// Parameters:
+//   - path: Log file; its directory is created if needed
+//
+// Returns:
+//   - *os.File: File to use as the process's stdout and stderr
+//   - error: Directory creation or open failures
+func OpenProcessLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+}
+
+// ProcessExit tracks the termination of a started child process. Exactly
+// one goroutine waits on the process, so its exit can be observed by any
+// number of callers (shutdown, supervision) without racing on Wait.
+//
+// Fields:
+//   - done: Closed when the process has exited
+//   - err: Result of cmd.Wait, valid once done is closed
+//   - startedAt: When tracking began, used to compute uptime
+//   - exitedAt: When the process exited, valid once done is closed
+type ProcessExit struct {
+	done      chan struct{}
+	err       error
+	startedAt time.Time
+	exitedAt  time.Time
+}
+
+// WatchProcess starts waiting for cmd to exit in the background.
+//
+// Parameters:
+//   - cmd: A command that has been successfully started
+//
+// Returns:
+//   - *ProcessExit: Handle reporting the process exit
+//
+// Callers must not call cmd.Wait or cmd.Process.Wait themselves.
+func WatchProcess(cmd *exec.Cmd) *ProcessExit {
+	e := &ProcessExit{done: make(chan struct{}), startedAt: time.Now()}
+	go func() {
+		e.err = cmd.Wait()
+		e.exitedAt = time.Now()
+		close(e.done)
+	}()
+	return e
+}
+
+// Done returns a channel that is closed when the process exits.
+// A nil receiver returns a nil channel, which blocks forever.
+func (e *ProcessExit) Done() <-chan struct{} {
+	if e == nil {
+		return nil
+	}
+	return e.done
+}
+
+// Exited reports whether the process has exited.
+func (e *ProcessExit) Exited() bool {
+	if e == nil {
+		return false
+	}
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Err returns the process exit error, or nil if it exited cleanly or is
+// still running.
+func (e *ProcessExit) Err() error {
+	if !e.Exited() {
+		return nil
+	}
+	return e.err
+}
+
+// Uptime returns how long the process ran, or has been running so far.
+func (e *ProcessExit) Uptime() time.Duration {
+	if e == nil {
+		return 0
+	}
+	if e.Exited() {
+		return e.exitedAt.Sub(e.startedAt)
+	}
+	return time.Since(e.startedAt)
+}
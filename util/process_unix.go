@@ -0,0 +1,71 @@
+//go:build !windows
+
+package util
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// executableExtensions lists the file extensions tried when resolving an
+// executable name. Unix executables carry no extension.
+func executableExtensions() []string {
+	return []string{""}
+}
+
+// PrepareCommand applies platform-specific process attributes required
+// for InterruptProcess to work. It must be called before cmd.Start and is
+// a no-op on Unix.
+//
+// Parameters:
+//   - cmd: Command about to be started
+func PrepareCommand(cmd *exec.Cmd) {}
+
+// PrepareDetachedCommand starts cmd in a new session, so it neither
+// receives signals meant for the manager's terminal nor depends on the
+// manager staying alive. It replaces PrepareCommand for detached
+// services and must be called before cmd.Start.
+//
+// Parameters:
+//   - cmd: Command about to be started
+func PrepareDetachedCommand(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
+
+// InterruptProcess asks p to shut down gracefully by sending SIGINT.
+//
+// Parameters:
+//   - p: Process to interrupt
+//
+// Returns:
+//   - error: Any error delivering the signal
+func InterruptProcess(p *os.Process) error {
+	return p.Signal(os.Interrupt)
+}
+
+// IsInterruptExit reports whether err is the expected result of a process
+// terminating due to InterruptProcess.
+//
+// Parameters:
+//   - err: Error returned by cmd.Wait
+//
+// Returns:
+//   - bool: true if the process was killed by SIGINT
+func IsInterruptExit(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGINT
+}
+
+// processAlive probes p with signal 0.
+func processAlive(p *os.Process) bool {
+	return p.Signal(syscall.Signal(0)) == nil
+}
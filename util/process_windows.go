@@ -0,0 +1,150 @@
+//go:build windows
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// ctrlBreakEvent is the CTRL_BREAK_EVENT console control code
+	ctrlBreakEvent = 1
+
+	// statusControlCExit is the exit code of a process terminated by a console control event
+	statusControlCExit = 0xC000013A
+
+	// stillActive is the exit code reported by GetExitCodeProcess for running processes
+	stillActive = 259
+
+	// processQueryLimitedInformation is the PROCESS_QUERY_LIMITED_INFORMATION access right
+	processQueryLimitedInformation = 0x1000
+
+	// detachedProcess is the DETACHED_PROCESS creation flag
+	detachedProcess = 0x8
+)
+
+var procGenerateConsoleCtrlEvent = syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+
+// executableExtensions lists the file extensions tried when resolving an
+// executable name, taken from PATHEXT with ".exe" as the fallback.
+func executableExtensions() []string {
+	var exts []string
+	for _, ext := range strings.Split(os.Getenv("PATHEXT"), ";") {
+		if ext != "" {
+			exts = append(exts, strings.ToLower(ext))
+		}
+	}
+	if len(exts) == 0 {
+		exts = []string{".exe"}
+	}
+	return exts
+}
+
+// PrepareCommand starts cmd in its own process group so that a console
+// control event can be delivered to it without interrupting the manager
+// itself. It must be called before cmd.Start.
+//
+// Parameters:
+//   - cmd: Command about to be started
+func PrepareCommand(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// PrepareDetachedCommand starts cmd in its own process group without a
+// console, so it does not depend on the manager's console staying open.
+// It replaces PrepareCommand for detached services and must be called
+// before cmd.Start. InterruptProcess falls back to taskkill for such
+// processes.
+//
+// Parameters:
+//   - cmd: Command about to be started
+func PrepareDetachedCommand(cmd *exec.Cmd) {
+	PrepareCommand(cmd)
+	cmd.SysProcAttr.CreationFlags |= detachedProcess
+}
+
+// InterruptProcess asks p to shut down gracefully. Windows has no SIGINT,
+// so a CTRL_BREAK_EVENT is sent to the process group created by
+// PrepareCommand. When no console is attached, for example when running as
+// a service, it falls back to terminating the process tree with taskkill.
+//
+// Parameters:
+//   - p: Process to interrupt
+//
+// Returns:
+//   - error: Any error if neither mechanism succeeded
+func InterruptProcess(p *os.Process) error {
+	r, _, callErr := procGenerateConsoleCtrlEvent.Call(ctrlBreakEvent, uintptr(p.Pid))
+	if r != 0 {
+		return nil
+	}
+	out, err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("GenerateConsoleCtrlEvent: %v; taskkill: %w: %s", callErr, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// IsInterruptExit reports whether err is the expected result of a process
+// terminating due to InterruptProcess.
+//
+// Parameters:
+//   - err: Error returned by cmd.Wait
+//
+// Returns:
+//   - bool: true if the process exited with STATUS_CONTROL_C_EXIT
+func IsInterruptExit(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	return uint32(exitErr.ExitCode()) == statusControlCExit
+}
+
+// processAlive queries the process exit code, since Windows does not
+// support signal 0.
+func processAlive(p *os.Process) bool {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(p.Pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// ProcessExecutable returns the path of the executable a process runs.
+//
+// Parameters:
+//   - pid: Process ID
+//
+// Returns:
+//   - string: Absolute executable path
+//   - error: If the process does not exist or cannot be inspected
+func ProcessExecutable(pid int) (string, error) {
+	h, err := windows.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return "", err
+	}
+	defer windows.CloseHandle(h)
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(h, 0, &buf[0], &size); err != nil {
+		return "", err
+	}
+	return windows.UTF16ToString(buf[:size]), nil
+}
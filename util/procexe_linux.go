@@ -0,0 +1,24 @@
+package util
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ProcessExecutable returns the path of the executable a process runs.
+//
+// Parameters:
+//   - pid: Process ID
+//
+// Returns:
+//   - string: Absolute executable path
+//   - error: If the process does not exist or cannot be inspected
+func ProcessExecutable(pid int) (string, error) {
+	path, err := os.Readlink("/proc/" + strconv.Itoa(pid) + "/exe")
+	if err != nil {
+		return "", err
+	}
+	// The executable was replaced, e.g. by an upgrade, while running
+	return strings.TrimSuffix(path, " (deleted)"), nil
+}
@@ -0,0 +1,32 @@
+//go:build !linux && !windows
+
+package util
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ProcessExecutable returns the path of the executable a process runs,
+// as reported by ps. Processes started through a relative path report
+// that path.
+//
+// Parameters:
+//   - pid: Process ID
+//
+// Returns:
+//   - string: Executable path
+//   - error: If the process does not exist or cannot be inspected
+func ProcessExecutable(pid int) (string, error) {
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", fmt.Errorf("ps: %w", err)
+	}
+	path := strings.TrimSpace(string(out))
+	if path == "" {
+		return "", fmt.Errorf("process %d not found", pid)
+	}
+	return path, nil
+}
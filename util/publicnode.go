@@ -0,0 +1,71 @@
+package util
+
+// Settings the public node profile applies where the configuration
+// leaves them at 0
+const (
+	// PublicNodeOutPeers is the outgoing peer limit of a public node
+	PublicNodeOutPeers = 32
+
+	// PublicNodeInPeers is the incoming peer limit of a public node
+	PublicNodeInPeers = 64
+
+	// PublicNodeLimitRate caps a public node's upload and download rate
+	// in kB/s, so serving the public does not saturate the link
+	PublicNodeLimitRate = 8192
+)
+
+// publicRestrictedBindIP is the address a public node's restricted RPC
+// server listens on; the authenticated port stays on MoneroBindIP
+const publicRestrictedBindIP = "0.0.0.0"
+
+// EffectiveRestrictedPort returns RestrictedPort, or with PublicNode the
+// network's default restricted port when it is 0.
+//
+// Returns:
+//   - int: Restricted RPC port, 0 when disabled
+func (c Config) EffectiveRestrictedPort() int {
+	if c.RestrictedPort == 0 && c.PublicNode {
+		return c.NetType().RestrictedPort()
+	}
+	return c.RestrictedPort
+}
+
+// PublicNodeArgs returns the monerod flags of the public node profile:
+// --public-node, advertising the restricted RPC port to peers so wallets
+// find it, and a restricted server listening on all interfaces.
+//
+// Returns:
+//   - []string: The flags, nil unless PublicNode is set
+func (c Config) PublicNodeArgs() []string {
+	if !c.PublicNode {
+		return nil
+	}
+	return []string{"--public-node", "--rpc-restricted-bind-ip", publicRestrictedBindIP}
+}
+
+// publicNodeDefault returns value, or with PublicNode the profile's
+// setting when value is 0.
+func (c Config) publicNodeDefault(value, profile int) int {
+	if value == 0 && c.PublicNode {
+		return profile
+	}
+	return value
+}
+
+// EffectiveOutPeers returns OutPeers, or PublicNodeOutPeers for a public
+// node without a limit of its own.
+func (c Config) EffectiveOutPeers() int {
+	return c.publicNodeDefault(c.OutPeers, PublicNodeOutPeers)
+}
+
+// EffectiveInPeers returns InPeers, or PublicNodeInPeers for a public
+// node without a limit of its own.
+func (c Config) EffectiveInPeers() int {
+	return c.publicNodeDefault(c.InPeers, PublicNodeInPeers)
+}
+
+// EffectiveLimitRate returns LimitRate, or PublicNodeLimitRate for a
+// public node without a limit of its own.
+func (c Config) EffectiveLimitRate() int {
+	return c.publicNodeDefault(c.LimitRate, PublicNodeLimitRate)
+}
@@ -0,0 +1,247 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/moneroger/rpc"
+)
+
+const (
+	// remoteNodeProbeTimeout bounds a single remote node probe
+	remoteNodeProbeTimeout = 5 * time.Second
+
+	// remoteNodeSelectTimeout bounds remote node selection in RecommendConfig
+	remoteNodeSelectTimeout = 10 * time.Second
+
+	// maxRemoteNodeLag is how many blocks a node may trail the highest
+	// reported height and still be considered current
+	maxRemoteNodeLag = 2
+
+	// defaultRemoteNodePort is assumed when a remote node URL has no port
+	defaultRemoteNodePort = "18081"
+)
+
+// minRemoteNodeVersion is the oldest monerod release accepted as a remote
+// node, matching the current network upgrade.
+var minRemoteNodeVersion = [2]int{0, 18}
+
+// DefaultRemoteNodes is a curated list of public mainnet nodes with their
+// restricted RPC ports, probed when no local daemon can be run.
+var DefaultRemoteNodes = []string{
+	"http://node.sethforprivacy.com:18089",
+	"http://node.moneroworld.com:18089",
+	"http://xmr-node.cakewallet.com:18081",
+	"http://nodes.hashvault.pro:18081",
+	"http://node.community.rino.io:18081",
+	"http://xmr.stormycloud.org:18089",
+}
+
+// RemoteNodeProbe is the result of probing one remote node.
+//
+// Fields:
+//   - Node: The probed node URL
+//   - Latency: Round-trip time of the get_info request
+//   - Height: Reported blockchain height
+//   - Version: Reported monerod version, such as "0.18.3.1"
+//   - Synchronized: Whether the node reports being in sync
+//   - Err: Why the node is unusable, nil for a usable node
+type RemoteNodeProbe struct {
+	Node         string
+	Latency      time.Duration
+	Height       uint64
+	Version      string
+	Synchronized bool
+	Err          error
+}
+
+// remoteNodeInfo is the subset of get_info used for node selection.
+type remoteNodeInfo struct {
+	Status       string `json:"status"`
+	Height       uint64 `json:"height"`
+	Version      string `json:"version"`
+	Synchronized bool   `json:"synchronized"`
+}
+
+// ProbeRemoteNodes queries every node's get_info concurrently.
+//
+// Parameters:
+//   - ctx: Context bounding all probes
+//   - nodes: Node URLs to probe
+//
+// Returns:
+//   - []RemoteNodeProbe: One result per node, in the order given; Err is
+//     set for unreachable, unsynchronized or outdated nodes
+func ProbeRemoteNodes(ctx context.Context, nodes []string) []RemoteNodeProbe {
+	results := make([]RemoteNodeProbe, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			results[i] = probeRemoteNode(ctx, node)
+		}(i, node)
+	}
+	wg.Wait()
+	return results
+}
+
+// probeRemoteNode measures one node's get_info latency and checks its state.
+func probeRemoteNode(ctx context.Context, node string) RemoteNodeProbe {
+	result := RemoteNodeProbe{Node: node}
+	ctx, cancel := context.WithTimeout(ctx, remoteNodeProbeTimeout)
+	defer cancel()
+
+	endpoint := node
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	client := rpc.NewClient(endpoint, "", "", rpc.Options{Timeout: remoteNodeProbeTimeout})
+	defer client.Close()
+
+	// Latency is that of the attempt that succeeded
+	var info remoteNodeInfo
+	var start time.Time
+	err := Retry(ctx, DefaultRetryPolicy(), func(ctx context.Context) error {
+		start = time.Now()
+		return client.Call(ctx, "get_info", nil, &info)
+	})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Latency = time.Since(start)
+	result.Height = info.Height
+	result.Version = info.Version
+	result.Synchronized = info.Synchronized
+
+	switch {
+	case info.Status != "OK":
+		result.Err = fmt.Errorf("node status %q", info.Status)
+	case !info.Synchronized:
+		result.Err = fmt.Errorf("node is not synchronized")
+	case !versionAtLeast(info.Version, minRemoteNodeVersion):
+		result.Err = fmt.Errorf("node version %q is older than %d.%d", info.Version, minRemoteNodeVersion[0], minRemoteNodeVersion[1])
+	}
+	return result
+}
+
+// RankRemoteNodes orders usable probe results from best to worst. Nodes
+// trailing the highest reported height by more than maxRemoteNodeLag
+// blocks are dropped; the rest are ordered by latency.
+//
+// Parameters:
+//   - probes: Results from ProbeRemoteNodes
+//
+// Returns:
+//   - []RemoteNodeProbe: Usable nodes, fastest first
+func RankRemoteNodes(probes []RemoteNodeProbe) []RemoteNodeProbe {
+	var top uint64
+	for _, p := range probes {
+		if p.Err == nil && p.Height > top {
+			top = p.Height
+		}
+	}
+	var ranked []RemoteNodeProbe
+	for _, p := range probes {
+		if p.Err == nil && p.Height+maxRemoteNodeLag >= top {
+			ranked = append(ranked, p)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Latency < ranked[j].Latency
+	})
+	return ranked
+}
+
+// SelectRemoteNodes probes nodes and returns the usable ones, best first.
+//
+// Parameters:
+//   - ctx: Context bounding the probes
+//   - nodes: Candidate node URLs, DefaultRemoteNodes when empty
+//
+// Returns:
+//   - []string: Usable node URLs, fastest current node first
+//   - error: If no node is usable
+func SelectRemoteNodes(ctx context.Context, nodes []string) ([]string, error) {
+	if len(nodes) == 0 {
+		nodes = DefaultRemoteNodes
+	}
+	ranked := RankRemoteNodes(ProbeRemoteNodes(ctx, nodes))
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("none of %d remote nodes is usable", len(nodes))
+	}
+	selected := make([]string, len(ranked))
+	for i, p := range ranked {
+		selected[i] = p.Node
+	}
+	return selected, nil
+}
+
+// ParseRemoteNode checks that a remote node URL is correctly formed and
+// returns its components. A bare "host:port" is accepted and treated as
+// http.
+//
+// Parameters:
+//   - uri: Remote node URL, such as "https://node.example:18089"
+//
+// Returns:
+//   - scheme: "http" or "https"
+//   - host: Host name or IP address, without IPv6 brackets
+//   - port: Port number, "18081" when the URL has none
+//   - err: Why the URL is unusable
+func ParseRemoteNode(uri string) (scheme, host, port string, err error) {
+	if !strings.Contains(uri, "://") {
+		uri = "http://" + uri
+	}
+	newUri, err := url.Parse(uri)
+	if err != nil {
+		return
+	}
+	scheme = strings.ToLower(newUri.Scheme)
+	if scheme != "http" && scheme != "https" {
+		err = fmt.Errorf("Remote node URLs must use http or https, not %q", newUri.Scheme)
+		return
+	}
+	host = newUri.Hostname()
+	if host == "" {
+		err = fmt.Errorf("Remote node URL has no host: %s", uri)
+		return
+	}
+	port = newUri.Port()
+	if port == "" {
+		port = defaultRemoteNodePort
+	}
+	if n, convErr := strconv.Atoi(port); convErr != nil || n < 1 || n > 65535 {
+		err = fmt.Errorf("Remote node URL has an invalid port: %s", port)
+		return
+	}
+	if len(newUri.Path) > 1 {
+		err = fmt.Errorf("Remote node URLs may not contain a path: %s", newUri.Path)
+		return
+	}
+	return
+}
+
+// versionAtLeast reports whether a dotted version string is at least min.
+func versionAtLeast(version string, min [2]int) bool {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	if major != min[0] {
+		return major > min[0]
+	}
+	return minor >= min[1]
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// RetryPolicy bounds how Retry repeats a failing operation.
+//
+// Fields:
+//   - MaxAttempts: Total attempts including the first, values below 1
+//     are treated as 1
+//   - InitialDelay: Delay before the second attempt
+//   - MaxDelay: Upper bound for the exponentially growing delay, 0 for
+//     none
+//   - Multiplier: Factor applied to the delay after each attempt,
+//     values below 1 are treated as 2
+//
+// Related:
+//   - DefaultRetryPolicy
+//   - RestartPolicy, the equivalent for restarting crashed services
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+}
+
+// DefaultRetryPolicy returns the policy used for RPC calls and remote
+// node probes: 3 attempts, waiting 250 milliseconds, then 500.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 250 * time.Millisecond,
+		MaxDelay:     2 * time.Second,
+		Multiplier:   2,
+	}
+}
+
+// RetryRPC retries an RPC call with DefaultRetryPolicy. It is the
+// rpc.Options.Retry of the clients of managed services, so a briefly
+// restarting daemon costs a short wait rather than an error.
+//
+// Parameters:
+//   - ctx: Context bounding the call and the waits between attempts
+//   - fn: One attempt of the call
+//
+// Returns:
+//   - error: As Retry
+func RetryRPC(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Retry(ctx, DefaultRetryPolicy(), fn)
+}
+
+// Delay returns how long to wait after failed attempt number attempt
+// (starting at 0).
+//
+// Parameters:
+//   - attempt: Zero-based count of failed attempts so far
+//
+// Returns:
+//   - time.Duration: InitialDelay * Multiplier^attempt, capped at MaxDelay
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	return backoff(p.InitialDelay, p.MaxDelay, p.Multiplier, attempt)
+}
+
+// backoff computes the exponential delay shared by RetryPolicy and
+// RestartPolicy: initial * multiplier^attempt, capped at limit when limit
+// is positive. A multiplier below 1 is treated as 2.
+func backoff(initial, limit time.Duration, multiplier float64, attempt int) time.Duration {
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	d := float64(initial) * math.Pow(multiplier, float64(attempt))
+	if limit > 0 && d > float64(limit) {
+		return limit
+	}
+	return time.Duration(d)
+}
+
+// Retry calls fn until it succeeds, returns an error that is not
+// retryable, or the policy's attempts are used up. Delays between
+// attempts vary by 10% so that several callers do not retry in step.
+//
+// Parameters:
+//   - ctx: Context passed to fn and bounding the waits between attempts
+//   - policy: Number of attempts and delays between them
+//   - fn: The operation; it should bound each attempt by ctx
+//
+// Returns:
+//   - error: nil once fn succeeds; otherwise the last error of fn, or
+//     ctx.Err() when cancelled while waiting, or on timeout an error
+//     wrapping context.DeadlineExceeded and describing the last failure
+//
+// Related:
+//   - errors.IsRetryable for which errors are retried
+//   - WaitReady for polling until a service is up
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !errors.IsRetryable(err) || attempt+1 >= policy.MaxAttempts {
+			return err
+		}
+		timer := time.NewTimer(Jitter(policy.Delay(attempt), 0.1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%w: %v", ctx.Err(), err)
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+		logger().Debug("retrying", "attempt", attempt+2, "error", err)
+	}
+}
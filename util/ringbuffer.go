@@ -0,0 +1,112 @@
+package util
+
+import (
+	"sync"
+)
+
+// RingBuffer is a fixed-size, concurrency-safe io.Writer that retains only
+// the most recently written bytes. It is used to keep a bounded tail of
+// child process output for error reports without growing memory for the
+// lifetime of a long-running process.
+//
+// Fields:
+//   - buf: Backing storage, allocated once at construction
+//   - pos: Index where the next byte will be written
+//   - full: Whether the buffer has wrapped at least once
+//
+// The zero value is not usable; create instances with NewRingBuffer.
+type RingBuffer struct {
+	mu   sync.Mutex
+	buf  []byte
+	pos  int
+	full bool
+}
+
+// NewRingBuffer creates a RingBuffer that keeps the last size bytes written.
+//
+// Parameters:
+//   - size: Capacity in bytes, values below 1 are raised to 1
+//
+// Returns:
+//   - *RingBuffer: An empty buffer ready for writing
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &RingBuffer{buf: make([]byte, size)}
+}
+
+// Write implements io.Writer. It never fails; once capacity is reached the
+// oldest bytes are overwritten.
+//
+// Parameters:
+//   - p: Bytes to append
+//
+// Returns:
+//   - int: Always len(p)
+//   - error: Always nil
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(p)
+	if n >= len(r.buf) {
+		// Only the tail of p can survive
+		copy(r.buf, p[n-len(r.buf):])
+		r.pos = 0
+		r.full = true
+		return n, nil
+	}
+
+	written := copy(r.buf[r.pos:], p)
+	if written < n {
+		copy(r.buf, p[written:])
+		r.full = true
+	}
+	r.pos = (r.pos + n) % len(r.buf)
+	if r.pos == 0 {
+		r.full = true
+	}
+	return n, nil
+}
+
+// Bytes returns a copy of the retained bytes in the order they were written.
+//
+// Returns:
+//   - []byte: The buffered output, oldest byte first
+func (r *RingBuffer) Bytes() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		out := make([]byte, r.pos)
+		copy(out, r.buf[:r.pos])
+		return out
+	}
+	out := make([]byte, 0, len(r.buf))
+	out = append(out, r.buf[r.pos:]...)
+	return append(out, r.buf[:r.pos]...)
+}
+
+// String returns the retained output as a string.
+func (r *RingBuffer) String() string {
+	return string(r.Bytes())
+}
+
+// Len returns the number of bytes currently retained.
+func (r *RingBuffer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return len(r.buf)
+	}
+	return r.pos
+}
+
+// Reset discards all retained output.
+func (r *RingBuffer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pos = 0
+	r.full = false
+}
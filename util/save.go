@@ -0,0 +1,292 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configComments documents each setting in files written by SaveConfig,
+// keyed by field name, with nested fields as "Section.Field".
+var configComments = map[string]string{
+	"DataDir":                 "Base directory for blockchain data and wallet files; testnet and stagenet use its testnet and stagenet subdirectories",
+	"WalletDir":               "Directory holding the wallet files; empty for the network's data directory",
+	"WalletName":              "Wallet in WalletDir opened at startup with the managed wallet password; empty opens none",
+	"WalletFile":              "Deprecated name of WalletDir, read when WalletDir is empty",
+	"MoneroPort":              "monerod RPC port; 0 for the network's default, 18081, 28081 or 38081",
+	"WalletPort":              "monero-wallet-rpc RPC port; 0 for the network's default, 18083, 28083 or 38083",
+	"MoneroBindIP":            "IPv4 address the monerod RPC binds to; empty for loopback only",
+	"WalletBindIP":            "IPv4 address the wallet RPC binds to; empty for loopback only",
+	"AutoPort":                "Use a free port when MoneroPort or WalletPort is taken by another program",
+	"AutoPortRange":           "Ports AutoPort chooses from; Min and Max 0 for any free port",
+	"TestNet":                 "Run on testnet instead of mainnet; superseded by Network",
+	"Network":                 "mainnet, testnet or stagenet; empty follows TestNet",
+	"RemoteNode":              "Remote daemon used instead of a local monerod; empty runs a local node",
+	"RemoteNodes":             "Fallback remote daemons, tried in order when RemoteNode fails",
+	"ZMQPubPort":              "monerod ZMQ publisher port for block and tx notifications; 0 disables it",
+	"RestrictedPort":          "monerod RPC port serving restricted calls without a login, for a public node; 0 disables it",
+	"PublicNode":              "share the node: advertise restricted RPC to peers on all interfaces, with peer and rate limits suited to serving the public",
+	"DaemonLogLevel":          "monerod log level, 0 to 4; changed without a restart on reload",
+	"DaemonLogFile":           "monerod log file; empty for logs/bitmonero.log in the network's data directory",
+	"DaemonMaxLogFileSize":    "Bytes at which monerod rotates its log file; 0 for monerod's default",
+	"OutPeers":                "monerod outgoing peer limit; 0 for the default; changed without a restart on reload",
+	"InPeers":                 "monerod incoming peer limit; 0 for the default; changed without a restart on reload",
+	"LimitRate":               "monerod upload and download limit in kB/s; 0 for the default",
+	"LimitRateUp":             "monerod upload limit in kB/s, overriding LimitRate; 0 for none",
+	"LimitRateDown":           "monerod download limit in kB/s, overriding LimitRate; 0 for none",
+	"DBSyncMode":              "monerod database sync mode, such as safe or fast:async:250000000bytes; empty for the default",
+	"MaxConcurrency":          "monerod worker threads; 0 for one per CPU",
+	"BlockSyncSize":           "Blocks monerod requests per batch while syncing; 0 for the default",
+	"NoIGD":                   "Disable monerod's UPnP port mapping on the router",
+	"Offline":                 "Keep monerod from connecting to peers",
+	"Prune":                   "Keep a pruned blockchain, about a third of the full size; existing data is pruned on start",
+	"BanList":                 "File of IP addresses and subnets, one per line, banned when monerod starts",
+	"MoneroRPCUser":           "monerod RPC username",
+	"MoneroRPCPass":           "monerod RPC password; generated once and kept in DataDir when empty",
+	"WalletRPCUser":           "Wallet RPC username",
+	"WalletRPCPass":           "Wallet RPC password; generated at startup when empty",
+	"Keyring":                 "Keep generated RPC passwords and the wallet password in the OS keyring",
+	"LogProcessOutput":        "Log monerod and wallet output at debug level",
+	"LogBufferSize":           "Bytes of recent process output kept for error reports; 0 for the default",
+	"Restart":                 "Automatic restarts of crashed services",
+	"HealthInterval":          "How often running services are checked over RPC; 0 for the default, negative disables",
+	"AutosaveInterval":        "How often open wallets are saved to their files; 0 for the default, negative disables",
+	"PaymentInterval":         "How often open wallets are checked for incoming payments; 0 for the default, negative disables",
+	"StartupTimeout":          "How long each service may take to become ready; raise it for slow disks",
+	"ShutdownTimeout":         "How long each service may take to exit once interrupted",
+	"StatusAddress":           "host:port serving /healthz, /readyz and /status over HTTP, such as 127.0.0.1:18090; empty disables; non-loopback addresses need StatusToken or StatusTLS",
+	"StatusToken":             "Bearer token status requests must send, at least 16 characters; empty for none",
+	"StatusTLS":               "Serve the status over https",
+	"StatusTLS.CertFile":      "PEM certificate; empty disables TLS",
+	"StatusTLS.KeyFile":       "PEM private key",
+	"StatusTLS.CAFile":        "CA bundle clients use to verify the certificate",
+	"GRPC":                    "gRPC management API; set Address and Token to enable",
+	"GRPC.Address":            "host:port of the API, such as 127.0.0.1:18095",
+	"GRPC.Token":              "Bearer token clients must send, at least 16 characters",
+	"GRPC.TLS":                "Certificate served by the API; required unless Address is loopback",
+	"GRPC.TLS.CertFile":       "PEM certificate; empty disables TLS",
+	"GRPC.TLS.KeyFile":        "PEM private key",
+	"GRPC.TLS.CAFile":         "CA bundle clients use to verify the certificate",
+	"MonerodExtraArgs":        "Flags appended to the monerod command line, such as [\"--db-sync-mode\", \"safe\"]",
+	"WalletRPCExtraArgs":      "Flags appended to the monero-wallet-rpc command line",
+	"Docker":                  "Run the services in containers; set Image to enable",
+	"Docker.Image":            "Image providing monerod and monero-wallet-rpc on its PATH",
+	"Docker.WalletImage":      "Image for monero-wallet-rpc; empty uses Image",
+	"Docker.Binary":           "Docker compatible CLI, such as podman; empty for docker",
+	"Docker.Network":          "Network joined by the containers; empty for host, others must be user-defined",
+	"Detach":                  "Start services detached, logging to files in DataDir, so they outlive the manager",
+	"Restart.MaxRetries":      "Consecutive restarts before giving up; 0 disables, negative retries forever",
+	"Restart.MinUptime":       "Uptime after which a process counts as stable",
+	"Restart.InitialDelay":    "Delay before the first restart",
+	"Restart.MaxDelay":        "Upper bound for the growing restart delay",
+	"Restart.Multiplier":      "Factor applied to the delay after each restart",
+	"Tor":                     "Route node traffic over Tor; set Proxy to enable",
+	"Tor.Proxy":               "Tor SOCKS proxy, such as 127.0.0.1:9050",
+	"Tor.OnionAddress":        "Onion address announced for anonymous inbound peers",
+	"Tor.InboundPort":         "Local port the onion service forwards to; 0 for the default",
+	"Tor.MaxConnections":      "Connection limit over Tor; 0 for the default",
+	"I2P":                     "Broadcast transactions over I2P; set Proxy to enable",
+	"I2P.Proxy":               "I2P router SOCKS proxy, such as 127.0.0.1:4447",
+	"I2P.Address":             "b32.i2p address announced for anonymous inbound peers",
+	"I2P.InboundPort":         "Local port the I2P tunnel forwards to; 0 for the default",
+	"I2P.MaxConnections":      "Connection limit over I2P; 0 for the default",
+	"Bootstrap":               "Node answering wallet queries while the local daemon syncs",
+	"Bootstrap.Address":       "Bootstrap node as host:port, or auto",
+	"Bootstrap.Login":         "Bootstrap node credentials as user:pass",
+	"DiskWatch":               "Free space monitoring under DataDir; set WarnGB or FallbackGB to enable",
+	"DiskWatch.Interval":      "How often free space is checked; 0 for one minute",
+	"DiskWatch.WarnGB":        "Free space thresholds in GB warned about, such as [50, 20]",
+	"DiskWatch.FallbackGB":    "Free space in GB below which monerod is stopped and wallets use a remote node; 0 disables",
+	"DiskWatch.FallbackNodes": "Remote nodes used by the fallback; empty picks public mainnet nodes",
+	"HWDevice":                "Hardware wallet holding the wallet keys; empty for software wallets",
+	"HWDevice.Name":           "Ledger or Trezor",
+	"HWDevice.DerivationPath": "Derivation path on the device, such as m/44'/128'/1'; empty for the default",
+	"Versions":                "Accepted releases of monerod and monero-wallet-rpc",
+	"Versions.Minimum":        "Oldest acceptable release, such as 0.18.3.1; empty accepts any",
+	"Versions.AllowMismatch":  "Accept monerod and monero-wallet-rpc from different releases",
+	"Versions.WarnOnly":       "Log version problems instead of refusing to start",
+	"DaemonTLS":               "Serve the monerod RPC over https",
+	"DaemonTLS.CertFile":      "PEM certificate; empty disables TLS",
+	"DaemonTLS.KeyFile":       "PEM private key",
+	"DaemonTLS.CAFile":        "CA bundle used to verify the certificate; empty trusts CertFile",
+	"WalletTLS":               "Serve the wallet RPC over https",
+	"WalletTLS.CertFile":      "PEM certificate; empty disables TLS",
+	"WalletTLS.KeyFile":       "PEM private key",
+	"WalletTLS.CAFile":        "CA bundle used to verify the certificate; empty trusts CertFile",
+}
+
+// configEntry is one setting or section of a saved configuration.
+type configEntry struct {
+	key     string
+	value   interface{}
+	entries []configEntry
+}
+
+// SaveConfig writes config to path in the format chosen by its extension,
+// documenting every setting with a comment in YAML and TOML files. The
+// result can be read back with LoadConfig.
+//
+// Parameters:
+//   - path: Destination file; .yaml, .yml, .toml or .json
+//   - config: Configuration to write; Logger and output writers are skipped
+//
+// Returns:
+//   - error: For unsupported extensions or write failures
+//
+// The file is created with mode 0600, since it may hold RPC passwords.
+//
+// Related:
+//   - LoadConfig
+//   - RecommendConfig for a starting configuration
+func SaveConfig(path string, config Config) error {
+	format, err := configFormat(path)
+	if err != nil {
+		return err
+	}
+	entries := configEntries(reflect.ValueOf(config))
+
+	var buf bytes.Buffer
+	switch format {
+	case "yaml":
+		writeYAML(&buf, entries, "", "")
+	case "toml":
+		writeTOML(&buf, entries)
+	case "json":
+		data, err := json.MarshalIndent(configMap(entries), "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o600)
+}
+
+// configEntries lists the fields of a config struct in declaration order,
+// skipping fields tagged mapstructure:"-".
+func configEntries(v reflect.Value) []configEntry {
+	var entries []configEntry
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			entries = append(entries, configEntry{key: field.Name, entries: configEntries(fv)})
+			continue
+		}
+		value := fv.Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		} else if fv.Kind() == reflect.String {
+			// Named string types such as Network are written as strings
+			value = fv.String()
+		}
+		entries = append(entries, configEntry{key: field.Name, value: value})
+	}
+	return entries
+}
+
+// configMap converts entries to nested maps for JSON encoding.
+func configMap(entries []configEntry) map[string]interface{} {
+	m := make(map[string]interface{}, len(entries))
+	for _, e := range entries {
+		if e.entries != nil {
+			m[e.key] = configMap(e.entries)
+		} else {
+			m[e.key] = e.value
+		}
+	}
+	return m
+}
+
+// writeYAML writes entries as a YAML mapping with a comment above each key.
+func writeYAML(buf *bytes.Buffer, entries []configEntry, prefix, indent string) {
+	for _, e := range entries {
+		writeComment(buf, indent, configComments[prefix+e.key])
+		if e.entries != nil {
+			fmt.Fprintf(buf, "%s%s:\n", indent, e.key)
+			writeYAML(buf, e.entries, prefix+e.key+".", indent+"  ")
+		} else {
+			fmt.Fprintf(buf, "%s%s: %s\n", indent, e.key, formatValue(e.value))
+		}
+		if indent == "" {
+			buf.WriteByte('\n')
+		}
+	}
+}
+
+// writeTOML writes entries as TOML, top-level settings first and each
+// section as a table, with nested sections as dotted tables after it.
+func writeTOML(buf *bytes.Buffer, entries []configEntry) {
+	for _, e := range entries {
+		if e.entries == nil {
+			writeComment(buf, "", configComments[e.key])
+			fmt.Fprintf(buf, "%s = %s\n\n", e.key, formatValue(e.value))
+		}
+	}
+	writeTOMLTables(buf, entries, "")
+}
+
+// writeTOMLTables writes the sections among entries as tables named
+// prefix plus the section key.
+func writeTOMLTables(buf *bytes.Buffer, entries []configEntry, prefix string) {
+	for _, e := range entries {
+		if e.entries == nil {
+			continue
+		}
+		table := prefix + e.key
+		writeComment(buf, "", configComments[table])
+		fmt.Fprintf(buf, "[%s]\n", table)
+		for _, sub := range e.entries {
+			if sub.entries == nil {
+				writeComment(buf, "", configComments[table+"."+sub.key])
+				fmt.Fprintf(buf, "%s = %s\n", sub.key, formatValue(sub.value))
+			}
+		}
+		buf.WriteByte('\n')
+		writeTOMLTables(buf, e.entries, table+".")
+	}
+}
+
+// writeComment writes text as a "#" comment line, if it is not empty.
+func writeComment(buf *bytes.Buffer, indent, text string) {
+	if text != "" {
+		fmt.Fprintf(buf, "%s# %s\n", indent, text)
+	}
+}
+
+// formatValue renders a scalar, string list or integer list in syntax
+// shared by YAML and TOML. Strings use JSON quoting, which both formats accept.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case []int:
+		values := make([]string, len(v))
+		for i, n := range v {
+			values[i] = strconv.Itoa(n)
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quote returns s as a double-quoted string.
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
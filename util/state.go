@@ -0,0 +1,70 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransition is wrapped by CheckTransition for state changes the
+// lifecycle does not allow.
+var ErrInvalidTransition = errors.New("invalid state transition")
+
+// ServiceState is the lifecycle state of a managed process.
+type ServiceState uint8
+
+// Service states, in lifecycle order.
+const (
+	StateUnknown  ServiceState = iota // Never started
+	StateStarting                     // Process spawned, not yet serving
+	StateRunning                      // Serving requests
+	StateStopping                     // Shutdown requested, process exiting
+	StateStopped                      // Exited after Shutdown or a failed start
+	StateCrashed                      // Exited without Shutdown being requested
+)
+
+// String returns the lowercase name of the state, "unknown" for values
+// outside the lifecycle.
+func (s ServiceState) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateCrashed:
+		return "crashed"
+	default:
+		return "unknown"
+	}
+}
+
+// transitions lists the states each state may change to.
+var transitions = map[ServiceState][]ServiceState{
+	StateUnknown:  {StateStarting},
+	StateStarting: {StateRunning, StateStopping, StateStopped, StateCrashed},
+	StateRunning:  {StateStopping, StateCrashed},
+	StateStopping: {StateStopped},
+	StateStopped:  {StateStarting},
+	StateCrashed:  {StateStarting},
+}
+
+// CheckTransition validates a state change.
+//
+// Parameters:
+//   - from: Current state
+//   - to: Requested state
+//
+// Returns:
+//   - error: Wrapping ErrInvalidTransition if the lifecycle does not
+//     allow the change, such as starting a running process
+func CheckTransition(from, to ServiceState) error {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, to)
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notification states understood by systemd, see sd_notify(3)
+const (
+	// NotifyReady tells systemd startup is complete
+	NotifyReady = "READY=1"
+
+	// NotifyReloading tells systemd the configuration is being reloaded;
+	// NotifyReady follows once it is applied
+	NotifyReloading = "RELOADING=1"
+
+	// NotifyStopping tells systemd shutdown has begun
+	NotifyStopping = "STOPPING=1"
+
+	// NotifyWatchdog keeps the WatchdogSec timer from expiring
+	NotifyWatchdog = "WATCHDOG=1"
+)
+
+// Notify sends a state change to systemd over the socket named by the
+// NOTIFY_SOCKET environment variable, as sd_notify(3) does. Several
+// states can be sent at once separated by newlines, for example
+// NotifyReady + "\nSTATUS=running".
+//
+// Parameters:
+//   - state: Notification, such as NotifyReady
+//
+// Returns:
+//   - bool: Whether the notification was sent; false when not running
+//     under systemd with Type=notify
+//   - error: Socket failures
+func Notify(state string) (bool, error) {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if name == "" {
+		return false, nil
+	}
+	// A leading @ names a socket in the abstract namespace
+	if strings.HasPrefix(name, "@") {
+		name = "\x00" + name[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often NotifyWatchdog must be sent to
+// satisfy WatchdogSec: half the timeout systemd passes in WATCHDOG_USEC,
+// as sd_watchdog_enabled(3) recommends.
+//
+// Returns:
+//   - time.Duration: Ping interval, 0 if the watchdog is disabled or
+//     meant for another process
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
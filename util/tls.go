@@ -0,0 +1,119 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// RPCTLS enables TLS on an RPC server. When enabled, the manager's own
+// clients connect over https and verify the server against CAFile, or
+// against CertFile itself for self-signed certificates.
+//
+// Fields:
+//   - CertFile: PEM certificate served by the RPC server; empty disables TLS
+//   - KeyFile: PEM private key for CertFile
+//   - CAFile: Optional PEM bundle used to verify the certificate
+type RPCTLS struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Enabled reports whether TLS is configured.
+func (t RPCTLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Scheme returns "https" when TLS is enabled and "http" otherwise.
+func (t RPCTLS) Scheme() string {
+	if t.Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// Validate checks that the certificate, key and CA files exist.
+//
+// Returns:
+//   - error: Description of the first problem, nil if valid
+func (t RPCTLS) Validate() error {
+	if !t.Enabled() {
+		if t.KeyFile != "" || t.CAFile != "" {
+			return fmt.Errorf("tls key or CA file given without a certificate")
+		}
+		return nil
+	}
+	if t.KeyFile == "" {
+		return fmt.Errorf("tls certificate %s has no private key", t.CertFile)
+	}
+	for _, f := range []string{t.CertFile, t.KeyFile, t.CAFile} {
+		if f != "" && !FileExists(f) {
+			return fmt.Errorf("tls file does not exist: %s", f)
+		}
+	}
+	return nil
+}
+
+// ServerArgs returns the --rpc-ssl flags shared by monerod and
+// monero-wallet-rpc.
+//
+// Returns:
+//   - []string: Command line arguments, nil when TLS is disabled
+func (t RPCTLS) ServerArgs() []string {
+	if !t.Enabled() {
+		return nil
+	}
+	return []string{
+		"--rpc-ssl", "enabled",
+		"--rpc-ssl-private-key", t.KeyFile,
+		"--rpc-ssl-certificate", t.CertFile,
+	}
+}
+
+// VerifyFile returns the PEM file clients should trust: CAFile when set,
+// otherwise the server certificate itself.
+func (t RPCTLS) VerifyFile() string {
+	if t.CAFile != "" {
+		return t.CAFile
+	}
+	return t.CertFile
+}
+
+// ClientConfig builds the TLS configuration for clients of the server.
+//
+// Returns:
+//   - *tls.Config: Configuration trusting VerifyFile, nil when TLS is disabled
+//   - error: If the trusted certificates cannot be read or parsed
+func (t RPCTLS) ClientConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+	pem, err := os.ReadFile(t.VerifyFile())
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", t.VerifyFile())
+	}
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// ServerConfig builds the TLS configuration for servers run by the
+// manager itself, such as the gRPC management API.
+//
+// Returns:
+//   - *tls.Config: Configuration serving CertFile, nil when TLS is disabled
+//   - error: If the certificate or key cannot be loaded
+func (t RPCTLS) ServerConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
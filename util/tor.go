@@ -0,0 +1,131 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultTorInboundPort is the local port an onion service forwards to
+	DefaultTorInboundPort = 18084
+
+	// DefaultTorMaxConnections limits connections over the anonymity network
+	DefaultTorMaxConnections = 16
+)
+
+// TorConfig routes node traffic through a Tor SOCKS proxy. Setting Proxy
+// alone is enough to send all outgoing daemon and wallet traffic over Tor;
+// the remaining fields add an onion service for anonymous inbound peers.
+//
+// Fields:
+//   - Proxy: SOCKS5 proxy address, such as "127.0.0.1:9050"; empty disables Tor
+//   - OnionAddress: Hidden service address announced to peers, such as
+//     "abc...xyz.onion:18084"; empty disables anonymous inbound
+//   - InboundPort: Local port the hidden service forwards to,
+//     DefaultTorInboundPort when 0
+//   - MaxConnections: Connection limit for the anonymity network,
+//     DefaultTorMaxConnections when 0
+type TorConfig struct {
+	Proxy          string
+	OnionAddress   string
+	InboundPort    int
+	MaxConnections int
+}
+
+// Enabled reports whether traffic should be routed over Tor.
+func (t TorConfig) Enabled() bool {
+	return t.Proxy != ""
+}
+
+// Validate checks that the proxy and onion addresses are well formed.
+//
+// Returns:
+//   - error: Description of the first invalid field, nil if valid
+func (t TorConfig) Validate() error {
+	if !t.Enabled() {
+		if t.OnionAddress != "" {
+			return fmt.Errorf("tor onion address requires a tor proxy")
+		}
+		return nil
+	}
+	if err := validateHostPort(t.Proxy); err != nil {
+		return fmt.Errorf("invalid tor proxy %q: %w", t.Proxy, err)
+	}
+	if t.OnionAddress != "" {
+		if err := validateHostPort(t.OnionAddress); err != nil {
+			return fmt.Errorf("invalid onion address %q: %w", t.OnionAddress, err)
+		}
+		host, _, _ := net.SplitHostPort(t.OnionAddress)
+		if !strings.HasSuffix(host, ".onion") {
+			return fmt.Errorf("invalid onion address %q: host must end in .onion", t.OnionAddress)
+		}
+	}
+	if t.InboundPort < 0 || t.InboundPort > 65535 {
+		return fmt.Errorf("invalid tor inbound port: %d", t.InboundPort)
+	}
+	if t.MaxConnections < 0 {
+		return fmt.Errorf("invalid tor connection limit: %d", t.MaxConnections)
+	}
+	return nil
+}
+
+// DaemonArgs returns the monerod flags implementing this configuration:
+// --proxy for all outgoing peer connections, --tx-proxy to broadcast
+// transactions over Tor, DNS checkpoint lookups disabled to avoid leaks,
+// and --anonymous-inbound when an onion address is set.
+//
+// Returns:
+//   - []string: Command line arguments, nil when Tor is disabled
+func (t TorConfig) DaemonArgs() []string {
+	if !t.Enabled() {
+		return nil
+	}
+	maxConns := t.MaxConnections
+	if maxConns == 0 {
+		maxConns = DefaultTorMaxConnections
+	}
+	args := []string{
+		"--proxy", t.Proxy,
+		"--tx-proxy", fmt.Sprintf("tor,%s,%d", t.Proxy, maxConns),
+		"--disable-dns-checkpoints",
+		"--no-igd",
+	}
+	if t.OnionAddress != "" {
+		port := t.InboundPort
+		if port == 0 {
+			port = DefaultTorInboundPort
+		}
+		args = append(args, "--anonymous-inbound",
+			fmt.Sprintf("%s,127.0.0.1:%d,%d", t.OnionAddress, port, maxConns))
+	}
+	return args
+}
+
+// WalletArgs returns the monero-wallet-rpc flags implementing this
+// configuration, routing the wallet's daemon connection through the proxy.
+//
+// Returns:
+//   - []string: Command line arguments, nil when Tor is disabled
+func (t TorConfig) WalletArgs() []string {
+	if !t.Enabled() {
+		return nil
+	}
+	return []string{"--proxy", t.Proxy}
+}
+
+// validateHostPort checks for a "host:port" address with a valid port.
+func validateHostPort(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return fmt.Errorf("missing host")
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
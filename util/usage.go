@@ -0,0 +1,138 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// usageRetention is how long UsageMeter remembers a process it no longer
+// measures, such as one replaced by a restart
+const usageRetention = 10 * time.Minute
+
+// ProcessUsage is a snapshot of the resources a process uses, suitable
+// for JSON serialization. Counters a platform does not report are zero.
+//
+// Fields:
+//   - CPUPercent: CPU use in percent of one core, so a process busy on
+//     two cores reports 200. ReadProcessUsage averages over the lifetime
+//     of the process, UsageMeter over the time since its last measurement
+//   - CPUTime: User plus system CPU time consumed so far
+//   - RSS: Resident memory in bytes
+//   - OpenFiles: Open file descriptors, open handles on Windows; not
+//     reported on other platforms
+//   - ReadBytes, WriteBytes: Bytes read from and written to storage, all
+//     I/O on Windows; not reported on other platforms
+type ProcessUsage struct {
+	CPUPercent float64       `json:"cpu_percent"`
+	CPUTime    time.Duration `json:"cpu_time_ns"`
+	RSS        uint64        `json:"rss_bytes"`
+	OpenFiles  int           `json:"open_files,omitempty"`
+	ReadBytes  uint64        `json:"read_bytes,omitempty"`
+	WriteBytes uint64        `json:"write_bytes,omitempty"`
+}
+
+// ReadProcessUsage reports the resources a process uses. Linux reads
+// /proc, Windows queries the process, and other systems run ps.
+//
+// Parameters:
+//   - pid: Process ID
+//
+// Returns:
+//   - ProcessUsage: The snapshot, with CPU use averaged over the lifetime
+//     of the process
+//   - error: If the process does not exist or cannot be inspected
+//
+// Related:
+//   - UsageMeter for CPU use over shorter periods
+func ReadProcessUsage(pid int) (ProcessUsage, error) {
+	usage, started, err := readUsage(pid)
+	if err != nil {
+		return ProcessUsage{}, err
+	}
+	usage.CPUPercent = cpuPercent(usage.CPUTime, time.Since(started))
+	return usage, nil
+}
+
+// cpuPercent converts CPU time used over a period of wall time into a
+// percentage of one core.
+func cpuPercent(cpu, wall time.Duration) float64 {
+	if wall <= 0 || cpu < 0 {
+		return 0
+	}
+	return 100 * cpu.Seconds() / wall.Seconds()
+}
+
+// UsageMeter measures processes repeatedly, reporting CPU use over the
+// time since the previous measurement of the same process, as top does.
+// The zero value is ready to use and safe for concurrent use.
+type UsageMeter struct {
+	mu   sync.Mutex
+	last map[int]usageSample
+}
+
+// usageSample is the CPU time of a process when it was last measured.
+type usageSample struct {
+	cpu time.Duration
+	at  time.Time
+}
+
+// Measure reports the resources a process uses.
+//
+// Parameters:
+//   - pid: Process ID
+//
+// Returns:
+//   - ProcessUsage: The snapshot, with CPU use since the previous
+//     measurement, or over the lifetime of the process the first time
+//   - error: If the process does not exist or cannot be inspected
+func (u *UsageMeter) Measure(pid int) (ProcessUsage, error) {
+	usage, err := ReadProcessUsage(pid)
+	if err != nil {
+		return usage, err
+	}
+	now := time.Now()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if prev, ok := u.last[pid]; ok && usage.CPUTime >= prev.cpu {
+		usage.CPUPercent = cpuPercent(usage.CPUTime-prev.cpu, now.Sub(prev.at))
+	}
+	if u.last == nil {
+		u.last = make(map[int]usageSample)
+	}
+	for p, sample := range u.last {
+		if now.Sub(sample.at) > usageRetention {
+			delete(u.last, p)
+		}
+	}
+	u.last[pid] = usageSample{cpu: usage.CPUTime, at: now}
+	return usage, nil
+}
+
+// parsePSDuration parses the CPU and elapsed times printed by ps, such as
+// "1-02:03:04", "02:03:04", "03:04" or "3:04.56".
+func parsePSDuration(s string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(s, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days, s = n, rest
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var seconds float64
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds*float64(time.Second)), nil
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the unit of the CPU and start times in /proc, USER_HZ,
+// which is 100 on every Linux architecture
+const clockTicks = 100
+
+// readUsage reads the resources a process uses from /proc, along with
+// when it started. Open files and I/O are only readable for processes of
+// the same user and are left zero otherwise.
+func readUsage(pid int) (ProcessUsage, time.Time, error) {
+	dir := "/proc/" + strconv.Itoa(pid)
+	data, err := os.ReadFile(dir + "/stat")
+	if err != nil {
+		return ProcessUsage{}, time.Time{}, err
+	}
+	// The command name may contain spaces and parentheses, the fields
+	// follow the last ')' starting with the state, field 3 of proc(5)
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("%s/stat: malformed", dir)
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("%s/stat: malformed", dir)
+	}
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(fields[n-3], 10, 64)
+		return v
+	}
+	var usage ProcessUsage
+	usage.CPUTime = time.Duration(field(14)+field(15)) * time.Second / clockTicks
+	usage.RSS = field(24) * uint64(os.Getpagesize())
+
+	var started time.Time
+	if boot, err := bootTime(); err == nil {
+		started = boot.Add(time.Duration(field(22)) * time.Second / clockTicks)
+	}
+	if fds, err := os.ReadDir(dir + "/fd"); err == nil {
+		usage.OpenFiles = len(fds)
+	}
+	if f, err := os.Open(dir + "/io"); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, _ := strings.Cut(scanner.Text(), ": ")
+			n, _ := strconv.ParseUint(value, 10, 64)
+			switch key {
+			case "read_bytes":
+				usage.ReadBytes = n
+			case "write_bytes":
+				usage.WriteBytes = n
+			}
+		}
+	}
+	return usage, started, nil
+}
+
+// bootTime returns when the system booted, from btime in /proc/stat.
+func bootTime() (time.Time, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			secs, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("/proc/stat: malformed btime")
+			}
+			return time.Unix(secs, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("/proc/stat: no btime")
+}
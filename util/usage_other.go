@@ -0,0 +1,39 @@
+//go:build !linux && !windows
+
+package util
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// readUsage reads the CPU time and resident memory of a process from ps,
+// along with when it started. Open files and I/O are not reported.
+func readUsage(pid int) (ProcessUsage, time.Time, error) {
+	out, err := exec.Command("ps", "-o", "rss=,time=,etime=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("ps: %w", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 3 {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("process %d not found", pid)
+	}
+	rss, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("ps: invalid rss %q", fields[0])
+	}
+	cpu, err := parsePSDuration(fields[1])
+	if err != nil {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("ps: %w", err)
+	}
+	elapsed, err := parsePSDuration(fields[2])
+	if err != nil {
+		return ProcessUsage{}, time.Time{}, fmt.Errorf("ps: %w", err)
+	}
+	// ps reports kilobytes
+	usage := ProcessUsage{CPUTime: cpu, RSS: rss * 1024}
+	return usage, time.Now().Add(-elapsed), nil
+}
@@ -6,16 +6,24 @@ package util
 import (
 	"context"
 	"fmt"
-	"log"
-	"math/rand"
 	"net"
 	"os"
 	"path/filepath"
-	"strings"
+	"runtime"
+	"strconv"
 	"time"
 
 	moneroconst "github.com/opd-ai/moneroger/const"
-	"github.com/sethvargo/go-password/password"
+)
+
+// Readiness polling used by WaitReady and WaitForAddr. The delay
+// between attempts starts at PortPollInterval and doubles up to
+// PortPollMaxInterval, so quick startups are noticed within
+// milliseconds without probing slow ones constantly. Tests may lower
+// both before starting services.
+var (
+	PortPollInterval    = 50 * time.Millisecond
+	PortPollMaxInterval = time.Second
 )
 
 // FileExists checks if a file exists at the specified path.
@@ -50,17 +58,52 @@ func DirExists(path string) bool {
 	return false
 }
 
+// BinDir returns the directory holding Monero executables installed by
+// moneroger, such as those downloaded by the fetch package:
+// $MONEROGER_BIN_DIR when set, otherwise a "moneroger/bin" directory in
+// the user's data directory (%LOCALAPPDATA% on Windows,
+// ~/Library/Application Support on macOS, $XDG_DATA_HOME or
+// ~/.local/share elsewhere).
+//
+// Returns:
+//   - string: The directory, which may not exist yet; empty if the
+//     user's home directory is unknown
+func BinDir() string {
+	if dir := os.Getenv(EnvPrefix + "_BIN_DIR"); dir != "" {
+		return dir
+	}
+	var base string
+	switch runtime.GOOS {
+	case "windows":
+		base = os.Getenv("LOCALAPPDATA")
+	case "darwin":
+		base, _ = os.UserConfigDir()
+	default:
+		base = os.Getenv("XDG_DATA_HOME")
+		if base == "" {
+			if home, err := os.UserHomeDir(); err == nil {
+				base = filepath.Join(home, ".local", "share")
+			}
+		}
+	}
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, "moneroger", "bin")
+}
+
 // Path returns a slice of directories to search for executables.
 // It combines:
 // - The directory containing the current executable
 // - The current working directory
+// - BinDir, where moneroger installs downloaded releases
 // - The system PATH environment variable
 //
 // Returns:
 //   - []string: Slice of directory paths to search
 //
-// Note: Logs errors but continues execution if unable to determine
-// executable or working directory paths.
+// Note: Logs errors via the package logger (see SetLogger) but continues
+// execution if unable to determine executable or working directory paths.
 func Path() []string {
 	path := os.Getenv("PATH")
 	elements := []string{}
@@ -68,7 +111,7 @@ func Path() []string {
 	// Get executable directory
 	me, err := os.Executable()
 	if err != nil {
-		log.Println("Failed to get executable path:", err)
+		logger().Warn("failed to get executable path", "error", err)
 	} else {
 		meDir := filepath.Dir(me)
 		elements = append(elements, meDir)
@@ -77,58 +120,105 @@ func Path() []string {
 	// Get working directory
 	workDir, err := os.Getwd()
 	if err != nil {
-		log.Println("Failed to get working directory:", err)
+		logger().Warn("failed to get working directory", "error", err)
 	} else {
 		elements = append(elements, workDir)
 	}
 
+	if binDir := BinDir(); binDir != "" {
+		elements = append(elements, binDir)
+	}
+
 	// Add system PATH elements
 	if path != "" {
-		elements = append(elements, strings.Split(path, ":")...)
+		elements = append(elements, filepath.SplitList(path)...)
 	}
 
 	return elements
 }
 
-// SecurePassword generates a cryptographically secure random password.
+// IsPortInUse checks if a TCP port is currently in use on localhost.
+//
+// Parameters:
+//   - port: Port number to check (int)
 //
 // Returns:
-//   - string: A 20-character password with random number of digits (0-19)
+//   - bool: true if the port is in use, false otherwise
 //
-// Panics:
-//   - If the password generation fails (should be extremely rare)
+// Note: This function attempts a TCP connection with a 1-second timeout.
+// A successful connection indicates the port is in use.
+func IsPortInUse(port int) bool {
+	return IsAddrInUse("localhost", port)
+}
+
+// IsAddrInUse checks if a TCP port is accepting connections on host.
+//
+// Parameters:
+//   - host: Host to probe, typically DialHost of the bind address
+//   - port: Port number to check
+//
+// Returns:
+//   - bool: true if a connection succeeds within one second
 //
-// Uses:
-//   - github.com/sethvargo/go-password/password for generation
-func SecurePassword() string {
-	rand.Seed(time.Now().UnixNano())
-	digs := rand.Intn(19)
-	res, err := password.Generate(20, digs, 0, false, true)
+// Related:
+//   - IsPortInUse for the localhost case
+func IsAddrInUse(host string, port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), time.Second)
 	if err != nil {
-		panic(err)
+		return false
 	}
-	return res
+	conn.Close()
+	return true
 }
 
-// IsPortInUse checks if a TCP port is currently in use on localhost.
+// IsPortAvailable checks whether a server could listen on a TCP port by
+// briefly binding it, as opposed to IsAddrInUse, which reports whether
+// something answers there. Binding also notices ports held on another
+// address only, and ignores connections lingering in TIME_WAIT.
 //
 // Parameters:
-//   - port: Port number to check (int)
+//   - addr: IPv4 address to bind, empty for the loopback address
+//     monerod and monero-wallet-rpc bind by default
+//   - port: Port number to check
 //
 // Returns:
-//   - bool: true if the port is in use, false otherwise
+//   - bool: true if the port could be bound
 //
-// Note: This function attempts a TCP connection with a 1-second timeout.
-// A successful connection indicates the port is in use.
-func IsPortInUse(port int) bool {
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("localhost:%d", port), time.Second)
+// Related:
+//   - IsAddrInUse to check whether a server is up
+func IsPortAvailable(addr string, port int) bool {
+	if addr == "" {
+		addr = "127.0.0.1"
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
 	if err != nil {
 		return false
 	}
-	conn.Close()
+	ln.Close()
 	return true
 }
 
+// GetFreePort asks the operating system for a TCP port that is currently
+// free on localhost.
+//
+// Returns:
+//   - int: A free port number
+//   - error: If no port could be allocated
+//
+// The port is only guaranteed free at the time of the call; another
+// process may still claim it before it is bound. Callers in this
+// process are not given the same port again for a while.
+//
+// Related:
+//   - GetFreePorts and ReservePorts for several ports or a port range
+func GetFreePort() (int, error) {
+	ports, err := GetFreePorts("", 1, PortRange{})
+	if err != nil {
+		return 0, err
+	}
+	return ports[0], nil
+}
+
 // WaitForPort waits for a TCP port to become available.
 //
 // Parameters:
@@ -140,23 +230,81 @@ func IsPortInUse(port int) bool {
 //
 // Errors:
 //   - Context cancellation error if context is cancelled
-//   - Timeout error if port doesn't become available within DefaultStartupTimeout
+//   - Timeout error if port doesn't become available before the
+//     deadline of ctx, or within DefaultStartupTimeout without one
 //
 // Related:
 //   - moneroconst.DefaultStartupTimeout
 //   - IsPortInUse function
 func WaitForPort(ctx context.Context, port int) error {
-	deadline := time.Now().Add(moneroconst.DefaultStartupTimeout)
-	for time.Now().Before(deadline) {
+	return WaitForAddr(ctx, "localhost", port)
+}
+
+// WaitForAddr waits for a TCP port on host to accept connections,
+// polling as WaitReady does.
+//
+// Parameters:
+//   - ctx: Context for cancellation; its deadline, or
+//     moneroconst.DefaultStartupTimeout without one, bounds the wait
+//   - host: Host to probe, typically DialHost of the bind address
+//   - port: Port number to wait for
+//
+// Returns:
+//   - error: nil once the port accepts connections, otherwise a
+//     cancellation or timeout error
+//
+// An open port does not mean a service is usable: monerod accepts
+// connections long before it answers RPC. Services wait with WaitReady
+// and an RPC probe instead.
+//
+// Related:
+//   - WaitForPort for the localhost case
+func WaitForAddr(ctx context.Context, host string, port int) error {
+	return WaitReady(ctx, func(context.Context) error {
+		if IsAddrInUse(host, port) {
+			return nil
+		}
+		return fmt.Errorf("port %d is not accepting connections", port)
+	})
+}
+
+// WaitReady calls check until it succeeds, with exponential backoff
+// from PortPollInterval to PortPollMaxInterval between attempts.
+//
+// Parameters:
+//   - ctx: Context for cancellation; its deadline, or
+//     moneroconst.DefaultStartupTimeout without one, bounds the wait
+//   - check: Readiness probe, such as an RPC call; it should bound its
+//     own attempts
+//
+// Returns:
+//   - error: nil once check succeeds, ctx.Err() when cancelled, or on
+//     timeout an error wrapping context.DeadlineExceeded and describing
+//     the last failed check
+func WaitReady(ctx context.Context, check func(ctx context.Context) error) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, moneroconst.DefaultStartupTimeout)
+		defer cancel()
+	}
+	delay := PortPollInterval
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		timer := time.NewTimer(delay)
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			if IsPortInUse(port) {
-				return nil
+			timer.Stop()
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%w: %v", ctx.Err(), err)
 			}
-			time.Sleep(time.Second)
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if delay *= 2; delay > PortPollMaxInterval {
+			delay = PortPollMaxInterval
 		}
 	}
-	return fmt.Errorf("timeout waiting for port %d", port)
 }
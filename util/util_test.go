@@ -2,9 +2,26 @@ package util
 
 import (
 	"context"
+	"encoding/pem"
+	stderrors "errors"
+	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	moneroconst "github.com/opd-ai/moneroger/const"
+	"github.com/opd-ai/moneroger/errors"
 )
 
 // TestFileExists verifies the FileExists function correctly identifies
@@ -57,6 +74,19 @@ func TestPath(t *testing.T) {
 	if !found {
 		t.Error("Path() should include working directory")
 	}
+
+	binDir := t.TempDir()
+	t.Setenv(EnvPrefix+"_BIN_DIR", binDir)
+	if got := BinDir(); got != binDir {
+		t.Errorf("BinDir() = %q, want %q", got, binDir)
+	}
+	found = false
+	for _, p := range Path() {
+		found = found || p == binDir
+	}
+	if !found {
+		t.Error("Path() should include BinDir()")
+	}
 }
 
 // TestSecurePassword verifies password generation requirements
@@ -73,6 +103,43 @@ func TestSecurePassword(t *testing.T) {
 	if p1 == p2 {
 		t.Error("SecurePassword() should generate unique passwords")
 	}
+
+	// Every password has the default mix, and no symbols
+	for i := 0; i < 100; i++ {
+		p := SecurePassword()
+		if countIn(p, passwordLower) < 2 || countIn(p, passwordUpper) < 2 || countIn(p, passwordDigits) < 2 {
+			t.Fatalf("SecurePassword() = %q, want at least 2 of each class", p)
+		}
+		if countIn(p, passwordSymbols) != 0 {
+			t.Fatalf("SecurePassword() = %q, want no symbols", p)
+		}
+	}
+
+	policy := PasswordPolicy{MinDigits: 4, MinSymbols: 3}
+	p, err := SecurePasswordN(8, policy)
+	if err != nil {
+		t.Fatalf("SecurePasswordN() error = %v", err)
+	}
+	if len(p) != 8 || countIn(p, passwordDigits) < 4 || countIn(p, passwordSymbols) < 3 {
+		t.Errorf("SecurePasswordN(8, %+v) = %q", policy, p)
+	}
+	if _, err := SecurePasswordN(6, policy); err == nil {
+		t.Error("SecurePasswordN() accepted minimums longer than the password")
+	}
+	if _, err := SecurePasswordN(8, PasswordPolicy{MinLower: -1}); err == nil {
+		t.Error("SecurePasswordN() accepted a negative minimum")
+	}
+}
+
+// countIn counts the characters of s found in chars
+func countIn(s, chars string) int {
+	n := 0
+	for _, c := range s {
+		if strings.ContainsRune(chars, c) {
+			n++
+		}
+	}
+	return n
 }
 
 // TestIsPortInUse verifies port availability checking
@@ -104,6 +171,28 @@ func TestIsPortInUse(t *testing.T) {
 	}
 }
 
+// TestIsPortAvailable verifies the bind-based port check
+func TestIsPortAvailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if IsPortAvailable("", port) || IsPortAvailable("127.0.0.1", port) {
+		t.Errorf("IsPortAvailable(%d) = true for a bound port", port)
+	}
+
+	free, err := GetFreePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsPortAvailable("", free) {
+		t.Errorf("IsPortAvailable(%d) = false for a free port", free)
+	}
+}
+
 // TestWaitForPort verifies port waiting behavior
 func TestWaitForPort(t *testing.T) {
 	// Test immediate availability
@@ -131,4 +220,1517 @@ func TestWaitForPort(t *testing.T) {
 			t.Error("WaitForPort() should return error on cancelled context")
 		}
 	})
+
+	// Test a port opening shortly after the wait began
+	t.Run("port opened later", func(t *testing.T) {
+		port, err := GetFreePort()
+		if err != nil {
+			t.Fatal(err)
+		}
+		opened := time.AfterFunc(100*time.Millisecond, func() {
+			listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+			if err == nil {
+				t.Cleanup(func() { listener.Close() })
+			}
+		})
+		defer opened.Stop()
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := WaitForAddr(ctx, "127.0.0.1", port); err != nil {
+			t.Fatalf("WaitForAddr() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed >= PortPollMaxInterval {
+			t.Errorf("WaitForAddr() took %v, want the port noticed before the first full interval", elapsed)
+		}
+	})
+}
+
+// TestRingBuffer verifies that only the most recent output is retained
+func TestRingBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		writes []string
+		want   string
+	}{
+		{"under capacity", 8, []string{"abc", "de"}, "abcde"},
+		{"exact capacity", 4, []string{"ab", "cd"}, "abcd"},
+		{"wraps", 4, []string{"abc", "def"}, "cdef"},
+		{"single oversized write", 3, []string{"abcdefg"}, "efg"},
+		{"many small writes", 5, []string{"a", "b", "c", "d", "e", "f", "g"}, "cdefg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := NewRingBuffer(tt.size)
+			for _, w := range tt.writes {
+				if n, err := rb.Write([]byte(w)); err != nil || n != len(w) {
+					t.Fatalf("Write(%q) = %d, %v", w, n, err)
+				}
+			}
+			if got := rb.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if rb.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", rb.Len(), len(tt.want))
+			}
+		})
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		rb := NewRingBuffer(4)
+		rb.Write([]byte("abcdef"))
+		rb.Reset()
+		if rb.Len() != 0 || rb.String() != "" {
+			t.Errorf("Reset() left %q", rb.String())
+		}
+	})
+}
+
+// TestCachedCheck verifies result caching and call coalescing
+func TestCachedCheck(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	cc := NewCachedCheck(time.Minute, func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cc.Check(context.Background()); err != nil {
+				t.Errorf("Check() error = %v", err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if err := cc.Check(context.Background()); err != nil {
+		t.Errorf("cached Check() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("check ran %d times, want 1", n)
+	}
+
+	cc.Invalidate()
+	cc.Check(context.Background())
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("check ran %d times after Invalidate, want 2", n)
+	}
+}
+
+// TestJitter verifies jittered durations stay within bounds
+func TestJitter(t *testing.T) {
+	base := time.Second
+	for i := 0; i < 100; i++ {
+		d := Jitter(base, 0.1)
+		if d < 900*time.Millisecond || d > 1100*time.Millisecond {
+			t.Fatalf("Jitter() = %v, outside ±10%% of %v", d, base)
+		}
+	}
+	if Jitter(base, 0) != base {
+		t.Error("Jitter() with zero fraction should return the input")
+	}
+}
+
+// TestDetectClockJump verifies suspend detection from clock samples
+func TestDetectClockJump(t *testing.T) {
+	interval := 5 * time.Second
+	threshold := 10 * time.Second
+	tests := []struct {
+		name       string
+		wall, mono time.Duration
+		want       bool
+	}{
+		{"normal tick", interval, interval, false},
+		{"slightly late tick", interval + time.Second, interval + time.Second, false},
+		{"suspended", time.Hour, interval, true},
+		{"stalled process", time.Minute, time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := detectClockJump(tt.wall, tt.mono, interval, threshold); got != tt.want {
+				t.Errorf("detectClockJump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLineWriter verifies output is split into prefixed lines across writes
+func TestLineWriter(t *testing.T) {
+	var out strings.Builder
+	w := NewPrefixWriter(&out, "[monerod] ")
+	for _, chunk := range []string{"first li", "ne\nsecond line\r\n", "tail"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "[monerod] first line\n[monerod] second line\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	w.Flush()
+	if want += "[monerod] tail\n"; out.String() != want {
+		t.Errorf("output after Flush = %q, want %q", out.String(), want)
+	}
+}
+
+// TestFindExecutable verifies executables are found in search order
+func TestFindExecutable(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	name := "monerod" + executableExtensions()[0]
+	for _, dir := range []string{first, second} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := FindExecutable([]string{t.TempDir(), second, first}, "monerod")
+	if err != nil {
+		t.Fatalf("FindExecutable() error = %v", err)
+	}
+	if want := filepath.Join(second, name); got != want {
+		t.Errorf("FindExecutable() = %q, want %q", got, want)
+	}
+	if _, err := FindExecutable([]string{first}, "monero-wallet-rpc"); err == nil {
+		t.Error("FindExecutable() expected error for missing executable")
+	}
+}
+
+// TestRemoteNodeList verifies remote nodes are ordered and deduplicated
+func TestRemoteNodeList(t *testing.T) {
+	c := Config{RemoteNode: "a:18081", RemoteNodes: []string{"b:18081", "", "a:18081", "c:18089"}}
+	got := c.RemoteNodeList()
+	want := []string{"a:18081", "b:18081", "c:18089"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("RemoteNodeList() = %v, want %v", got, want)
+	}
+	if nodes := (Config{}).RemoteNodeList(); len(nodes) != 0 {
+		t.Errorf("RemoteNodeList() without remote nodes = %v, want empty", nodes)
+	}
+}
+
+// TestSelectRemoteNodes verifies nodes are filtered by state and ranked by latency
+func TestSelectRemoteNodes(t *testing.T) {
+	node := func(delay time.Duration, info string) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			fmt.Fprintf(w, `{"id":1,"result":%s}`, info)
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	slow := node(50*time.Millisecond, `{"status":"OK","height":1000,"version":"0.18.3.1","synchronized":true}`)
+	fast := node(0, `{"status":"OK","height":999,"version":"0.18.3.1","synchronized":true}`)
+	stale := node(0, `{"status":"OK","height":900,"version":"0.18.3.1","synchronized":true}`)
+	syncing := node(0, `{"status":"OK","height":1000,"version":"0.18.3.1","synchronized":false}`)
+	old := node(0, `{"status":"OK","height":1000,"version":"0.17.3.2","synchronized":true}`)
+
+	got, err := SelectRemoteNodes(context.Background(), []string{slow, stale, syncing, old, fast})
+	if err != nil {
+		t.Fatalf("SelectRemoteNodes() error = %v", err)
+	}
+	if want := []string{fast, slow}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("SelectRemoteNodes() = %v, want %v", got, want)
+	}
+	if _, err := SelectRemoteNodes(context.Background(), []string{syncing, old}); err == nil {
+		t.Error("SelectRemoteNodes() expected error when no node is usable")
+	}
+}
+
+// TestTorConfig verifies Tor validation and generated process flags
+func TestTorConfig(t *testing.T) {
+	if args := (TorConfig{}).DaemonArgs(); args != nil {
+		t.Errorf("DaemonArgs() without proxy = %v, want nil", args)
+	}
+
+	tor := TorConfig{Proxy: "127.0.0.1:9050", OnionAddress: "example.onion:18084"}
+	if err := tor.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := "--proxy 127.0.0.1:9050 --tx-proxy tor,127.0.0.1:9050,16 --disable-dns-checkpoints --no-igd " +
+		"--anonymous-inbound example.onion:18084,127.0.0.1:18084,16"
+	if got := strings.Join(tor.DaemonArgs(), " "); got != want {
+		t.Errorf("DaemonArgs() = %q, want %q", got, want)
+	}
+	if got := strings.Join(tor.WalletArgs(), " "); got != "--proxy 127.0.0.1:9050" {
+		t.Errorf("WalletArgs() = %q", got)
+	}
+
+	for _, bad := range []TorConfig{
+		{Proxy: "127.0.0.1"},
+		{Proxy: "127.0.0.1:9050", OnionAddress: "example.com:18084"},
+		{OnionAddress: "example.onion:18084"},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", bad)
+		}
+	}
+}
+
+// TestI2PConfig verifies I2P validation, flags and proxy reachability checks
+func TestI2PConfig(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	i2p := I2PConfig{Proxy: ln.Addr().String(), Address: "example.b32.i2p"}
+	if err := i2p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := fmt.Sprintf("--tx-proxy i2p,%s,16 --anonymous-inbound example.b32.i2p,127.0.0.1:18085,16", i2p.Proxy)
+	if got := strings.Join(i2p.DaemonArgs(), " "); got != want {
+		t.Errorf("DaemonArgs() = %q, want %q", got, want)
+	}
+	if err := i2p.CheckProxy(context.Background()); err != nil {
+		t.Errorf("CheckProxy() on listening proxy error = %v", err)
+	}
+
+	ln.Close()
+	if err := i2p.CheckProxy(context.Background()); err == nil {
+		t.Error("CheckProxy() expected error for closed proxy")
+	}
+	if err := (I2PConfig{Proxy: "127.0.0.1:4447", Address: "example.onion"}).Validate(); err == nil {
+		t.Error("Validate() expected error for non-b32 address")
+	}
+}
+
+func TestRPCTLS(t *testing.T) {
+	if err := (RPCTLS{}).Validate(); err != nil {
+		t.Errorf("Validate() on disabled TLS error = %v", err)
+	}
+	if cfg, err := (RPCTLS{}).ClientConfig(); cfg != nil || err != nil {
+		t.Errorf("ClientConfig() on disabled TLS = %v, %v", cfg, err)
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "rpc.crt")
+	keyFile := filepath.Join(dir, "rpc.key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsConfig := RPCTLS{CertFile: certFile, KeyFile: keyFile}
+	if err := tlsConfig.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if tlsConfig.Scheme() != "https" {
+		t.Errorf("Scheme() = %q, want https", tlsConfig.Scheme())
+	}
+	want := fmt.Sprintf("--rpc-ssl enabled --rpc-ssl-private-key %s --rpc-ssl-certificate %s", keyFile, certFile)
+	if got := strings.Join(tlsConfig.ServerArgs(), " "); got != want {
+		t.Errorf("ServerArgs() = %q, want %q", got, want)
+	}
+
+	clientConfig, err := tlsConfig.ClientConfig()
+	if err != nil {
+		t.Fatalf("ClientConfig() error = %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request trusting the server certificate failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := (RPCTLS{CertFile: certFile}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing key")
+	}
+	if err := (RPCTLS{CertFile: certFile, KeyFile: keyFile, CAFile: filepath.Join(dir, "missing")}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing CA file")
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	tests := []struct {
+		ip       string
+		wantArgs string
+		wantHost string
+		wantErr  bool
+	}{
+		{ip: "", wantArgs: "", wantHost: "localhost"},
+		{ip: "127.0.0.1", wantArgs: "--rpc-bind-ip 127.0.0.1", wantHost: "127.0.0.1"},
+		{ip: "0.0.0.0", wantArgs: "--rpc-bind-ip 0.0.0.0 --confirm-external-bind", wantHost: "localhost"},
+		{ip: "192.168.1.10", wantArgs: "--rpc-bind-ip 192.168.1.10 --confirm-external-bind", wantHost: "192.168.1.10"},
+		{ip: "::1", wantErr: true},
+		{ip: "node.local", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := ValidateBindIP(tt.ip)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateBindIP(%q) error = %v, wantErr %v", tt.ip, err, tt.wantErr)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got := strings.Join(BindArgs(tt.ip), " "); got != tt.wantArgs {
+			t.Errorf("BindArgs(%q) = %q, want %q", tt.ip, got, tt.wantArgs)
+		}
+		if got := DialHost(tt.ip); got != tt.wantHost {
+			t.Errorf("DialHost(%q) = %q, want %q", tt.ip, got, tt.wantHost)
+		}
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+	if !IsAddrInUse("127.0.0.1", port) {
+		t.Errorf("IsAddrInUse(127.0.0.1, %d) = false for listening port", port)
+	}
+	if err := WaitForAddr(context.Background(), "127.0.0.1", port); err != nil {
+		t.Errorf("WaitForAddr() error = %v", err)
+	}
+}
+
+func TestBootstrapConfig(t *testing.T) {
+	if err := (BootstrapConfig{}).Validate(); err != nil {
+		t.Errorf("Validate() on disabled bootstrap error = %v", err)
+	}
+	if args := (BootstrapConfig{}).DaemonArgs(""); args != nil {
+		t.Errorf("DaemonArgs() on disabled bootstrap = %v", args)
+	}
+
+	b := BootstrapConfig{Address: "node.example.com:18081", Login: "user:pass"}
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := "--bootstrap-daemon-address node.example.com:18081 --bootstrap-daemon-proxy 127.0.0.1:9050"
+	if got := strings.Join(b.DaemonArgs("127.0.0.1:9050"), " "); got != want {
+		t.Errorf("DaemonArgs() = %q, want %q", got, want)
+	}
+	if got := b.DaemonOptions()["bootstrap-daemon-login"]; got != "user:pass" {
+		t.Errorf("DaemonOptions() login = %q, want user:pass", got)
+	}
+
+	for _, invalid := range []BootstrapConfig{
+		{Login: "user:pass"},
+		{Address: "node.example.com"},
+		{Address: "node.example.com:18081", Login: "user"},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", invalid)
+		}
+	}
+	if err := (BootstrapConfig{Address: BootstrapAuto}).Validate(); err != nil {
+		t.Errorf("Validate() with auto error = %v", err)
+	}
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	want := Config{
+		DataDir:     "/var/lib/monero",
+		WalletFile:  "/var/lib/monero/wallet",
+		MoneroPort:  18081,
+		WalletPort:  18083,
+		TestNet:     true,
+		RemoteNodes: []string{"http://a.example:18089", "http://b.example:18089"},
+		Restart:     RestartPolicy{MaxRetries: 3, MinUptime: time.Minute},
+		Tor:         TorConfig{Proxy: "127.0.0.1:9050"},
+	}
+	files := map[string]string{
+		"config.yaml": `datadir: /var/lib/monero
+walletfile: /var/lib/monero/wallet
+moneroport: 18081
+walletport: 18083
+testnet: true
+remotenodes:
+  - http://a.example:18089
+  - http://b.example:18089
+restart:
+  maxretries: 3
+  minuptime: 1m
+tor:
+  proxy: 127.0.0.1:9050
+`,
+		"config.toml": `DataDir = "/var/lib/monero"
+WalletFile = "/var/lib/monero/wallet"
+MoneroPort = 18081
+WalletPort = 18083
+TestNet = true
+RemoteNodes = ["http://a.example:18089", "http://b.example:18089"]
+
+[Restart]
+MaxRetries = 3
+MinUptime = "1m"
+
+[Tor]
+Proxy = "127.0.0.1:9050"
+`,
+		"config.json": `{
+  "DataDir": "/var/lib/monero",
+  "WalletFile": "/var/lib/monero/wallet",
+  "MoneroPort": 18081,
+  "WalletPort": 18083,
+  "TestNet": true,
+  "RemoteNodes": ["http://a.example:18089", "http://b.example:18089"],
+  "Restart": {"MaxRetries": 3, "MinUptime": "1m"},
+  "Tor": {"Proxy": "127.0.0.1:9050"}
+}
+`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+
+	path := filepath.Join(dir, "config.ini")
+	if err := os.WriteFile(path, []byte("datadir = /tmp\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig() expected error for unsupported extension")
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("MONEROGER_DAEMON_PORT", "28081")
+	t.Setenv("MONEROGER_TESTNET", "true")
+	t.Setenv("MONEROGER_REMOTE_NODES", "http://a.example:18089,http://b.example:18089")
+	t.Setenv("MONEROGER_WALLET_RPC_PASS", "secret")
+	t.Setenv("MONEROGER_TOR_PROXY", "127.0.0.1:9050")
+	t.Setenv("MONEROGER_STARTUP_TIMEOUT", "5m")
+
+	config := Config{DataDir: "/data", MoneroPort: 18081, WalletPort: 18083}
+	if err := config.ApplyEnv(); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	want := Config{
+		DataDir:        "/data",
+		MoneroPort:     28081,
+		WalletPort:     18083,
+		TestNet:        true,
+		RemoteNodes:    []string{"http://a.example:18089", "http://b.example:18089"},
+		WalletRPCPass:  "secret",
+		Tor:            TorConfig{Proxy: "127.0.0.1:9050"},
+		StartupTimeout: 5 * time.Minute,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("ApplyEnv() = %+v, want %+v", config, want)
+	}
+	if got := config.EffectiveShutdownTimeout(); got != moneroconst.DefaultShutdownTimeout {
+		t.Errorf("EffectiveShutdownTimeout() = %v, want the default %v", got, moneroconst.DefaultShutdownTimeout)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("moneroport: 18081\nwalletport: 18083\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if loaded.MoneroPort != 28081 || loaded.WalletPort != 18083 {
+		t.Errorf("LoadConfig() ports = %d, %d; want environment to override file", loaded.MoneroPort, loaded.WalletPort)
+	}
+
+	t.Setenv("MONEROGER_WALLET_PORT", "not-a-port")
+	if err := config.ApplyEnv(); err == nil {
+		t.Error("ApplyEnv() expected error for non-numeric port")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		DataDir:    t.TempDir(),
+		MoneroPort: 18081,
+		WalletPort: 18083,
+		ZMQPubPort: 18086,
+		RemoteNode: "https://node.example:18089",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := Config{
+		DataDir:     notDir,
+		MoneroPort:  18081,
+		WalletPort:  18081,
+		ZMQPubPort:  70000,
+		RemoteNodes: []string{"ftp://node.example"},
+		Tor:         TorConfig{OnionAddress: "abc.onion:18084"},
+	}
+	err := invalid.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected errors")
+	}
+	problems := err.(interface{ Unwrap() []error }).Unwrap()
+	if len(problems) != 5 {
+		t.Errorf("Validate() reported %d problems, want 5: %v", len(problems), err)
+	}
+	kinds := make(map[errors.Kind]int)
+	for _, p := range problems {
+		kinds[errors.GetKind(p)]++
+	}
+	if kinds[errors.KindConfig] != 4 || kinds[errors.KindSystem] != 1 {
+		t.Errorf("Validate() kinds = %v, want 4 config and 1 system", kinds)
+	}
+
+	valid.StatusAddress = "127.0.0.1:18090"
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() with a status address error = %v", err)
+	}
+	for _, addr := range []string{"127.0.0.1:18083", ":18090", "localhost"} {
+		valid.StatusAddress = addr
+		if err := valid.Validate(); err == nil {
+			t.Errorf("Validate() accepted status address %q", addr)
+		}
+	}
+	valid.StatusAddress = "0.0.0.0:18090"
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a non-loopback status address without a token or TLS")
+	}
+	valid.StatusToken = "0123456789abcdef"
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() with a non-loopback status address and a token error = %v", err)
+	}
+	valid.StatusToken = "short"
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a short status token")
+	}
+	valid.StatusAddress, valid.StatusToken = "", "0123456789abcdef"
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a status token without a status address")
+	}
+	valid.StatusToken = ""
+
+	valid.LimitRateUp, valid.LimitRateDown = 256, 2048
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() with rate limits error = %v", err)
+	}
+	valid.LimitRateDown = -1
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a negative download limit")
+	}
+	valid.LimitRateUp, valid.LimitRateDown = 0, 0
+
+	valid.RestrictedPort = 18089
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() with a restricted port error = %v", err)
+	}
+	valid.RestrictedPort = 18081
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a restricted port equal to the daemon port")
+	}
+	valid.RestrictedPort = 0
+
+	public := valid
+	public.PublicNode = true
+	if err := public.Validate(); err == nil {
+		t.Error("Validate() accepted a public node using a remote node")
+	}
+	public.RemoteNode = ""
+	if err := public.Validate(); err != nil {
+		t.Errorf("Validate() of a public node error = %v", err)
+	}
+	public.Offline = true
+	if err := public.Validate(); err == nil {
+		t.Error("Validate() accepted an offline public node")
+	}
+
+	for _, watch := range []DiskWatchConfig{
+		{WarnGB: []int{0}},
+		{FallbackGB: -1},
+		{FallbackNodes: []string{"https://node.example:18089"}},
+		{FallbackGB: 10, FallbackNodes: []string{"ftp://node.example"}},
+	} {
+		valid.DiskWatch = watch
+		if err := valid.Validate(); err == nil {
+			t.Errorf("Validate() accepted disk watch %+v", watch)
+		}
+	}
+	valid.DiskWatch = DiskWatchConfig{}
+
+	valid.HWDevice = HWDeviceConfig{Name: "Trezor", DerivationPath: "m/44'/128'/1'"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() with a hardware wallet error = %v", err)
+	}
+	valid.WalletRPCExtraArgs = []string{"--hw-device=Ledger"}
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted --hw-device as an extra argument")
+	}
+	valid.WalletRPCExtraArgs = nil
+	for _, device := range []HWDeviceConfig{
+		{Name: "KeepKey"},
+		{DerivationPath: "m/44'/128'/0'"},
+		{Name: "Ledger", DerivationPath: "44'/128'/0'"},
+	} {
+		valid.HWDevice = device
+		if err := valid.Validate(); err == nil {
+			t.Errorf("Validate() accepted hardware wallet %+v", device)
+		}
+	}
+	valid.HWDevice = HWDeviceConfig{Name: "Ledger"}
+	valid.Docker = DockerConfig{Image: "monero:latest"}
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a hardware wallet with the Docker driver")
+	}
+	valid.HWDevice, valid.Docker = HWDeviceConfig{}, DockerConfig{}
+
+	banList := filepath.Join(t.TempDir(), "ban.txt")
+	if err := os.WriteFile(banList, []byte("# spy nodes\n10.0.0.1\n2001:db8::/32 # lab\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := ReadBanList(banList)
+	if err != nil || fmt.Sprint(hosts) != "[10.0.0.1 2001:db8::/32]" {
+		t.Errorf("ReadBanList() = %q, %v", hosts, err)
+	}
+	valid.BanList = banList
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() with a ban list error = %v", err)
+	}
+	if err := os.WriteFile(banList, []byte("10.0.0.1\nnode.example\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := valid.Validate(); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Validate() with a host name in the ban list error = %v", err)
+	}
+	valid.BanList = filepath.Join(t.TempDir(), "missing.txt")
+	if err := valid.Validate(); err == nil {
+		t.Error("Validate() accepted a missing ban list")
+	}
+}
+
+// TestWalletDir verifies the deprecated WalletFile and its environment
+// variable still select the wallet directory, and wallet name validation
+func TestWalletDir(t *testing.T) {
+	if dir := (Config{WalletFile: "/old"}).EffectiveWalletDir(); dir != "/old" {
+		t.Errorf("EffectiveWalletDir() of WalletFile = %q", dir)
+	}
+	if dir := (Config{WalletDir: "/new", WalletFile: "/old"}).EffectiveWalletDir(); dir != "/new" {
+		t.Errorf("EffectiveWalletDir() of both = %q, want WalletDir", dir)
+	}
+
+	t.Setenv("MONEROGER_WALLET_FILE", "/env-old")
+	var config Config
+	if err := config.ApplyEnv(); err != nil || config.WalletDir != "/env-old" || config.WalletFile != "" {
+		t.Errorf("ApplyEnv() with MONEROGER_WALLET_FILE = %+v, %v, want WalletDir set", config, err)
+	}
+	t.Setenv("MONEROGER_WALLET_DIR", "/env-new")
+	t.Setenv("MONEROGER_WALLET_NAME", "shop")
+	if err := config.ApplyEnv(); err != nil || config.WalletDir != "/env-new" || config.WalletName != "shop" {
+		t.Errorf("ApplyEnv() with MONEROGER_WALLET_DIR = %+v, %v, want it to win", config, err)
+	}
+
+	valid := Config{DataDir: t.TempDir(), MoneroPort: 18081, WalletPort: 18083, WalletName: "shop"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, name := range []string{"..", "../shop", `sub\shop`} {
+		invalid := valid
+		invalid.WalletName = name
+		if err := invalid.Validate(); errors.GetKind(err) != errors.KindConfig {
+			t.Errorf("Validate() with wallet name %q error = %v, want KindConfig", name, err)
+		}
+	}
+}
+
+// TestRecommendThresholds verifies the node mode chosen for free space
+// and the free space measured under a directory not yet created
+func TestRecommendThresholds(t *testing.T) {
+	for _, tt := range []struct {
+		thresholds RecommendThresholds
+		freeGB     uint64
+		want       NodeMode
+	}{
+		{RecommendThresholds{}, 300, NodeModeFull},
+		{RecommendThresholds{}, 250, NodeModeFull},
+		{RecommendThresholds{}, 249, NodeModePruned},
+		{RecommendThresholds{}, 100, NodeModePruned},
+		{RecommendThresholds{}, 99, NodeModeRemote},
+		{RecommendThresholds{FullGB: 500, PrunedGB: 200}, 300, NodeModePruned},
+		{RecommendThresholds{FullGB: 500, PrunedGB: 200}, 150, NodeModeRemote},
+		{RecommendThresholds{FullGB: 50}, 60, NodeModeFull},
+		{RecommendThresholds{FullGB: 50}, 49, NodeModeRemote},
+	} {
+		if got := tt.thresholds.Mode(tt.freeGB * Gigabyte); got != tt.want {
+			t.Errorf("%+v.Mode(%d GB) = %s, want %s", tt.thresholds, tt.freeGB, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []RecommendThresholds{{FullGB: -1}, {PrunedGB: -1}, {FullGB: 100, PrunedGB: 200}, {PrunedGB: 300}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("%+v.Validate() accepted invalid thresholds", invalid)
+		}
+	}
+	if err := (RecommendThresholds{FullGB: 50}).Validate(); err != nil {
+		t.Errorf("Validate() of a low full node threshold error = %v", err)
+	}
+
+	if free := AvailableSpace(filepath.Join(t.TempDir(), "missing", "dir")); free == 0 {
+		t.Error("AvailableSpace() of a missing directory = 0, want its parent's free space")
+	}
+}
+
+func TestSaveConfigRoundTrip(t *testing.T) {
+	config := Config{
+		DataDir:       "/var/lib/monero",
+		WalletFile:    "/var/lib/monero/wallet",
+		MoneroPort:    18081,
+		WalletPort:    18083,
+		ZMQPubPort:    18086,
+		RemoteNode:    "https://node.example:18089",
+		RemoteNodes:   []string{"http://a.example:18089", `http://b.example:18089/"quoted"`},
+		WalletRPCPass: "p@ss\\word",
+		Restart:       DefaultRestartPolicy(),
+		Tor:           TorConfig{Proxy: "127.0.0.1:9050"},
+		Bootstrap:     BootstrapConfig{Address: BootstrapAuto},
+		DiskWatch:     DiskWatchConfig{Interval: 5 * time.Minute, WarnGB: []int{50, 20}, FallbackGB: 10},
+		Prune:         true,
+	}
+	config.Restart.Multiplier = 1.5
+
+	dir := t.TempDir()
+	for _, name := range []string{"config.yaml", "config.toml", "config.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := SaveConfig(path, config); err != nil {
+				t.Fatalf("SaveConfig() error = %v", err)
+			}
+			got, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, config) {
+				t.Errorf("round trip = %+v, want %+v", *got, config)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+				t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+			}
+		})
+	}
+
+	if err := SaveConfig(filepath.Join(dir, "config.txt"), config); err == nil {
+		t.Error("SaveConfig() expected error for unsupported extension")
+	}
+}
+
+// TestNetwork verifies network selection, flags and default ports
+func TestNetwork(t *testing.T) {
+	if _, err := ParseNetwork("regtest"); err == nil {
+		t.Error("ParseNetwork(regtest) expected error")
+	}
+	n, err := ParseNetwork("stagenet")
+	if err != nil || n != NetworkStagenet {
+		t.Fatalf("ParseNetwork(stagenet) = %q, %v", n, err)
+	}
+	if n.Flag() != "--stagenet" || n.DaemonPort() != 38081 || n.WalletPort() != 38083 {
+		t.Errorf("stagenet flag/ports = %q %d %d", n.Flag(), n.DaemonPort(), n.WalletPort())
+	}
+	if NetworkMainnet.Flag() != "" || NetworkTestnet.DaemonPort() != 28081 {
+		t.Error("unexpected mainnet flag or testnet port")
+	}
+	if got := (Config{TestNet: true}).NetType(); got != NetworkTestnet {
+		t.Errorf("NetType() with TestNet = %q, want testnet", got)
+	}
+
+	config := Config{
+		MoneroPort: 18081,
+		WalletPort: 18090,
+		ZMQPubPort: 18086,
+		RemoteNode: "http://node.example:18089",
+	}
+	config.SetNetwork(NetworkStagenet)
+	want := Config{Network: NetworkStagenet, MoneroPort: 38081, WalletPort: 18090, ZMQPubPort: 38086}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("SetNetwork() = %+v, want %+v", config, want)
+	}
+
+	mainnet := Config{DataDir: "/data"}
+	if dir := mainnet.NetworkDataDir(); dir != "/data" || mainnet.EffectiveWalletDir() != "/data" {
+		t.Errorf("mainnet NetworkDataDir() = %q, want DataDir itself", dir)
+	}
+	if mainnet.EffectiveMoneroPort() != 18081 || mainnet.EffectiveWalletPort() != 18083 {
+		t.Errorf("mainnet default ports = %d, %d", mainnet.EffectiveMoneroPort(), mainnet.EffectiveWalletPort())
+	}
+	stagenet := Config{DataDir: "/data", Network: NetworkStagenet, WalletPort: 40000}
+	if dir := stagenet.NetworkDataDir(); dir != filepath.Join("/data", "stagenet") || stagenet.EffectiveWalletDir() != dir {
+		t.Errorf("stagenet NetworkDataDir() = %q, want the stagenet subdirectory", dir)
+	}
+	if stagenet.EffectiveMoneroPort() != 38081 || stagenet.EffectiveWalletPort() != 40000 {
+		t.Errorf("stagenet ports = %d, %d, want 38081 and the configured 40000", stagenet.EffectiveMoneroPort(), stagenet.EffectiveWalletPort())
+	}
+	if dir := (Config{TestNet: true}).NetworkDataDir(); dir != "" {
+		t.Errorf("NetworkDataDir() without DataDir = %q", dir)
+	}
+	if file := stagenet.EffectiveDaemonLogFile(); file != filepath.Join("/data", "stagenet", "logs", "bitmonero.log") {
+		t.Errorf("stagenet EffectiveDaemonLogFile() = %q, want logs under the stagenet subdirectory", file)
+	}
+	if file := (Config{DataDir: "/data", DaemonLogFile: "/var/log/monerod.log"}).EffectiveDaemonLogFile(); file != "/var/log/monerod.log" {
+		t.Errorf("EffectiveDaemonLogFile() = %q, want DaemonLogFile", file)
+	}
+	if err := (Config{DataDir: t.TempDir(), DaemonMaxLogFileSize: -1}).Validate(); err == nil {
+		t.Error("Validate() accepted a negative log file size")
+	}
+
+	bad := Config{TestNet: true, Network: NetworkStagenet}
+	if err := bad.Validate(); err == nil || !strings.Contains(err.Error(), "testnet is set") {
+		t.Errorf("Validate() = %v, want a testnet/network conflict", err)
+	}
+
+	t.Setenv("MONEROGER_NETWORK", "stagenet")
+	t.Setenv("MONEROGER_WALLET_PORT", "40000")
+	env := Config{MoneroPort: 18081, WalletPort: 18083}
+	if err := env.ApplyEnv(); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if env.NetType() != NetworkStagenet || env.MoneroPort != 38081 || env.WalletPort != 40000 {
+		t.Errorf("ApplyEnv() = %+v, want stagenet with ports 38081 and 40000", env)
+	}
+}
+
+// TestPublicNode verifies the settings the public node profile fills in
+// and those it leaves alone
+func TestPublicNode(t *testing.T) {
+	private := Config{OutPeers: 8}
+	if private.EffectiveRestrictedPort() != 0 || private.PublicNodeArgs() != nil ||
+		private.EffectiveOutPeers() != 8 || private.EffectiveInPeers() != 0 || private.EffectiveLimitRate() != 0 {
+		t.Errorf("private node settings = %d %v %d %d %d", private.EffectiveRestrictedPort(), private.PublicNodeArgs(),
+			private.EffectiveOutPeers(), private.EffectiveInPeers(), private.EffectiveLimitRate())
+	}
+
+	public := Config{Network: NetworkStagenet, PublicNode: true, OutPeers: 8}
+	if public.EffectiveRestrictedPort() != 38089 || public.EffectiveOutPeers() != 8 ||
+		public.EffectiveInPeers() != PublicNodeInPeers || public.EffectiveLimitRate() != PublicNodeLimitRate {
+		t.Errorf("public node settings = %d %d %d %d", public.EffectiveRestrictedPort(),
+			public.EffectiveOutPeers(), public.EffectiveInPeers(), public.EffectiveLimitRate())
+	}
+	if got := strings.Join(public.PublicNodeArgs(), " "); got != "--public-node --rpc-restricted-bind-ip 0.0.0.0" {
+		t.Errorf("PublicNodeArgs() = %q", got)
+	}
+	public.RestrictedPort = 40089
+	if public.EffectiveRestrictedPort() != 40089 {
+		t.Errorf("EffectiveRestrictedPort() = %d, want the configured 40089", public.EffectiveRestrictedPort())
+	}
+
+	moved := Config{RestrictedPort: 18089}
+	moved.SetNetwork(NetworkTestnet)
+	if moved.RestrictedPort != 28089 {
+		t.Errorf("SetNetwork() moved the restricted port to %d, want 28089", moved.RestrictedPort)
+	}
+
+	public.MonerodExtraArgs = []string{"--public-node"}
+	if err := public.ValidateMonerodExtraArgs(); err == nil {
+		t.Error("ValidateMonerodExtraArgs() accepted --public-node for a public node")
+	}
+}
+
+// TestGetFreePort verifies the returned port can be bound
+func TestGetFreePort(t *testing.T) {
+	port, err := GetFreePort()
+	if err != nil {
+		t.Fatalf("GetFreePort() error = %v", err)
+	}
+	if IsPortInUse(port) {
+		t.Errorf("GetFreePort() = %d, which is in use", port)
+	}
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatalf("binding port %d: %v", port, err)
+	}
+	l.Close()
+}
+
+// TestPortReservation verifies reserved ports are distinct, held until
+// released, drawn from the range, and not handed out twice
+func TestPortReservation(t *testing.T) {
+	res, err := ReservePorts("", 2, PortRange{})
+	if err != nil {
+		t.Fatalf("ReservePorts() error = %v", err)
+	}
+	ports := res.Ports()
+	if len(ports) != 2 || ports[0] == ports[1] {
+		t.Fatalf("ReservePorts() = %v, want two distinct ports", ports)
+	}
+	if IsPortAvailable("", ports[0]) {
+		t.Errorf("port %d is available before Release", ports[0])
+	}
+	res.Release()
+	if !IsPortAvailable("", ports[0]) {
+		t.Errorf("port %d is not available after Release", ports[0])
+	}
+
+	// Pick a small range around a free port, then exhaust it
+	base, err := GetFreePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base > 65533 {
+		base = 65533
+	}
+	r := PortRange{Min: base, Max: base + 2}
+	got, err := GetFreePorts("", 2, r)
+	if err != nil {
+		t.Skipf("range %d-%d is busy: %v", r.Min, r.Max, err)
+	}
+	for _, port := range got {
+		if port < r.Min || port > r.Max {
+			t.Errorf("GetFreePorts() = %d, outside %d-%d", port, r.Min, r.Max)
+		}
+	}
+	if again, err := GetFreePorts("", 2, r); err == nil {
+		t.Errorf("GetFreePorts() = %v, want an error once the range is reserved", again)
+	}
+
+	for _, tt := range []struct {
+		in      string
+		want    PortRange
+		wantErr bool
+	}{
+		{"", PortRange{}, false},
+		{"20000-20999", PortRange{Min: 20000, Max: 20999}, false},
+		{"20000", PortRange{}, true},
+		{"2000-1000", PortRange{}, true},
+		{"0-100", PortRange{}, true},
+		{"1-70000", PortRange{}, true},
+	} {
+		got, err := ParsePortRange(tt.in)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("ParsePortRange(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+// TestRetry verifies Retry stops on success, on permanent errors and
+// once attempts are used up
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+	transient := errors.E(errors.KindNetwork, fmt.Errorf("refused"))
+	ctx := context.Background()
+
+	calls := 0
+	err := Retry(ctx, policy, func(context.Context) error {
+		if calls++; calls < 2 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Retry() = %v after %d calls, want success after 2", err, calls)
+	}
+
+	calls = 0
+	err = Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return transient
+	})
+	if err != transient || calls != 3 {
+		t.Errorf("Retry() = %v after %d calls, want the last error after 3", err, calls)
+	}
+
+	calls = 0
+	err = Retry(ctx, policy, func(context.Context) error {
+		calls++
+		return errors.E(errors.KindAuth, fmt.Errorf("denied"))
+	})
+	if errors.GetKind(err) != errors.KindAuth || calls != 1 {
+		t.Errorf("Retry() = %v after %d calls, want KindAuth after 1", err, calls)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Retry(cancelled, RetryPolicy{MaxAttempts: 3, InitialDelay: time.Hour}, func(context.Context) error {
+		return transient
+	})
+	if err != context.Canceled {
+		t.Errorf("Retry() with a cancelled context = %v, want context.Canceled", err)
+	}
+
+	if got := (RetryPolicy{InitialDelay: time.Second, MaxDelay: 3 * time.Second}).Delay(2); got != 3*time.Second {
+		t.Errorf("Delay(2) = %v, want the 3s cap", got)
+	}
+}
+
+// memCredentials is an in-memory CredentialProvider
+type memCredentials map[string]string
+
+func (m memCredentials) Get(account string) (string, error) {
+	secret, ok := m[account]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", account, ErrCredentialNotFound)
+	}
+	return secret, nil
+}
+
+func (m memCredentials) Set(account, secret string) error {
+	m[account] = secret
+	return nil
+}
+
+// TestResolveCredential verifies secrets are generated once, then reused
+func TestResolveCredential(t *testing.T) {
+	store := memCredentials{}
+	first, err := ResolveCredential(store, WalletAccount("/wallets"))
+	if err != nil || first == "" {
+		t.Fatalf("ResolveCredential() = %q, %v", first, err)
+	}
+	if store[WalletAccount("/wallets")] != first {
+		t.Errorf("generated secret was not stored")
+	}
+	second, err := ResolveCredential(store, WalletAccount("/wallets"))
+	if err != nil || second != first {
+		t.Errorf("ResolveCredential() again = %q, %v; want %q", second, err, first)
+	}
+	if other, _ := ResolveCredential(store, WalletRPCAccount("/wallets")); other == first {
+		t.Errorf("accounts share a secret")
+	}
+
+	var config Config
+	if config.CredentialStore() != nil {
+		t.Errorf("CredentialStore() without a store = %v, want nil", config.CredentialStore())
+	}
+	config.Keyring = true
+	if _, ok := config.CredentialStore().(*Keyring); !ok {
+		t.Errorf("CredentialStore() with Keyring = %T, want *Keyring", config.CredentialStore())
+	}
+	config.Credentials = store
+	if _, ok := config.CredentialStore().(memCredentials); !ok {
+		t.Errorf("CredentialStore() with Credentials = %T, want the provider", config.CredentialStore())
+	}
+}
+
+// TestCheckTransition verifies the service lifecycle
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to ServiceState
+		ok       bool
+	}{
+		{StateUnknown, StateStarting, true},
+		{StateUnknown, StateRunning, false},
+		{StateStarting, StateRunning, true},
+		{StateStarting, StateStopping, true},
+		{StateRunning, StateStarting, false},
+		{StateRunning, StateStopping, true},
+		{StateRunning, StateCrashed, true},
+		{StateStopping, StateStarting, false},
+		{StateStopping, StateStopped, true},
+		{StateStopped, StateStarting, true},
+		{StateStopped, StateRunning, false},
+		{StateCrashed, StateStarting, true},
+		{StateCrashed, StateStopped, false},
+	}
+	for _, tt := range tests {
+		err := CheckTransition(tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckTransition(%s, %s) error = %v, want ok %v", tt.from, tt.to, err, tt.ok)
+		}
+		if err != nil && !stderrors.Is(err, ErrInvalidTransition) {
+			t.Errorf("CheckTransition(%s, %s) error = %v, want ErrInvalidTransition", tt.from, tt.to, err)
+		}
+	}
+	if got := StateCrashed.String(); got != "crashed" {
+		t.Errorf("StateCrashed.String() = %q, want %q", got, "crashed")
+	}
+}
+
+// TestFindOrphan verifies PID files are matched against the executable
+func TestFindOrphan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.pid")
+	if p, err := FindOrphan(path, "/nonexistent"); p != nil || err != nil {
+		t.Errorf("FindOrphan() without PID file = %v, %v; want nil, nil", p, err)
+	}
+
+	if err := WritePIDFile(path, os.Getpid()); err != nil {
+		t.Fatalf("WritePIDFile() error = %v", err)
+	}
+	if pid, err := ReadPIDFile(path); err != nil || pid != os.Getpid() {
+		t.Errorf("ReadPIDFile() = %d, %v; want %d", pid, err, os.Getpid())
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() error = %v", err)
+	}
+	p, err := FindOrphan(path, exe)
+	if err != nil || p == nil || p.Pid != os.Getpid() {
+		t.Fatalf("FindOrphan() of this process = %v, %v", p, err)
+	}
+
+	// A live process running another executable is a reused PID
+	if p, err := FindOrphan(path, filepath.Join(t.TempDir(), "monerod")); p != nil || err != nil {
+		t.Errorf("FindOrphan() with another executable = %v, %v; want nil, nil", p, err)
+	}
+	if FileExists(path) {
+		t.Error("stale PID file was not removed")
+	}
+}
+
+// TestRemoveStaleLMDBLock verifies lock files no process holds are
+// removed
+func TestRemoveStaleLMDBLock(t *testing.T) {
+	dataDir := t.TempDir()
+	if removed, err := RemoveStaleLMDBLock(dataDir); removed || err != nil {
+		t.Errorf("RemoveStaleLMDBLock() without database = %v, %v; want false, nil", removed, err)
+	}
+
+	path := LMDBLockFile(dataDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, 64), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := RemoveStaleLMDBLock(dataDir); !removed || err != nil {
+		t.Errorf("RemoveStaleLMDBLock() with stale lock = %v, %v; want true, nil", removed, err)
+	}
+	if FileExists(path) {
+		t.Error("stale lock file was not removed")
+	}
+}
+
+// TestStopProcess verifies processes that are not our children can be
+// watched and stopped
+func TestStopProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+	cmd := exec.Command("sleep", "30")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep unavailable: %v", err)
+	}
+	// Reap the child so it does not linger as a zombie
+	go cmd.Wait()
+
+	exit := WatchOrphan(cmd.Process)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := StopProcess(ctx, cmd.Process, 5*time.Second); err != nil {
+		t.Fatalf("StopProcess() error = %v", err)
+	}
+	select {
+	case <-exit.Done():
+	case <-ctx.Done():
+		t.Fatal("WatchOrphan() did not report the exit")
+	}
+	if exit.Err() != nil {
+		t.Errorf("Err() = %v, want nil for orphans", exit.Err())
+	}
+}
+
+// TestProcessUsage verifies resource usage is read for a running process
+// and CPU use is measured between measurements
+func TestProcessUsage(t *testing.T) {
+	var meter UsageMeter
+	usage, err := meter.Measure(os.Getpid())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if usage.RSS == 0 {
+		t.Error("Measure() reported no resident memory")
+	}
+	if runtime.GOOS == "linux" && usage.OpenFiles == 0 {
+		t.Error("Measure() reported no open files")
+	}
+	// Keep a core busy so the next measurement sees CPU use
+	for deadline := time.Now().Add(50 * time.Millisecond); time.Now().Before(deadline); {
+	}
+	next, err := meter.Measure(os.Getpid())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if next.CPUTime < usage.CPUTime || next.CPUPercent <= 0 {
+		t.Errorf("Measure() after busy loop = %v, %.1f%%; want CPU use", next.CPUTime, next.CPUPercent)
+	}
+
+	if _, err := ReadProcessUsage(-1); err == nil {
+		t.Error("ReadProcessUsage() of a nonexistent process succeeded")
+	}
+
+	durations := map[string]time.Duration{
+		"03:04":      3*time.Minute + 4*time.Second,
+		"3:04.50":    3*time.Minute + 4500*time.Millisecond,
+		"02:03:04":   2*time.Hour + 3*time.Minute + 4*time.Second,
+		"1-02:03:04": 26*time.Hour + 3*time.Minute + 4*time.Second,
+	}
+	for s, want := range durations {
+		if got, err := parsePSDuration(s); err != nil || got != want {
+			t.Errorf("parsePSDuration(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	if _, err := parsePSDuration("1:2:3:4"); err == nil {
+		t.Error("parsePSDuration() accepted four fields")
+	}
+}
+
+// TestOpenProcessLog verifies process logs are created with their
+// directory and appended to.
+func TestOpenProcessLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "monerod.log")
+	for _, line := range []string{"first\n", "second\n"} {
+		f, err := OpenProcessLog(path)
+		if err != nil {
+			t.Fatalf("OpenProcessLog() error = %v", err)
+		}
+		if _, err := f.WriteString(line); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("log = %q, want both lines", data)
+	}
+}
+
+// TestNotify verifies notifications reach the systemd socket and are
+// skipped outside systemd.
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := Notify(NotifyReady); sent || err != nil {
+		t.Errorf("Notify() without NOTIFY_SOCKET = %v, %v, want false, nil", sent, err)
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram sockets are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+	if sent, err := Notify(NotifyReady); !sent || err != nil {
+		t.Fatalf("Notify() = %v, %v, want true, nil", sent, err)
+	}
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != NotifyReady {
+		t.Errorf("received %q, want %q", got, NotifyReady)
+	}
+}
+
+// TestWatchdogInterval verifies the ping interval is half of
+// WATCHDOG_USEC and only applies to the process systemd names.
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "")
+	t.Setenv("WATCHDOG_PID", "")
+	if got := WatchdogInterval(); got != 0 {
+		t.Errorf("WatchdogInterval() without WATCHDOG_USEC = %v, want 0", got)
+	}
+	t.Setenv("WATCHDOG_USEC", "60000000")
+	if got := WatchdogInterval(); got != 30*time.Second {
+		t.Errorf("WatchdogInterval() = %v, want 30s", got)
+	}
+	t.Setenv("WATCHDOG_PID", fmt.Sprint(os.Getpid()+1))
+	if got := WatchdogInterval(); got != 0 {
+		t.Errorf("WatchdogInterval() for another process = %v, want 0", got)
+	}
+}
+
+// TestVersionPolicy verifies release parsing, ordering and the minimum
+// and mismatch checks
+func TestVersionPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"0.18.3.4", "v0.18.3.4", 0},
+		{"0.18.3.1-release", "0.18.3.4", -1},
+		{"0.18.10.0", "0.18.9.9", 1},
+		{"0.18", "0.18.0.0", 0},
+	} {
+		if got, err := CompareVersions(tt.a, tt.b); err != nil || got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, %v; want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+	if _, err := ParseVersion("latest"); err == nil {
+		t.Error("ParseVersion(latest) expected error")
+	}
+	if err := (VersionPolicy{Minimum: "0.18.x"}).Validate(); err == nil {
+		t.Error("Validate() accepted a malformed minimum")
+	}
+
+	policy := VersionPolicy{Minimum: "0.18.3.1"}
+	if err := policy.Check("0.18.3.4", "0.18.3.4"); err != nil {
+		t.Errorf("Check() of current releases error = %v", err)
+	}
+	if err := policy.Check("", "0.18.2.0"); err == nil {
+		t.Error("Check() accepted a wallet older than the minimum")
+	}
+	if err := policy.Check("0.18.3.4", "0.18.3.3"); err == nil {
+		t.Error("Check() accepted mismatched releases")
+	}
+	policy.AllowMismatch = true
+	if err := policy.Check("0.18.3.4", "0.18.3.3"); err != nil {
+		t.Errorf("Check() with AllowMismatch error = %v", err)
+	}
+}
+
+// TestExecutableVersion verifies the release is read from --version output
+func TestExecutableVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	exe := filepath.Join(t.TempDir(), "monerod")
+	script := "#!/bin/sh\necho \"Monero 'Fluorine Fermi' (v0.18.3.4-release)\"\n"
+	if err := os.WriteFile(exe, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	version, err := ExecutableVersion(context.Background(), exe)
+	if err != nil || version != "0.18.3.4" {
+		t.Errorf("ExecutableVersion() = %q, %v; want 0.18.3.4", version, err)
+	}
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\necho hello\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExecutableVersion(context.Background(), exe); err == nil {
+		t.Error("ExecutableVersion() expected error without a version")
+	}
+}
+
+// TestGRPCConfig verifies the gRPC API requires a token, and TLS off
+// loopback
+func TestGRPCConfig(t *testing.T) {
+	token := strings.Repeat("t", minGRPCTokenLength)
+	for _, tt := range []struct {
+		name    string
+		config  GRPCConfig
+		wantErr bool
+	}{
+		{"disabled", GRPCConfig{}, false},
+		{"loopback", GRPCConfig{Address: "127.0.0.1:18095", Token: token}, false},
+		{"localhost", GRPCConfig{Address: "localhost:18095", Token: token}, false},
+		{"token without address", GRPCConfig{Token: token}, true},
+		{"no port", GRPCConfig{Address: "127.0.0.1", Token: token}, true},
+		{"short token", GRPCConfig{Address: "127.0.0.1:18095", Token: "secret"}, true},
+		{"public without TLS", GRPCConfig{Address: "0.0.0.0:18095", Token: token}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestDockerDriver verifies the docker run command lines, with and
+// without the host network
+func TestDockerDriver(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the docker driver is not supported on Windows")
+	}
+	if err := (DockerConfig{Network: "monero"}).Validate(); err == nil {
+		t.Error("Validate() accepted a network without an image")
+	}
+	if _, ok := (Config{}).Driver().(LocalDriver); !ok {
+		t.Error("Driver() without an image is not the local driver")
+	}
+
+	spec := ProcessSpec{
+		Path:    "/usr/bin/docker",
+		Program: "monerod",
+		Name:    "monerod",
+		Args:    []string{"--data-dir", "/var/lib/monero"},
+		Mounts:  []string{"/var/lib/monero", "", "/var/lib/monero"},
+		Ports:   []int{18081},
+	}
+	host := (Config{DataDir: "/var/lib/monero", Docker: DockerConfig{Image: "monero:latest"}}).Driver()
+	cmd, err := host.Command(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	line := strings.Join(cmd.Args, " ")
+	for _, want := range []string{
+		"/usr/bin/docker run --rm --entrypoint monerod --network host --name moneroger-",
+		"--volume /var/lib/monero:/var/lib/monero monero:latest --data-dir /var/lib/monero",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Command() = %q, want it to contain %q", line, want)
+		}
+	}
+	if strings.Count(line, "--volume") != 1 || strings.Contains(line, "--publish") {
+		t.Errorf("Command() = %q, want one volume and no published ports on the host network", line)
+	}
+	if got := host.BindIP(""); got != "" {
+		t.Errorf("BindIP() on the host network = %q, want it unchanged", got)
+	}
+
+	bridged := NewDockerDriver(DockerConfig{Image: "monero:latest", WalletImage: "wallet:latest", Network: "monero"}, "/var/lib/monero")
+	spec.Program = "monero-wallet-rpc"
+	if cmd, err = bridged.Command(context.Background(), spec); err != nil {
+		t.Fatalf("Command() error = %v", err)
+	}
+	line = strings.Join(cmd.Args, " ")
+	if !strings.Contains(line, "--publish 127.0.0.1:18081:18081") || !strings.Contains(line, " wallet:latest ") {
+		t.Errorf("Command() = %q, want the port published on loopback and the wallet image", line)
+	}
+	if got := bridged.BindIP(""); got != "0.0.0.0" {
+		t.Errorf("BindIP() on a bridge network = %q, want 0.0.0.0", got)
+	}
+	if got, want := bridged.DaemonAddress("127.0.0.1:18081"), bridged.container("monerod")+":18081"; got != want {
+		t.Errorf("DaemonAddress() = %q, want %q", got, want)
+	}
+}
+
+// TestExtraArgs verifies extra arguments may not repeat managed flags
+func TestExtraArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"unmodeled flags", Config{MonerodExtraArgs: []string{"--enable-dns-blocklist"}, WalletRPCExtraArgs: []string{"--max-concurrency=2"}}, false},
+		{"prune", Config{MonerodExtraArgs: []string{"--prune-blockchain"}}, true},
+		{"db sync mode", Config{MonerodExtraArgs: []string{"--db-sync-mode", "safe"}}, true},
+		{"data dir", Config{MonerodExtraArgs: []string{"--data-dir=/tmp"}}, true},
+		{"wallet port", Config{WalletRPCExtraArgs: []string{"--rpc-bind-port", "1"}}, true},
+		{"dns checkpoints without tor", Config{MonerodExtraArgs: []string{"--disable-dns-checkpoints"}}, false},
+		{"dns checkpoints with tor", Config{MonerodExtraArgs: []string{"--disable-dns-checkpoints"}, Tor: TorConfig{Proxy: "127.0.0.1:9050"}}, true},
+		{"extra tx proxy with tor", Config{MonerodExtraArgs: []string{"--tx-proxy", "i2p,127.0.0.1:4447"}, Tor: TorConfig{Proxy: "127.0.0.1:9050"}}, false},
+		{"wallet proxy with tor", Config{WalletRPCExtraArgs: []string{"--proxy", "127.0.0.1:9050"}, Tor: TorConfig{Proxy: "127.0.0.1:9050"}}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := stderrors.Join(tt.config.ValidateMonerodExtraArgs(), tt.config.ValidateWalletRPCExtraArgs())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validation error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
 }
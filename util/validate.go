@@ -0,0 +1,299 @@
+package util
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/opd-ai/moneroger/errors"
+)
+
+// OpValidate is the operation name for configuration validation errors
+const OpValidate errors.Op = "Config.Validate"
+
+// Validate checks the whole configuration before any process is started
+// and reports every problem at once.
+//
+// Returns:
+//   - error: nil if the configuration is usable, otherwise every problem
+//     joined with errors.Join; each is an *errors.Error with
+//     Op OpValidate, KindConfig for invalid settings or KindSystem for an
+//     unwritable data directory
+//
+// Checks:
+//   - Ports are in range and no two services share one
+//   - DataDir is set and writable, or can be created
+//   - Remote node URLs are well formed
+//   - Bind and status addresses, Tor, I2P, bootstrap, version, gRPC,
+//     Docker and TLS settings are valid
+//   - Extra arguments do not repeat flags moneroger sets
+//   - The ban list can be read and holds only addresses and subnets
+//
+// Example:
+//
+//	if err := config.Validate(); err != nil {
+//	    for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+//	        log.Println(e)
+//	    }
+//	}
+func (c Config) Validate() error {
+	var problems []error
+	config := func(format string, args ...interface{}) {
+		problems = append(problems, errors.E(OpValidate, errors.ComponentUtil, errors.KindConfig,
+			fmt.Errorf(format, args...)))
+	}
+
+	c.validatePorts(config)
+	if err := c.Network.Validate(); err != nil {
+		config("%v", err)
+	} else if c.TestNet && c.Network != "" && c.Network != NetworkTestnet {
+		config("testnet is set, but network is %s", c.Network)
+	}
+	if c.DaemonLogLevel < 0 || c.DaemonLogLevel > 4 {
+		config("invalid daemon log level: %d", c.DaemonLogLevel)
+	}
+	if c.DaemonMaxLogFileSize < 0 {
+		config("invalid daemon log file size: %d", c.DaemonMaxLogFileSize)
+	}
+	if c.OutPeers < 0 || c.InPeers < 0 {
+		config("invalid peer limits: out %d, in %d", c.OutPeers, c.InPeers)
+	}
+	if c.LimitRateUp < 0 || c.LimitRateDown < 0 {
+		config("invalid daemon rate limits: up %d, down %d", c.LimitRateUp, c.LimitRateDown)
+	}
+	if c.LimitRate < 0 || c.MaxConcurrency < 0 || c.BlockSyncSize < 0 {
+		config("invalid daemon limits: rate %d, concurrency %d, block sync size %d",
+			c.LimitRate, c.MaxConcurrency, c.BlockSyncSize)
+	}
+	if err := ValidateDBSyncMode(c.DBSyncMode); err != nil {
+		config("%v", err)
+	}
+	if c.PublicNode && len(c.RemoteNodeList()) > 0 {
+		config("a public node needs a local daemon, not a remote node")
+	}
+	if c.PublicNode && c.Offline {
+		config("a public node cannot run offline")
+	}
+	if c.BanList != "" {
+		if _, err := ReadBanList(c.BanList); err != nil {
+			config("%v", err)
+		}
+	}
+	if c.StartupTimeout < 0 || c.ShutdownTimeout < 0 {
+		config("invalid timeouts: startup %v, shutdown %v", c.StartupTimeout, c.ShutdownTimeout)
+	}
+
+	if c.WalletName != "" {
+		if err := ValidateWalletName(c.WalletName); err != nil {
+			config("%v", err)
+		}
+	}
+
+	if c.DataDir == "" {
+		config("data directory cannot be empty")
+	} else if err := checkWritable(c.DataDir); err != nil {
+		problems = append(problems, errors.E(OpValidate, errors.ComponentUtil, errors.KindSystem,
+			fmt.Errorf("data directory %s is not writable: %w", c.DataDir, err)))
+	}
+
+	for _, node := range c.RemoteNodeList() {
+		if _, _, _, err := ParseRemoteNode(node); err != nil {
+			config("invalid remote node %q: %v", node, err)
+		}
+	}
+
+	if err := ValidateBindIP(c.MoneroBindIP); err != nil {
+		config("daemon: %v", err)
+	}
+	if err := ValidateBindIP(c.WalletBindIP); err != nil {
+		config("wallet: %v", err)
+	}
+	if c.HWDevice.Enabled() && c.Docker.Enabled() {
+		config("hardware wallets need host processes, not the Docker driver")
+	}
+	if err := c.validateStatusServer(); err != nil {
+		config("%v", err)
+	}
+	for _, check := range []func() error{
+		c.Tor.Validate,
+		c.I2P.Validate,
+		c.Bootstrap.Validate,
+		c.DiskWatch.Validate,
+		c.HWDevice.Validate,
+		c.Versions.Validate,
+		c.GRPC.Validate,
+		c.AutoPortRange.Validate,
+		c.Docker.Validate,
+		c.ValidateMonerodExtraArgs,
+		c.ValidateWalletRPCExtraArgs,
+		c.DaemonTLS.Validate,
+		c.WalletTLS.Validate,
+	} {
+		if err := check(); err != nil {
+			config("%v", err)
+		}
+	}
+
+	return stderrors.Join(problems...)
+}
+
+// validateStatusServer checks the status server's address, token and TLS
+// settings. The server exposes the manager's state, so only loopback
+// addresses may serve it without a token or TLS.
+//
+// Returns:
+//   - error: Description of the first problem, nil if valid
+func (c Config) validateStatusServer() error {
+	if c.StatusAddress == "" {
+		if c.StatusToken != "" || c.StatusTLS.Enabled() {
+			return fmt.Errorf("status token or TLS given without a status address")
+		}
+		return nil
+	}
+	if err := validateHostPort(c.StatusAddress); err != nil {
+		return fmt.Errorf("invalid status address %q: %w", c.StatusAddress, err)
+	}
+	if c.StatusToken != "" && len(c.StatusToken) < minGRPCTokenLength {
+		return fmt.Errorf("status token must be at least %d characters", minGRPCTokenLength)
+	}
+	if err := c.StatusTLS.Validate(); err != nil {
+		return fmt.Errorf("status server: %w", err)
+	}
+	if c.StatusToken == "" && !c.StatusTLS.Enabled() && !isLoopbackAddress(c.StatusAddress) {
+		return fmt.Errorf("status address %s is not loopback and requires a status token or TLS", c.StatusAddress)
+	}
+	return nil
+}
+
+// validatePorts reports out-of-range ports and ports claimed by more than
+// one service.
+func (c Config) validatePorts(report func(format string, args ...interface{})) {
+	type port struct {
+		name     string
+		value    int
+		optional bool
+	}
+	ports := []port{
+		{name: "daemon RPC", value: c.EffectiveMoneroPort()},
+		{name: "wallet RPC", value: c.EffectiveWalletPort()},
+		{name: "ZMQ publisher", value: c.ZMQPubPort, optional: true},
+		{name: "restricted RPC", value: c.EffectiveRestrictedPort(), optional: true},
+	}
+	if c.Tor.OnionAddress != "" {
+		ports = append(ports, port{name: "Tor inbound", value: orDefault(c.Tor.InboundPort, DefaultTorInboundPort)})
+	}
+	if c.I2P.Address != "" {
+		ports = append(ports, port{name: "I2P inbound", value: orDefault(c.I2P.InboundPort, DefaultI2PInboundPort)})
+	}
+	for _, server := range []struct{ name, addr string }{
+		{"status server", c.StatusAddress},
+		{"gRPC API", c.GRPC.Address},
+	} {
+		if _, p, err := net.SplitHostPort(server.addr); err == nil {
+			if n, err := strconv.Atoi(p); err == nil {
+				ports = append(ports, port{name: server.name, value: n})
+			}
+		}
+	}
+
+	owner := make(map[int]string)
+	for _, p := range ports {
+		if p.optional && p.value == 0 {
+			continue
+		}
+		if p.value < 1 || p.value > 65535 {
+			report("invalid %s port: %d", p.name, p.value)
+			continue
+		}
+		if other, taken := owner[p.value]; taken {
+			report("%s port %d is already used by the %s", p.name, p.value, other)
+			continue
+		}
+		owner[p.value] = p.name
+	}
+}
+
+// ValidateDBSyncMode checks a monerod --db-sync-mode value of the form
+// safe|fast|fastest[:sync|async[:<n>[blocks|bytes]]].
+//
+// Parameters:
+//   - mode: The value, empty for monerod's default
+//
+// Returns:
+//   - error: Description of the problem, nil if valid
+func ValidateDBSyncMode(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	parts := strings.Split(mode, ":")
+	if len(parts) > 3 {
+		return fmt.Errorf("invalid db sync mode %q: too many fields", mode)
+	}
+	switch parts[0] {
+	case "safe", "fast", "fastest":
+	default:
+		return fmt.Errorf("invalid db sync mode %q: must start with safe, fast or fastest", mode)
+	}
+	if len(parts) > 1 && parts[1] != "sync" && parts[1] != "async" {
+		return fmt.Errorf("invalid db sync mode %q: second field must be sync or async", mode)
+	}
+	if len(parts) > 2 {
+		n := strings.TrimSuffix(strings.TrimSuffix(parts[2], "blocks"), "bytes")
+		if v, err := strconv.ParseUint(n, 10, 64); err != nil || v == 0 {
+			return fmt.Errorf("invalid db sync mode %q: third field must be a positive count of blocks or bytes", mode)
+		}
+	}
+	return nil
+}
+
+// ValidateWalletName rejects wallet names that monero-wallet-rpc would
+// resolve outside its --wallet-dir.
+//
+// Parameters:
+//   - name: Wallet file name, without directory
+//
+// Returns:
+//   - error: Description of the problem, nil if valid
+func ValidateWalletName(name string) error {
+	if name == "" {
+		return fmt.Errorf("wallet name cannot be empty")
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("wallet name %q must be a file name, not a path", name)
+	}
+	return nil
+}
+
+// orDefault returns v, or def when v is 0.
+func orDefault(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// checkWritable verifies that files can be created in dir, or in its
+// nearest existing ancestor when dir does not exist yet.
+func checkWritable(dir string) error {
+	for !DirExists(dir) {
+		if FileExists(dir) {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("no existing parent directory")
+		}
+		dir = parent
+	}
+	f, err := os.CreateTemp(dir, ".moneroger-write-test-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMinimumVersion is the oldest Monero release RecommendConfig
+// accepts, the same as for remote nodes.
+const DefaultMinimumVersion = "0.18.0.0"
+
+// versionOutput finds the release in "--version" output such as
+// "Monero 'Fluorine Fermi' (v0.18.3.4-release)"
+var versionOutput = regexp.MustCompile(`\bv(\d+(?:\.\d+)+)`)
+
+// VersionPolicy controls the startup checks of the versions of monerod
+// and monero-wallet-rpc. The zero value accepts any release, but
+// refuses executables from different releases.
+//
+// Fields:
+//   - Minimum: Oldest acceptable release, such as "0.18.3.1"; empty
+//     accepts any
+//   - AllowMismatch: Accept monerod and monero-wallet-rpc from different
+//     releases
+//   - WarnOnly: Log version problems instead of refusing to start
+type VersionPolicy struct {
+	Minimum       string
+	AllowMismatch bool
+	WarnOnly      bool
+}
+
+// Validate checks that Minimum is a version.
+//
+// Returns:
+//   - error: Description of a malformed minimum, nil if valid
+func (p VersionPolicy) Validate() error {
+	if p.Minimum == "" {
+		return nil
+	}
+	if _, err := ParseVersion(p.Minimum); err != nil {
+		return fmt.Errorf("invalid minimum version: %w", err)
+	}
+	return nil
+}
+
+// Check applies the policy to the releases of the executables, ignoring
+// WarnOnly so the caller decides how to report the problems.
+//
+// Parameters:
+//   - daemon: monerod release, empty when no local daemon is used
+//   - wallet: monero-wallet-rpc release
+//
+// Returns:
+//   - error: Every problem found, joined with errors.Join; nil if the
+//     releases are acceptable
+func (p VersionPolicy) Check(daemon, wallet string) error {
+	var problems []error
+	if p.Minimum != "" {
+		for _, exe := range []struct{ name, version string }{
+			{"monerod", daemon},
+			{"monero-wallet-rpc", wallet},
+		} {
+			if exe.version == "" {
+				continue
+			}
+			older, err := CompareVersions(exe.version, p.Minimum)
+			if err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", exe.name, err))
+			} else if older < 0 {
+				problems = append(problems, fmt.Errorf("%s %s is older than the required %s", exe.name, exe.version, p.Minimum))
+			}
+		}
+	}
+	if !p.AllowMismatch && daemon != "" && wallet != "" {
+		if same, err := CompareVersions(daemon, wallet); err == nil && same != 0 {
+			problems = append(problems, fmt.Errorf("monerod %s and monero-wallet-rpc %s are from different releases", daemon, wallet))
+		}
+	}
+	return stderrors.Join(problems...)
+}
+
+// ParseVersion splits a Monero release into its numbers.
+//
+// Parameters:
+//   - version: Release such as "0.18.3.4", "v0.18.3.4" or
+//     "0.18.3.4-release"
+//
+// Returns:
+//   - []int: The dotted numbers, such as [0 18 3 4]
+//   - error: If the version is not dotted numbers
+func ParseVersion(version string) ([]int, error) {
+	trimmed := strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(trimmed, "-+ "); i >= 0 {
+		trimmed = trimmed[:i]
+	}
+	var parts []int
+	for _, field := range strings.Split(trimmed, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("malformed version %q", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+// CompareVersions orders two Monero releases, treating missing numbers
+// as 0 so "0.18" equals "0.18.0.0".
+//
+// Returns:
+//   - int: -1 if a is older than b, 0 if equal, 1 if newer
+//   - error: If either version is malformed
+func CompareVersions(a, b string) (int, error) {
+	x, err := ParseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := ParseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(x) || i < len(y); i++ {
+		var m, n int
+		if i < len(x) {
+			m = x[i]
+		}
+		if i < len(y) {
+			n = y[i]
+		}
+		if m != n {
+			if m < n {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// ExecutableVersion runs a Monero executable with --version.
+//
+// Parameters:
+//   - ctx: Context bounding the run
+//   - path: monerod or monero-wallet-rpc executable
+//
+// Returns:
+//   - string: Release such as "0.18.3.4"
+//   - error: If the executable fails or prints no version
+//
+// Related:
+//   - CommandVersion for executables run through a Driver
+func ExecutableVersion(ctx context.Context, path string) (string, error) {
+	return CommandVersion(exec.CommandContext(ctx, path, "--version"))
+}
+
+// CommandVersion runs a command printing a Monero release, such as one
+// built by Driver.Command with "--version" as the arguments.
+//
+// Parameters:
+//   - cmd: The unstarted command
+//
+// Returns:
+//   - string: Release such as "0.18.3.4"
+//   - error: If the command fails or prints no version
+func CommandVersion(cmd *exec.Cmd) (string, error) {
+	PrepareCommand(cmd)
+	line := strings.Join(cmd.Args, " ")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", line, err)
+	}
+	m := versionOutput.FindSubmatch(out)
+	if m == nil {
+		return "", fmt.Errorf("%s printed no version: %q", line, bytes.TrimSpace(out))
+	}
+	return string(m[1]), nil
+}
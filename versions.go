@@ -0,0 +1,98 @@
+package moneroger
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/monerod"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// OpCheckVersions is the operation name for errors from version checks
+const OpCheckVersions errors.Op = "CheckVersions"
+
+// versionTimeout bounds each "--version" run
+const versionTimeout = 30 * time.Second
+
+// Versions are the releases of the Monero executables.
+//
+// Fields:
+//   - Daemon: monerod release such as "0.18.3.4", empty when remote
+//     nodes are used
+//   - Wallet: monero-wallet-rpc release
+type Versions struct {
+	Daemon string `json:"daemon,omitempty"`
+	Wallet string `json:"wallet,omitempty"`
+}
+
+// DetectVersions runs the executables the services would be started
+// from with --version, in containers when the configuration selects the
+// Docker driver.
+//
+// Parameters:
+//   - ctx: Context bounding the runs
+//   - config: Configuration deciding whether monerod is used
+//
+// Returns:
+//   - Versions: The releases found
+//   - error: KindSystem if an executable is missing or prints no version
+func DetectVersions(ctx context.Context, config util.Config) (Versions, error) {
+	var versions Versions
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+	driver := config.Driver()
+	if len(config.RemoteNodeList()) == 0 {
+		version, err := programVersion(ctx, driver, "monerod", monerod.MoneroDPath)
+		if err != nil {
+			return versions, errors.E(OpCheckVersions, errors.ComponentMonerod, errors.KindSystem, err)
+		}
+		versions.Daemon = version
+	}
+	version, err := programVersion(ctx, driver, "monero-wallet-rpc", monerowalletrpc.MoneroWalletRPCPath)
+	if err != nil {
+		return versions, errors.E(OpCheckVersions, errors.ComponentWalletRPC, errors.KindSystem, err)
+	}
+	versions.Wallet = version
+	return versions, nil
+}
+
+// programVersion runs a Monero program with --version through the
+// driver the services are started with.
+func programVersion(ctx context.Context, driver util.Driver, program string, find func() (string, error)) (string, error) {
+	path, err := driver.Executable(find)
+	if err != nil {
+		return "", err
+	}
+	cmd, err := driver.Command(ctx, util.ProcessSpec{Path: path, Program: program, Args: []string{"--version"}})
+	if err != nil {
+		return "", err
+	}
+	return util.CommandVersion(cmd)
+}
+
+// checkVersions detects the releases of the executables and applies
+// config.Versions, logging problems instead when WarnOnly is set.
+func checkVersions(ctx context.Context, config util.Config, logger *slog.Logger) (Versions, error) {
+	versions, err := DetectVersions(ctx, config)
+	if err != nil {
+		return versions, err
+	}
+	logger.Info("found Monero executables", "monerod", versions.Daemon, "monero-wallet-rpc", versions.Wallet)
+	if err := config.Versions.Check(versions.Daemon, versions.Wallet); err != nil {
+		if !config.Versions.WarnOnly {
+			return versions, errors.E(OpCheckVersions, errors.KindConfig, err)
+		}
+		logger.Warn("unsupported Monero executables", "error", err)
+	}
+	return versions, nil
+}
+
+// Versions returns the releases of the executables found when the
+// manager started the services. It is empty for managers returned by
+// Attach, whose services may predate the executables on disk.
+func (m *Moneroger) Versions() Versions {
+	return m.versions
+}
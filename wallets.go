@@ -0,0 +1,303 @@
+package moneroger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/opd-ai/moneroger/errors"
+	monerowalletrpc "github.com/opd-ai/moneroger/monero-wallet-rpc"
+	"github.com/opd-ai/moneroger/util"
+)
+
+// DefaultWalletName is the name of the wallet started by NewMoneroger.
+const DefaultWalletName = "default"
+
+const (
+	// OpAddWallet is the operation name for errors from AddWallet
+	OpAddWallet errors.Op = "AddWallet"
+
+	// OpRemoveWallet is the operation name for errors from RemoveWallet
+	OpRemoveWallet errors.Op = "RemoveWallet"
+
+	// OpWallet is the operation name for errors from Wallet
+	OpWallet errors.Op = "Wallet"
+)
+
+// WalletConfig describes an additional wallet-rpc process managed next to
+// the default wallet. Settings not listed here, such as the network and
+// restart policy, are inherited from the manager's util.Config.
+//
+// Fields:
+//   - Name: Unique identifier used with RemoveWallet and Wallet
+//   - WalletDir: Directory holding this wallet's files, required
+//   - Port: RPC port for this wallet-rpc process
+//   - RPCUser, RPCPass: Credentials, generated when empty
+//   - Output: Optional writer receiving the process output
+type WalletConfig struct {
+	Name      string
+	WalletDir string
+	Port      int
+	RPCUser   string
+	RPCPass   string
+	Output    io.Writer
+}
+
+// WalletInfo is a snapshot of the state of one managed wallet.
+//
+// Fields:
+//   - Name: Wallet name, DefaultWalletName for the default wallet
+//   - Port: RPC port of the wallet-rpc process
+//   - PID: Process ID, "-1" if not running
+//   - Alive: Whether the process is running
+//   - Uptime: How long the current process has been running
+//   - Wallet: Wallet file open in the process, empty when none is
+type WalletInfo struct {
+	Name   string
+	Port   int
+	PID    string
+	Alive  bool
+	Uptime time.Duration
+	Wallet string
+}
+
+// managedWallet is an additional wallet together with the settings it was
+// added with and its supervisor.
+type managedWallet struct {
+	rpc *monerowalletrpc.WalletRPC
+	cfg WalletConfig
+	sup *supervision
+}
+
+// AddWallet starts an additional wallet-rpc process against the managed
+// daemon and supervises it like the default wallet.
+//
+// Parameters:
+//   - ctx: Context bounding the startup only; the wallet keeps running
+//     after it ends, until RemoveWallet or Shutdown
+//   - cfg: Name, directory, port and credentials of the new wallet
+//
+// Returns:
+//   - *monerowalletrpc.WalletRPC: The started wallet
+//   - error: KindConfig for a missing or duplicate name, a missing
+//     directory or after Shutdown, KindTimeout when ctx ended before the
+//     wallet was ready, otherwise any startup error
+//
+// Related:
+//   - RemoveWallet
+//   - Wallets
+func (m *Moneroger) AddWallet(ctx context.Context, cfg WalletConfig) (*monerowalletrpc.WalletRPC, error) {
+	if cfg.Name == "" {
+		return nil, errors.E(OpAddWallet, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("wallet name cannot be empty"))
+	}
+	if cfg.WalletDir == "" {
+		// The network's data directory belongs to the default wallet
+		return nil, errors.E(OpAddWallet, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("wallet %s needs its own wallet directory", cfg.Name))
+	}
+	// A reload must not miss a wallet that is still starting
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	if err := m.reserveWallet(cfg.Name); err != nil {
+		return nil, err
+	}
+
+	// The process is bound to startCtx, which only ends with ctx while
+	// the wallet is starting, as in NewMonerogerContext
+	startCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+	wallet, err := monerowalletrpc.NewWalletRPC(startCtx, m.walletConfig(m.currentConfig(), cfg), m.monerod)
+	if err != nil {
+		stop()
+		m.releaseWallet(cfg.Name)
+		return nil, startupError(ctx, err)
+	}
+	if !stop() {
+		// ctx ended just as the wallet became ready
+		_ = wallet.Shutdown(context.WithoutCancel(ctx))
+		m.releaseWallet(cfg.Name)
+		return nil, startupError(ctx, ctx.Err())
+	}
+
+	m.walletsMu.Lock()
+	if m.walletsClosed {
+		m.walletsMu.Unlock()
+		_ = wallet.Shutdown(ctx)
+		return nil, errors.E(OpAddWallet, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("manager is shut down"))
+	}
+	m.wallets[cfg.Name] = &managedWallet{
+		rpc: wallet,
+		cfg: cfg,
+		sup: m.superviseWallet(m.backgroundContext(), cfg.Name, wallet),
+	}
+	m.walletsMu.Unlock()
+
+	m.emitWallet(EventWalletReady, cfg.Name, nil)
+	return wallet, nil
+}
+
+// walletConfig derives the configuration of an additional wallet from the
+// manager's configuration.
+func (m *Moneroger) walletConfig(config util.Config, cfg WalletConfig) util.Config {
+	config.WalletDir, config.WalletFile = cfg.WalletDir, ""
+	// The default wallet's file is not in this wallet's directory
+	config.WalletName = ""
+	config.WalletPort = cfg.Port
+	config.WalletRPCUser = cfg.RPCUser
+	config.WalletRPCPass = cfg.RPCPass
+	config.WalletOutput = cfg.Output
+	config.Logger = m.logger().With("wallet", cfg.Name)
+	return config
+}
+
+// RemoveWallet stops supervision of an additional wallet and shuts its
+// process down.
+//
+// Parameters:
+//   - ctx: Context bounding the shutdown
+//   - name: Name passed to AddWallet
+//
+// Returns:
+//   - error: KindConfig for unknown names or the default wallet,
+//     otherwise any shutdown error
+func (m *Moneroger) RemoveWallet(ctx context.Context, name string) error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	m.walletsMu.Lock()
+	w, ok := m.wallets[name]
+	if ok && w != nil {
+		delete(m.wallets, name)
+	}
+	m.walletsMu.Unlock()
+
+	if !ok || w == nil {
+		return errors.E(OpRemoveWallet, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("no removable wallet named %q", name))
+	}
+	w.sup.stop()
+	return w.rpc.Shutdown(ctx)
+}
+
+// Wallet returns a managed wallet by name. DefaultWalletName returns the
+// wallet started by NewMoneroger.
+//
+// Parameters:
+//   - name: Wallet name
+//
+// Returns:
+//   - *monerowalletrpc.WalletRPC: The wallet, for clients, health and logs
+//   - error: KindConfig if no wallet has that name
+func (m *Moneroger) Wallet(name string) (*monerowalletrpc.WalletRPC, error) {
+	if name == DefaultWalletName {
+		return m.monerowalletrpc, nil
+	}
+	m.walletsMu.RLock()
+	defer m.walletsMu.RUnlock()
+	if w := m.wallets[name]; w != nil {
+		return w.rpc, nil
+	}
+	return nil, errors.E(OpWallet, errors.ComponentWalletRPC, errors.KindConfig,
+		fmt.Errorf("no wallet named %q", name))
+}
+
+// Wallets reports the state of every managed wallet, the default wallet
+// first and the rest sorted by name.
+//
+// Returns:
+//   - []WalletInfo: One entry per wallet
+func (m *Moneroger) Wallets() []WalletInfo {
+	infos := []WalletInfo{walletInfo(DefaultWalletName, m.monerowalletrpc)}
+
+	m.walletsMu.RLock()
+	names := make([]string, 0, len(m.wallets))
+	for name, w := range m.wallets {
+		if w != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		infos = append(infos, walletInfo(name, m.wallets[name].rpc))
+	}
+	m.walletsMu.RUnlock()
+	return infos
+}
+
+// walletInfo builds a state snapshot for one wallet.
+func walletInfo(name string, w *monerowalletrpc.WalletRPC) WalletInfo {
+	return WalletInfo{
+		Name:   name,
+		Port:   w.WalletRPCPort(),
+		PID:    w.PID(),
+		Alive:  w.Alive(),
+		Uptime: w.Uptime(),
+		Wallet: w.CurrentWallet(),
+	}
+}
+
+// reserveWallet claims name for a wallet that is being started, so
+// concurrent AddWallet calls cannot use the same name.
+func (m *Moneroger) reserveWallet(name string) error {
+	m.walletsMu.Lock()
+	defer m.walletsMu.Unlock()
+	if m.walletsClosed {
+		return errors.E(OpAddWallet, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("manager is shut down"))
+	}
+	if _, taken := m.wallets[name]; taken || name == DefaultWalletName {
+		return errors.E(OpAddWallet, errors.ComponentWalletRPC, errors.KindConfig,
+			fmt.Errorf("wallet %q already exists", name))
+	}
+	if m.wallets == nil {
+		m.wallets = make(map[string]*managedWallet)
+	}
+	// A nil entry marks a wallet that is still starting
+	m.wallets[name] = nil
+	return nil
+}
+
+// releaseWallet drops a reservation made by reserveWallet.
+func (m *Moneroger) releaseWallet(name string) {
+	m.walletsMu.Lock()
+	defer m.walletsMu.Unlock()
+	delete(m.wallets, name)
+}
+
+// eachWallet calls fn for the default wallet and every additional wallet.
+func (m *Moneroger) eachWallet(fn func(name string, w *monerowalletrpc.WalletRPC)) {
+	fn(DefaultWalletName, m.monerowalletrpc)
+	m.walletsMu.RLock()
+	extra := make(map[string]*monerowalletrpc.WalletRPC, len(m.wallets))
+	for name, w := range m.wallets {
+		if w != nil {
+			extra[name] = w.rpc
+		}
+	}
+	m.walletsMu.RUnlock()
+	for name, w := range extra {
+		fn(name, w)
+	}
+}
+
+// closeWallets prevents new wallets from being added and stops the
+// supervision of the additional ones, returning them for shutdown.
+func (m *Moneroger) closeWallets() map[string]*managedWallet {
+	m.walletsMu.Lock()
+	m.walletsClosed = true
+	wallets := m.wallets
+	m.wallets = nil
+	m.walletsMu.Unlock()
+
+	for name, w := range wallets {
+		if w == nil {
+			delete(wallets, name)
+			continue
+		}
+		w.sup.stop()
+	}
+	return wallets
+}
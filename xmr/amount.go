@@ -0,0 +1,220 @@
+// Package xmr provides an exact Monero amount type, so amounts are
+// parsed, formatted and added without float rounding.
+package xmr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// PiconeroPerXMR is the number of piconero in one XMR.
+const PiconeroPerXMR = 1_000_000_000_000
+
+// decimals is the number of decimal places of XMR amounts
+const decimals = 12
+
+// ErrOverflow is returned by arithmetic whose result does not fit in an
+// Amount, above about 18.4 million XMR.
+var ErrOverflow = errors.New("amount overflows")
+
+// ErrNegative is returned by Sub when the result would be below zero.
+var ErrNegative = errors.New("amount would be negative")
+
+// Amount is an amount of Monero in its smallest unit, 1e-12 XMR, as
+// used by every RPC method.
+//
+// An Amount marshals to JSON as an integer number of piconero, the form
+// monerod and wallet-rpc use, and unmarshals from an integer or from a
+// string holding one, for clients that cannot represent large integers
+// exactly.
+type Amount uint64
+
+// Max is the largest Amount, about 18.4 million XMR.
+const Max Amount = math.MaxUint64
+
+// ParseXMR parses a decimal XMR amount such as "1.5", "0.000000000001"
+// or "12".
+//
+// Parameters:
+//   - s: Amount in XMR, with at most twelve decimals and no sign,
+//     exponent or thousands separators
+//
+// Returns:
+//   - Amount: The exact amount
+//   - error: If s is malformed, has more than twelve decimals, or
+//     overflows
+func ParseXMR(s string) (Amount, error) {
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if (whole == "" && frac == "") || (hasPoint && frac == "") || !digitsOnly(whole) || !digitsOnly(frac) {
+		return 0, fmt.Errorf("invalid XMR amount %q", s)
+	}
+	if len(frac) > decimals {
+		return 0, fmt.Errorf("XMR amount %q has more than %d decimals", s, decimals)
+	}
+	var w, f uint64
+	var err error
+	if whole != "" {
+		if w, err = strconv.ParseUint(whole, 10, 64); err != nil {
+			return 0, fmt.Errorf("XMR amount %q: %w", s, ErrOverflow)
+		}
+	}
+	if frac != "" {
+		// Pad to twelve digits, so "5" means 500000000000 piconero
+		f, _ = strconv.ParseUint(frac+strings.Repeat("0", decimals-len(frac)), 10, 64)
+	}
+	hi, lo := bits.Mul64(w, PiconeroPerXMR)
+	sum, carry := bits.Add64(lo, f, 0)
+	if hi != 0 || carry != 0 {
+		return 0, fmt.Errorf("XMR amount %q: %w", s, ErrOverflow)
+	}
+	return Amount(sum), nil
+}
+
+// MustParseXMR is ParseXMR for constants, panicking on invalid input.
+func MustParseXMR(s string) Amount {
+	a, err := ParseXMR(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// digitsOnly reports whether s holds nothing but ASCII digits.
+func digitsOnly(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount in XMR with all twelve decimals, such as
+// "1.500000000000".
+func (a Amount) String() string {
+	return fmt.Sprintf("%d.%012d", uint64(a)/PiconeroPerXMR, uint64(a)%PiconeroPerXMR)
+}
+
+// Short formats the amount in XMR without trailing zeros, such as "1.5"
+// or "2". ParseXMR reads it back exactly.
+func (a Amount) Short() string {
+	s := strings.TrimRight(a.String(), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// XMR returns the amount in XMR. The float loses precision for large
+// amounts; use it for display, not arithmetic.
+func (a Amount) XMR() float64 {
+	return float64(a) / PiconeroPerXMR
+}
+
+// Add returns a + b.
+//
+// Returns:
+//   - Amount: The sum
+//   - error: ErrOverflow if it does not fit
+func (a Amount) Add(b Amount) (Amount, error) {
+	sum, carry := bits.Add64(uint64(a), uint64(b), 0)
+	if carry != 0 {
+		return 0, ErrOverflow
+	}
+	return Amount(sum), nil
+}
+
+// Sub returns a - b.
+//
+// Returns:
+//   - Amount: The difference
+//   - error: ErrNegative if b is larger than a
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b > a {
+		return 0, ErrNegative
+	}
+	return a - b, nil
+}
+
+// Mul returns a * n, such as the price of n items.
+//
+// Returns:
+//   - Amount: The product
+//   - error: ErrOverflow if it does not fit
+func (a Amount) Mul(n uint64) (Amount, error) {
+	hi, lo := bits.Mul64(uint64(a), n)
+	if hi != 0 {
+		return 0, ErrOverflow
+	}
+	return Amount(lo), nil
+}
+
+// Split divides a into n shares that differ by at most one piconero and
+// add up to a exactly, the first ones taking the remainder.
+//
+// Parameters:
+//   - n: Number of shares, at least 1
+//
+// Returns:
+//   - []Amount: The shares, nil when n is 0
+func (a Amount) Split(n int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+	share, rest := uint64(a)/uint64(n), uint64(a)%uint64(n)
+	shares := make([]Amount, n)
+	for i := range shares {
+		shares[i] = Amount(share)
+		if uint64(i) < rest {
+			shares[i]++
+		}
+	}
+	return shares
+}
+
+// Sum adds amounts.
+//
+// Returns:
+//   - Amount: The total
+//   - error: ErrOverflow if it does not fit
+func Sum(amounts ...Amount) (Amount, error) {
+	var total Amount
+	for _, a := range amounts {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// MarshalJSON encodes the amount as an integer number of piconero.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(a), 10), nil
+}
+
+// UnmarshalJSON decodes an integer number of piconero, bare or quoted.
+// Fractions, exponents and negative numbers are rejected rather than
+// rounded.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	if s == "" || !digitsOnly(s) {
+		return fmt.Errorf("invalid piconero amount %s", data)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("piconero amount %s: %w", data, ErrOverflow)
+	}
+	*a = Amount(n)
+	return nil
+}
@@ -0,0 +1,99 @@
+package xmr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestAmount covers parsing and formatting of XMR strings, checked
+// arithmetic, and JSON encoding
+func TestAmount(t *testing.T) {
+	parses := []struct {
+		in   string
+		want Amount
+	}{
+		{"0", 0},
+		{"1", PiconeroPerXMR},
+		{"1.5", 1_500_000_000_000},
+		{".25", 250_000_000_000},
+		{"0.000000000001", 1},
+		{"18446744.073709551615", Max},
+	}
+	for _, tt := range parses {
+		got, err := ParseXMR(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseXMR(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+		if back, err := ParseXMR(got.Short()); err != nil || back != got {
+			t.Errorf("ParseXMR(%q) = %d, %v, want round trip of %d", got.Short(), back, err, got)
+		}
+	}
+	for _, in := range []string{"", ".", "1.", "-1", "+1", "1e3", "1,000", " 1", "0.0000000000001"} {
+		if _, err := ParseXMR(in); err == nil {
+			t.Errorf("ParseXMR(%q) succeeded, want error", in)
+		}
+	}
+	for _, in := range []string{"18446744.073709551616", "99999999999999999999"} {
+		if _, err := ParseXMR(in); !errors.Is(err, ErrOverflow) {
+			t.Errorf("ParseXMR(%q) error = %v, want ErrOverflow", in, err)
+		}
+	}
+
+	a := MustParseXMR("1.5")
+	if a.String() != "1.500000000000" || a.Short() != "1.5" || Amount(2*PiconeroPerXMR).Short() != "2" || a.XMR() != 1.5 {
+		t.Errorf("formatting = %s, %s, %v", a, a.Short(), a.XMR())
+	}
+
+	if sum, err := a.Add(1); err != nil || sum != 1_500_000_000_001 {
+		t.Errorf("Add() = %d, %v", sum, err)
+	}
+	if _, err := Max.Add(1); err != ErrOverflow {
+		t.Errorf("Max.Add(1) error = %v, want ErrOverflow", err)
+	}
+	if diff, err := a.Sub(a); err != nil || diff != 0 {
+		t.Errorf("Sub() = %d, %v", diff, err)
+	}
+	if _, err := Amount(1).Sub(2); err != ErrNegative {
+		t.Errorf("Sub() below zero error = %v, want ErrNegative", err)
+	}
+	if prod, err := a.Mul(3); err != nil || prod != MustParseXMR("4.5") {
+		t.Errorf("Mul() = %d, %v", prod, err)
+	}
+	if _, err := Max.Mul(2); err != ErrOverflow {
+		t.Errorf("Max.Mul(2) error = %v, want ErrOverflow", err)
+	}
+	if total, err := Sum(1, 2, 3); err != nil || total != 6 {
+		t.Errorf("Sum() = %d, %v", total, err)
+	}
+	if _, err := Sum(Max, 1); err != ErrOverflow {
+		t.Errorf("Sum() error = %v, want ErrOverflow", err)
+	}
+	shares := Amount(10).Split(3)
+	if len(shares) != 3 || shares[0] != 4 || shares[1] != 3 || shares[2] != 3 || Amount(10).Split(0) != nil {
+		t.Errorf("Split() = %v", shares)
+	}
+
+	var v struct {
+		Amount Amount `json:"amount"`
+	}
+	data, _ := json.Marshal(struct{ Amount Amount }{Max})
+	if string(data) != `{"Amount":18446744073709551615}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+	for in, want := range map[string]Amount{
+		`{"amount":18446744073709551615}`: Max,
+		`{"amount":"1500"}`:               1500,
+		`{"amount":null}`:                 0,
+	} {
+		v.Amount = 0
+		if err := json.Unmarshal([]byte(in), &v); err != nil || v.Amount != want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", in, v.Amount, err, want)
+		}
+	}
+	for _, in := range []string{`{"amount":1.5}`, `{"amount":-1}`, `{"amount":1e3}`, `{"amount":""}`, `{"amount":18446744073709551616}`} {
+		if err := json.Unmarshal([]byte(in), &v); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", in)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package zmq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// TopicChainMain is published by monerod for every new main-chain block
+	TopicChainMain = "json-minimal-chain_main"
+
+	// TopicTxPoolAdd is published by monerod when transactions enter the pool
+	TopicTxPoolAdd = "json-minimal-txpool_add"
+
+	// notificationBuffer is the capacity of each notification channel
+	notificationBuffer = 64
+
+	// reconnectInterval is the pause between connection attempts
+	reconnectInterval = time.Second
+)
+
+// Block is a new main-chain block announced by monerod.
+//
+// Fields:
+//   - Height: Height of the block
+//   - ID: Block hash, hex encoded
+type Block struct {
+	Height uint64
+	ID     string
+}
+
+// Tx is a transaction that entered monerod's transaction pool.
+//
+// Fields:
+//   - ID: Transaction hash, hex encoded
+//   - BlobSize: Serialized size in bytes
+//   - Weight: Transaction weight used for fee calculation
+//   - Fee: Fee in atomic units
+type Tx struct {
+	ID       string `json:"id"`
+	BlobSize uint64 `json:"blob_size"`
+	Weight   uint64 `json:"weight"`
+	Fee      uint64 `json:"fee"`
+}
+
+// chainMain is the body of a json-minimal-chain_main message. A single
+// message covers several blocks after a reorganization or a batch sync.
+type chainMain struct {
+	FirstHeight uint64   `json:"first_height"`
+	FirstPrevID string   `json:"first_prev_id"`
+	IDs         []string `json:"ids"`
+}
+
+// Notifications delivers monerod's block and transaction pool events. It
+// reconnects on its own when the publisher goes away, for example while
+// monerod restarts.
+//
+// Fields:
+//   - Blocks: New main-chain blocks, in height order
+//   - Txs: Transactions entering the pool
+//   - dropped: Notifications discarded because a channel was full
+//   - err: Most recent connection or decoding error
+//
+// Both channels are buffered and closed once the watch context is done.
+// Like a ZMQ PUB socket, Notifications never blocks on a slow reader:
+// events that do not fit in a full channel are dropped and counted, so
+// a consumer that only reads Blocks cannot stall block delivery.
+type Notifications struct {
+	Blocks <-chan Block
+	Txs    <-chan Tx
+
+	blocks  chan Block
+	txs     chan Tx
+	dropped atomic.Uint64
+	errMu   sync.Mutex
+	err     error
+}
+
+// Watch subscribes to block and transaction pool notifications on a
+// monerod --zmq-pub endpoint.
+//
+// Parameters:
+//   - ctx: Context ending the subscription
+//   - endpoint: Publisher address, such as "tcp://127.0.0.1:18086"
+//
+// Returns:
+//   - *Notifications: Event channels, open until ctx is done
+//
+// Watch does not fail when the publisher is unreachable; it keeps
+// retrying and reports the latest failure through Err.
+func Watch(ctx context.Context, endpoint string) *Notifications {
+	n := &Notifications{
+		blocks: make(chan Block, notificationBuffer),
+		txs:    make(chan Tx, notificationBuffer),
+	}
+	n.Blocks, n.Txs = n.blocks, n.txs
+	go n.run(ctx, endpoint)
+	return n
+}
+
+// Err returns the most recent connection or decoding error, or nil while
+// connected and receiving well-formed messages.
+func (n *Notifications) Err() error {
+	n.errMu.Lock()
+	defer n.errMu.Unlock()
+	return n.err
+}
+
+// Dropped returns how many notifications were discarded because their
+// channel was full.
+func (n *Notifications) Dropped() uint64 {
+	return n.dropped.Load()
+}
+
+// setErr records the latest error.
+func (n *Notifications) setErr(err error) {
+	n.errMu.Lock()
+	n.err = err
+	n.errMu.Unlock()
+}
+
+// run connects, receives and reconnects until ctx is done.
+func (n *Notifications) run(ctx context.Context, endpoint string) {
+	defer close(n.blocks)
+	defer close(n.txs)
+
+	for ctx.Err() == nil {
+		sub, err := Dial(ctx, endpoint, TopicChainMain, TopicTxPoolAdd)
+		if err != nil {
+			n.setErr(err)
+			waitContext(ctx, reconnectInterval)
+			continue
+		}
+		n.setErr(nil)
+		stop := context.AfterFunc(ctx, func() { sub.Close() })
+		for {
+			frames, err := sub.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					n.setErr(err)
+				}
+				break
+			}
+			for _, frame := range frames {
+				if err := n.dispatch(frame); err != nil {
+					n.setErr(err)
+				}
+			}
+		}
+		stop()
+		sub.Close()
+	}
+}
+
+// dispatch decodes one "topic:json" message and delivers its events.
+func (n *Notifications) dispatch(msg []byte) error {
+	topic, body, ok := bytes.Cut(msg, []byte(":"))
+	if !ok {
+		return fmt.Errorf("malformed notification: no topic separator")
+	}
+	switch string(topic) {
+	case TopicChainMain:
+		var chain chainMain
+		if err := json.Unmarshal(body, &chain); err != nil {
+			return fmt.Errorf("decoding %s: %w", topic, err)
+		}
+		for i, id := range chain.IDs {
+			n.sendBlock(Block{Height: chain.FirstHeight + uint64(i), ID: id})
+		}
+	case TopicTxPoolAdd:
+		var txs []Tx
+		if err := json.Unmarshal(body, &txs); err != nil {
+			return fmt.Errorf("decoding %s: %w", topic, err)
+		}
+		for _, tx := range txs {
+			n.sendTx(tx)
+		}
+	}
+	return nil
+}
+
+// sendBlock delivers b without blocking, counting it as dropped if the
+// channel is full.
+func (n *Notifications) sendBlock(b Block) {
+	select {
+	case n.blocks <- b:
+	default:
+		n.dropped.Add(1)
+	}
+}
+
+// sendTx delivers tx without blocking, counting it as dropped if the
+// channel is full.
+func (n *Notifications) sendTx(tx Tx) {
+	select {
+	case n.txs <- tx:
+	default:
+		n.dropped.Add(1)
+	}
+}
+
+// waitContext sleeps for d or until ctx is done.
+func waitContext(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
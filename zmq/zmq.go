@@ -0,0 +1,209 @@
+// Package zmq implements a minimal ZeroMQ (ZMTP 3.0) SUB socket, sufficient
+// to consume monerod's --zmq-pub notifications without a cgo dependency on
+// libzmq. Only the NULL security mechanism and TCP transport are supported.
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Frame flag bits defined by ZMTP 3.0
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// maxFrameSize guards against corrupt length prefixes allocating huge buffers
+const maxFrameSize = 64 << 20
+
+// Subscriber is a connected ZMQ SUB socket.
+//
+// Fields:
+//   - conn: Underlying TCP connection
+//   - r: Buffered reader for incoming frames
+type Subscriber struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Dial connects to a ZMQ PUB endpoint and subscribes to the given topics.
+//
+// Parameters:
+//   - ctx: Context bounding the connection and handshake
+//   - endpoint: Publisher address, either "tcp://host:port" or "host:port"
+//   - topics: Topic prefixes to subscribe to, none subscribes to everything
+//
+// Returns:
+//   - *Subscriber: Connected subscriber
+//   - error: Dial, handshake or protocol errors
+//
+// Example:
+//
+//	sub, err := zmq.Dial(ctx, "tcp://127.0.0.1:18084", "json-minimal-chain_main")
+func Dial(ctx context.Context, endpoint string, topics ...string) (*Subscriber, error) {
+	addr := strings.TrimPrefix(endpoint, "tcp://")
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	s := &Subscriber{conn: conn, r: bufio.NewReader(conn)}
+	if err := handshake(conn, s.r, "SUB"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if len(topics) == 0 {
+		topics = []string{""}
+	}
+	for _, topic := range topics {
+		if err := writeFrame(conn, 0, append([]byte{1}, topic...)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	conn.SetDeadline(time.Time{})
+	return s, nil
+}
+
+// Recv blocks until the next message arrives and returns its frames.
+//
+// Returns:
+//   - [][]byte: Message frames, monerod publishes single-frame messages
+//   - error: Connection or protocol errors
+func (s *Subscriber) Recv() ([][]byte, error) {
+	var msg [][]byte
+	for {
+		flags, body, err := readFrame(s.r)
+		if err != nil {
+			return nil, err
+		}
+		if flags&flagCommand != 0 {
+			// Commands such as PING are not part of the message stream
+			continue
+		}
+		msg = append(msg, body)
+		if flags&flagMore == 0 {
+			return msg, nil
+		}
+	}
+}
+
+// SetDeadline sets a read deadline for subsequent Recv calls.
+func (s *Subscriber) SetDeadline(t time.Time) error {
+	return s.conn.SetDeadline(t)
+}
+
+// Close closes the connection, unblocking any pending Recv.
+func (s *Subscriber) Close() error {
+	return s.conn.Close()
+}
+
+// handshake performs the ZMTP 3.0 greeting and NULL mechanism READY exchange.
+func handshake(w io.Writer, r *bufio.Reader, socketType string) error {
+	greeting := make([]byte, 64)
+	greeting[0] = 0xff
+	greeting[9] = 0x7f
+	greeting[10] = 3 // major version
+	greeting[11] = 0 // minor version
+	copy(greeting[12:32], "NULL")
+	if _, err := w.Write(greeting); err != nil {
+		return err
+	}
+
+	peer := make([]byte, 64)
+	if _, err := io.ReadFull(r, peer); err != nil {
+		return fmt.Errorf("reading greeting: %w", err)
+	}
+	if peer[0] != 0xff || peer[9] != 0x7f {
+		return fmt.Errorf("peer is not a ZMTP endpoint")
+	}
+	if peer[10] < 3 {
+		return fmt.Errorf("unsupported ZMTP version %d.%d", peer[10], peer[11])
+	}
+	if mech := string(bytes.TrimRight(peer[12:32], "\x00")); mech != "NULL" {
+		return fmt.Errorf("unsupported security mechanism %q", mech)
+	}
+
+	if err := writeFrame(w, flagCommand, readyCommand(socketType)); err != nil {
+		return err
+	}
+	flags, body, err := readFrame(r)
+	if err != nil {
+		return fmt.Errorf("reading READY: %w", err)
+	}
+	if flags&flagCommand == 0 || len(body) < 6 || string(body[1:6]) != "READY" {
+		return fmt.Errorf("expected READY command from peer")
+	}
+	return nil
+}
+
+// readyCommand builds a READY command body advertising socketType.
+func readyCommand(socketType string) []byte {
+	var b bytes.Buffer
+	b.WriteByte(5)
+	b.WriteString("READY")
+	b.WriteByte(byte(len("Socket-Type")))
+	b.WriteString("Socket-Type")
+	binary.Write(&b, binary.BigEndian, uint32(len(socketType)))
+	b.WriteString(socketType)
+	return b.Bytes()
+}
+
+// writeFrame writes a single ZMTP frame.
+func writeFrame(w io.Writer, flags byte, body []byte) error {
+	var header []byte
+	if len(body) > 255 {
+		header = make([]byte, 9)
+		header[0] = flags | flagLong
+		binary.BigEndian.PutUint64(header[1:], uint64(len(body)))
+	} else {
+		header = []byte{flags, byte(len(body))}
+	}
+	if _, err := w.Write(append(header, body...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// readFrame reads a single ZMTP frame.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(buf[:])
+	} else {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds limit", size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
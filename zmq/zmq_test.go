@@ -0,0 +1,152 @@
+package zmq
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// servePub accepts one subscriber, records its subscription and publishes msg
+func servePub(t *testing.T, ln net.Listener, msg string, subscribed chan<- string) {
+	t.Helper()
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	if err := handshake(conn, r, "PUB"); err != nil {
+		t.Errorf("publisher handshake: %v", err)
+		return
+	}
+	_, body, err := readFrame(r)
+	if err != nil {
+		t.Errorf("reading subscription: %v", err)
+		return
+	}
+	subscribed <- string(body[1:])
+	// A PING command must be skipped by Recv
+	writeFrame(conn, flagCommand, []byte("\x04PING"))
+	writeFrame(conn, 0, []byte(msg))
+	time.Sleep(50 * time.Millisecond)
+}
+
+// TestSubscriber verifies handshake, subscription and message delivery
+func TestSubscriber(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	msg := "json-minimal-chain_main:" + strings.Repeat("x", 300)
+	subscribed := make(chan string, 1)
+	go servePub(t, ln, msg, subscribed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	sub, err := Dial(ctx, "tcp://"+ln.Addr().String(), "json-minimal-chain_main")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer sub.Close()
+
+	if topic := <-subscribed; topic != "json-minimal-chain_main" {
+		t.Errorf("subscribed to %q", topic)
+	}
+	sub.SetDeadline(time.Now().Add(2 * time.Second))
+	frames, err := sub.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	if len(frames) != 1 || string(frames[0]) != msg {
+		t.Errorf("Recv() = %q", frames)
+	}
+}
+
+// TestDialNotZMTP verifies that non-ZMQ peers are rejected
+func TestDialNotZMTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(strings.Repeat("HTTP/1.1 400 Bad Request\r\n", 4)))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := Dial(ctx, ln.Addr().String()); err == nil {
+		t.Error("Dial() should fail against a non-ZMTP peer")
+	}
+}
+
+// TestWatch verifies that block and transaction pool messages are decoded
+// into their channels and that the channels close with the context
+func TestWatch(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		if err := handshake(conn, r, "PUB"); err != nil {
+			t.Errorf("publisher handshake: %v", err)
+			return
+		}
+		for i := 0; i < 2; i++ {
+			if _, _, err := readFrame(r); err != nil {
+				t.Errorf("reading subscription: %v", err)
+				return
+			}
+		}
+		writeFrame(conn, 0, []byte(TopicChainMain+`:{"first_height":100,"first_prev_id":"aa","ids":["bb","cc"]}`))
+		writeFrame(conn, 0, []byte(TopicTxPoolAdd+`:[{"id":"dd","blob_size":1500,"weight":1500,"fee":30000}]`))
+		time.Sleep(time.Second)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	n := Watch(ctx, "tcp://"+ln.Addr().String())
+
+	for _, want := range []Block{{Height: 100, ID: "bb"}, {Height: 101, ID: "cc"}} {
+		select {
+		case got := <-n.Blocks:
+			if got != want {
+				t.Errorf("block = %+v, want %+v", got, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for block %+v, last error %v", want, n.Err())
+		}
+	}
+	select {
+	case got := <-n.Txs:
+		if want := (Tx{ID: "dd", BlobSize: 1500, Weight: 1500, Fee: 30000}); got != want {
+			t.Errorf("tx = %+v, want %+v", got, want)
+		}
+	case <-ctx.Done():
+		t.Fatalf("timed out waiting for tx, last error %v", n.Err())
+	}
+
+	cancel()
+	for range n.Blocks {
+	}
+	for range n.Txs {
+	}
+}